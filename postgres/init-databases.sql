@@ -0,0 +1,6 @@
+-- Every service owns its database and migrates it on start, none of them
+-- references tables of another.
+CREATE DATABASE user_service;
+CREATE DATABASE product_service;
+CREATE DATABASE payment_service;
+CREATE DATABASE order_service;
@@ -0,0 +1,164 @@
+package handler
+
+import (
+	"api-gateway-service/pkg/response"
+	"github.com/gin-gonic/gin"
+	"io"
+	"net/http"
+)
+
+// ListAddresses godoc
+// @Summary List user addresses
+// @Description List the address book of a user. A user reads its own addresses, those of another user require the users:read:any permission.
+// @Tags addresses
+// @Accept  json
+// @Produce  json
+// @Security BearerAuth
+// @Param id path string true "User ID"
+// @Success 200 {object} response.Response
+// @Failure 401 {object} response.Response
+// @Failure 403 {object} response.Response
+// @Failure 404 {object} response.Response
+// @Failure 500 {object} response.Response
+// @Router /users/{id}/addresses [get]
+func (u *UserHandler) ListAddresses(c *gin.Context) {
+	u.forwardAddress(c, http.MethodGet, "/"+c.Param("id")+"/addresses/", nil)
+}
+
+// CreateAddress godoc
+// @Summary Add a user address
+// @Description Add an address to the address book of a user. A user manages its own addresses, those of another user require the users:write permission.
+// @Tags addresses
+// @Accept  json
+// @Produce  json
+// @Security BearerAuth
+// @Param id path string true "User ID"
+// @Param address body address.Request true "Address data"
+// @Success 201 {object} response.Response
+// @Failure 400 {object} response.Response
+// @Failure 401 {object} response.Response
+// @Failure 403 {object} response.Response
+// @Failure 404 {object} response.Response
+// @Failure 422 {object} response.Response
+// @Failure 500 {object} response.Response
+// @Router /users/{id}/addresses [post]
+func (u *UserHandler) CreateAddress(c *gin.Context) {
+	u.forwardAddress(c, http.MethodPost, "/"+c.Param("id")+"/addresses/", c.Request.Body)
+}
+
+// GetDefaultAddress godoc
+// @Summary Get default user address
+// @Description Get the default address of a user. A user reads its own addresses, those of another user require the users:read:any permission.
+// @Tags addresses
+// @Accept  json
+// @Produce  json
+// @Security BearerAuth
+// @Param id path string true "User ID"
+// @Success 200 {object} response.Response
+// @Failure 401 {object} response.Response
+// @Failure 403 {object} response.Response
+// @Failure 404 {object} response.Response
+// @Failure 500 {object} response.Response
+// @Router /users/{id}/addresses/default [get]
+func (u *UserHandler) GetDefaultAddress(c *gin.Context) {
+	u.forwardAddress(c, http.MethodGet, "/"+c.Param("id")+"/addresses/default", nil)
+}
+
+// GetAddress godoc
+// @Summary Get user address by ID
+// @Description Get an address of a user by its ID. A user reads its own addresses, those of another user require the users:read:any permission.
+// @Tags addresses
+// @Accept  json
+// @Produce  json
+// @Security BearerAuth
+// @Param id path string true "User ID"
+// @Param address_id path string true "Address ID"
+// @Success 200 {object} response.Response
+// @Failure 401 {object} response.Response
+// @Failure 403 {object} response.Response
+// @Failure 404 {object} response.Response
+// @Failure 500 {object} response.Response
+// @Router /users/{id}/addresses/{address_id} [get]
+func (u *UserHandler) GetAddress(c *gin.Context) {
+	u.forwardAddress(c, http.MethodGet, "/"+c.Param("id")+"/addresses/"+c.Param("address_id"), nil)
+}
+
+// UpdateAddress godoc
+// @Summary Update user address by ID
+// @Description Replace an address of a user by its ID. A user manages its own addresses, those of another user require the users:write permission.
+// @Tags addresses
+// @Accept  json
+// @Produce  json
+// @Security BearerAuth
+// @Param id path string true "User ID"
+// @Param address_id path string true "Address ID"
+// @Param address body address.Request true "Address data"
+// @Success 200 {object} response.Response
+// @Failure 400 {object} response.Response
+// @Failure 401 {object} response.Response
+// @Failure 403 {object} response.Response
+// @Failure 404 {object} response.Response
+// @Failure 422 {object} response.Response
+// @Failure 500 {object} response.Response
+// @Router /users/{id}/addresses/{address_id} [put]
+func (u *UserHandler) UpdateAddress(c *gin.Context) {
+	u.forwardAddress(c, http.MethodPut, "/"+c.Param("id")+"/addresses/"+c.Param("address_id"), c.Request.Body)
+}
+
+// SetDefaultAddress godoc
+// @Summary Set default user address
+// @Description Make an address the default address of a user. A user manages its own addresses, those of another user require the users:write permission.
+// @Tags addresses
+// @Accept  json
+// @Produce  json
+// @Security BearerAuth
+// @Param id path string true "User ID"
+// @Param address_id path string true "Address ID"
+// @Success 200 {object} response.Response
+// @Failure 401 {object} response.Response
+// @Failure 403 {object} response.Response
+// @Failure 404 {object} response.Response
+// @Failure 500 {object} response.Response
+// @Router /users/{id}/addresses/{address_id}/default [put]
+func (u *UserHandler) SetDefaultAddress(c *gin.Context) {
+	u.forwardAddress(c, http.MethodPut, "/"+c.Param("id")+"/addresses/"+c.Param("address_id")+"/default", nil)
+}
+
+// DeleteAddress godoc
+// @Summary Delete user address by ID
+// @Description Delete an address of a user by its ID. A user manages its own addresses, those of another user require the users:write permission.
+// @Tags addresses
+// @Accept  json
+// @Produce  json
+// @Security BearerAuth
+// @Param id path string true "User ID"
+// @Param address_id path string true "Address ID"
+// @Success 200 {object} response.Response
+// @Failure 401 {object} response.Response
+// @Failure 403 {object} response.Response
+// @Failure 404 {object} response.Response
+// @Failure 500 {object} response.Response
+// @Router /users/{id}/addresses/{address_id} [delete]
+func (u *UserHandler) DeleteAddress(c *gin.Context) {
+	u.forwardAddress(c, http.MethodDelete, "/"+c.Param("id")+"/addresses/"+c.Param("address_id"), nil)
+}
+
+func (u *UserHandler) forwardAddress(c *gin.Context, method, path string, body io.Reader) {
+	req, err := http.NewRequestWithContext(c.Request.Context(), method, u.userUrl+path, body)
+	if err != nil {
+		upstreamError(c, err)
+		return
+	}
+	resp, err := upstreamClient.Do(req)
+	if err != nil {
+		upstreamError(c, err)
+		return
+	}
+	defer resp.Body.Close()
+	res, err := response.ParseResponse(resp)
+	if err != nil {
+		upstreamError(c, err)
+		return
+	}
+	c.JSON(resp.StatusCode, res)
+}
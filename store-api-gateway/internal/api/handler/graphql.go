@@ -0,0 +1,97 @@
+package handler
+
+import (
+	"api-gateway-service/internal/graph"
+	"encoding/json"
+	"github.com/gin-gonic/gin"
+	"github.com/graphql-go/graphql"
+	"net/http"
+)
+
+// GraphQLHandler serves the GraphQL schema over the services. Answers use
+// the GraphQL response shape, {"data": ..., "errors": [...]}, rather than
+// the response envelope of the REST routes, so GraphQL clients work as is.
+type GraphQLHandler struct {
+	schema   graphql.Schema
+	upstream *graph.Upstream
+}
+
+func NewGraphQLHandler(upstream *graph.Upstream) (*GraphQLHandler, error) {
+	schema, err := graph.NewSchema(upstream)
+	if err != nil {
+		return nil, err
+	}
+	return &GraphQLHandler{schema, upstream}, nil
+}
+
+type graphQLRequest struct {
+	Query         string         `json:"query" example:"{ order(id: \"1\") { status items { quantity product { title price { amount currency } } } payments { status } } }"`
+	Variables     map[string]any `json:"variables"`
+	OperationName string         `json:"operationName"`
+}
+
+// graphQLResponse documents the shape of graphql.Result.
+type graphQLResponse struct {
+	Data   any              `json:"data,omitempty"`
+	Errors []graphQLMessage `json:"errors,omitempty"`
+}
+
+type graphQLMessage struct {
+	Message string `json:"message"`
+}
+
+// Query godoc
+// @Summary GraphQL query
+// @Description Runs a GraphQL query over users, products, orders and payments. Nested fields are batched per request; queries nested deeper than 10 fields or estimated above 5000 fields are rejected. GET takes query, variables and operationName as query parameters.
+// @Tags graphql
+// @Accept  json
+// @Produce  json
+// @Param query body graphQLRequest true "GraphQL request"
+// @Success 200 {object} graphQLResponse
+// @Failure 400 {object} graphQLResponse
+// @Router /graphql [post]
+func (g *GraphQLHandler) Query(c *gin.Context) {
+	var req graphQLRequest
+	if c.Request.Method == http.MethodGet {
+		req.Query = c.Query("query")
+		req.OperationName = c.Query("operationName")
+		if variables := c.Query("variables"); variables != "" {
+			if err := json.Unmarshal([]byte(variables), &req.Variables); err != nil {
+				graphQLError(c, "invalid variables: "+err.Error())
+				return
+			}
+		}
+	} else if err := c.BindJSON(&req); err != nil {
+		graphQLError(c, "invalid request: "+err.Error())
+		return
+	}
+	if req.Query == "" {
+		graphQLError(c, "query is required")
+		return
+	}
+	if err := graph.CheckLimits(g.schema, req.Query, req.OperationName); err != nil {
+		graphQLError(c, err.Error())
+		return
+	}
+
+	res := graphql.Do(graphql.Params{
+		Schema:         g.schema,
+		RequestString:  req.Query,
+		VariableValues: req.Variables,
+		OperationName:  req.OperationName,
+		Context:        graph.WithLoaders(c.Request.Context(), graph.NewLoaders(g.upstream)),
+	})
+	// a query that did not run at all is the client's fault, errors of
+	// single fields come back next to the data that did resolve
+	code := http.StatusOK
+	if res.Data == nil && res.HasErrors() {
+		code = http.StatusBadRequest
+	}
+	c.JSON(code, res)
+}
+
+func graphQLError(c *gin.Context, message string) {
+	c.JSON(http.StatusBadRequest, graphQLResponse{
+		Errors: []graphQLMessage{{message}},
+	})
+}
@@ -0,0 +1,27 @@
+package handler
+
+import (
+	"api-gateway-service/pkg/health"
+	"github.com/gin-gonic/gin"
+)
+
+type HealthHandler struct {
+	checker *health.Checker
+}
+
+func NewHealthHandler(checker *health.Checker) *HealthHandler {
+	return &HealthHandler{
+		checker: checker,
+	}
+}
+
+// Live answers the liveness probe.
+func (hh *HealthHandler) Live(c *gin.Context) {
+	hh.checker.Live(c)
+}
+
+// Ready answers the readiness probe, the gateway is ready when every
+// service it routes to is.
+func (hh *HealthHandler) Ready(c *gin.Context) {
+	hh.checker.Ready(c)
+}
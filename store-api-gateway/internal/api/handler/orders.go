@@ -1,46 +1,57 @@
 package handler
 
 import (
+	"api-gateway-service/pkg/pb/orderpb"
 	"api-gateway-service/pkg/response"
 	"github.com/gin-gonic/gin"
 	"net/http"
 )
 
+// OrderHandler proxies to the REST API of the service. When client is set
+// the calls its gRPC API has go over gRPC instead.
 type OrderHandler struct {
 	orderUrl string
+	client   orderpb.OrderServiceClient
 }
 
-func NewOrderHandler(orderUrl string) *OrderHandler {
-	return &OrderHandler{orderUrl}
+func NewOrderHandler(orderUrl string, client orderpb.OrderServiceClient) *OrderHandler {
+	return &OrderHandler{orderUrl, client}
 }
 
 // CreateOrder godoc
 // @Summary Create order
-// @Description Create order
+// @Description Create order. A customer orders for itself, ordering for another user requires the orders:write permission.
 // @Tags orders
 // @Accept  json
 // @Produce  json
+// @Security BearerAuth
 // @Param order body order.Request true "Order data"
 // @Success 201 {object} response.Response
 // @Failure 400 {object} response.Response
+// @Failure 401 {object} response.Response
+// @Failure 403 {object} response.Response
+// @Failure 422 {object} response.Response
 // @Failure 500 {object} response.Response
 // @Router /orders [post]
 func (o *OrderHandler) CreateOrder(c *gin.Context) {
-	req, err := http.NewRequest("POST", o.orderUrl, c.Request.Body)
+	if o.client != nil {
+		o.createOrderRPC(c)
+		return
+	}
+	req, err := http.NewRequestWithContext(c.Request.Context(), "POST", o.orderUrl, c.Request.Body)
 	if err != nil {
-		c.JSON(500, gin.H{"error": err.Error()})
+		upstreamError(c, err)
 		return
 	}
-	client := &http.Client{}
-	resp, err := client.Do(req)
+	resp, err := upstreamClient.Do(req)
 	if err != nil {
-		c.JSON(500, gin.H{"error": err.Error()})
+		upstreamError(c, err)
 		return
 	}
 	defer resp.Body.Close()
 	res, err := response.ParseResponse(resp)
 	if err != nil {
-		c.JSON(500, gin.H{"error": err.Error()})
+		upstreamError(c, err)
 		return
 	}
 	c.JSON(resp.StatusCode, res)
@@ -48,29 +59,35 @@ func (o *OrderHandler) CreateOrder(c *gin.Context) {
 
 // ListOrders godoc
 // @Summary List all orders
-// @Description List all orders
+// @Description List all orders. Requires the orders:read:any permission.
 // @Tags orders
 // @Accept  json
 // @Produce  json
+// @Security BearerAuth
 // @Success 200 {object} response.Response
+// @Failure 401 {object} response.Response
+// @Failure 403 {object} response.Response
 // @Failure 500 {object} response.Response
 // @Router /orders [get]
 func (o *OrderHandler) ListOrders(c *gin.Context) {
-	req, err := http.NewRequest("GET", o.orderUrl, nil)
+	if o.client != nil {
+		o.listOrdersRPC(c)
+		return
+	}
+	req, err := http.NewRequestWithContext(c.Request.Context(), "GET", o.orderUrl, nil)
 	if err != nil {
-		c.JSON(500, gin.H{"error": err.Error()})
+		upstreamError(c, err)
 		return
 	}
-	client := &http.Client{}
-	resp, err := client.Do(req)
+	resp, err := upstreamClient.Do(req)
 	if err != nil {
-		c.JSON(500, gin.H{"error": err.Error()})
+		upstreamError(c, err)
 		return
 	}
 	defer resp.Body.Close()
 	res, err := response.ParseResponse(resp)
 	if err != nil {
-		c.JSON(500, gin.H{"error": err.Error()})
+		upstreamError(c, err)
 		return
 	}
 	c.JSON(resp.StatusCode, res)
@@ -78,31 +95,73 @@ func (o *OrderHandler) ListOrders(c *gin.Context) {
 
 // GetOrder godoc
 // @Summary Get order by ID
-// @Description Get order by ID
+// @Description Get order by ID. A customer reads its own orders, any other requires the orders:read:any permission.
 // @Tags orders
 // @Accept  json
 // @Produce  json
+// @Security BearerAuth
 // @Param id path string true "Order ID"
 // @Success 200 {object} response.Response
+// @Header 200 {string} ETag "Version of the order, only over REST"
 // @Failure 400 {object} response.Response
+// @Failure 401 {object} response.Response
+// @Failure 403 {object} response.Response
 // @Failure 500 {object} response.Response
 // @Router /orders/{id} [get]
 func (o *OrderHandler) GetOrder(c *gin.Context) {
-	req, err := http.NewRequest("GET", o.orderUrl+"/"+c.Param("id"), nil)
+	if o.client != nil {
+		o.getOrderRPC(c)
+		return
+	}
+	req, err := http.NewRequestWithContext(c.Request.Context(), "GET", o.orderUrl+"/"+c.Param("id"), nil)
 	if err != nil {
-		c.JSON(500, gin.H{"error": err.Error()})
+		upstreamError(c, err)
 		return
 	}
-	client := &http.Client{}
-	resp, err := client.Do(req)
+	resp, err := upstreamClient.Do(req)
 	if err != nil {
-		c.JSON(500, gin.H{"error": err.Error()})
+		upstreamError(c, err)
 		return
 	}
 	defer resp.Body.Close()
 	res, err := response.ParseResponse(resp)
 	if err != nil {
-		c.JSON(500, gin.H{"error": err.Error()})
+		upstreamError(c, err)
+		return
+	}
+	copyETag(c, resp)
+	c.JSON(resp.StatusCode, res)
+}
+
+// GetInvoice godoc
+// @Summary Get order invoice
+// @Description Get the subtotal, discount, tax breakdown and total of an order. A customer reads its own orders, any other requires the orders:read:any permission.
+// @Tags orders
+// @Accept  json
+// @Produce  json
+// @Security BearerAuth
+// @Param id path string true "Order ID"
+// @Success 200 {object} response.Response
+// @Failure 401 {object} response.Response
+// @Failure 403 {object} response.Response
+// @Failure 404 {object} response.Response
+// @Failure 500 {object} response.Response
+// @Router /orders/{id}/invoice [get]
+func (o *OrderHandler) GetInvoice(c *gin.Context) {
+	req, err := http.NewRequestWithContext(c.Request.Context(), "GET", o.orderUrl+"/"+c.Param("id")+"/invoice", nil)
+	if err != nil {
+		upstreamError(c, err)
+		return
+	}
+	resp, err := upstreamClient.Do(req)
+	if err != nil {
+		upstreamError(c, err)
+		return
+	}
+	defer resp.Body.Close()
+	res, err := response.ParseResponse(resp)
+	if err != nil {
+		upstreamError(c, err)
 		return
 	}
 	c.JSON(resp.StatusCode, res)
@@ -110,97 +169,202 @@ func (o *OrderHandler) GetOrder(c *gin.Context) {
 
 // UpdateOrder godoc
 // @Summary Update order by ID
-// @Description Update order by ID
+// @Description Update order by ID. Requires the orders:write permission.
 // @Tags orders
 // @Accept  json
 // @Produce  json
+// @Security BearerAuth
 // @Param id path string true "Order ID"
+// @Param If-Match header string false "ETag of the order"
 // @Param order body order.Request true "Order data"
 // @Success 200 {object} response.Response
+// @Header 200 {string} ETag "Version of the order"
 // @Failure 400 {object} response.Response
+// @Failure 401 {object} response.Response
+// @Failure 403 {object} response.Response
+// @Failure 412 {object} response.Response
+// @Failure 422 {object} response.Response
 // @Failure 500 {object} response.Response
 // @Router /orders/{id} [put]
 func (o *OrderHandler) UpdateOrder(c *gin.Context) {
-	req, err := http.NewRequest("PUT", o.orderUrl+"/"+c.Param("id"), c.Request.Body)
+	// the gRPC API has no versions, a conditional update goes over REST
+	if o.client != nil && c.GetHeader("If-Match") == "" {
+		o.updateOrderRPC(c)
+		return
+	}
+	req, err := http.NewRequestWithContext(c.Request.Context(), "PUT", o.orderUrl+"/"+c.Param("id"), c.Request.Body)
 	if err != nil {
-		c.JSON(500, gin.H{"error": err.Error()})
+		upstreamError(c, err)
 		return
 	}
-	client := &http.Client{}
-	resp, err := client.Do(req)
+	forwardIfMatch(c, req)
+	resp, err := upstreamClient.Do(req)
 	if err != nil {
-		c.JSON(500, gin.H{"error": err.Error()})
+		upstreamError(c, err)
 		return
 	}
 	defer resp.Body.Close()
 	res, err := response.ParseResponse(resp)
 	if err != nil {
-		c.JSON(500, gin.H{"error": err.Error()})
+		upstreamError(c, err)
 		return
 	}
+	copyETag(c, resp)
+	c.JSON(resp.StatusCode, res)
+}
+
+// PatchOrder godoc
+// @Summary Patch order by ID
+// @Description Change the fields of a order given in a JSON merge patch. Patches always go over REST. Requires the orders:write permission.
+// @Tags orders
+// @Accept  json
+// @Produce  json
+// @Security BearerAuth
+// @Param id path string true "Order ID"
+// @Param If-Match header string false "ETag of the order"
+// @Param order body order.Patch true "Order patch"
+// @Success 200 {object} response.Response
+// @Header 200 {string} ETag "Version of the order"
+// @Failure 400 {object} response.Response
+// @Failure 401 {object} response.Response
+// @Failure 403 {object} response.Response
+// @Failure 404 {object} response.Response
+// @Failure 412 {object} response.Response
+// @Failure 422 {object} response.Response
+// @Failure 500 {object} response.Response
+// @Router /orders/{id} [patch]
+func (o *OrderHandler) PatchOrder(c *gin.Context) {
+	req, err := http.NewRequestWithContext(c.Request.Context(), http.MethodPatch, o.orderUrl+"/"+c.Param("id"), c.Request.Body)
+	if err != nil {
+		upstreamError(c, err)
+		return
+	}
+	req.Header.Set("Content-Type", c.GetHeader("Content-Type"))
+	forwardIfMatch(c, req)
+	resp, err := upstreamClient.Do(req)
+	if err != nil {
+		upstreamError(c, err)
+		return
+	}
+	defer resp.Body.Close()
+	res, err := response.ParseResponse(resp)
+	if err != nil {
+		upstreamError(c, err)
+		return
+	}
+	copyETag(c, resp)
 	c.JSON(resp.StatusCode, res)
 }
 
 // DeleteOrder godoc
 // @Summary Delete order by ID
-// @Description Delete order by ID
+// @Description Delete order by ID. Requires the orders:write permission.
 // @Tags orders
 // @Accept  json
 // @Produce  json
+// @Security BearerAuth
 // @Param id path string true "Order ID"
 // @Success 204 {object} response.Response
 // @Failure 400 {object} response.Response
+// @Failure 401 {object} response.Response
+// @Failure 403 {object} response.Response
 // @Failure 500 {object} response.Response
 // @Router /orders/{id} [delete]
 func (o *OrderHandler) DeleteOrder(c *gin.Context) {
-	req, err := http.NewRequest("DELETE", o.orderUrl+"/"+c.Param("id"), nil)
+	if o.client != nil {
+		o.deleteOrderRPC(c)
+		return
+	}
+	req, err := http.NewRequestWithContext(c.Request.Context(), "DELETE", o.orderUrl+"/"+c.Param("id"), nil)
+	if err != nil {
+		upstreamError(c, err)
+		return
+	}
+	resp, err := upstreamClient.Do(req)
+	if err != nil {
+		upstreamError(c, err)
+		return
+	}
+	defer resp.Body.Close()
+	res, err := response.ParseResponse(resp)
+	if err != nil {
+		upstreamError(c, err)
+		return
+	}
+	c.JSON(resp.StatusCode, res)
+}
+
+// RestoreOrder godoc
+// @Summary Restore a deleted order
+// @Description Bring a deleted order back, for admins
+// @Tags orders
+// @Accept  json
+// @Produce  json
+// @Security BearerAuth
+// @Param id path string true "Order ID"
+// @Success 200 {object} response.Response
+// @Header 200 {string} ETag "Version of the order"
+// @Failure 401 {object} response.Response
+// @Failure 403 {object} response.Response
+// @Failure 404 {object} response.Response
+// @Failure 409 {object} response.Response
+// @Failure 500 {object} response.Response
+// @Router /orders/{id}/restore [post]
+func (o *OrderHandler) RestoreOrder(c *gin.Context) {
+	req, err := http.NewRequestWithContext(c.Request.Context(), http.MethodPost, o.orderUrl+"/"+c.Param("id")+"/restore", nil)
 	if err != nil {
-		c.JSON(500, gin.H{"error": err.Error()})
+		upstreamError(c, err)
 		return
 	}
-	client := &http.Client{}
-	resp, err := client.Do(req)
+	resp, err := upstreamClient.Do(req)
 	if err != nil {
-		c.JSON(500, gin.H{"error": err.Error()})
+		upstreamError(c, err)
 		return
 	}
 	defer resp.Body.Close()
 	res, err := response.ParseResponse(resp)
 	if err != nil {
-		c.JSON(500, gin.H{"error": err.Error()})
+		upstreamError(c, err)
 		return
 	}
+	copyETag(c, resp)
 	c.JSON(resp.StatusCode, res)
 }
 
 // SearchOrders godoc
 // @Summary Search orders
-// @Description Search orders
+// @Description Search orders. Requires the orders:read:any permission.
 // @Tags orders
 // @Accept  json
 // @Produce  json
+// @Security BearerAuth
 // @Param filter query string true "Filter"
 // @Param value query string true "Value"
 // @Success 200 {object} response.Response
 // @Failure 400 {object} response.Response
+// @Failure 401 {object} response.Response
+// @Failure 403 {object} response.Response
 // @Failure 500 {object} response.Response
 // @Router /orders/search [get]
 func (o *OrderHandler) SearchOrders(c *gin.Context) {
-	req, err := http.NewRequest("GET", o.orderUrl+"/search?filter="+c.Query("filter")+"&value="+c.Query("value"), nil)
+	if o.client != nil {
+		o.searchOrdersRPC(c)
+		return
+	}
+	req, err := http.NewRequestWithContext(c.Request.Context(), "GET", o.orderUrl+"/search?filter="+c.Query("filter")+"&value="+c.Query("value"), nil)
 	if err != nil {
-		c.JSON(500, gin.H{"error": err.Error()})
+		upstreamError(c, err)
 		return
 	}
-	client := &http.Client{}
-	resp, err := client.Do(req)
+	resp, err := upstreamClient.Do(req)
 	if err != nil {
-		c.JSON(500, gin.H{"error": err.Error()})
+		upstreamError(c, err)
 		return
 	}
 	defer resp.Body.Close()
 	res, err := response.ParseResponse(resp)
 	if err != nil {
-		c.JSON(500, gin.H{"error": err.Error()})
+		upstreamError(c, err)
 		return
 	}
 	c.JSON(resp.StatusCode, res)
@@ -0,0 +1,108 @@
+package handler
+
+import (
+	"api-gateway-service/internal/domain/order"
+	"api-gateway-service/pkg/apperror"
+	"api-gateway-service/pkg/pb/orderpb"
+	"api-gateway-service/pkg/response"
+	"github.com/gin-gonic/gin"
+	"net/http"
+)
+
+func (o *OrderHandler) createOrderRPC(c *gin.Context) {
+	req := order.Request{}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(apperror.InvalidBody(err))
+		return
+	}
+
+	ctx, cancel := upstreamContext(c)
+	defer cancel()
+	res, err := o.client.CreateOrder(ctx, &orderpb.CreateOrderRequest{Order: orderInput(req)})
+	if err != nil {
+		rpcError(c, err)
+		return
+	}
+	successRes := response.ClientResponse(http.StatusCreated, "the order was successfully created", res.GetId(), nil)
+	c.JSON(http.StatusCreated, successRes)
+}
+
+func (o *OrderHandler) listOrdersRPC(c *gin.Context) {
+	ctx, cancel := upstreamContext(c)
+	defer cancel()
+	res, err := o.client.ListOrders(ctx, &orderpb.ListOrdersRequest{})
+	if err != nil {
+		rpcError(c, err)
+		return
+	}
+	successRes := response.ClientResponse(http.StatusOK, "the orders list", order.ParseFromProtos(res.GetOrders()), nil)
+	c.JSON(http.StatusOK, successRes)
+}
+
+func (o *OrderHandler) getOrderRPC(c *gin.Context) {
+	ctx, cancel := upstreamContext(c)
+	defer cancel()
+	res, err := o.client.GetOrder(ctx, &orderpb.GetOrderRequest{Id: c.Param("id")})
+	if err != nil {
+		rpcError(c, err)
+		return
+	}
+	successRes := response.ClientResponse(http.StatusOK, "the order details", order.ParseFromProto(res), nil)
+	c.JSON(http.StatusOK, successRes)
+}
+
+func (o *OrderHandler) updateOrderRPC(c *gin.Context) {
+	req := order.Request{}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(apperror.InvalidBody(err))
+		return
+	}
+
+	ctx, cancel := upstreamContext(c)
+	defer cancel()
+	_, err := o.client.UpdateOrder(ctx, &orderpb.UpdateOrderRequest{Id: c.Param("id"), Order: orderInput(req)})
+	if err != nil {
+		rpcError(c, err)
+		return
+	}
+	successRes := response.ClientResponse(http.StatusOK, "the order was successfully updated", nil, nil)
+	c.JSON(http.StatusOK, successRes)
+}
+
+func (o *OrderHandler) deleteOrderRPC(c *gin.Context) {
+	ctx, cancel := upstreamContext(c)
+	defer cancel()
+	if _, err := o.client.DeleteOrder(ctx, &orderpb.DeleteOrderRequest{Id: c.Param("id")}); err != nil {
+		rpcError(c, err)
+		return
+	}
+	successRes := response.ClientResponse(http.StatusOK, "the order was successfully deleted", nil, nil)
+	c.JSON(http.StatusOK, successRes)
+}
+
+func (o *OrderHandler) searchOrdersRPC(c *gin.Context) {
+	ctx, cancel := upstreamContext(c)
+	defer cancel()
+	res, err := o.client.SearchOrders(ctx, &orderpb.SearchOrdersRequest{Filter: c.Query("filter"), Value: c.Query("value")})
+	if err != nil {
+		rpcError(c, err)
+		return
+	}
+	successRes := response.ClientResponse(http.StatusOK, "the orders list", order.ParseFromProtos(res.GetOrders()), nil)
+	c.JSON(http.StatusOK, successRes)
+}
+
+func orderInput(req order.Request) *orderpb.OrderInput {
+	return &orderpb.OrderInput{
+		UserId:            req.UserID,
+		ProductId:         req.ProductID,
+		Pricing:           &orderpb.Money{Amount: req.Pricing.Decimal(), Currency: req.Pricing.Currency},
+		Currency:          req.Currency,
+		PromoCodes:        req.PromoCodes,
+		Country:           req.Country,
+		Region:            req.Region,
+		ShippingAddressId: req.ShippingAddressID,
+		ShippingMethod:    req.ShippingMethod,
+		Status:            req.Status,
+	}
+}
@@ -1,46 +1,57 @@
 package handler
 
 import (
+	"api-gateway-service/pkg/pb/paymentpb"
 	"api-gateway-service/pkg/response"
 	"github.com/gin-gonic/gin"
 	"net/http"
 )
 
+// PaymentHandler proxies to the REST API of the service. When client is set
+// the calls its gRPC API has go over gRPC instead.
 type PaymentHandler struct {
 	paymentUrl string
+	client     paymentpb.PaymentServiceClient
 }
 
-func NewPaymentHandler(paymentUrl string) *PaymentHandler {
-	return &PaymentHandler{paymentUrl}
+func NewPaymentHandler(paymentUrl string, client paymentpb.PaymentServiceClient) *PaymentHandler {
+	return &PaymentHandler{paymentUrl, client}
 }
 
 // CreatePayment godoc
 // @Summary Create payment
-// @Description Create payment
+// @Description Create payment. A customer pays for itself, paying for another user requires the payments:write permission.
 // @Tags payments
 // @Accept  json
 // @Produce  json
+// @Security BearerAuth
 // @Param payment body payment.Request true "Payment data"
 // @Success 201 {object} response.Response
 // @Failure 400 {object} response.Response
+// @Failure 401 {object} response.Response
+// @Failure 403 {object} response.Response
+// @Failure 422 {object} response.Response
 // @Failure 500 {object} response.Response
 // @Router /payments [post]
 func (p *PaymentHandler) CreatePayment(c *gin.Context) {
-	req, err := http.NewRequest("POST", p.paymentUrl, c.Request.Body)
+	if p.client != nil {
+		p.createPaymentRPC(c)
+		return
+	}
+	req, err := http.NewRequestWithContext(c.Request.Context(), "POST", p.paymentUrl, c.Request.Body)
 	if err != nil {
-		c.JSON(500, gin.H{"error": err.Error()})
+		upstreamError(c, err)
 		return
 	}
-	client := &http.Client{}
-	resp, err := client.Do(req)
+	resp, err := upstreamClient.Do(req)
 	if err != nil {
-		c.JSON(500, gin.H{"error": err.Error()})
+		upstreamError(c, err)
 		return
 	}
 	defer resp.Body.Close()
 	res, err := response.ParseResponse(resp)
 	if err != nil {
-		c.JSON(500, gin.H{"error": err.Error()})
+		upstreamError(c, err)
 		return
 	}
 	c.JSON(resp.StatusCode, res)
@@ -48,29 +59,35 @@ func (p *PaymentHandler) CreatePayment(c *gin.Context) {
 
 // ListPayments godoc
 // @Summary List all payments
-// @Description List all payments
+// @Description List all payments. Requires the payments:read:any permission.
 // @Tags payments
 // @Accept  json
 // @Produce  json
+// @Security BearerAuth
 // @Success 200 {object} response.Response
+// @Failure 401 {object} response.Response
+// @Failure 403 {object} response.Response
 // @Failure 500 {object} response.Response
 // @Router /payments [get]
 func (p *PaymentHandler) ListPayments(c *gin.Context) {
-	req, err := http.NewRequest("GET", p.paymentUrl, nil)
+	if p.client != nil {
+		p.listPaymentsRPC(c)
+		return
+	}
+	req, err := http.NewRequestWithContext(c.Request.Context(), "GET", p.paymentUrl, nil)
 	if err != nil {
-		c.JSON(500, gin.H{"error": err.Error()})
+		upstreamError(c, err)
 		return
 	}
-	client := &http.Client{}
-	resp, err := client.Do(req)
+	resp, err := upstreamClient.Do(req)
 	if err != nil {
-		c.JSON(500, gin.H{"error": err.Error()})
+		upstreamError(c, err)
 		return
 	}
 	defer resp.Body.Close()
 	res, err := response.ParseResponse(resp)
 	if err != nil {
-		c.JSON(500, gin.H{"error": err.Error()})
+		upstreamError(c, err)
 		return
 	}
 	c.JSON(resp.StatusCode, res)
@@ -78,128 +95,280 @@ func (p *PaymentHandler) ListPayments(c *gin.Context) {
 
 // GetPayment godoc
 // @Summary Get payment
-// @Description Get payment
+// @Description Get payment. A customer reads its own payments, any other requires the payments:read:any permission.
 // @Tags payments
 // @Accept  json
 // @Produce  json
+// @Security BearerAuth
 // @Param id path string true "Payment ID"
 // @Success 200 {object} response.Response
+// @Header 200 {string} ETag "Version of the payment, only over REST"
 // @Failure 400 {object} response.Response
+// @Failure 401 {object} response.Response
+// @Failure 403 {object} response.Response
 // @Failure 500 {object} response.Response
 // @Router /payments/{id} [get]
 func (p *PaymentHandler) GetPayment(c *gin.Context) {
-	req, err := http.NewRequest("GET", p.paymentUrl+"/"+c.Param("id"), nil)
+	if p.client != nil {
+		p.getPaymentRPC(c)
+		return
+	}
+	req, err := http.NewRequestWithContext(c.Request.Context(), "GET", p.paymentUrl+"/"+c.Param("id"), nil)
 	if err != nil {
-		c.JSON(500, gin.H{"error": err.Error()})
+		upstreamError(c, err)
 		return
 	}
-	client := &http.Client{}
-	resp, err := client.Do(req)
+	resp, err := upstreamClient.Do(req)
 	if err != nil {
-		c.JSON(500, gin.H{"error": err.Error()})
+		upstreamError(c, err)
 		return
 	}
 	defer resp.Body.Close()
 	res, err := response.ParseResponse(resp)
 	if err != nil {
-		c.JSON(500, gin.H{"error": err.Error()})
+		upstreamError(c, err)
 		return
 	}
+	copyETag(c, resp)
 	c.JSON(resp.StatusCode, res)
 }
 
 // UpdatePayment godoc
 // @Summary Update payment
-// @Description Update payment
+// @Description Update payment. Requires the payments:write permission.
 // @Tags payments
 // @Accept  json
 // @Produce  json
+// @Security BearerAuth
 // @Param id path string true "Payment ID"
+// @Param If-Match header string false "ETag of the payment"
 // @Param payment body payment.Request true "Payment data"
 // @Success 200 {object} response.Response
+// @Header 200 {string} ETag "Version of the payment"
 // @Failure 400 {object} response.Response
+// @Failure 401 {object} response.Response
+// @Failure 403 {object} response.Response
+// @Failure 412 {object} response.Response
+// @Failure 422 {object} response.Response
 // @Failure 500 {object} response.Response
 // @Router /payments/{id} [put]
 func (p *PaymentHandler) UpdatePayment(c *gin.Context) {
-	req, err := http.NewRequest("PUT", p.paymentUrl+"/"+c.Param("id"), c.Request.Body)
+	// the gRPC API has no versions, a conditional update goes over REST
+	if p.client != nil && c.GetHeader("If-Match") == "" {
+		p.updatePaymentRPC(c)
+		return
+	}
+	req, err := http.NewRequestWithContext(c.Request.Context(), "PUT", p.paymentUrl+"/"+c.Param("id"), c.Request.Body)
+	if err != nil {
+		upstreamError(c, err)
+		return
+	}
+	forwardIfMatch(c, req)
+	resp, err := upstreamClient.Do(req)
+	if err != nil {
+		upstreamError(c, err)
+		return
+	}
+	defer resp.Body.Close()
+	res, err := response.ParseResponse(resp)
+	if err != nil {
+		upstreamError(c, err)
+		return
+	}
+	copyETag(c, resp)
+	c.JSON(resp.StatusCode, res)
+}
+
+// PatchPayment godoc
+// @Summary Patch payment by ID
+// @Description Change the fields of a payment given in a JSON merge patch. Patches always go over REST. Requires the payments:write permission.
+// @Tags payments
+// @Accept  json
+// @Produce  json
+// @Security BearerAuth
+// @Param id path string true "Payment ID"
+// @Param If-Match header string false "ETag of the payment"
+// @Param payment body payment.Patch true "Payment patch"
+// @Success 200 {object} response.Response
+// @Header 200 {string} ETag "Version of the payment"
+// @Failure 400 {object} response.Response
+// @Failure 401 {object} response.Response
+// @Failure 403 {object} response.Response
+// @Failure 404 {object} response.Response
+// @Failure 412 {object} response.Response
+// @Failure 422 {object} response.Response
+// @Failure 500 {object} response.Response
+// @Router /payments/{id} [patch]
+func (p *PaymentHandler) PatchPayment(c *gin.Context) {
+	req, err := http.NewRequestWithContext(c.Request.Context(), http.MethodPatch, p.paymentUrl+"/"+c.Param("id"), c.Request.Body)
 	if err != nil {
-		c.JSON(500, gin.H{"error": err.Error()})
+		upstreamError(c, err)
 		return
 	}
-	client := &http.Client{}
-	resp, err := client.Do(req)
+	req.Header.Set("Content-Type", c.GetHeader("Content-Type"))
+	forwardIfMatch(c, req)
+	resp, err := upstreamClient.Do(req)
 	if err != nil {
-		c.JSON(500, gin.H{"error": err.Error()})
+		upstreamError(c, err)
 		return
 	}
 	defer resp.Body.Close()
 	res, err := response.ParseResponse(resp)
 	if err != nil {
-		c.JSON(500, gin.H{"error": err.Error()})
+		upstreamError(c, err)
 		return
 	}
+	copyETag(c, resp)
 	c.JSON(resp.StatusCode, res)
 }
 
 // DeletePayment godoc
 // @Summary Delete payment
-// @Description Delete payment
+// @Description Delete payment. Requires the payments:write permission.
 // @Tags payments
 // @Accept  json
 // @Produce  json
+// @Security BearerAuth
 // @Param id path string true "Payment ID"
 // @Success 200 {object} response.Response
 // @Failure 400 {object} response.Response
+// @Failure 401 {object} response.Response
+// @Failure 403 {object} response.Response
 // @Failure 500 {object} response.Response
 // @Router /payments/{id} [delete]
 func (p *PaymentHandler) DeletePayment(c *gin.Context) {
-	req, err := http.NewRequest("DELETE", p.paymentUrl+"/"+c.Param("id"), nil)
+	if p.client != nil {
+		p.deletePaymentRPC(c)
+		return
+	}
+	req, err := http.NewRequestWithContext(c.Request.Context(), "DELETE", p.paymentUrl+"/"+c.Param("id"), nil)
 	if err != nil {
-		c.JSON(500, gin.H{"error": err.Error()})
+		upstreamError(c, err)
 		return
 	}
-	client := &http.Client{}
-	resp, err := client.Do(req)
+	resp, err := upstreamClient.Do(req)
 	if err != nil {
-		c.JSON(500, gin.H{"error": err.Error()})
+		upstreamError(c, err)
 		return
 	}
 	defer resp.Body.Close()
 	res, err := response.ParseResponse(resp)
 	if err != nil {
-		c.JSON(500, gin.H{"error": err.Error()})
+		upstreamError(c, err)
 		return
 	}
 	c.JSON(resp.StatusCode, res)
 }
 
+// RestorePayment godoc
+// @Summary Restore a deleted payment
+// @Description Bring a deleted payment back, for admins
+// @Tags payments
+// @Accept  json
+// @Produce  json
+// @Security BearerAuth
+// @Param id path string true "Payment ID"
+// @Success 200 {object} response.Response
+// @Header 200 {string} ETag "Version of the payment"
+// @Failure 401 {object} response.Response
+// @Failure 403 {object} response.Response
+// @Failure 404 {object} response.Response
+// @Failure 409 {object} response.Response
+// @Failure 500 {object} response.Response
+// @Router /payments/{id}/restore [post]
+func (p *PaymentHandler) RestorePayment(c *gin.Context) {
+	req, err := http.NewRequestWithContext(c.Request.Context(), http.MethodPost, p.paymentUrl+"/"+c.Param("id")+"/restore", nil)
+	if err != nil {
+		upstreamError(c, err)
+		return
+	}
+	resp, err := upstreamClient.Do(req)
+	if err != nil {
+		upstreamError(c, err)
+		return
+	}
+	defer resp.Body.Close()
+	res, err := response.ParseResponse(resp)
+	if err != nil {
+		upstreamError(c, err)
+		return
+	}
+	copyETag(c, resp)
+	c.JSON(resp.StatusCode, res)
+}
+
 // SearchPayments godoc
 // @Summary Search payments
-// @Description Search payments
+// @Description Search payments. Requires the payments:read:any permission.
 // @Tags payments
 // @Accept  json
 // @Produce  json
+// @Security BearerAuth
 // @Param filter query string false "Filter"
 // @Param value query string false "Value"
 // @Success 200 {object} response.Response
+// @Failure 401 {object} response.Response
+// @Failure 403 {object} response.Response
 // @Failure 500 {object} response.Response
 // @Router /payments/search [get]
 func (p *PaymentHandler) SearchPayments(c *gin.Context) {
-	req, err := http.NewRequest("GET", p.paymentUrl+"/search?"+c.Request.URL.RawQuery, nil)
+	if p.client != nil {
+		p.searchPaymentsRPC(c)
+		return
+	}
+	req, err := http.NewRequestWithContext(c.Request.Context(), "GET", p.paymentUrl+"/search?"+c.Request.URL.RawQuery, nil)
+	if err != nil {
+		upstreamError(c, err)
+		return
+	}
+	resp, err := upstreamClient.Do(req)
+	if err != nil {
+		upstreamError(c, err)
+		return
+	}
+	defer resp.Body.Close()
+	res, err := response.ParseResponse(resp)
+	if err != nil {
+		upstreamError(c, err)
+		return
+	}
+	c.JSON(resp.StatusCode, res)
+}
+
+// RefundPayment godoc
+// @Summary Refund payment by ID
+// @Description Refund a successful payment, its order is marked refunded. Requires the payments:write permission.
+// @Tags payments
+// @Accept  json
+// @Produce  json
+// @Security BearerAuth
+// @Param id path string true "Payment ID"
+// @Success 200 {object} response.Response
+// @Failure 401 {object} response.Response
+// @Failure 403 {object} response.Response
+// @Failure 404 {object} response.Response
+// @Failure 409 {object} response.Response
+// @Failure 500 {object} response.Response
+// @Router /payments/{id}/refund [post]
+func (p *PaymentHandler) RefundPayment(c *gin.Context) {
+	if p.client != nil {
+		p.refundPaymentRPC(c)
+		return
+	}
+	req, err := http.NewRequestWithContext(c.Request.Context(), "POST", p.paymentUrl+"/"+c.Param("id")+"/refund", nil)
 	if err != nil {
-		c.JSON(500, gin.H{"error": err.Error()})
+		upstreamError(c, err)
 		return
 	}
-	client := &http.Client{}
-	resp, err := client.Do(req)
+	resp, err := upstreamClient.Do(req)
 	if err != nil {
-		c.JSON(500, gin.H{"error": err.Error()})
+		upstreamError(c, err)
 		return
 	}
 	defer resp.Body.Close()
 	res, err := response.ParseResponse(resp)
 	if err != nil {
-		c.JSON(500, gin.H{"error": err.Error()})
+		upstreamError(c, err)
 		return
 	}
 	c.JSON(resp.StatusCode, res)
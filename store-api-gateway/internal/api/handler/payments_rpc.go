@@ -0,0 +1,113 @@
+package handler
+
+import (
+	"api-gateway-service/internal/domain/payment"
+	"api-gateway-service/pkg/apperror"
+	"api-gateway-service/pkg/pb/paymentpb"
+	"api-gateway-service/pkg/response"
+	"github.com/gin-gonic/gin"
+	"net/http"
+)
+
+func (p *PaymentHandler) createPaymentRPC(c *gin.Context) {
+	req := payment.Request{}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(apperror.InvalidBody(err))
+		return
+	}
+
+	ctx, cancel := upstreamContext(c)
+	defer cancel()
+	res, err := p.client.CreatePayment(ctx, &paymentpb.CreatePaymentRequest{
+		UserId:  req.UserID,
+		OrderId: req.OrderID,
+		Amount:  &paymentpb.Money{Amount: req.Amount.Decimal(), Currency: req.Amount.Currency},
+	})
+	if err != nil {
+		rpcError(c, err)
+		return
+	}
+	successRes := response.ClientResponse(http.StatusCreated, "the payment was successfully created", res.GetId(), nil)
+	c.JSON(http.StatusCreated, successRes)
+}
+
+func (p *PaymentHandler) listPaymentsRPC(c *gin.Context) {
+	ctx, cancel := upstreamContext(c)
+	defer cancel()
+	res, err := p.client.ListPayments(ctx, &paymentpb.ListPaymentsRequest{})
+	if err != nil {
+		rpcError(c, err)
+		return
+	}
+	successRes := response.ClientResponse(http.StatusOK, "the payments list", payment.ParseFromProtos(res.GetPayments()), nil)
+	c.JSON(http.StatusOK, successRes)
+}
+
+func (p *PaymentHandler) getPaymentRPC(c *gin.Context) {
+	ctx, cancel := upstreamContext(c)
+	defer cancel()
+	res, err := p.client.GetPayment(ctx, &paymentpb.GetPaymentRequest{Id: c.Param("id")})
+	if err != nil {
+		rpcError(c, err)
+		return
+	}
+	successRes := response.ClientResponse(http.StatusOK, "the payment details", payment.ParseFromProto(res), nil)
+	c.JSON(http.StatusOK, successRes)
+}
+
+func (p *PaymentHandler) updatePaymentRPC(c *gin.Context) {
+	req := payment.Request{}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(apperror.InvalidBody(err))
+		return
+	}
+
+	ctx, cancel := upstreamContext(c)
+	defer cancel()
+	_, err := p.client.UpdatePayment(ctx, &paymentpb.UpdatePaymentRequest{
+		Id:      c.Param("id"),
+		UserId:  req.UserID,
+		OrderId: req.OrderID,
+		Amount:  &paymentpb.Money{Amount: req.Amount.Decimal(), Currency: req.Amount.Currency},
+	})
+	if err != nil {
+		rpcError(c, err)
+		return
+	}
+	successRes := response.ClientResponse(http.StatusOK, "the payment was successfully updated", nil, nil)
+	c.JSON(http.StatusOK, successRes)
+}
+
+func (p *PaymentHandler) deletePaymentRPC(c *gin.Context) {
+	ctx, cancel := upstreamContext(c)
+	defer cancel()
+	if _, err := p.client.DeletePayment(ctx, &paymentpb.DeletePaymentRequest{Id: c.Param("id")}); err != nil {
+		rpcError(c, err)
+		return
+	}
+	successRes := response.ClientResponse(http.StatusOK, "the payment was successfully deleted", nil, nil)
+	c.JSON(http.StatusOK, successRes)
+}
+
+func (p *PaymentHandler) searchPaymentsRPC(c *gin.Context) {
+	ctx, cancel := upstreamContext(c)
+	defer cancel()
+	res, err := p.client.SearchPayments(ctx, &paymentpb.SearchPaymentsRequest{Filter: c.Query("filter"), Value: c.Query("value")})
+	if err != nil {
+		rpcError(c, err)
+		return
+	}
+	successRes := response.ClientResponse(http.StatusOK, "the payments list", payment.ParseFromProtos(res.GetPayments()), nil)
+	c.JSON(http.StatusOK, successRes)
+}
+
+func (p *PaymentHandler) refundPaymentRPC(c *gin.Context) {
+	ctx, cancel := upstreamContext(c)
+	defer cancel()
+	if _, err := p.client.RefundPayment(ctx, &paymentpb.RefundPaymentRequest{Id: c.Param("id")}); err != nil {
+		rpcError(c, err)
+		return
+	}
+	successRes := response.ClientResponse(http.StatusOK, "the payment was successfully refunded", nil, nil)
+	c.JSON(http.StatusOK, successRes)
+}
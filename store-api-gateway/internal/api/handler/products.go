@@ -1,41 +1,52 @@
 package handler
 
 import (
+	"api-gateway-service/pkg/pb/productpb"
 	"api-gateway-service/pkg/response"
 	"github.com/gin-gonic/gin"
 	"io"
 	"net/http"
 )
 
+// ProductHandler proxies to the REST API of the service. When client is set
+// the calls its gRPC API has go over gRPC instead.
 type ProductHandler struct {
 	productUrl string
+	client     productpb.ProductServiceClient
 }
 
-func NewProductHandler(productUrl string) *ProductHandler {
-	return &ProductHandler{productUrl}
+func NewProductHandler(productUrl string, client productpb.ProductServiceClient) *ProductHandler {
+	return &ProductHandler{productUrl, client}
 }
 
 // CreateProduct godoc
 // @Summary Create a new product
-// @Description Create a new product
+// @Description Create a new product. Requires the products:write permission.
 // @Tags products
 // @Accept  json
 // @Produce  json
+// @Security BearerAuth
 // @Param product body product.Request true "Product data"
 // @Success 201 {object} response.Response
 // @Failure 400 {object} response.Response
+// @Failure 401 {object} response.Response
+// @Failure 403 {object} response.Response
+// @Failure 422 {object} response.Response
 // @Failure 500 {object} response.Response
 // @Router /products [post]
 func (p *ProductHandler) CreateProduct(c *gin.Context) {
-	req, err := http.NewRequest("POST", p.productUrl, c.Request.Body)
+	if p.client != nil {
+		p.createProductRPC(c)
+		return
+	}
+	req, err := http.NewRequestWithContext(c.Request.Context(), "POST", p.productUrl, c.Request.Body)
 	if err != nil {
-		c.JSON(500, gin.H{"error": err.Error()})
+		upstreamError(c, err)
 		return
 	}
-	client := &http.Client{}
-	resp, err := client.Do(req)
+	resp, err := upstreamClient.Do(req)
 	if err != nil {
-		c.JSON(500, gin.H{"error": err.Error()})
+		upstreamError(c, err)
 		return
 	}
 	defer resp.Body.Close()
@@ -48,25 +59,31 @@ func (p *ProductHandler) CreateProduct(c *gin.Context) {
 // @Tags products
 // @Accept  json
 // @Produce  json
+// @Param currency query string false "Currency to show prices in"
+// @Param Accept-Currency header string false "Currency to show prices in"
 // @Success 200 {object} response.Response
 // @Failure 500 {object} response.Response
 // @Router /products [get]
 func (p *ProductHandler) ListProducts(c *gin.Context) {
-	req, err := http.NewRequest("GET", p.productUrl, nil)
+	if p.client != nil {
+		p.listProductsRPC(c)
+		return
+	}
+	req, err := http.NewRequestWithContext(c.Request.Context(), "GET", p.productUrl+"?"+c.Request.URL.RawQuery, nil)
 	if err != nil {
-		c.JSON(500, gin.H{"error": err.Error()})
+		upstreamError(c, err)
 		return
 	}
-	client := &http.Client{}
-	resp, err := client.Do(req)
+	req.Header.Set("Accept-Currency", c.GetHeader("Accept-Currency"))
+	resp, err := upstreamClient.Do(req)
 	if err != nil {
-		c.JSON(500, gin.H{"error": err.Error()})
+		upstreamError(c, err)
 		return
 	}
 	defer resp.Body.Close()
 	res, err := response.ParseResponse(resp)
 	if err != nil {
-		c.JSON(500, gin.H{"error": err.Error()})
+		upstreamError(c, err)
 		return
 	}
 	c.JSON(resp.StatusCode, res)
@@ -79,96 +96,203 @@ func (p *ProductHandler) ListProducts(c *gin.Context) {
 // @Accept  json
 // @Produce  json
 // @Param id path string true "Product ID"
+// @Param currency query string false "Currency to show prices in"
+// @Param Accept-Currency header string false "Currency to show prices in"
 // @Success 200 {object} response.Response
+// @Header 200 {string} ETag "Version of the product, only over REST"
 // @Failure 400 {object} response.Response
 // @Failure 500 {object} response.Response
 // @Router /products/{id} [get]
 func (p *ProductHandler) GetProduct(c *gin.Context) {
-	req, err := http.NewRequest("GET", p.productUrl+"/"+c.Param("id"), nil)
+	if p.client != nil {
+		p.getProductRPC(c)
+		return
+	}
+	req, err := http.NewRequestWithContext(c.Request.Context(), "GET", p.productUrl+"/"+c.Param("id")+"?"+c.Request.URL.RawQuery, nil)
 	if err != nil {
-		c.JSON(500, gin.H{"error": err.Error()})
+		upstreamError(c, err)
 		return
 	}
-	client := &http.Client{}
-	resp, err := client.Do(req)
+	req.Header.Set("Accept-Currency", c.GetHeader("Accept-Currency"))
+	resp, err := upstreamClient.Do(req)
 	if err != nil {
-		c.JSON(500, gin.H{"error": err.Error()})
+		upstreamError(c, err)
 		return
 	}
 	defer resp.Body.Close()
 	res, err := response.ParseResponse(resp)
 	if err != nil {
-		c.JSON(500, gin.H{"error": err.Error()})
+		upstreamError(c, err)
 		return
 	}
+	copyETag(c, resp)
 	c.JSON(resp.StatusCode, res)
 }
 
 // UpdateProduct godoc
 // @Summary Update product by ID
-// @Description Update product by ID
+// @Description Update product by ID. Requires the products:write permission.
 // @Tags products
 // @Accept  json
 // @Produce  json
+// @Security BearerAuth
 // @Param id path string true "Product ID"
+// @Param If-Match header string false "ETag of the product"
 // @Param product body product.Request true "Product data"
 // @Success 200 {object} response.Response
+// @Header 200 {string} ETag "Version of the product"
 // @Failure 400 {object} response.Response
+// @Failure 401 {object} response.Response
+// @Failure 403 {object} response.Response
+// @Failure 412 {object} response.Response
+// @Failure 422 {object} response.Response
 // @Failure 500 {object} response.Response
 // @Router /products/{id} [put]
 func (p *ProductHandler) UpdateProduct(c *gin.Context) {
-	req, err := http.NewRequest("PUT", p.productUrl+"/"+c.Param("id"), c.Request.Body)
+	// the gRPC API has no versions, a conditional update goes over REST
+	if p.client != nil && c.GetHeader("If-Match") == "" {
+		p.updateProductRPC(c)
+		return
+	}
+	req, err := http.NewRequestWithContext(c.Request.Context(), "PUT", p.productUrl+"/"+c.Param("id"), c.Request.Body)
+	if err != nil {
+		upstreamError(c, err)
+		return
+	}
+	forwardIfMatch(c, req)
+	resp, err := upstreamClient.Do(req)
+	if err != nil {
+		upstreamError(c, err)
+		return
+	}
+	defer resp.Body.Close()
+	res, err := response.ParseResponse(resp)
+	if err != nil {
+		upstreamError(c, err)
+		return
+	}
+	copyETag(c, resp)
+	c.JSON(resp.StatusCode, res)
+}
+
+// PatchProduct godoc
+// @Summary Patch product by ID
+// @Description Change the fields of a product given in a JSON merge patch. Patches always go over REST. Requires the products:write permission.
+// @Tags products
+// @Accept  json
+// @Produce  json
+// @Security BearerAuth
+// @Param id path string true "Product ID"
+// @Param If-Match header string false "ETag of the product"
+// @Param product body product.Patch true "Product patch"
+// @Success 200 {object} response.Response
+// @Header 200 {string} ETag "Version of the product"
+// @Failure 400 {object} response.Response
+// @Failure 401 {object} response.Response
+// @Failure 403 {object} response.Response
+// @Failure 404 {object} response.Response
+// @Failure 412 {object} response.Response
+// @Failure 422 {object} response.Response
+// @Failure 500 {object} response.Response
+// @Router /products/{id} [patch]
+func (p *ProductHandler) PatchProduct(c *gin.Context) {
+	req, err := http.NewRequestWithContext(c.Request.Context(), http.MethodPatch, p.productUrl+"/"+c.Param("id"), c.Request.Body)
 	if err != nil {
-		c.JSON(500, gin.H{"error": err.Error()})
+		upstreamError(c, err)
 		return
 	}
-	client := &http.Client{}
-	resp, err := client.Do(req)
+	req.Header.Set("Content-Type", c.GetHeader("Content-Type"))
+	forwardIfMatch(c, req)
+	resp, err := upstreamClient.Do(req)
 	if err != nil {
-		c.JSON(500, gin.H{"error": err.Error()})
+		upstreamError(c, err)
 		return
 	}
 	defer resp.Body.Close()
 	res, err := response.ParseResponse(resp)
 	if err != nil {
-		c.JSON(500, gin.H{"error": err.Error()})
+		upstreamError(c, err)
 		return
 	}
+	copyETag(c, resp)
 	c.JSON(resp.StatusCode, res)
 }
 
 // DeleteProduct godoc
 // @Summary Delete product by ID
-// @Description Delete product by ID
+// @Description Delete product by ID. Requires the products:write permission.
 // @Tags products
 // @Accept  json
 // @Produce  json
+// @Security BearerAuth
 // @Param id path string true "Product ID"
 // @Success 200 {object} response.Response
 // @Failure 400 {object} response.Response
+// @Failure 401 {object} response.Response
+// @Failure 403 {object} response.Response
 // @Failure 500 {object} response.Response
 // @Router /products/{id} [delete]
 func (p *ProductHandler) DeleteProduct(c *gin.Context) {
-	req, err := http.NewRequest("DELETE", p.productUrl+"/"+c.Param("id"), nil)
+	if p.client != nil {
+		p.deleteProductRPC(c)
+		return
+	}
+	req, err := http.NewRequestWithContext(c.Request.Context(), "DELETE", p.productUrl+"/"+c.Param("id"), nil)
 	if err != nil {
-		c.JSON(500, gin.H{"error": err.Error()})
+		upstreamError(c, err)
 		return
 	}
-	client := &http.Client{}
-	resp, err := client.Do(req)
+	resp, err := upstreamClient.Do(req)
 	if err != nil {
-		c.JSON(500, gin.H{"error": err.Error()})
+		upstreamError(c, err)
 		return
 	}
 	defer resp.Body.Close()
 	res, err := response.ParseResponse(resp)
 	if err != nil {
-		c.JSON(500, gin.H{"error": err.Error()})
+		upstreamError(c, err)
 		return
 	}
 	c.JSON(resp.StatusCode, res)
 }
 
+// RestoreProduct godoc
+// @Summary Restore a deleted product
+// @Description Bring a deleted product back, for admins
+// @Tags products
+// @Accept  json
+// @Produce  json
+// @Security BearerAuth
+// @Param id path string true "Product ID"
+// @Success 200 {object} response.Response
+// @Header 200 {string} ETag "Version of the product"
+// @Failure 401 {object} response.Response
+// @Failure 403 {object} response.Response
+// @Failure 404 {object} response.Response
+// @Failure 409 {object} response.Response
+// @Failure 500 {object} response.Response
+// @Router /products/{id}/restore [post]
+func (p *ProductHandler) RestoreProduct(c *gin.Context) {
+	req, err := http.NewRequestWithContext(c.Request.Context(), http.MethodPost, p.productUrl+"/"+c.Param("id")+"/restore", nil)
+	if err != nil {
+		upstreamError(c, err)
+		return
+	}
+	resp, err := upstreamClient.Do(req)
+	if err != nil {
+		upstreamError(c, err)
+		return
+	}
+	defer resp.Body.Close()
+	res, err := response.ParseResponse(resp)
+	if err != nil {
+		upstreamError(c, err)
+		return
+	}
+	copyETag(c, resp)
+	c.JSON(resp.StatusCode, res)
+}
+
 // SearchProducts godoc
 // @Summary Search products
 // @Description Search products
@@ -177,26 +301,94 @@ func (p *ProductHandler) DeleteProduct(c *gin.Context) {
 // @Produce  json
 // @Param filter query string false "Search filter"
 // @Param value query string false "Search value"
+// @Param currency query string false "Currency to show prices in"
+// @Param Accept-Currency header string false "Currency to show prices in"
 // @Success 200 {object} response.Response
 // @Failure 500 {object} response.Response
 // @Router /products/search [get]
 func (p *ProductHandler) SearchProducts(c *gin.Context) {
-	req, err := http.NewRequest("GET", p.productUrl+"/search?filter="+c.Query("filter")+"&value="+c.Query("value"), nil)
+	if p.client != nil {
+		p.searchProductsRPC(c)
+		return
+	}
+	req, err := http.NewRequestWithContext(c.Request.Context(), "GET", p.productUrl+"/search?"+c.Request.URL.RawQuery, nil)
 	if err != nil {
-		c.JSON(500, gin.H{"error": err.Error()})
+		upstreamError(c, err)
 		return
 	}
-	client := &http.Client{}
-	resp, err := client.Do(req)
+	req.Header.Set("Accept-Currency", c.GetHeader("Accept-Currency"))
+	resp, err := upstreamClient.Do(req)
 	if err != nil {
-		c.JSON(500, gin.H{"error": err.Error()})
+		upstreamError(c, err)
 		return
 	}
 	defer resp.Body.Close()
 	res, err := response.ParseResponse(resp)
 	if err != nil {
-		c.JSON(500, gin.H{"error": err.Error()})
+		upstreamError(c, err)
 		return
 	}
 	c.JSON(resp.StatusCode, res)
 }
+
+// ImportProducts godoc
+// @Summary Import products in bulk
+// @Description Create or update products from a CSV or NDJSON file in one transaction, the report lists the rejected rows. Requires the products:write permission.
+// @Tags products
+// @Accept text/csv,application/x-ndjson,multipart/form-data
+// @Produce json
+// @Security BearerAuth
+// @Param format query string false "File format (csv or ndjson)"
+// @Param file formData file false "Import file when sent as multipart/form-data"
+// @Success 200 {object} response.Response
+// @Failure 400 {object} response.Response
+// @Failure 401 {object} response.Response
+// @Failure 403 {object} response.Response
+// @Failure 500 {object} response.Response
+// @Router /products/import [post]
+func (p *ProductHandler) ImportProducts(c *gin.Context) {
+	req, err := http.NewRequestWithContext(c.Request.Context(), "POST", p.productUrl+"/import?"+c.Request.URL.RawQuery, c.Request.Body)
+	if err != nil {
+		upstreamError(c, err)
+		return
+	}
+	req.Header.Set("Content-Type", c.GetHeader("Content-Type"))
+	resp, err := upstreamClient.Do(req)
+	if err != nil {
+		upstreamError(c, err)
+		return
+	}
+	defer resp.Body.Close()
+	res, err := response.ParseResponse(resp)
+	if err != nil {
+		upstreamError(c, err)
+		return
+	}
+	c.JSON(resp.StatusCode, res)
+}
+
+// ExportProducts godoc
+// @Summary Export all products
+// @Description Stream every product as CSV or NDJSON
+// @Tags products
+// @Produce text/csv,application/x-ndjson
+// @Param format query string false "File format (csv or ndjson)" default(csv)
+// @Success 200 {file} file
+// @Failure 400 {object} response.Response
+// @Failure 500 {object} response.Response
+// @Router /products/export [get]
+func (p *ProductHandler) ExportProducts(c *gin.Context) {
+	req, err := http.NewRequestWithContext(c.Request.Context(), "GET", p.productUrl+"/export?"+c.Request.URL.RawQuery, nil)
+	if err != nil {
+		upstreamError(c, err)
+		return
+	}
+	resp, err := upstreamClient.Do(req)
+	if err != nil {
+		upstreamError(c, err)
+		return
+	}
+	defer resp.Body.Close()
+	c.Header("Content-Disposition", resp.Header.Get("Content-Disposition"))
+	c.DataFromReader(resp.StatusCode, -1, resp.Header.Get("Content-Type"), resp.Body, nil)
+}
@@ -0,0 +1,112 @@
+package handler
+
+import (
+	"api-gateway-service/internal/domain/product"
+	"api-gateway-service/pkg/apperror"
+	"api-gateway-service/pkg/pb/productpb"
+	"api-gateway-service/pkg/response"
+	"github.com/gin-gonic/gin"
+	"net/http"
+)
+
+func (p *ProductHandler) createProductRPC(c *gin.Context) {
+	req := product.Request{}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(apperror.InvalidBody(err))
+		return
+	}
+
+	ctx, cancel := upstreamContext(c)
+	defer cancel()
+	res, err := p.client.CreateProduct(ctx, &productpb.CreateProductRequest{
+		Title:       req.Title,
+		Description: req.Description,
+		Price:       &productpb.Money{Amount: req.Price.Decimal(), Currency: req.Price.Currency},
+		Category:    req.Category,
+		Quantity:    int64(req.Quantity),
+		Weight:      int64(req.Weight),
+	})
+	if err != nil {
+		rpcError(c, err)
+		return
+	}
+	successRes := response.ClientResponse(http.StatusCreated, "the product was successfully created", res.GetId(), nil)
+	c.JSON(http.StatusCreated, successRes)
+}
+
+func (p *ProductHandler) listProductsRPC(c *gin.Context) {
+	ctx, cancel := upstreamContext(c)
+	defer cancel()
+	res, err := p.client.ListProducts(ctx, &productpb.ListProductsRequest{Currency: requestCurrency(c)})
+	if err != nil {
+		rpcError(c, err)
+		return
+	}
+	successRes := response.ClientResponse(http.StatusOK, "the products list", product.ParseFromProtos(res.GetProducts()), nil)
+	c.JSON(http.StatusOK, successRes)
+}
+
+func (p *ProductHandler) getProductRPC(c *gin.Context) {
+	ctx, cancel := upstreamContext(c)
+	defer cancel()
+	res, err := p.client.GetProduct(ctx, &productpb.GetProductRequest{Id: c.Param("id"), Currency: requestCurrency(c)})
+	if err != nil {
+		rpcError(c, err)
+		return
+	}
+	successRes := response.ClientResponse(http.StatusOK, "the product details", product.ParseFromProto(res), nil)
+	c.JSON(http.StatusOK, successRes)
+}
+
+func (p *ProductHandler) updateProductRPC(c *gin.Context) {
+	req := product.Request{}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(apperror.InvalidBody(err))
+		return
+	}
+
+	ctx, cancel := upstreamContext(c)
+	defer cancel()
+	_, err := p.client.UpdateProduct(ctx, &productpb.UpdateProductRequest{
+		Id:          c.Param("id"),
+		Title:       req.Title,
+		Description: req.Description,
+		Price:       &productpb.Money{Amount: req.Price.Decimal(), Currency: req.Price.Currency},
+		Category:    req.Category,
+		Quantity:    int64(req.Quantity),
+		Weight:      int64(req.Weight),
+	})
+	if err != nil {
+		rpcError(c, err)
+		return
+	}
+	successRes := response.ClientResponse(http.StatusOK, "the product was successfully updated", nil, nil)
+	c.JSON(http.StatusOK, successRes)
+}
+
+func (p *ProductHandler) deleteProductRPC(c *gin.Context) {
+	ctx, cancel := upstreamContext(c)
+	defer cancel()
+	if _, err := p.client.DeleteProduct(ctx, &productpb.DeleteProductRequest{Id: c.Param("id")}); err != nil {
+		rpcError(c, err)
+		return
+	}
+	successRes := response.ClientResponse(http.StatusOK, "the product was successfully deleted", nil, nil)
+	c.JSON(http.StatusOK, successRes)
+}
+
+func (p *ProductHandler) searchProductsRPC(c *gin.Context) {
+	ctx, cancel := upstreamContext(c)
+	defer cancel()
+	res, err := p.client.SearchProducts(ctx, &productpb.SearchProductsRequest{
+		Filter:   c.Query("filter"),
+		Value:    c.Query("value"),
+		Currency: requestCurrency(c),
+	})
+	if err != nil {
+		rpcError(c, err)
+		return
+	}
+	successRes := response.ClientResponse(http.StatusOK, "the products list", product.ParseFromProtos(res.GetProducts()), nil)
+	c.JSON(http.StatusOK, successRes)
+}
@@ -0,0 +1,193 @@
+package handler
+
+import (
+	"api-gateway-service/pkg/response"
+	"github.com/gin-gonic/gin"
+	"net/http"
+)
+
+// bootstrapHeader carries the secret with which the user service issues the
+// first administrator a token.
+const bootstrapHeader = "X-Bootstrap-Secret"
+
+// RoleHandler proxies to the role endpoints of the user service, they have
+// no gRPC API.
+type RoleHandler struct {
+	roleUrl string
+}
+
+func NewRoleHandler(roleUrl string) *RoleHandler {
+	return &RoleHandler{roleUrl}
+}
+
+// IssueToken godoc
+// @Summary Issue an access token
+// @Description Sign an access token for the user with the permissions of its roles. Grants and revokes show in the next token. A user renews its own token with the current one until its session reaches the token max age. A token for another user, or one starting a new session, requires the tokens:issue permission or the bootstrap secret.
+// @Tags users
+// @Accept  json
+// @Produce  json
+// @Security BearerAuth
+// @Param id path string true "User ID"
+// @Param X-Bootstrap-Secret header string false "Bootstrap secret of the user service"
+// @Success 201 {object} response.Response
+// @Failure 401 {object} response.Response
+// @Failure 403 {object} response.Response
+// @Failure 404 {object} response.Response
+// @Failure 500 {object} response.Response
+// @Router /users/{id}/token [post]
+func (u *UserHandler) IssueToken(c *gin.Context) {
+	req, err := http.NewRequestWithContext(c.Request.Context(), http.MethodPost, u.userUrl+"/"+c.Param("id")+"/token", nil)
+	if err != nil {
+		upstreamError(c, err)
+		return
+	}
+	if secret := c.GetHeader(bootstrapHeader); secret != "" {
+		req.Header.Set(bootstrapHeader, secret)
+	}
+	resp, err := upstreamClient.Do(req)
+	if err != nil {
+		upstreamError(c, err)
+		return
+	}
+	defer resp.Body.Close()
+	res, err := response.ParseResponse(resp)
+	if err != nil {
+		upstreamError(c, err)
+		return
+	}
+	c.JSON(resp.StatusCode, res)
+}
+
+// GrantRole godoc
+// @Summary Grant a role to a user
+// @Description Give the user a role, requires the roles:write permission
+// @Tags users
+// @Accept  json
+// @Produce  json
+// @Security BearerAuth
+// @Param id path string true "User ID"
+// @Param role path string true "Role"
+// @Success 200 {object} response.Response
+// @Header 200 {string} ETag "Version of the user"
+// @Failure 401 {object} response.Response
+// @Failure 403 {object} response.Response
+// @Failure 404 {object} response.Response
+// @Failure 500 {object} response.Response
+// @Router /users/{id}/roles/{role} [put]
+func (u *UserHandler) GrantRole(c *gin.Context) {
+	forward(c, http.MethodPut, u.userUrl+"/"+c.Param("id")+"/roles/"+c.Param("role"))
+}
+
+// RevokeRole godoc
+// @Summary Revoke a role from a user
+// @Description Take a role away from the user, requires the roles:write permission
+// @Tags users
+// @Accept  json
+// @Produce  json
+// @Security BearerAuth
+// @Param id path string true "User ID"
+// @Param role path string true "Role"
+// @Success 200 {object} response.Response
+// @Header 200 {string} ETag "Version of the user"
+// @Failure 401 {object} response.Response
+// @Failure 403 {object} response.Response
+// @Failure 404 {object} response.Response
+// @Failure 500 {object} response.Response
+// @Router /users/{id}/roles/{role} [delete]
+func (u *UserHandler) RevokeRole(c *gin.Context) {
+	forward(c, http.MethodDelete, u.userUrl+"/"+c.Param("id")+"/roles/"+c.Param("role"))
+}
+
+// ListRoles godoc
+// @Summary List roles
+// @Description List the roles with their permissions, requires the roles:read permission
+// @Tags roles
+// @Accept  json
+// @Produce  json
+// @Security BearerAuth
+// @Success 200 {object} response.Response
+// @Failure 401 {object} response.Response
+// @Failure 403 {object} response.Response
+// @Failure 500 {object} response.Response
+// @Router /roles [get]
+func (r *RoleHandler) ListRoles(c *gin.Context) {
+	forward(c, http.MethodGet, r.roleUrl+"/")
+}
+
+// ListPermissions godoc
+// @Summary List permissions
+// @Description List the permissions the services check, requires the roles:read permission
+// @Tags roles
+// @Accept  json
+// @Produce  json
+// @Security BearerAuth
+// @Success 200 {object} response.Response
+// @Failure 401 {object} response.Response
+// @Failure 403 {object} response.Response
+// @Failure 500 {object} response.Response
+// @Router /roles/permissions [get]
+func (r *RoleHandler) ListPermissions(c *gin.Context) {
+	forward(c, http.MethodGet, r.roleUrl+"/permissions")
+}
+
+// GrantPermission godoc
+// @Summary Grant a permission to a role
+// @Description Give a role a permission, a new role is created by its first permission. Requires the roles:write permission.
+// @Tags roles
+// @Accept  json
+// @Produce  json
+// @Security BearerAuth
+// @Param role path string true "Role"
+// @Param permission path string true "Permission, e.g. products:restore"
+// @Success 200 {object} response.Response
+// @Failure 400 {object} response.Response
+// @Failure 401 {object} response.Response
+// @Failure 403 {object} response.Response
+// @Failure 404 {object} response.Response
+// @Failure 500 {object} response.Response
+// @Router /roles/{role}/permissions/{permission} [put]
+func (r *RoleHandler) GrantPermission(c *gin.Context) {
+	forward(c, http.MethodPut, r.roleUrl+"/"+c.Param("role")+"/permissions/"+c.Param("permission"))
+}
+
+// RevokePermission godoc
+// @Summary Revoke a permission from a role
+// @Description Take a permission away from a role, requires the roles:write permission
+// @Tags roles
+// @Accept  json
+// @Produce  json
+// @Security BearerAuth
+// @Param role path string true "Role"
+// @Param permission path string true "Permission, e.g. products:restore"
+// @Success 200 {object} response.Response
+// @Failure 401 {object} response.Response
+// @Failure 403 {object} response.Response
+// @Failure 404 {object} response.Response
+// @Failure 500 {object} response.Response
+// @Router /roles/{role}/permissions/{permission} [delete]
+func (r *RoleHandler) RevokePermission(c *gin.Context) {
+	forward(c, http.MethodDelete, r.roleUrl+"/"+c.Param("role")+"/permissions/"+c.Param("permission"))
+}
+
+// forward passes a request without a body on, upstreamClient sends the
+// caller's token along and the user service checks its permissions itself.
+func forward(c *gin.Context, method, url string) {
+	req, err := http.NewRequestWithContext(c.Request.Context(), method, url, nil)
+	if err != nil {
+		upstreamError(c, err)
+		return
+	}
+	resp, err := upstreamClient.Do(req)
+	if err != nil {
+		upstreamError(c, err)
+		return
+	}
+	defer resp.Body.Close()
+	res, err := response.ParseResponse(resp)
+	if err != nil {
+		upstreamError(c, err)
+		return
+	}
+	copyETag(c, resp)
+	c.JSON(resp.StatusCode, res)
+}
@@ -0,0 +1,91 @@
+package handler
+
+import (
+	"api-gateway-service/pkg/apperror"
+	"context"
+	"encoding/json"
+	"github.com/gin-gonic/gin"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// upstreamTimeout bounds a call to a gRPC upstream. The deadline is derived
+// from the incoming request, so a client that goes away or a shorter
+// deadline cancels the upstream call too.
+const upstreamTimeout = 10 * time.Second
+
+// codeValidationFailed is the code of a request the service rejected with a list of
+// field violations.
+const codeValidationFailed = "validation_failed"
+
+func upstreamContext(c *gin.Context) (context.Context, context.CancelFunc) {
+	return context.WithTimeout(c.Request.Context(), upstreamTimeout)
+}
+
+// rpcError answers a failed gRPC upstream call with the HTTP status the
+// REST API of the service would have used and the error code the service
+// sent along as an ErrorInfo.
+func rpcError(c *gin.Context, err error) {
+	st := status.Convert(err)
+	code := http.StatusInternalServerError
+	switch st.Code() {
+	case codes.InvalidArgument:
+		code = http.StatusBadRequest
+	case codes.NotFound:
+		code = http.StatusNotFound
+	case codes.FailedPrecondition, codes.AlreadyExists, codes.Aborted:
+		code = http.StatusConflict
+	case codes.Unauthenticated:
+		code = http.StatusUnauthorized
+	case codes.PermissionDenied:
+		code = http.StatusForbidden
+	case codes.Unavailable:
+		code = http.StatusServiceUnavailable
+	case codes.DeadlineExceeded:
+		code = http.StatusGatewayTimeout
+	case codes.Canceled:
+		// the client is gone, nobody reads the answer
+		c.Abort()
+		return
+	}
+	appErr := apperror.New(code, strings.ToLower(st.Code().String()), st.Message())
+	for _, detail := range st.Details() {
+		if info, ok := detail.(*errdetails.ErrorInfo); ok {
+			appErr.Code = info.GetReason()
+			for key, value := range info.GetMetadata() {
+				appErr = appErr.WithDetail(key, metadataValue(value))
+			}
+		}
+	}
+	if appErr.Code == codeValidationFailed {
+		// gRPC has no status for it, over REST it is 422
+		appErr.Status = http.StatusUnprocessableEntity
+	}
+	c.Error(appErr)
+}
+
+// metadataValue reads ErrorInfo metadata back, the services send lists and
+// objects such as validation violations as JSON.
+func metadataValue(value string) any {
+	if !strings.HasPrefix(value, "[") && !strings.HasPrefix(value, "{") {
+		return value
+	}
+	var res any
+	if err := json.Unmarshal([]byte(value), &res); err != nil {
+		return value
+	}
+	return res
+}
+
+// requestCurrency picks the display currency from ?currency= or, failing
+// that, the Accept-Currency header, like the product service does.
+func requestCurrency(c *gin.Context) string {
+	if currency := c.Query("currency"); currency != "" {
+		return currency
+	}
+	return c.GetHeader("Accept-Currency")
+}
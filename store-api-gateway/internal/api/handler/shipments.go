@@ -0,0 +1,110 @@
+package handler
+
+import (
+	"api-gateway-service/pkg/response"
+	"github.com/gin-gonic/gin"
+	"io"
+	"net/http"
+)
+
+// ListShipments godoc
+// @Summary List order shipments
+// @Description List the shipments of an order, for warehouse staff, requires the shipments:read permission
+// @Tags shipments
+// @Accept  json
+// @Produce  json
+// @Security BearerAuth
+// @Param id path string true "Order ID"
+// @Success 200 {object} response.Response
+// @Failure 401 {object} response.Response
+// @Failure 403 {object} response.Response
+// @Failure 500 {object} response.Response
+// @Router /orders/{id}/shipments [get]
+func (o *OrderHandler) ListShipments(c *gin.Context) {
+	o.forwardShipment(c, http.MethodGet, "/"+c.Param("id")+"/shipments/", nil)
+}
+
+// CreateShipment godoc
+// @Summary Create order shipment
+// @Description Pack items of an order into a shipment, for warehouse staff, requires the shipments:write permission
+// @Tags shipments
+// @Accept  json
+// @Produce  json
+// @Security BearerAuth
+// @Param id path string true "Order ID"
+// @Param shipment body shipment.Request true "Shipment data"
+// @Success 201 {object} response.Response
+// @Failure 400 {object} response.Response
+// @Failure 401 {object} response.Response
+// @Failure 403 {object} response.Response
+// @Failure 404 {object} response.Response
+// @Failure 422 {object} response.Response
+// @Failure 500 {object} response.Response
+// @Router /orders/{id}/shipments [post]
+func (o *OrderHandler) CreateShipment(c *gin.Context) {
+	o.forwardShipment(c, http.MethodPost, "/"+c.Param("id")+"/shipments/", c.Request.Body)
+}
+
+// GetShipment godoc
+// @Summary Get order shipment by ID
+// @Description Get a shipment of an order, for warehouse staff, requires the shipments:read permission
+// @Tags shipments
+// @Accept  json
+// @Produce  json
+// @Security BearerAuth
+// @Param id path string true "Order ID"
+// @Param shipment_id path string true "Shipment ID"
+// @Success 200 {object} response.Response
+// @Failure 401 {object} response.Response
+// @Failure 403 {object} response.Response
+// @Failure 404 {object} response.Response
+// @Failure 500 {object} response.Response
+// @Router /orders/{id}/shipments/{shipment_id} [get]
+func (o *OrderHandler) GetShipment(c *gin.Context) {
+	o.forwardShipment(c, http.MethodGet, "/"+c.Param("id")+"/shipments/"+c.Param("shipment_id"), nil)
+}
+
+// UpdateShipment godoc
+// @Summary Update order shipment by ID
+// @Description Set tracking details or move a shipment to packed, shipped or delivered, for warehouse staff, requires the shipments:write permission
+// @Tags shipments
+// @Accept  json
+// @Produce  json
+// @Security BearerAuth
+// @Param id path string true "Order ID"
+// @Param shipment_id path string true "Shipment ID"
+// @Param shipment body shipment.UpdateRequest true "Shipment data"
+// @Success 200 {object} response.Response
+// @Failure 400 {object} response.Response
+// @Failure 401 {object} response.Response
+// @Failure 403 {object} response.Response
+// @Failure 404 {object} response.Response
+// @Failure 409 {object} response.Response
+// @Failure 422 {object} response.Response
+// @Failure 500 {object} response.Response
+// @Router /orders/{id}/shipments/{shipment_id} [put]
+func (o *OrderHandler) UpdateShipment(c *gin.Context) {
+	o.forwardShipment(c, http.MethodPut, "/"+c.Param("id")+"/shipments/"+c.Param("shipment_id"), c.Request.Body)
+}
+
+// forwardShipment passes the request on, upstreamClient sends the caller's
+// token along and the order service checks its permissions itself.
+func (o *OrderHandler) forwardShipment(c *gin.Context, method, path string, body io.Reader) {
+	req, err := http.NewRequestWithContext(c.Request.Context(), method, o.orderUrl+path, body)
+	if err != nil {
+		upstreamError(c, err)
+		return
+	}
+	resp, err := upstreamClient.Do(req)
+	if err != nil {
+		upstreamError(c, err)
+		return
+	}
+	defer resp.Body.Close()
+	res, err := response.ParseResponse(resp)
+	if err != nil {
+		upstreamError(c, err)
+		return
+	}
+	c.JSON(resp.StatusCode, res)
+}
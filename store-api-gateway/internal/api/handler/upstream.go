@@ -0,0 +1,42 @@
+package handler
+
+import (
+	"api-gateway-service/pkg/apperror"
+	"api-gateway-service/pkg/auth"
+	"api-gateway-service/pkg/logging"
+	"api-gateway-service/pkg/metrics"
+	"api-gateway-service/pkg/tracing"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"net/http"
+)
+
+// upstreamClient makes the REST calls to the services. It passes the
+// request ID and the trace context of the incoming request on, so the calls
+// show up under the gateway's span, and counts them in the upstream metrics.
+// The access token goes along, the services check it themselves.
+var upstreamClient = &http.Client{Transport: tracing.Transport(metrics.Transport{Base: auth.Transport{Base: logging.Transport{}}})}
+
+// errUpstream is what a client gets when a service could not be asked or
+// its answer could not be read.
+var errUpstream = apperror.New(http.StatusBadGateway, "upstream_unavailable", "upstream service is unavailable")
+
+func upstreamError(c *gin.Context, err error) {
+	c.Error(fmt.Errorf("%w: %v", errUpstream, err))
+}
+
+// forwardIfMatch passes the If-Match of the client on, so the service only
+// updates the version the client read.
+func forwardIfMatch(c *gin.Context, req *http.Request) {
+	if ifMatch := c.GetHeader("If-Match"); ifMatch != "" {
+		req.Header.Set("If-Match", ifMatch)
+	}
+}
+
+// copyETag hands the version the service tagged its answer with to the
+// client.
+func copyETag(c *gin.Context, resp *http.Response) {
+	if etag := resp.Header.Get("ETag"); etag != "" {
+		c.Header("ETag", etag)
+	}
+}
@@ -1,46 +1,54 @@
 package handler
 
 import (
+	"api-gateway-service/pkg/pb/userpb"
 	"api-gateway-service/pkg/response"
 	"github.com/gin-gonic/gin"
 	"net/http"
 )
 
+// UserHandler proxies to the REST API of the service. When client is set
+// the calls its gRPC API has go over gRPC instead.
 type UserHandler struct {
 	userUrl string
+	client  userpb.UserServiceClient
 }
 
-func NewUserHandler(userUrl string) *UserHandler {
-	return &UserHandler{userUrl}
+func NewUserHandler(userUrl string, client userpb.UserServiceClient) *UserHandler {
+	return &UserHandler{userUrl, client}
 }
 
 // CreateUser godoc
 // @Summary Create a new user
-// @Description Create a new user
+// @Description Sign up a new user, no token is needed. The user gets the default role only.
 // @Tags users
 // @Accept  json
 // @Produce  json
 // @Param user body user.Request true "User data"
 // @Success 201 {object} response.Response
 // @Failure 400 {object} response.Response
+// @Failure 422 {object} response.Response
 // @Failure 500 {object} response.Response
 // @Router /users [post]
 func (u *UserHandler) CreateUser(c *gin.Context) {
-	req, err := http.NewRequest(http.MethodPost, u.userUrl, c.Request.Body)
+	if u.client != nil {
+		u.createUserRPC(c)
+		return
+	}
+	req, err := http.NewRequestWithContext(c.Request.Context(), http.MethodPost, u.userUrl, c.Request.Body)
 	if err != nil {
-		c.JSON(500, gin.H{"error": err.Error()})
+		upstreamError(c, err)
 		return
 	}
-	client := &http.Client{}
-	resp, err := client.Do(req)
+	resp, err := upstreamClient.Do(req)
 	if err != nil {
-		c.JSON(500, gin.H{"error": err.Error()})
+		upstreamError(c, err)
 		return
 	}
 	defer resp.Body.Close()
 	res, err := response.ParseResponse(resp)
 	if err != nil {
-		c.JSON(500, gin.H{"error": err.Error()})
+		upstreamError(c, err)
 		return
 	}
 	c.JSON(resp.StatusCode, res)
@@ -48,29 +56,35 @@ func (u *UserHandler) CreateUser(c *gin.Context) {
 
 // ListUsers godoc
 // @Summary List all users
-// @Description List all users
+// @Description List all users. Requires the users:read:any permission.
 // @Tags users
 // @Accept  json
 // @Produce  json
+// @Security BearerAuth
 // @Success 200 {object} response.Response
+// @Failure 401 {object} response.Response
+// @Failure 403 {object} response.Response
 // @Failure 500 {object} response.Response
 // @Router /users [get]
 func (u *UserHandler) ListUsers(c *gin.Context) {
-	req, err := http.NewRequest("GET", u.userUrl, nil)
+	if u.client != nil {
+		u.listUsersRPC(c)
+		return
+	}
+	req, err := http.NewRequestWithContext(c.Request.Context(), "GET", u.userUrl, nil)
 	if err != nil {
-		c.JSON(500, gin.H{"error": err.Error()})
+		upstreamError(c, err)
 		return
 	}
-	client := &http.Client{}
-	resp, err := client.Do(req)
+	resp, err := upstreamClient.Do(req)
 	if err != nil {
-		c.JSON(500, gin.H{"error": err.Error()})
+		upstreamError(c, err)
 		return
 	}
 	defer resp.Body.Close()
 	res, err := response.ParseResponse(resp)
 	if err != nil {
-		c.JSON(500, gin.H{"error": err.Error()})
+		upstreamError(c, err)
 		return
 	}
 	c.JSON(resp.StatusCode, res)
@@ -78,138 +92,251 @@ func (u *UserHandler) ListUsers(c *gin.Context) {
 
 // GetUser godoc
 // @Summary Get user by id
-// @Description Get user by id
+// @Description Get user by id. A user reads itself, another user requires the users:read:any permission.
 // @Tags users
 // @Accept  json
 // @Produce  json
+// @Security BearerAuth
 // @Param id path string true "User ID"
 // @Success 200 {object} response.Response
+// @Header 200 {string} ETag "Version of the user, only over REST"
 // @Failure 400 {object} response.Response
+// @Failure 401 {object} response.Response
+// @Failure 403 {object} response.Response
 // @Failure 404 {object} response.Response
 // @Failure 500 {object} response.Response
 // @Router /users/{id} [get]
 func (u *UserHandler) GetUser(c *gin.Context) {
+	if u.client != nil {
+		u.getUserRPC(c)
+		return
+	}
 	id := c.Param("id")
-	req, err := http.NewRequest("GET", u.userUrl+"/"+id, nil)
+	req, err := http.NewRequestWithContext(c.Request.Context(), "GET", u.userUrl+"/"+id, nil)
 	if err != nil {
-		c.JSON(500, gin.H{"error": err.Error()})
+		upstreamError(c, err)
 		return
 	}
-	client := &http.Client{}
-	resp, err := client.Do(req)
+	resp, err := upstreamClient.Do(req)
 	if err != nil {
-		c.JSON(500, gin.H{"error": err.Error()})
+		upstreamError(c, err)
 		return
 	}
 	defer resp.Body.Close()
 	res, err := response.ParseResponse(resp)
 	if err != nil {
-		c.JSON(500, gin.H{"error": err.Error()})
+		upstreamError(c, err)
 		return
 	}
+	copyETag(c, resp)
 	c.JSON(resp.StatusCode, res)
 
 }
 
 // UpdateUser godoc
 // @Summary Update user by id
-// @Description Update user by id
+// @Description Update user by id. A user changes itself, another user requires the users:write permission.
 // @Tags users
 // @Accept  json
 // @Produce  json
+// @Security BearerAuth
 // @Param id path string true "User ID"
+// @Param If-Match header string false "ETag of the user"
 // @Param user body user.Request true "User data"
 // @Success 200 {object} response.Response
+// @Header 200 {string} ETag "Version of the user"
 // @Failure 400 {object} response.Response
+// @Failure 401 {object} response.Response
+// @Failure 403 {object} response.Response
 // @Failure 404 {object} response.Response
+// @Failure 412 {object} response.Response
+// @Failure 422 {object} response.Response
 // @Failure 500 {object} response.Response
 // @Router /users/{id} [put]
 func (u *UserHandler) UpdateUser(c *gin.Context) {
+	// the gRPC API has no versions, a conditional update goes over REST
+	if u.client != nil && c.GetHeader("If-Match") == "" {
+		u.updateUserRPC(c)
+		return
+	}
 	id := c.Param("id")
-	req, err := http.NewRequest("PUT", u.userUrl+"/"+id, c.Request.Body)
+	req, err := http.NewRequestWithContext(c.Request.Context(), "PUT", u.userUrl+"/"+id, c.Request.Body)
 	if err != nil {
-		c.JSON(500, gin.H{"error": err.Error()})
+		upstreamError(c, err)
 		return
 	}
-	client := &http.Client{}
-	resp, err := client.Do(req)
+	forwardIfMatch(c, req)
+	resp, err := upstreamClient.Do(req)
 	if err != nil {
-		c.JSON(500, gin.H{"error": err.Error()})
+		upstreamError(c, err)
 		return
 	}
 	defer resp.Body.Close()
 	res, err := response.ParseResponse(resp)
 	if err != nil {
-		c.JSON(500, gin.H{"error": err.Error()})
+		upstreamError(c, err)
 		return
 	}
+	copyETag(c, resp)
+	c.JSON(resp.StatusCode, res)
+}
+
+// PatchUser godoc
+// @Summary Patch user by ID
+// @Description Change the fields of a user given in a JSON merge patch. Patches always go over REST. A user changes itself, another user requires the users:write permission.
+// @Tags users
+// @Accept  json
+// @Produce  json
+// @Security BearerAuth
+// @Param id path string true "User ID"
+// @Param If-Match header string false "ETag of the user"
+// @Param user body user.Patch true "User patch"
+// @Success 200 {object} response.Response
+// @Header 200 {string} ETag "Version of the user"
+// @Failure 400 {object} response.Response
+// @Failure 401 {object} response.Response
+// @Failure 403 {object} response.Response
+// @Failure 404 {object} response.Response
+// @Failure 412 {object} response.Response
+// @Failure 422 {object} response.Response
+// @Failure 500 {object} response.Response
+// @Router /users/{id} [patch]
+func (u *UserHandler) PatchUser(c *gin.Context) {
+	req, err := http.NewRequestWithContext(c.Request.Context(), http.MethodPatch, u.userUrl+"/"+c.Param("id"), c.Request.Body)
+	if err != nil {
+		upstreamError(c, err)
+		return
+	}
+	req.Header.Set("Content-Type", c.GetHeader("Content-Type"))
+	forwardIfMatch(c, req)
+	resp, err := upstreamClient.Do(req)
+	if err != nil {
+		upstreamError(c, err)
+		return
+	}
+	defer resp.Body.Close()
+	res, err := response.ParseResponse(resp)
+	if err != nil {
+		upstreamError(c, err)
+		return
+	}
+	copyETag(c, resp)
 	c.JSON(resp.StatusCode, res)
 }
 
 // DeleteUser godoc
 // @Summary Delete user by id
-// @Description Delete user by id
+// @Description Delete user by id. A user changes itself, another user requires the users:write permission.
 // @Tags users
 // @Accept  json
 // @Produce  json
+// @Security BearerAuth
 // @Param id path string true "User ID"
 // @Success 200 {object} response.Response
 // @Failure 400 {object} response.Response
+// @Failure 401 {object} response.Response
+// @Failure 403 {object} response.Response
 // @Failure 404 {object} response.Response
 // @Failure 500 {object} response.Response
 // @Router /users/{id} [delete]
 func (u *UserHandler) DeleteUser(c *gin.Context) {
+	if u.client != nil {
+		u.deleteUserRPC(c)
+		return
+	}
 	id := c.Param("id")
-	req, err := http.NewRequest("DELETE", u.userUrl+"/"+id, nil)
+	req, err := http.NewRequestWithContext(c.Request.Context(), "DELETE", u.userUrl+"/"+id, nil)
 	if err != nil {
-		c.JSON(500, gin.H{"error": err.Error()})
+		upstreamError(c, err)
 		return
 	}
-	client := &http.Client{}
-	resp, err := client.Do(req)
+	resp, err := upstreamClient.Do(req)
 	if err != nil {
-		c.JSON(500, gin.H{"error": err.Error()})
+		upstreamError(c, err)
 		return
 	}
 	defer resp.Body.Close()
 	res, err := response.ParseResponse(resp)
 	if err != nil {
-		c.JSON(500, gin.H{"error": err.Error()})
+		upstreamError(c, err)
 		return
 	}
 	c.JSON(resp.StatusCode, res)
 }
 
+// RestoreUser godoc
+// @Summary Restore a deleted user
+// @Description Bring a deleted user back, for admins
+// @Tags users
+// @Accept  json
+// @Produce  json
+// @Security BearerAuth
+// @Param id path string true "User ID"
+// @Success 200 {object} response.Response
+// @Header 200 {string} ETag "Version of the user"
+// @Failure 401 {object} response.Response
+// @Failure 403 {object} response.Response
+// @Failure 404 {object} response.Response
+// @Failure 409 {object} response.Response
+// @Failure 500 {object} response.Response
+// @Router /users/{id}/restore [post]
+func (u *UserHandler) RestoreUser(c *gin.Context) {
+	req, err := http.NewRequestWithContext(c.Request.Context(), http.MethodPost, u.userUrl+"/"+c.Param("id")+"/restore", nil)
+	if err != nil {
+		upstreamError(c, err)
+		return
+	}
+	resp, err := upstreamClient.Do(req)
+	if err != nil {
+		upstreamError(c, err)
+		return
+	}
+	defer resp.Body.Close()
+	res, err := response.ParseResponse(resp)
+	if err != nil {
+		upstreamError(c, err)
+		return
+	}
+	copyETag(c, resp)
+	c.JSON(resp.StatusCode, res)
+}
+
 // SearchUser godoc
 // @Summary Search user
-// @Description Search user
+// @Description Search user. Requires the users:read:any permission.
 // @Tags users
 // @Accept  json
 // @Produce  json
+// @Security BearerAuth
 // @Param filter query string true "Search filter"
 // @Param value query string true "Search value"
 // @Success 200 {object} response.Response
 // @Failure 400 {object} response.Response
+// @Failure 401 {object} response.Response
+// @Failure 403 {object} response.Response
 // @Failure 500 {object} response.Response
 // @Router /users/search [get]
 func (u *UserHandler) SearchUser(c *gin.Context) {
+	if u.client != nil {
+		u.searchUserRPC(c)
+		return
+	}
 	filter := c.Query("filter")
 	val := c.Query("value")
-	req, err := http.NewRequest("GET", u.userUrl+"/search?filter="+filter+"&value="+val, nil)
+	req, err := http.NewRequestWithContext(c.Request.Context(), "GET", u.userUrl+"/search?filter="+filter+"&value="+val, nil)
 	if err != nil {
-		c.JSON(500, gin.H{"error": err.Error()})
+		upstreamError(c, err)
 		return
 	}
-	client := &http.Client{}
-	resp, err := client.Do(req)
+	resp, err := upstreamClient.Do(req)
 	if err != nil {
-		c.JSON(500, gin.H{"error": err.Error()})
+		upstreamError(c, err)
 		return
 	}
 	defer resp.Body.Close()
 	res, err := response.ParseResponse(resp)
 	if err != nil {
-		c.JSON(500, gin.H{"error": err.Error()})
+		upstreamError(c, err)
 		return
 	}
 	c.JSON(resp.StatusCode, res)
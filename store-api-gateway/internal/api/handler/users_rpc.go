@@ -0,0 +1,102 @@
+package handler
+
+import (
+	"api-gateway-service/internal/domain/user"
+	"api-gateway-service/pkg/apperror"
+	"api-gateway-service/pkg/pb/userpb"
+	"api-gateway-service/pkg/response"
+	"github.com/gin-gonic/gin"
+	"net/http"
+)
+
+func (u *UserHandler) createUserRPC(c *gin.Context) {
+	req := user.Request{}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(apperror.InvalidBody(err))
+		return
+	}
+
+	ctx, cancel := upstreamContext(c)
+	defer cancel()
+	res, err := u.client.CreateUser(ctx, &userpb.CreateUserRequest{
+		Name:    req.Name,
+		Email:   req.Email,
+		Address: req.Address,
+	})
+	if err != nil {
+		rpcError(c, err)
+		return
+	}
+	successRes := response.ClientResponse(http.StatusCreated, "the user was successfully created", res.GetId(), nil)
+	c.JSON(http.StatusCreated, successRes)
+}
+
+func (u *UserHandler) listUsersRPC(c *gin.Context) {
+	ctx, cancel := upstreamContext(c)
+	defer cancel()
+	res, err := u.client.ListUsers(ctx, &userpb.ListUsersRequest{})
+	if err != nil {
+		rpcError(c, err)
+		return
+	}
+	successRes := response.ClientResponse(http.StatusOK, "the users list", user.ParseFromProtos(res.GetUsers()), nil)
+	c.JSON(http.StatusOK, successRes)
+}
+
+func (u *UserHandler) getUserRPC(c *gin.Context) {
+	ctx, cancel := upstreamContext(c)
+	defer cancel()
+	res, err := u.client.GetUser(ctx, &userpb.GetUserRequest{Id: c.Param("id")})
+	if err != nil {
+		rpcError(c, err)
+		return
+	}
+	successRes := response.ClientResponse(http.StatusOK, "the user details", user.ParseFromProto(res), nil)
+	c.JSON(http.StatusOK, successRes)
+}
+
+func (u *UserHandler) updateUserRPC(c *gin.Context) {
+	req := user.Request{}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(apperror.InvalidBody(err))
+		return
+	}
+
+	ctx, cancel := upstreamContext(c)
+	defer cancel()
+	_, err := u.client.UpdateUser(ctx, &userpb.UpdateUserRequest{
+		Id:      c.Param("id"),
+		Name:    req.Name,
+		Email:   req.Email,
+		Address: req.Address,
+	})
+	if err != nil {
+		rpcError(c, err)
+		return
+	}
+	successRes := response.ClientResponse(http.StatusOK, "the user was successfully updated", nil, nil)
+	c.JSON(http.StatusOK, successRes)
+}
+
+func (u *UserHandler) deleteUserRPC(c *gin.Context) {
+	ctx, cancel := upstreamContext(c)
+	defer cancel()
+	if _, err := u.client.DeleteUser(ctx, &userpb.DeleteUserRequest{Id: c.Param("id")}); err != nil {
+		rpcError(c, err)
+		return
+	}
+	successRes := response.ClientResponse(http.StatusOK, "the user was successfully deleted", nil, nil)
+	c.JSON(http.StatusOK, successRes)
+}
+
+func (u *UserHandler) searchUserRPC(c *gin.Context) {
+	ctx, cancel := upstreamContext(c)
+	defer cancel()
+	res, err := u.client.SearchUsers(ctx, &userpb.SearchUsersRequest{Filter: c.Query("filter"), Value: c.Query("value")})
+	if err != nil {
+		rpcError(c, err)
+		return
+	}
+	successRes := response.ClientResponse(http.StatusOK, "the users list", user.ParseFromProtos(res.GetUsers()), nil)
+	c.JSON(http.StatusOK, successRes)
+}
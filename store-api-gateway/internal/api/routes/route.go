@@ -2,47 +2,102 @@ package routes
 
 import (
 	"api-gateway-service/internal/api/handler"
+	"api-gateway-service/pkg/auth"
 	"github.com/gin-gonic/gin"
 )
 
-func InitRoutes(router *gin.RouterGroup, userHandler *handler.UserHandler, orderHandler *handler.OrderHandler, productHandler *handler.ProductHandler, paymentHandler *handler.PaymentHandler) {
+func InitRoutes(router *gin.RouterGroup, userHandler *handler.UserHandler, roleHandler *handler.RoleHandler, orderHandler *handler.OrderHandler, productHandler *handler.ProductHandler, paymentHandler *handler.PaymentHandler, graphQLHandler *handler.GraphQLHandler) {
+	// the services check the same permissions again, and whose an order or
+	// a payment is, which the gateway does not know
 	users := router.Group("/users")
 	{
-		users.GET("/", userHandler.ListUsers)
+		readAny := auth.RequirePermission("users:read:any")
+		read := auth.RequireSelfOrPermission("id", "users:read:any")
+		write := auth.RequireSelfOrPermission("id", "users:write")
+		roleWrite := auth.RequirePermission("roles:write")
+		users.GET("/", readAny, userHandler.ListUsers)
+		// sign-up is open, the user service grants the default role only
 		users.POST("/", userHandler.CreateUser)
-		users.GET("/:id", userHandler.GetUser)
-		users.PUT("/:id", userHandler.UpdateUser)
-		users.DELETE("/:id", userHandler.DeleteUser)
-		users.PUT("/search", userHandler.SearchUser)
+		users.GET("/:id", read, userHandler.GetUser)
+		users.PUT("/:id", write, userHandler.UpdateUser)
+		users.PATCH("/:id", write, userHandler.PatchUser)
+		users.DELETE("/:id", write, userHandler.DeleteUser)
+		users.POST("/:id/restore", auth.RequirePermission("users:restore"), userHandler.RestoreUser)
+		// the user service also takes its bootstrap secret here
+		users.POST("/:id/token", userHandler.IssueToken)
+		users.PUT("/:id/roles/:role", roleWrite, userHandler.GrantRole)
+		users.DELETE("/:id/roles/:role", roleWrite, userHandler.RevokeRole)
+		users.PUT("/search", readAny, userHandler.SearchUser)
+		users.GET("/:id/addresses", read, userHandler.ListAddresses)
+		users.POST("/:id/addresses", write, userHandler.CreateAddress)
+		users.GET("/:id/addresses/default", read, userHandler.GetDefaultAddress)
+		users.GET("/:id/addresses/:address_id", read, userHandler.GetAddress)
+		users.PUT("/:id/addresses/:address_id", write, userHandler.UpdateAddress)
+		users.DELETE("/:id/addresses/:address_id", write, userHandler.DeleteAddress)
+		users.PUT("/:id/addresses/:address_id/default", write, userHandler.SetDefaultAddress)
+	}
+
+	roles := router.Group("/roles")
+	{
+		read := auth.RequirePermission("roles:read", "roles:write")
+		write := auth.RequirePermission("roles:write")
+		roles.GET("/", read, roleHandler.ListRoles)
+		roles.GET("/permissions", read, roleHandler.ListPermissions)
+		roles.PUT("/:role/permissions/:permission", write, roleHandler.GrantPermission)
+		roles.DELETE("/:role/permissions/:permission", write, roleHandler.RevokePermission)
 	}
 
 	products := router.Group("/products")
 	{
+		write := auth.RequirePermission("products:write")
 		products.GET("/", productHandler.ListProducts)
-		products.POST("/", productHandler.CreateProduct)
+		products.POST("/", write, productHandler.CreateProduct)
 		products.GET("/:id", productHandler.GetProduct)
-		products.PUT("/:id", productHandler.UpdateProduct)
-		products.DELETE("/:id", productHandler.DeleteProduct)
+		products.PUT("/:id", write, productHandler.UpdateProduct)
+		products.PATCH("/:id", write, productHandler.PatchProduct)
+		products.DELETE("/:id", write, productHandler.DeleteProduct)
+		products.POST("/:id/restore", auth.RequirePermission("products:restore"), productHandler.RestoreProduct)
 		products.PUT("/search", productHandler.SearchProducts)
+		products.POST("/import", write, productHandler.ImportProducts)
+		products.GET("/export", productHandler.ExportProducts)
 	}
 
 	orders := router.Group("/orders")
 	{
-		orders.GET("/", orderHandler.ListOrders)
-		orders.POST("/", orderHandler.CreateOrder)
-		orders.GET("/:id", orderHandler.GetOrder)
-		orders.PUT("/:id", orderHandler.UpdateOrder)
-		orders.DELETE("/:id", orderHandler.DeleteOrder)
-		orders.PUT("/search", orderHandler.SearchOrders)
+		readAny := auth.RequirePermission("orders:read:any")
+		write := auth.RequirePermission("orders:write")
+		shipmentRead := auth.RequirePermission("shipments:read", "shipments:write")
+		shipmentWrite := auth.RequirePermission("shipments:write")
+		orders.GET("/", readAny, orderHandler.ListOrders)
+		orders.POST("/", auth.RequireToken(), orderHandler.CreateOrder)
+		orders.GET("/:id", auth.RequireToken(), orderHandler.GetOrder)
+		orders.GET("/:id/invoice", auth.RequireToken(), orderHandler.GetInvoice)
+		orders.GET("/:id/shipments", shipmentRead, orderHandler.ListShipments)
+		orders.POST("/:id/shipments", shipmentWrite, orderHandler.CreateShipment)
+		orders.GET("/:id/shipments/:shipment_id", shipmentRead, orderHandler.GetShipment)
+		orders.PUT("/:id/shipments/:shipment_id", shipmentWrite, orderHandler.UpdateShipment)
+		orders.PUT("/:id", write, orderHandler.UpdateOrder)
+		orders.PATCH("/:id", write, orderHandler.PatchOrder)
+		orders.DELETE("/:id", write, orderHandler.DeleteOrder)
+		orders.POST("/:id/restore", auth.RequirePermission("orders:restore"), orderHandler.RestoreOrder)
+		orders.PUT("/search", readAny, orderHandler.SearchOrders)
 	}
 
 	payments := router.Group("/payments")
 	{
-		payments.GET("/", paymentHandler.ListPayments)
-		payments.POST("/", paymentHandler.CreatePayment)
-		payments.GET("/:id", paymentHandler.GetPayment)
-		payments.PUT("/:id", paymentHandler.UpdatePayment)
-		payments.DELETE("/:id", paymentHandler.DeletePayment)
-		payments.PUT("/search", paymentHandler.SearchPayments)
+		readAny := auth.RequirePermission("payments:read:any")
+		write := auth.RequirePermission("payments:write")
+		payments.GET("/", readAny, paymentHandler.ListPayments)
+		payments.POST("/", auth.RequireToken(), paymentHandler.CreatePayment)
+		payments.GET("/:id", auth.RequireToken(), paymentHandler.GetPayment)
+		payments.PUT("/:id", write, paymentHandler.UpdatePayment)
+		payments.PATCH("/:id", write, paymentHandler.PatchPayment)
+		payments.POST("/:id/refund", write, paymentHandler.RefundPayment)
+		payments.DELETE("/:id", write, paymentHandler.DeletePayment)
+		payments.POST("/:id/restore", auth.RequirePermission("payments:restore"), paymentHandler.RestorePayment)
+		payments.PUT("/search", readAny, paymentHandler.SearchPayments)
 	}
+
+	router.GET("/graphql", graphQLHandler.Query)
+	router.POST("/graphql", graphQLHandler.Query)
 }
@@ -3,34 +3,84 @@ package http
 import (
 	"api-gateway-service/internal/api/handler"
 	"api-gateway-service/internal/api/routes"
+	"api-gateway-service/internal/config"
+	"api-gateway-service/pkg/apperror"
+	"api-gateway-service/pkg/auth"
+	"api-gateway-service/pkg/logging"
+	"api-gateway-service/pkg/metrics"
+	"api-gateway-service/pkg/response"
+	"api-gateway-service/pkg/tracing"
+	"context"
 	"github.com/gin-gonic/gin"
 	swaggerFiles "github.com/swaggo/files"
 	ginSwagger "github.com/swaggo/gin-swagger"
-	"log"
+	"log/slog"
 	"net/http"
+	"os/signal"
+	"syscall"
+	"time"
 )
 
 type Server struct {
-	engine *gin.Engine
+	http            *http.Server
+	shutdownTimeout time.Duration
 }
 
-func NewServer(userHandler *handler.UserHandler, orderHandler *handler.OrderHandler, productHandler *handler.ProductHandler, paymentHandler *handler.PaymentHandler) *Server {
-	router := gin.Default()
-	router.Use(gin.Logger())
+func NewServer(cfg config.Config, userHandler *handler.UserHandler, roleHandler *handler.RoleHandler, orderHandler *handler.OrderHandler, productHandler *handler.ProductHandler, paymentHandler *handler.PaymentHandler, graphQLHandler *handler.GraphQLHandler, healthHandler *handler.HealthHandler) *Server {
+	router := gin.New()
+	router.Use(tracing.Middleware("api-gateway-service"))
+	router.Use(logging.Middleware(slog.Default()))
+	router.Use(metrics.Middleware())
 	router.Use(gin.Recovery())
+	router.Use(ErrorMiddleware())
 	router.Use(MethodNotAllowedMiddleware())
+	router.Use(auth.Middleware(cfg.TokenSecret))
 
 	router.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerFiles.Handler))
+	router.GET("/metrics", metrics.Handler())
+	router.GET("/healthz", healthHandler.Live)
+	router.GET("/readyz", healthHandler.Ready)
 
-	routes.InitRoutes(router.Group("/api"), userHandler, orderHandler, productHandler, paymentHandler)
+	routes.InitRoutes(router.Group("/api"), userHandler, roleHandler, orderHandler, productHandler, paymentHandler, graphQLHandler)
 
-	return &Server{router}
+	return &Server{
+		http: &http.Server{
+			Addr:         cfg.HTTPAddr,
+			Handler:      router,
+			ReadTimeout:  cfg.ReadTimeout,
+			WriteTimeout: cfg.WriteTimeout,
+			IdleTimeout:  cfg.IdleTimeout,
+		},
+		shutdownTimeout: cfg.ShutdownTimeout,
+	}
 }
 
-func (s *Server) Run(infoLog *log.Logger, errorLog *log.Logger) {
-	infoLog.Printf("starting server on: 8080")
-	err := s.engine.Run(":8080")
-	errorLog.Fatal(err)
+// Run serves until SIGINT or SIGTERM, then stops accepting connections and
+// gives the requests in flight shutdownTimeout to finish. The error is the
+// one the server failed with, nil after a signal.
+func (s *Server) Run(logger *slog.Logger) error {
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	errs := make(chan error, 1)
+	go func() {
+		logger.Info("starting server", "addr", s.http.Addr)
+		errs <- s.http.ListenAndServe()
+	}()
+
+	select {
+	case err := <-errs:
+		return err
+	case <-ctx.Done():
+		logger.Info("shutting down", "timeout", s.shutdownTimeout.String())
+	}
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), s.shutdownTimeout)
+	defer cancel()
+	if err := s.http.Shutdown(shutdownCtx); err != nil {
+		logger.Error("failed to drain connections", "error", err)
+	}
+	return nil
 }
 
 func MethodNotAllowedMiddleware() gin.HandlerFunc {
@@ -39,6 +89,7 @@ func MethodNotAllowedMiddleware() gin.HandlerFunc {
 			"GET":    true,
 			"POST":   true,
 			"PUT":    true,
+			"PATCH":  true,
 			"DELETE": true,
 		}
 
@@ -53,3 +104,18 @@ func MethodNotAllowedMiddleware() gin.HandlerFunc {
 		c.Next()
 	}
 }
+
+// ErrorMiddleware answers with the error a handler passed to c.Error, in the
+// response envelope with the apperror.Error under error. Any other error is
+// answered as internal, the logging middleware logs what it was.
+func ErrorMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Next()
+
+		if len(c.Errors) == 0 || c.Writer.Written() {
+			return
+		}
+		appErr := apperror.From(c.Errors.Last().Err)
+		c.JSON(appErr.Status, response.ClientResponse(appErr.Status, appErr.Message, nil, appErr))
+	}
+}
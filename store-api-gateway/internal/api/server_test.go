@@ -0,0 +1,137 @@
+package http
+
+import (
+	"api-gateway-service/internal/api/handler"
+	"api-gateway-service/internal/config"
+	"api-gateway-service/pkg/auth"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+const tokenSecret = "0123456789abcdef0123456789abcdef"
+
+// bearerToken returns the Authorization header of a token with permissions.
+func bearerToken(t *testing.T, subject string, permissions ...string) string {
+	t.Helper()
+	now := time.Now()
+	token, err := auth.Sign(auth.Claims{Subject: subject, Permissions: permissions, IssuedAt: now.Unix(), ExpiresAt: now.Add(time.Minute).Unix()}, tokenSecret)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return "Bearer " + token
+}
+
+func TestPatchPassesVersionThrough(t *testing.T) {
+	const patch = `{"status":"paid"}`
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPatch || r.URL.Path != "/orders/42" {
+			t.Errorf("upstream got %s %s, want PATCH /orders/42", r.Method, r.URL.Path)
+		}
+		if r.Header.Get("Authorization") == "" {
+			t.Error("upstream got no Authorization")
+		}
+		if got := r.Header.Get("Content-Type"); got != "application/merge-patch+json" {
+			t.Errorf("upstream Content-Type = %q", got)
+		}
+		body, _ := io.ReadAll(r.Body)
+		if string(body) != patch {
+			t.Errorf("upstream body = %s, want %s", body, patch)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		if r.Header.Get("If-Match") != `"3"` {
+			w.WriteHeader(http.StatusPreconditionFailed)
+			io.WriteString(w, `{"status_code":412,"message":"version mismatch"}`)
+			return
+		}
+		w.Header().Set("ETag", `"4"`)
+		io.WriteString(w, `{"status_code":200,"message":"order patched"}`)
+	}))
+	defer upstream.Close()
+
+	orderHandler := handler.NewOrderHandler(upstream.URL+"/orders", nil)
+	s := NewServer(config.Config{TokenSecret: tokenSecret}, nil, nil, orderHandler, nil, nil, nil, nil)
+	authorization := bearerToken(t, "admin", "orders:write")
+
+	tests := []struct {
+		name     string
+		ifMatch  string
+		wantCode int
+		wantETag string
+	}{
+		{"current version", `"3"`, http.StatusOK, `"4"`},
+		{"stale version", `"2"`, http.StatusPreconditionFailed, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPatch, "/api/orders/42", strings.NewReader(patch))
+			req.Header.Set("Content-Type", "application/merge-patch+json")
+			req.Header.Set("If-Match", tt.ifMatch)
+			req.Header.Set("Authorization", authorization)
+			rec := httptest.NewRecorder()
+			s.http.Handler.ServeHTTP(rec, req)
+
+			if rec.Code != tt.wantCode {
+				t.Fatalf("status = %d, want %d: %s", rec.Code, tt.wantCode, rec.Body)
+			}
+			if got := rec.Header().Get("ETag"); got != tt.wantETag {
+				t.Errorf("ETag = %q, want %q", got, tt.wantETag)
+			}
+		})
+	}
+}
+
+func TestMethodNotAllowed(t *testing.T) {
+	s := NewServer(config.Config{}, nil, nil, nil, nil, nil, nil, nil)
+
+	req := httptest.NewRequest(http.MethodOptions, "/api/orders/42", nil)
+	rec := httptest.NewRecorder()
+	s.http.Handler.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusMethodNotAllowed {
+		t.Errorf("OPTIONS status = %d, want %d", rec.Code, http.StatusMethodNotAllowed)
+	}
+}
+
+func TestUserRoutesCheckTokens(t *testing.T) {
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		io.WriteString(w, `{"status_code":200,"message":"ok"}`)
+	}))
+	defer upstream.Close()
+
+	userHandler := handler.NewUserHandler(upstream.URL+"/users", nil)
+	s := NewServer(config.Config{TokenSecret: tokenSecret}, userHandler, nil, nil, nil, nil, nil, nil)
+
+	tests := []struct {
+		name          string
+		method, path  string
+		authorization string
+		wantCode      int
+	}{
+		{"sign-up without a token", http.MethodPost, "/api/users/", "", http.StatusOK},
+		{"list without a token", http.MethodGet, "/api/users/", "", http.StatusUnauthorized},
+		{"list without the permission", http.MethodGet, "/api/users/", bearerToken(t, "u1"), http.StatusForbidden},
+		{"list with the permission", http.MethodGet, "/api/users/", bearerToken(t, "u1", "users:read:any"), http.StatusOK},
+		{"read oneself", http.MethodGet, "/api/users/u1", bearerToken(t, "u1"), http.StatusOK},
+		{"read another user", http.MethodGet, "/api/users/u2", bearerToken(t, "u1"), http.StatusForbidden},
+		{"forged token", http.MethodGet, "/api/users/u1", "Bearer " + strings.Repeat("x", 20), http.StatusUnauthorized},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.path, strings.NewReader(`{}`))
+			if tt.authorization != "" {
+				req.Header.Set("Authorization", tt.authorization)
+			}
+			rec := httptest.NewRecorder()
+			s.http.Handler.ServeHTTP(rec, req)
+
+			if rec.Code != tt.wantCode {
+				t.Errorf("status = %d, want %d: %s", rec.Code, tt.wantCode, rec.Body)
+			}
+		})
+	}
+}
@@ -1,38 +1,103 @@
 package config
 
 import (
-	"github.com/joho/godotenv"
-	"github.com/kelseyhightower/envconfig"
+	"api-gateway-service/pkg/settings"
+	"errors"
+	"io"
 	"os"
-	"path/filepath"
+	"strings"
+	"time"
 )
 
+// Config holds the settings of the service, see LoadConfig for where they
+// come from and pkg/settings for the tags.
 type Config struct {
-	UserURL    string
-	OrderURL   string
-	PaymentURL string
-	ProductURL string
-}
+	UserURL    string `yaml:"user_url" env:"userURL" required:"true"`
+	OrderURL   string `yaml:"order_url" env:"orderURL" required:"true"`
+	PaymentURL string `yaml:"payment_url" env:"paymentURL" required:"true"`
+	ProductURL string `yaml:"product_url" env:"productURL" required:"true"`
+	// RoleURL is the roles API of the user service, e.g.
+	// http://user-service:8000/roles.
+	RoleURL string `yaml:"role_url" env:"roleURL" required:"true"`
 
-func LoadConfig() (cfg Config, err error) {
+	// gRPC addresses of the services, e.g. user-service:9000. A service with
+	// an address is called over gRPC wherever its API has the call, the REST
+	// URL above serves the rest of its routes.
+	UserGRPC    string `yaml:"user_grpc" env:"userGRPC"`
+	OrderGRPC   string `yaml:"order_grpc" env:"orderGRPC"`
+	PaymentGRPC string `yaml:"payment_grpc" env:"paymentGRPC"`
+	ProductGRPC string `yaml:"product_grpc" env:"productGRPC"`
 
-	root, err := os.Getwd()
-	if err != nil {
-		return
-	}
+	// TokenSecret checks the access tokens the user service signs with the
+	// same secret, it must be at least 32 bytes.
+	TokenSecret string `yaml:"token_secret" env:"tokenSecret" required:"true" secret:"true"`
 
-	err = godotenv.Load(filepath.Join(root, ".env"))
-	if err != nil {
-		cfg.UserURL = os.Getenv("userURL")
-		cfg.OrderURL = os.Getenv("orderURL")
-		cfg.PaymentURL = os.Getenv("paymentURL")
-		cfg.ProductURL = os.Getenv("productURL")
+	// LogLevel is one of debug, info, warn or error. TraceExporter is otlp,
+	// stdout or empty for no exporter. OTLPEndpoint is the host:port of the
+	// collector for otlp, e.g. otel-collector:4317.
+	LogLevel      string `yaml:"log_level" env:"logLevel"`
+	TraceExporter string `yaml:"trace_exporter" env:"traceExporter"`
+	OTLPEndpoint  string `yaml:"otlp_endpoint" env:"otlpEndpoint"`
 
-		return cfg, nil
+	// HTTPAddr is the listen address of the gateway. ShutdownTimeout is how
+	// long requests in flight get to finish after SIGTERM.
+	HTTPAddr        string        `yaml:"http_addr" env:"httpAddr"`
+	ReadTimeout     time.Duration `yaml:"read_timeout" env:"readTimeout"`
+	WriteTimeout    time.Duration `yaml:"write_timeout" env:"writeTimeout"`
+	IdleTimeout     time.Duration `yaml:"idle_timeout" env:"idleTimeout"`
+	ShutdownTimeout time.Duration `yaml:"shutdown_timeout" env:"shutdownTimeout"`
+
+	// PrintConfig is set by the -print-config flag, the service then prints
+	// its settings instead of starting.
+	PrintConfig bool `yaml:"-"`
+}
+
+// Default is the configuration before any source is read.
+func Default() Config {
+	return Config{
+		LogLevel:        "info",
+		HTTPAddr:        ":8080",
+		ReadTimeout:     10 * time.Second,
+		WriteTimeout:    30 * time.Second,
+		IdleTimeout:     2 * time.Minute,
+		ShutdownTimeout: 15 * time.Second,
 	}
-	if err = envconfig.Process("", &cfg); err != nil {
+}
+
+// LoadConfig reads the configuration over Default from config.yaml, or the
+// file named by -config or configFile, then from the environment and the
+// command line flags, each overriding the one before. The error lists every
+// setting that is missing or wrong.
+func LoadConfig() (cfg Config, err error) {
+	cfg = Default()
+	if cfg.PrintConfig, err = settings.Load(&cfg, os.Args[1:]); err != nil {
 		return
 	}
-
+	err = cfg.Validate()
 	return
 }
+
+func (cfg Config) Validate() error {
+	errs := []error{
+		settings.OneOf("log_level", strings.ToLower(cfg.LogLevel), "debug", "info", "warn", "error"),
+		settings.OneOf("trace_exporter", cfg.TraceExporter, "", "otlp", "stdout"),
+		settings.Positive("read_timeout", cfg.ReadTimeout),
+		settings.Positive("write_timeout", cfg.WriteTimeout),
+		settings.Positive("idle_timeout", cfg.IdleTimeout),
+		settings.Positive("shutdown_timeout", cfg.ShutdownTimeout),
+	}
+	errs = append(errs,
+		settings.HTTPURL("user_url", cfg.UserURL),
+		settings.HTTPURL("order_url", cfg.OrderURL),
+		settings.HTTPURL("payment_url", cfg.PaymentURL),
+		settings.HTTPURL("product_url", cfg.ProductURL),
+		settings.HTTPURL("role_url", cfg.RoleURL),
+		settings.Secret("token_secret", cfg.TokenSecret, 32),
+	)
+	return errors.Join(errs...)
+}
+
+// Print writes the settings as YAML with the secrets masked.
+func (cfg Config) Print(w io.Writer) error {
+	return settings.Print(w, cfg)
+}
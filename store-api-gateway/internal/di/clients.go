@@ -0,0 +1,68 @@
+package di
+
+import (
+	"api-gateway-service/internal/config"
+	"api-gateway-service/pkg/auth"
+	"api-gateway-service/pkg/logging"
+	"api-gateway-service/pkg/metrics"
+	"api-gateway-service/pkg/pb/orderpb"
+	"api-gateway-service/pkg/pb/paymentpb"
+	"api-gateway-service/pkg/pb/productpb"
+	"api-gateway-service/pkg/pb/userpb"
+	"api-gateway-service/pkg/tracing"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+)
+
+// NewUserClient connects to the gRPC API of the user service at
+// cfg.UserGRPC, without an address the service is proxied over REST.
+func NewUserClient(cfg config.Config) (userpb.UserServiceClient, error) {
+	conn, err := dial(cfg.UserGRPC)
+	if conn == nil || err != nil {
+		return nil, err
+	}
+	return userpb.NewUserServiceClient(conn), nil
+}
+
+// NewOrderClient connects to the gRPC API of the order service at
+// cfg.OrderGRPC, without an address the service is proxied over REST.
+func NewOrderClient(cfg config.Config) (orderpb.OrderServiceClient, error) {
+	conn, err := dial(cfg.OrderGRPC)
+	if conn == nil || err != nil {
+		return nil, err
+	}
+	return orderpb.NewOrderServiceClient(conn), nil
+}
+
+// NewPaymentClient connects to the gRPC API of the payment service at
+// cfg.PaymentGRPC, without an address the service is proxied over REST.
+func NewPaymentClient(cfg config.Config) (paymentpb.PaymentServiceClient, error) {
+	conn, err := dial(cfg.PaymentGRPC)
+	if conn == nil || err != nil {
+		return nil, err
+	}
+	return paymentpb.NewPaymentServiceClient(conn), nil
+}
+
+// NewProductClient connects to the gRPC API of the product service at
+// cfg.ProductGRPC, without an address the service is proxied over REST.
+func NewProductClient(cfg config.Config) (productpb.ProductServiceClient, error) {
+	conn, err := dial(cfg.ProductGRPC)
+	if conn == nil || err != nil {
+		return nil, err
+	}
+	return productpb.NewProductServiceClient(conn), nil
+}
+
+// dial returns a nil connection for an empty address. The upstreams are on
+// the internal network, so the connection is not encrypted.
+func dial(addr string) (*grpc.ClientConn, error) {
+	if addr == "" {
+		return nil, nil
+	}
+	return grpc.NewClient(addr,
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithChainUnaryInterceptor(logging.UnaryClientInterceptor(), metrics.UnaryClientInterceptor(), auth.UnaryClientInterceptor()),
+		tracing.DialOption(),
+	)
+}
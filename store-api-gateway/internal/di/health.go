@@ -0,0 +1,33 @@
+package di
+
+import (
+	"api-gateway-service/internal/config"
+	"api-gateway-service/pkg/health"
+	"api-gateway-service/pkg/logging"
+	"api-gateway-service/pkg/metrics"
+	"api-gateway-service/pkg/tracing"
+	"net/http"
+	"net/url"
+)
+
+// NewHealthChecker makes the gateway ready when the services it routes to
+// are, each one is asked for its own /readyz.
+func NewHealthChecker(cfg config.Config) *health.Checker {
+	client := &http.Client{Transport: tracing.Transport(metrics.Transport{Base: logging.Transport{}})}
+	upstreams := []struct{ name, url string }{
+		{"user-service", cfg.UserURL},
+		{"product-service", cfg.ProductURL},
+		{"order-service", cfg.OrderURL},
+		{"payment-service", cfg.PaymentURL},
+	}
+
+	checker := health.NewChecker()
+	for _, upstream := range upstreams {
+		u, err := url.Parse(upstream.url)
+		if upstream.url == "" || err != nil {
+			continue
+		}
+		checker.Add(upstream.name, health.HTTP(client, u.Scheme+"://"+u.Host+"/readyz"))
+	}
+	return checker
+}
@@ -9,13 +9,38 @@ import (
 	"api-gateway-service/internal/api"
 	"api-gateway-service/internal/api/handler"
 	"api-gateway-service/internal/config"
+	"api-gateway-service/internal/graph"
 )
 
 func InitializeAPI(cfg config.Config) (*http.Server, error) {
-	orderHandler := handler.NewOrderHandler(cfg.OrderURL)
-	productHandler := handler.NewProductHandler(cfg.ProductURL)
-	paymentHandler := handler.NewPaymentHandler(cfg.PaymentURL)
-	userHandler := handler.NewUserHandler(cfg.UserURL)
-	server := http.NewServer(userHandler, orderHandler, productHandler, paymentHandler)
+	orderServiceClient, err := NewOrderClient(cfg)
+	if err != nil {
+		return nil, err
+	}
+	orderHandler := handler.NewOrderHandler(cfg.OrderURL, orderServiceClient)
+	productServiceClient, err := NewProductClient(cfg)
+	if err != nil {
+		return nil, err
+	}
+	productHandler := handler.NewProductHandler(cfg.ProductURL, productServiceClient)
+	paymentServiceClient, err := NewPaymentClient(cfg)
+	if err != nil {
+		return nil, err
+	}
+	paymentHandler := handler.NewPaymentHandler(cfg.PaymentURL, paymentServiceClient)
+	userServiceClient, err := NewUserClient(cfg)
+	if err != nil {
+		return nil, err
+	}
+	userHandler := handler.NewUserHandler(cfg.UserURL, userServiceClient)
+	roleHandler := handler.NewRoleHandler(cfg.RoleURL)
+	upstream := graph.NewUpstream(cfg, userServiceClient, productServiceClient, orderServiceClient, paymentServiceClient)
+	graphQLHandler, err := handler.NewGraphQLHandler(upstream)
+	if err != nil {
+		return nil, err
+	}
+	checker := NewHealthChecker(cfg)
+	healthHandler := handler.NewHealthHandler(checker)
+	server := http.NewServer(cfg, userHandler, roleHandler, orderHandler, productHandler, paymentHandler, graphQLHandler, healthHandler)
 	return server, nil
 }
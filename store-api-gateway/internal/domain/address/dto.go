@@ -0,0 +1,14 @@
+package address
+
+type Request struct {
+	Label      string `json:"label"`
+	Recipient  string `json:"recipient"`
+	Line1      string `json:"line1"`
+	Line2      string `json:"line2"`
+	City       string `json:"city"`
+	Region     string `json:"region"`
+	PostalCode string `json:"postal_code"`
+	Country    string `json:"country" example:"KZ"`
+	Phone      string `json:"phone"`
+	IsDefault  bool   `json:"is_default"`
+}
@@ -1,8 +1,134 @@
 package order
 
+import (
+	"api-gateway-service/pkg/money"
+	"api-gateway-service/pkg/pb/orderpb"
+	"time"
+)
+
 type Request struct {
-	UserID    string   `db:"user_id" bson:"user_id"`
-	ProductID []string `db:"product_id" bson:"product_id"`
-	Pricing   float64  `db:"pricing" bson:"pricing"`
-	Status    string   `db:"status" bson:"status"`
+	UserID            string      `db:"user_id" bson:"user_id"`
+	ProductID         []string    `db:"product_id" bson:"product_id"`
+	Pricing           money.Money `db:"pricing" bson:"pricing"`
+	Currency          string      `db:"currency" bson:"currency"`
+	PromoCodes        []string    `db:"promo_codes" bson:"promo_codes"`
+	Country           string      `db:"country" bson:"country"`
+	Region            string      `db:"region" bson:"region"`
+	ShippingAddressID string      `db:"shipping_address_id" bson:"shipping_address_id"`
+	ShippingMethod    string      `db:"shipping_method" bson:"shipping_method"`
+	Status            string      `db:"status" bson:"status"`
+}
+
+// Patch is a merge patch of an order, members left out keep their value.
+type Patch struct {
+	UserID    *string      `db:"user_id" bson:"user_id"`
+	ProductID *[]string    `db:"product_id" bson:"product_id"`
+	Pricing   *money.Money `db:"pricing" bson:"pricing"`
+	Status    *string      `db:"status" bson:"status"`
+}
+
+type Response struct {
+	ID                string             `json:"id"`
+	UserID            string             `db:"user_id" bson:"user_id"`
+	ProductID         []string           `db:"product_id" bson:"product_id"`
+	Pricing           money.Money        `db:"pricing" bson:"pricing"`
+	BasePricing       money.Money        `db:"base_pricing" bson:"base_pricing"`
+	ExchangeRate      string             `db:"exchange_rate" bson:"exchange_rate"`
+	Discount          money.Money        `db:"discount" bson:"discount"`
+	Discounts         []DiscountResponse `db:"discounts" bson:"discounts"`
+	Country           string             `db:"country" bson:"country"`
+	Region            string             `db:"region" bson:"region"`
+	Tax               money.Money        `db:"tax" bson:"tax"`
+	Taxes             []TaxResponse      `db:"taxes" bson:"taxes"`
+	ShippingAddressID string             `db:"shipping_address_id" bson:"shipping_address_id"`
+	ShippingAddress   string             `db:"shipping_address" bson:"shipping_address"`
+	ShippingMethod    string             `db:"shipping_method" bson:"shipping_method"`
+	Shipping          money.Money        `db:"shipping" bson:"shipping"`
+	Status            string             `db:"status" bson:"status"`
+	CreatedAt         time.Time          `db:"created_at" bson:"created_at"`
+}
+
+type DiscountResponse struct {
+	PromotionID string      `db:"promotion_id" bson:"promotion_id"`
+	Code        string      `db:"code" bson:"code"`
+	Type        string      `db:"type" bson:"type"`
+	Amount      money.Money `db:"amount" bson:"amount"`
+}
+
+type TaxResponse struct {
+	Name      string      `db:"name" bson:"name"`
+	Country   string      `db:"country" bson:"country"`
+	Region    string      `db:"region" bson:"region"`
+	Category  string      `db:"category" bson:"category"`
+	Rate      string      `db:"rate" bson:"rate"`
+	Inclusive bool        `db:"inclusive" bson:"inclusive"`
+	Taxable   money.Money `db:"taxable" bson:"taxable"`
+	Amount    money.Money `db:"amount" bson:"amount"`
+}
+
+func protoMoney(m *orderpb.Money) money.Money {
+	return parseMoney(m.GetAmount(), m.GetCurrency())
+}
+
+// ParseFromProto reads a order as served by the gRPC API of the service.
+func ParseFromProto(data *orderpb.Order) Response {
+	res := Response{
+		ID:                data.GetId(),
+		UserID:            data.GetUserId(),
+		ProductID:         data.GetProductId(),
+		Pricing:           protoMoney(data.GetPricing()),
+		BasePricing:       protoMoney(data.GetBasePricing()),
+		ExchangeRate:      data.GetExchangeRate(),
+		Discount:          protoMoney(data.GetDiscount()),
+		Discounts:         make([]DiscountResponse, 0, len(data.GetDiscounts())),
+		Country:           data.GetCountry(),
+		Region:            data.GetRegion(),
+		Tax:               protoMoney(data.GetTax()),
+		Taxes:             make([]TaxResponse, 0, len(data.GetTaxes())),
+		ShippingAddressID: data.GetShippingAddressId(),
+		ShippingAddress:   data.GetShippingAddress(),
+		ShippingMethod:    data.GetShippingMethod(),
+		Shipping:          protoMoney(data.GetShipping()),
+		Status:            data.GetStatus(),
+		CreatedAt:         data.GetCreatedAt().AsTime(),
+	}
+	for _, discount := range data.GetDiscounts() {
+		res.Discounts = append(res.Discounts, DiscountResponse{
+			PromotionID: discount.GetPromotionId(),
+			Code:        discount.GetCode(),
+			Type:        discount.GetType(),
+			Amount:      protoMoney(discount.GetAmount()),
+		})
+	}
+	for _, line := range data.GetTaxes() {
+		res.Taxes = append(res.Taxes, TaxResponse{
+			Name:      line.GetName(),
+			Country:   line.GetCountry(),
+			Region:    line.GetRegion(),
+			Category:  line.GetCategory(),
+			Rate:      line.GetRate(),
+			Inclusive: line.GetInclusive(),
+			Taxable:   protoMoney(line.GetTaxable()),
+			Amount:    protoMoney(line.GetAmount()),
+		})
+	}
+	return res
+}
+
+func ParseFromProtos(data []*orderpb.Order) (res []Response) {
+	res = make([]Response, 0, len(data))
+	for _, item := range data {
+		res = append(res, ParseFromProto(item))
+	}
+	return
+}
+
+// parseMoney reads an amount as formatted by the gRPC API of the service. An
+// unset amount is the zero value, like it is in the REST answers.
+func parseMoney(amount, currency string) money.Money {
+	m, err := money.Parse(amount, currency)
+	if err != nil {
+		return money.New(0, currency)
+	}
+	return m
 }
@@ -1,7 +1,59 @@
 package payment
 
+import (
+	"api-gateway-service/pkg/money"
+	"api-gateway-service/pkg/pb/paymentpb"
+	"time"
+)
+
 type Request struct {
-	UserID  string  `json:"user_id"`
-	OrderID string  `json:"order_id"`
-	Amount  float64 `json:"amount"`
+	UserID  string      `json:"user_id"`
+	OrderID string      `json:"order_id"`
+	Amount  money.Money `json:"amount"`
+}
+
+// Patch is a merge patch of a payment, members left out keep their value.
+type Patch struct {
+	UserID  *string      `json:"user_id"`
+	OrderID *string      `json:"order_id"`
+	Amount  *money.Money `json:"amount"`
+}
+
+type Response struct {
+	ID        string      `json:"id"`
+	UserID    string      `json:"user_id"`
+	OrderID   string      `json:"order_id"`
+	Amount    money.Money `json:"amount"`
+	Status    string      `json:"status"`
+	CreatedAt time.Time   `json:"created_at"`
+}
+
+// ParseFromProto reads a payment as served by the gRPC API of the service.
+func ParseFromProto(data *paymentpb.Payment) Response {
+	return Response{
+		ID:        data.GetId(),
+		UserID:    data.GetUserId(),
+		OrderID:   data.GetOrderId(),
+		Amount:    parseMoney(data.GetAmount().GetAmount(), data.GetAmount().GetCurrency()),
+		Status:    data.GetStatus(),
+		CreatedAt: data.GetCreatedAt().AsTime(),
+	}
+}
+
+func ParseFromProtos(data []*paymentpb.Payment) (res []Response) {
+	res = make([]Response, 0, len(data))
+	for _, item := range data {
+		res = append(res, ParseFromProto(item))
+	}
+	return
+}
+
+// parseMoney reads an amount as formatted by the gRPC API of the service. An
+// unset amount is the zero value, like it is in the REST answers.
+func parseMoney(amount, currency string) money.Money {
+	m, err := money.Parse(amount, currency)
+	if err != nil {
+		return money.New(0, currency)
+	}
+	return m
 }
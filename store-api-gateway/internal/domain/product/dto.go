@@ -1,9 +1,75 @@
 package product
 
+import (
+	"api-gateway-service/pkg/money"
+	"api-gateway-service/pkg/pb/productpb"
+	"time"
+)
+
 type Request struct {
-	Title       string  `json:"title"`
-	Description string  `json:"description"`
-	Price       float64 `json:"price"`
-	Category    string  `json:"category"`
-	Quantity    int     `json:"quantity"`
+	Title       string      `json:"title"`
+	Description string      `json:"description"`
+	Price       money.Money `json:"price"`
+	Category    string      `json:"category"`
+	Quantity    int         `json:"quantity"`
+	Weight      int         `json:"weight" example:"500"`
+}
+
+// Patch is a merge patch of a product, members left out keep their value.
+type Patch struct {
+	Title       *string      `json:"title"`
+	Description *string      `json:"description"`
+	Price       *money.Money `json:"price"`
+	Category    *string      `json:"category"`
+	Quantity    *int         `json:"quantity"`
+	Weight      *int         `json:"weight" example:"500"`
+}
+
+type Response struct {
+	ID          string       `json:"id"`
+	Title       string       `json:"title"`
+	Description string       `json:"description"`
+	Price       money.Money  `json:"price"`
+	BasePrice   *money.Money `json:"base_price,omitempty"`
+	Category    string       `json:"category"`
+	Quantity    int          `json:"quantity"`
+	Weight      int          `json:"weight"`
+	CreatedAt   time.Time    `json:"created_at"`
+}
+
+// ParseFromProto reads a product as served by the gRPC API of the service.
+func ParseFromProto(data *productpb.Product) Response {
+	res := Response{
+		ID:          data.GetId(),
+		Title:       data.GetTitle(),
+		Description: data.GetDescription(),
+		Price:       parseMoney(data.GetPrice().GetAmount(), data.GetPrice().GetCurrency()),
+		Category:    data.GetCategory(),
+		Quantity:    int(data.GetQuantity()),
+		Weight:      int(data.GetWeight()),
+		CreatedAt:   data.GetCreatedAt().AsTime(),
+	}
+	if base := data.GetBasePrice(); base != nil {
+		basePrice := parseMoney(base.GetAmount(), base.GetCurrency())
+		res.BasePrice = &basePrice
+	}
+	return res
+}
+
+func ParseFromProtos(data []*productpb.Product) (res []Response) {
+	res = make([]Response, 0, len(data))
+	for _, item := range data {
+		res = append(res, ParseFromProto(item))
+	}
+	return
+}
+
+// parseMoney reads an amount as formatted by the gRPC API of the service. An
+// unset amount is the zero value, like it is in the REST answers.
+func parseMoney(amount, currency string) money.Money {
+	m, err := money.Parse(amount, currency)
+	if err != nil {
+		return money.New(0, currency)
+	}
+	return m
 }
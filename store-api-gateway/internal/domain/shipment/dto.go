@@ -0,0 +1,18 @@
+package shipment
+
+type ItemRequest struct {
+	ProductID string `json:"product_id"`
+	Quantity  int    `json:"quantity" example:"1"`
+}
+
+type Request struct {
+	Carrier        string        `json:"carrier" example:"Kazpost"`
+	TrackingNumber string        `json:"tracking_number"`
+	Items          []ItemRequest `json:"items"`
+}
+
+type UpdateRequest struct {
+	Carrier        string `json:"carrier"`
+	TrackingNumber string `json:"tracking_number"`
+	Status         string `json:"status" example:"shipped"`
+}
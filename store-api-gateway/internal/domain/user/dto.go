@@ -1,8 +1,57 @@
 package user
 
+import (
+	"api-gateway-service/pkg/pb/userpb"
+	"strings"
+	"time"
+)
+
 type Request struct {
 	Name    string `json:"name"`
 	Email   string `json:"email"`
 	Address string `json:"address"`
-	Roles   string `json:"roles"`
+}
+
+// Patch is a merge patch of a user, members left out keep their value.
+type Patch struct {
+	Name    *string `json:"name"`
+	Email   *string `json:"email"`
+	Address *string `json:"address"`
+}
+
+type Response struct {
+	ID      string    `json:"id"`
+	Name    string    `json:"name"`
+	Email   string    `json:"email"`
+	Address string    `json:"address"`
+	RegDate time.Time `json:"reg_date"`
+	Roles   []string  `json:"roles"`
+}
+
+// ParseFromProto reads a user as served by the gRPC API of the service.
+func ParseFromProto(data *userpb.User) Response {
+	return Response{
+		ID:      data.GetId(),
+		Name:    data.GetName(),
+		Email:   data.GetEmail(),
+		Address: data.GetAddress(),
+		RegDate: data.GetRegDate().AsTime(),
+		Roles:   parseRoles(data.GetRoles()),
+	}
+}
+
+// parseRoles splits the comma separated roles of the gRPC API.
+func parseRoles(roles string) []string {
+	if roles == "" {
+		return []string{}
+	}
+	return strings.Split(roles, ",")
+}
+
+func ParseFromProtos(data []*userpb.User) (res []Response) {
+	res = make([]Response, 0, len(data))
+	for _, item := range data {
+		res = append(res, ParseFromProto(item))
+	}
+	return
 }
@@ -0,0 +1,137 @@
+package graph
+
+import (
+	"fmt"
+	"github.com/graphql-go/graphql"
+	"github.com/graphql-go/graphql/language/ast"
+	"github.com/graphql-go/graphql/language/parser"
+)
+
+const (
+	// maxDepth is the deepest nesting of fields a query may select, enough
+	// for payment -> order -> items -> product -> price -> amount and back.
+	maxDepth = 10
+	// maxComplexity caps the estimated number of fields a query resolves.
+	maxComplexity = 5000
+	// listFactor is the number of elements a list field is assumed to
+	// return, the selection under it counts that many times.
+	listFactor = 10
+)
+
+// CheckLimits rejects a query nesting deeper than maxDepth or selecting more
+// than maxComplexity fields before it reaches the services. A query that
+// does not parse passes, the executor reports its syntax error.
+func CheckLimits(schema graphql.Schema, query, operationName string) error {
+	doc, err := parser.Parse(parser.ParseParams{Source: query})
+	if err != nil {
+		return nil
+	}
+
+	w := walker{
+		schema:    schema,
+		fragments: map[string]*ast.FragmentDefinition{},
+		visiting:  map[string]bool{},
+	}
+	var operations []*ast.OperationDefinition
+	for _, def := range doc.Definitions {
+		switch def := def.(type) {
+		case *ast.FragmentDefinition:
+			w.fragments[def.Name.Value] = def
+		case *ast.OperationDefinition:
+			if operationName == "" || def.Name != nil && def.Name.Value == operationName {
+				operations = append(operations, def)
+			}
+		}
+	}
+
+	for _, op := range operations {
+		depth, complexity := w.selectionSet(op.SelectionSet, schema.QueryType())
+		if depth > maxDepth {
+			return fmt.Errorf("query depth %d exceeds the limit of %d", depth, maxDepth)
+		}
+		if complexity > maxComplexity {
+			return fmt.Errorf("query complexity %d exceeds the limit of %d", complexity, maxComplexity)
+		}
+	}
+	return nil
+}
+
+type walker struct {
+	schema    graphql.Schema
+	fragments map[string]*ast.FragmentDefinition
+	visiting  map[string]bool
+}
+
+// selectionSet returns the depth of set and its complexity, every field
+// costs one plus its own selection, times listFactor for a list field.
+func (w *walker) selectionSet(set *ast.SelectionSet, parent graphql.Type) (depth, complexity int) {
+	if set == nil {
+		return
+	}
+	for _, selection := range set.Selections {
+		d, c := 0, 0
+		switch s := selection.(type) {
+		case *ast.Field:
+			t := w.fieldType(parent, s.Name.Value)
+			d, c = w.selectionSet(s.SelectionSet, named(t))
+			if isList(t) {
+				c *= listFactor
+			}
+			d, c = d+1, c+1
+		case *ast.InlineFragment:
+			t := parent
+			if s.TypeCondition != nil {
+				t = w.schema.Type(s.TypeCondition.Name.Value)
+			}
+			d, c = w.selectionSet(s.SelectionSet, t)
+		case *ast.FragmentSpread:
+			name := s.Name.Value
+			fragment, ok := w.fragments[name]
+			if !ok || w.visiting[name] {
+				continue
+			}
+			w.visiting[name] = true
+			d, c = w.selectionSet(fragment.SelectionSet, w.schema.Type(fragment.TypeCondition.Name.Value))
+			w.visiting[name] = false
+		}
+		depth = max(depth, d)
+		complexity += c
+	}
+	return
+}
+
+// fieldType is the type of field name on parent, nil for introspection and
+// unknown fields, whose selections are still counted.
+func (w *walker) fieldType(parent graphql.Type, name string) graphql.Type {
+	object, ok := parent.(*graphql.Object)
+	if !ok {
+		return nil
+	}
+	field, ok := object.Fields()[name]
+	if !ok {
+		return nil
+	}
+	return field.Type
+}
+
+func isList(t graphql.Type) bool {
+	if nonNull, ok := t.(*graphql.NonNull); ok {
+		t = nonNull.OfType
+	}
+	_, ok := t.(*graphql.List)
+	return ok
+}
+
+// named strips the list and non-null wrappers off t.
+func named(t graphql.Type) graphql.Type {
+	for {
+		switch wrapper := t.(type) {
+		case *graphql.NonNull:
+			t = wrapper.OfType
+		case *graphql.List:
+			t = wrapper.OfType
+		default:
+			return t
+		}
+	}
+}
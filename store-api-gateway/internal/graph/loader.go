@@ -0,0 +1,106 @@
+package graph
+
+import (
+	"api-gateway-service/internal/domain/order"
+	"api-gateway-service/internal/domain/payment"
+	"api-gateway-service/internal/domain/product"
+	"api-gateway-service/internal/domain/user"
+	"context"
+	"errors"
+	"github.com/graph-gophers/dataloader/v7"
+	"sync"
+	"time"
+)
+
+const (
+	// loaderWait is how long a loader collects keys before it fetches them,
+	// the resolvers of one level of a query all run well inside it.
+	loaderWait = 2 * time.Millisecond
+	// fetchLimit bounds the calls a single batch makes to a service at once.
+	fetchLimit = 8
+)
+
+type productKey struct {
+	ID       string
+	Currency string
+}
+
+// Loaders batch and cache the upstream reads of one GraphQL request, so an
+// order list asking for the user of every order fetches each user once.
+type Loaders struct {
+	users           *dataloader.Loader[string, *user.Response]
+	products        *dataloader.Loader[productKey, *product.Response]
+	orders          *dataloader.Loader[string, *order.Response]
+	ordersByUser    *dataloader.Loader[string, []order.Response]
+	paymentsByOrder *dataloader.Loader[string, []payment.Response]
+	paymentsByUser  *dataloader.Loader[string, []payment.Response]
+	payments        *dataloader.Loader[string, *payment.Response]
+}
+
+func NewLoaders(up *Upstream) *Loaders {
+	return &Loaders{
+		users:    newLoader(up.GetUser),
+		orders:   newLoader(up.GetOrder),
+		payments: newLoader(up.GetPayment),
+		products: newLoader(func(ctx context.Context, key productKey) (product.Response, error) {
+			return up.GetProduct(ctx, key.ID, key.Currency)
+		}),
+		ordersByUser: newListLoader(func(ctx context.Context, id string) ([]order.Response, error) {
+			return up.SearchOrders(ctx, "user_id", id)
+		}),
+		paymentsByOrder: newListLoader(func(ctx context.Context, id string) ([]payment.Response, error) {
+			return up.SearchPayments(ctx, "order_id", id)
+		}),
+		paymentsByUser: newListLoader(func(ctx context.Context, id string) ([]payment.Response, error) {
+			return up.SearchPayments(ctx, "user_id", id)
+		}),
+	}
+}
+
+// newLoader loads single records, a record that does not exist loads as nil
+// so the field resolves to null instead of failing the query.
+func newLoader[K comparable, V any](fetch func(context.Context, K) (V, error)) *dataloader.Loader[K, *V] {
+	return newListLoader(func(ctx context.Context, key K) (*V, error) {
+		data, err := fetch(ctx, key)
+		if errors.Is(err, errNotFound) {
+			return nil, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		return &data, nil
+	})
+}
+
+// newListLoader builds a loader over fetch. The services have no bulk reads,
+// so a batch is the set of distinct keys fetched concurrently.
+func newListLoader[K comparable, V any](fetch func(context.Context, K) (V, error)) *dataloader.Loader[K, V] {
+	batch := func(ctx context.Context, keys []K) []*dataloader.Result[V] {
+		results := make([]*dataloader.Result[V], len(keys))
+		sem := make(chan struct{}, fetchLimit)
+		var wg sync.WaitGroup
+		for i, key := range keys {
+			wg.Add(1)
+			sem <- struct{}{}
+			go func(i int, key K) {
+				defer func() { <-sem; wg.Done() }()
+				data, err := fetch(ctx, key)
+				results[i] = &dataloader.Result[V]{Data: data, Error: err}
+			}(i, key)
+		}
+		wg.Wait()
+		return results
+	}
+	return dataloader.NewBatchedLoader(batch, dataloader.WithWait[K, V](loaderWait))
+}
+
+type loadersKey struct{}
+
+// WithLoaders returns a copy of ctx carrying the loaders of one request.
+func WithLoaders(ctx context.Context, loaders *Loaders) context.Context {
+	return context.WithValue(ctx, loadersKey{}, loaders)
+}
+
+func loadersFrom(ctx context.Context) *Loaders {
+	return ctx.Value(loadersKey{}).(*Loaders)
+}
@@ -0,0 +1,339 @@
+package graph
+
+import (
+	"api-gateway-service/internal/domain/order"
+	"api-gateway-service/internal/domain/payment"
+	"api-gateway-service/internal/domain/product"
+	"api-gateway-service/internal/domain/user"
+	"api-gateway-service/pkg/money"
+	"github.com/graph-gophers/dataloader/v7"
+	"github.com/graphql-go/graphql"
+)
+
+// orderItem is one line of an order, the products of an order repeat their
+// id once per unit.
+type orderItem struct {
+	ProductID string
+	Quantity  int
+	Currency  string
+}
+
+// NewSchema builds the schema over the services. Nested fields go through
+// the request's Loaders and resolve to thunks, which the executor runs level
+// by level, so the loads of all siblings land in one batch.
+func NewSchema(up *Upstream) (graphql.Schema, error) {
+	moneyType := graphql.NewObject(graphql.ObjectConfig{
+		Name:        "Money",
+		Description: "An amount in major units of its currency, as a decimal string.",
+		Fields: graphql.Fields{
+			"amount":   field(graphql.NewNonNull(graphql.String), func(m money.Money) any { return m.Decimal() }),
+			"currency": field(graphql.NewNonNull(graphql.String), func(m money.Money) any { return m.Currency }),
+		},
+	})
+
+	var userType, productType, orderType, paymentType *graphql.Object
+
+	userType = graphql.NewObject(graphql.ObjectConfig{
+		Name: "User",
+		Fields: graphql.FieldsThunk(func() graphql.Fields {
+			return graphql.Fields{
+				"id":      field(graphql.NewNonNull(graphql.ID), func(u user.Response) any { return u.ID }),
+				"name":    field(graphql.String, func(u user.Response) any { return u.Name }),
+				"email":   field(graphql.String, func(u user.Response) any { return u.Email }),
+				"address": field(graphql.String, func(u user.Response) any { return u.Address }),
+				"regDate": field(graphql.DateTime, func(u user.Response) any { return u.RegDate }),
+				"roles":   field(listOf(graphql.String), func(u user.Response) any { return u.Roles }),
+				"orders": {
+					Type: listOf(orderType),
+					Resolve: resolve(func(p graphql.ResolveParams, u user.Response) (any, error) {
+						return list(loadersFrom(p.Context).ordersByUser.Load(p.Context, u.ID)), nil
+					}),
+				},
+				"payments": {
+					Type: listOf(paymentType),
+					Resolve: resolve(func(p graphql.ResolveParams, u user.Response) (any, error) {
+						return list(loadersFrom(p.Context).paymentsByUser.Load(p.Context, u.ID)), nil
+					}),
+				},
+			}
+		}),
+	})
+
+	productType = graphql.NewObject(graphql.ObjectConfig{
+		Name: "Product",
+		Fields: graphql.Fields{
+			"id":          field(graphql.NewNonNull(graphql.ID), func(p product.Response) any { return p.ID }),
+			"title":       field(graphql.String, func(p product.Response) any { return p.Title }),
+			"description": field(graphql.String, func(p product.Response) any { return p.Description }),
+			"price":       field(moneyType, func(p product.Response) any { return p.Price }),
+			"basePrice": field(moneyType, func(p product.Response) any {
+				if p.BasePrice == nil {
+					return nil
+				}
+				return *p.BasePrice
+			}),
+			"category":  field(graphql.String, func(p product.Response) any { return p.Category }),
+			"quantity":  field(graphql.Int, func(p product.Response) any { return p.Quantity }),
+			"weight":    field(graphql.Int, func(p product.Response) any { return p.Weight }),
+			"createdAt": field(graphql.DateTime, func(p product.Response) any { return p.CreatedAt }),
+		},
+	})
+
+	orderItemType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "OrderItem",
+		Fields: graphql.Fields{
+			"productId": field(graphql.NewNonNull(graphql.ID), func(i orderItem) any { return i.ProductID }),
+			"quantity":  field(graphql.NewNonNull(graphql.Int), func(i orderItem) any { return i.Quantity }),
+			"product": {
+				Type:        productType,
+				Description: "The product priced in currency, the currency of the order by default.",
+				Args: graphql.FieldConfigArgument{
+					"currency": {Type: graphql.String},
+				},
+				Resolve: resolve(func(p graphql.ResolveParams, i orderItem) (any, error) {
+					currency, _ := p.Args["currency"].(string)
+					if currency == "" {
+						currency = i.Currency
+					}
+					return one(loadersFrom(p.Context).products.Load(p.Context, productKey{i.ProductID, currency})), nil
+				}),
+			},
+		},
+	})
+
+	discountType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Discount",
+		Fields: graphql.Fields{
+			"promotionId": field(graphql.ID, func(d order.DiscountResponse) any { return d.PromotionID }),
+			"code":        field(graphql.String, func(d order.DiscountResponse) any { return d.Code }),
+			"type":        field(graphql.String, func(d order.DiscountResponse) any { return d.Type }),
+			"amount":      field(moneyType, func(d order.DiscountResponse) any { return d.Amount }),
+		},
+	})
+
+	taxType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Tax",
+		Fields: graphql.Fields{
+			"name":      field(graphql.String, func(t order.TaxResponse) any { return t.Name }),
+			"country":   field(graphql.String, func(t order.TaxResponse) any { return t.Country }),
+			"region":    field(graphql.String, func(t order.TaxResponse) any { return t.Region }),
+			"category":  field(graphql.String, func(t order.TaxResponse) any { return t.Category }),
+			"rate":      field(graphql.String, func(t order.TaxResponse) any { return t.Rate }),
+			"inclusive": field(graphql.Boolean, func(t order.TaxResponse) any { return t.Inclusive }),
+			"taxable":   field(moneyType, func(t order.TaxResponse) any { return t.Taxable }),
+			"amount":    field(moneyType, func(t order.TaxResponse) any { return t.Amount }),
+		},
+	})
+
+	orderType = graphql.NewObject(graphql.ObjectConfig{
+		Name: "Order",
+		Fields: graphql.FieldsThunk(func() graphql.Fields {
+			return graphql.Fields{
+				"id":     field(graphql.NewNonNull(graphql.ID), func(o order.Response) any { return o.ID }),
+				"userId": field(graphql.ID, func(o order.Response) any { return o.UserID }),
+				"user": {
+					Type: userType,
+					Resolve: resolve(func(p graphql.ResolveParams, o order.Response) (any, error) {
+						return one(loadersFrom(p.Context).users.Load(p.Context, o.UserID)), nil
+					}),
+				},
+				"items": field(graphql.NewList(graphql.NewNonNull(orderItemType)), func(o order.Response) any {
+					return orderItems(o)
+				}),
+				"pricing":           field(moneyType, func(o order.Response) any { return o.Pricing }),
+				"basePricing":       field(moneyType, func(o order.Response) any { return o.BasePricing }),
+				"exchangeRate":      field(graphql.String, func(o order.Response) any { return o.ExchangeRate }),
+				"discount":          field(moneyType, func(o order.Response) any { return o.Discount }),
+				"discounts":         field(graphql.NewList(graphql.NewNonNull(discountType)), func(o order.Response) any { return o.Discounts }),
+				"country":           field(graphql.String, func(o order.Response) any { return o.Country }),
+				"region":            field(graphql.String, func(o order.Response) any { return o.Region }),
+				"tax":               field(moneyType, func(o order.Response) any { return o.Tax }),
+				"taxes":             field(graphql.NewList(graphql.NewNonNull(taxType)), func(o order.Response) any { return o.Taxes }),
+				"shippingAddressId": field(graphql.ID, func(o order.Response) any { return o.ShippingAddressID }),
+				"shippingAddress":   field(graphql.String, func(o order.Response) any { return o.ShippingAddress }),
+				"shippingMethod":    field(graphql.String, func(o order.Response) any { return o.ShippingMethod }),
+				"shipping":          field(moneyType, func(o order.Response) any { return o.Shipping }),
+				"status":            field(graphql.String, func(o order.Response) any { return o.Status }),
+				"createdAt":         field(graphql.DateTime, func(o order.Response) any { return o.CreatedAt }),
+				"payments": {
+					Type: listOf(paymentType),
+					Resolve: resolve(func(p graphql.ResolveParams, o order.Response) (any, error) {
+						return list(loadersFrom(p.Context).paymentsByOrder.Load(p.Context, o.ID)), nil
+					}),
+				},
+			}
+		}),
+	})
+
+	paymentType = graphql.NewObject(graphql.ObjectConfig{
+		Name: "Payment",
+		Fields: graphql.Fields{
+			"id":      field(graphql.NewNonNull(graphql.ID), func(p payment.Response) any { return p.ID }),
+			"userId":  field(graphql.ID, func(p payment.Response) any { return p.UserID }),
+			"orderId": field(graphql.ID, func(p payment.Response) any { return p.OrderID }),
+			"user": {
+				Type: userType,
+				Resolve: resolve(func(p graphql.ResolveParams, pm payment.Response) (any, error) {
+					return one(loadersFrom(p.Context).users.Load(p.Context, pm.UserID)), nil
+				}),
+			},
+			"order": {
+				Type: orderType,
+				Resolve: resolve(func(p graphql.ResolveParams, pm payment.Response) (any, error) {
+					return one(loadersFrom(p.Context).orders.Load(p.Context, pm.OrderID)), nil
+				}),
+			},
+			"amount":    field(moneyType, func(p payment.Response) any { return p.Amount }),
+			"status":    field(graphql.String, func(p payment.Response) any { return p.Status }),
+			"createdAt": field(graphql.DateTime, func(p payment.Response) any { return p.CreatedAt }),
+		},
+	})
+
+	idArgs := graphql.FieldConfigArgument{
+		"id": {Type: graphql.NewNonNull(graphql.ID)},
+	}
+	currencyArgs := graphql.FieldConfigArgument{
+		"currency": {Type: graphql.String, Description: "Currency to price in, the base currency by default."},
+	}
+
+	query := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Query",
+		Fields: graphql.Fields{
+			"user": {
+				Type: userType,
+				Args: idArgs,
+				Resolve: func(p graphql.ResolveParams) (any, error) {
+					return one(loadersFrom(p.Context).users.Load(p.Context, p.Args["id"].(string))), nil
+				},
+			},
+			"users": {
+				Type: listOf(userType),
+				Resolve: func(p graphql.ResolveParams) (any, error) {
+					data, err := up.ListUsers(p.Context)
+					prime(p, loadersFrom(p.Context).users, data, func(u user.Response) string { return u.ID })
+					return data, err
+				},
+			},
+			"product": {
+				Type: productType,
+				Args: graphql.FieldConfigArgument{
+					"id":       idArgs["id"],
+					"currency": currencyArgs["currency"],
+				},
+				Resolve: func(p graphql.ResolveParams) (any, error) {
+					currency, _ := p.Args["currency"].(string)
+					key := productKey{p.Args["id"].(string), currency}
+					return one(loadersFrom(p.Context).products.Load(p.Context, key)), nil
+				},
+			},
+			"products": {
+				Type: listOf(productType),
+				Args: currencyArgs,
+				Resolve: func(p graphql.ResolveParams) (any, error) {
+					currency, _ := p.Args["currency"].(string)
+					data, err := up.ListProducts(p.Context, currency)
+					prime(p, loadersFrom(p.Context).products, data, func(pr product.Response) productKey {
+						return productKey{pr.ID, currency}
+					})
+					return data, err
+				},
+			},
+			"order": {
+				Type: orderType,
+				Args: idArgs,
+				Resolve: func(p graphql.ResolveParams) (any, error) {
+					return one(loadersFrom(p.Context).orders.Load(p.Context, p.Args["id"].(string))), nil
+				},
+			},
+			"orders": {
+				Type: listOf(orderType),
+				Resolve: func(p graphql.ResolveParams) (any, error) {
+					data, err := up.ListOrders(p.Context)
+					prime(p, loadersFrom(p.Context).orders, data, func(o order.Response) string { return o.ID })
+					return data, err
+				},
+			},
+			"payment": {
+				Type: paymentType,
+				Args: idArgs,
+				Resolve: func(p graphql.ResolveParams) (any, error) {
+					return one(loadersFrom(p.Context).payments.Load(p.Context, p.Args["id"].(string))), nil
+				},
+			},
+			"payments": {
+				Type: listOf(paymentType),
+				Resolve: func(p graphql.ResolveParams) (any, error) {
+					data, err := up.ListPayments(p.Context)
+					prime(p, loadersFrom(p.Context).payments, data, func(pm payment.Response) string { return pm.ID })
+					return data, err
+				},
+			},
+		},
+	})
+
+	return graphql.NewSchema(graphql.SchemaConfig{Query: query})
+}
+
+func listOf(t graphql.Type) *graphql.List {
+	return graphql.NewList(graphql.NewNonNull(t))
+}
+
+// field is a field read straight off a source of type T.
+func field[T any](t graphql.Output, get func(T) any) *graphql.Field {
+	return &graphql.Field{
+		Type: t,
+		Resolve: resolve(func(_ graphql.ResolveParams, src T) (any, error) {
+			return get(src), nil
+		}),
+	}
+}
+
+func resolve[T any](fn func(graphql.ResolveParams, T) (any, error)) graphql.FieldResolveFn {
+	return func(p graphql.ResolveParams) (any, error) {
+		src, ok := p.Source.(T)
+		if !ok {
+			return nil, nil
+		}
+		return fn(p, src)
+	}
+}
+
+// one adapts a loader thunk to the thunk signature of the executor. The
+// record is handed on by value, so a missing one resolves to an untyped nil.
+func one[V any](thunk dataloader.Thunk[*V]) func() (interface{}, error) {
+	return func() (interface{}, error) {
+		data, err := thunk()
+		if err != nil || data == nil {
+			return nil, err
+		}
+		return *data, nil
+	}
+}
+
+func list[V any](thunk dataloader.Thunk[[]V]) func() (interface{}, error) {
+	return func() (interface{}, error) {
+		return thunk()
+	}
+}
+
+// prime fills loader with the records a list already returned, so nested
+// fields pointing back at them do not fetch them again.
+func prime[K comparable, V any](p graphql.ResolveParams, loader *dataloader.Loader[K, *V], data []V, key func(V) K) {
+	for i := range data {
+		loader.Prime(p.Context, key(data[i]), &data[i])
+	}
+}
+
+func orderItems(o order.Response) []orderItem {
+	items := []orderItem{}
+	index := map[string]int{}
+	for _, id := range o.ProductID {
+		if i, ok := index[id]; ok {
+			items[i].Quantity++
+			continue
+		}
+		index[id] = len(items)
+		items = append(items, orderItem{ProductID: id, Quantity: 1, Currency: o.Pricing.Currency})
+	}
+	return items
+}
@@ -0,0 +1,144 @@
+package graph
+
+import (
+	"api-gateway-service/internal/config"
+	"api-gateway-service/internal/domain/user"
+	"context"
+	"encoding/json"
+	"github.com/graphql-go/graphql"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// fakeServices answers the REST reads of the user and order services and
+// counts the requests for each path.
+type fakeServices struct {
+	mu       sync.Mutex
+	requests map[string]int
+	users    map[string]user.Response
+	// orders is the raw JSON of the order list, the order DTO has no JSON
+	// form for an order without money fields
+	orders string
+}
+
+func (fs *fakeServices) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	fs.mu.Lock()
+	fs.requests[r.URL.Path]++
+	fs.mu.Unlock()
+
+	var data any
+	switch {
+	case r.URL.Path == "/orders/":
+		data = json.RawMessage(fs.orders)
+	case strings.HasPrefix(r.URL.Path, "/users/"):
+		u, ok := fs.users[strings.TrimPrefix(r.URL.Path, "/users/")]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		data = u
+	default:
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	json.NewEncoder(w).Encode(map[string]any{"status_code": http.StatusOK, "data": data})
+}
+
+func newTestSchema(t *testing.T, services *fakeServices) (graphql.Schema, *Upstream) {
+	server := httptest.NewServer(services)
+	t.Cleanup(server.Close)
+
+	up := NewUpstream(config.Config{UserURL: server.URL + "/users", OrderURL: server.URL + "/orders"}, nil, nil, nil, nil)
+	schema, err := NewSchema(up)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return schema, up
+}
+
+func TestOrdersLoadEachUserOnce(t *testing.T) {
+	services := &fakeServices{
+		requests: map[string]int{},
+		users: map[string]user.Response{
+			"u1": {ID: "u1", Name: "Alice"},
+			"u2": {ID: "u2", Name: "Bob"},
+		},
+		orders: `[{"id":"o1","UserID":"u1"},{"id":"o2","UserID":"u2"},{"id":"o3","UserID":"u1"},{"id":"o4","UserID":"gone"}]`,
+	}
+	schema, up := newTestSchema(t, services)
+
+	res := graphql.Do(graphql.Params{
+		Schema:        schema,
+		RequestString: `{ orders { id user { name } } }`,
+		Context:       WithLoaders(context.Background(), NewLoaders(up)),
+	})
+	if res.HasErrors() {
+		t.Fatalf("errors: %v", res.Errors)
+	}
+
+	body, _ := json.Marshal(res.Data)
+	want := `{"orders":[{"id":"o1","user":{"name":"Alice"}},{"id":"o2","user":{"name":"Bob"}},` +
+		`{"id":"o3","user":{"name":"Alice"}},{"id":"o4","user":null}]}`
+	if string(body) != want {
+		t.Errorf("data = %s, want %s", body, want)
+	}
+	for _, path := range []string{"/orders/", "/users/u1", "/users/u2", "/users/gone"} {
+		if n := services.requests[path]; n != 1 {
+			t.Errorf("%s requested %d times, want once", path, n)
+		}
+	}
+}
+
+func TestCheckLimits(t *testing.T) {
+	schema, _ := newTestSchema(t, &fakeServices{requests: map[string]int{}})
+
+	tests := []struct {
+		name      string
+		query     string
+		operation string
+		err       string
+	}{
+		{
+			name:  "shallow query",
+			query: `{ orders { id user { name } } }`,
+		},
+		{
+			name:  "too deep",
+			query: `{ payments { order { user { orders { payments { order { user { orders { payments { order { id } } } } } } } } } } }`,
+			err:   "query depth 11 exceeds the limit of 10",
+		},
+		{
+			name:  "lists multiply the complexity",
+			query: `{ users { orders { payments { order { items { productId quantity } } } } } }`,
+			err:   "query complexity",
+		},
+		{
+			name:  "fragments count where they are spread",
+			query: `query { users { ...deep } } fragment deep on User { orders { payments { order { items { productId quantity } } } } }`,
+			err:   "query complexity",
+		},
+		{
+			name:  "recursive fragment does not loop",
+			query: `{ users { ...self } } fragment self on User { id ...self }`,
+		},
+		{
+			name:      "only the named operation counts",
+			query:     `query small { orders { id } } query big { users { orders { payments { order { items { productId quantity } } } } } }`,
+			operation: "small",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := CheckLimits(schema, tt.query, tt.operation)
+			switch {
+			case tt.err == "" && err != nil:
+				t.Errorf("unexpected error %v", err)
+			case tt.err != "" && (err == nil || !strings.Contains(err.Error(), tt.err)):
+				t.Errorf("error = %v, want %q", err, tt.err)
+			}
+		})
+	}
+}
@@ -0,0 +1,270 @@
+package graph
+
+import (
+	"api-gateway-service/internal/config"
+	"api-gateway-service/internal/domain/order"
+	"api-gateway-service/internal/domain/payment"
+	"api-gateway-service/internal/domain/product"
+	"api-gateway-service/internal/domain/user"
+	"api-gateway-service/pkg/auth"
+	"api-gateway-service/pkg/logging"
+	"api-gateway-service/pkg/metrics"
+	"api-gateway-service/pkg/pb/orderpb"
+	"api-gateway-service/pkg/pb/paymentpb"
+	"api-gateway-service/pkg/pb/productpb"
+	"api-gateway-service/pkg/pb/userpb"
+	"api-gateway-service/pkg/tracing"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// upstreamTimeout bounds a single call to a service, a shorter deadline of
+// the incoming request still wins.
+const upstreamTimeout = 10 * time.Second
+
+var errNotFound = errors.New("not found")
+
+// Upstream reads the services for the resolvers, over gRPC for a service
+// with a client and over its REST API otherwise.
+type Upstream struct {
+	userURL    string
+	productURL string
+	orderURL   string
+	paymentURL string
+	client     *http.Client
+
+	users    userpb.UserServiceClient
+	products productpb.ProductServiceClient
+	orders   orderpb.OrderServiceClient
+	payments paymentpb.PaymentServiceClient
+}
+
+func NewUpstream(
+	cfg config.Config,
+	users userpb.UserServiceClient,
+	products productpb.ProductServiceClient,
+	orders orderpb.OrderServiceClient,
+	payments paymentpb.PaymentServiceClient,
+) *Upstream {
+	return &Upstream{
+		userURL:    cfg.UserURL,
+		productURL: cfg.ProductURL,
+		orderURL:   cfg.OrderURL,
+		paymentURL: cfg.PaymentURL,
+		client:     &http.Client{Transport: tracing.Transport(metrics.Transport{Base: auth.Transport{Base: logging.Transport{}}})},
+		users:      users,
+		products:   products,
+		orders:     orders,
+		payments:   payments,
+	}
+}
+
+func (u *Upstream) GetUser(ctx context.Context, id string) (res user.Response, err error) {
+	ctx, cancel := context.WithTimeout(ctx, upstreamTimeout)
+	defer cancel()
+
+	if u.users != nil {
+		data, rpcErr := u.users.GetUser(ctx, &userpb.GetUserRequest{Id: id})
+		if rpcErr != nil {
+			return res, rpcError(rpcErr)
+		}
+		return user.ParseFromProto(data), nil
+	}
+	err = u.get(ctx, u.userURL+"/"+url.PathEscape(id), &res)
+	return
+}
+
+func (u *Upstream) ListUsers(ctx context.Context) (res []user.Response, err error) {
+	ctx, cancel := context.WithTimeout(ctx, upstreamTimeout)
+	defer cancel()
+
+	if u.users != nil {
+		data, rpcErr := u.users.ListUsers(ctx, &userpb.ListUsersRequest{})
+		if rpcErr != nil {
+			return nil, emptyList(rpcError(rpcErr))
+		}
+		return user.ParseFromProtos(data.GetUsers()), nil
+	}
+	err = emptyList(u.get(ctx, u.userURL+"/", &res))
+	return
+}
+
+func (u *Upstream) GetProduct(ctx context.Context, id, currency string) (res product.Response, err error) {
+	ctx, cancel := context.WithTimeout(ctx, upstreamTimeout)
+	defer cancel()
+
+	if u.products != nil {
+		data, rpcErr := u.products.GetProduct(ctx, &productpb.GetProductRequest{Id: id, Currency: currency})
+		if rpcErr != nil {
+			return res, rpcError(rpcErr)
+		}
+		return product.ParseFromProto(data), nil
+	}
+	err = u.get(ctx, u.productURL+"/"+url.PathEscape(id)+"?currency="+url.QueryEscape(currency), &res)
+	return
+}
+
+func (u *Upstream) ListProducts(ctx context.Context, currency string) (res []product.Response, err error) {
+	ctx, cancel := context.WithTimeout(ctx, upstreamTimeout)
+	defer cancel()
+
+	if u.products != nil {
+		data, rpcErr := u.products.ListProducts(ctx, &productpb.ListProductsRequest{Currency: currency})
+		if rpcErr != nil {
+			return nil, emptyList(rpcError(rpcErr))
+		}
+		return product.ParseFromProtos(data.GetProducts()), nil
+	}
+	err = emptyList(u.get(ctx, u.productURL+"/?currency="+url.QueryEscape(currency), &res))
+	return
+}
+
+func (u *Upstream) GetOrder(ctx context.Context, id string) (res order.Response, err error) {
+	ctx, cancel := context.WithTimeout(ctx, upstreamTimeout)
+	defer cancel()
+
+	if u.orders != nil {
+		data, rpcErr := u.orders.GetOrder(ctx, &orderpb.GetOrderRequest{Id: id})
+		if rpcErr != nil {
+			return res, rpcError(rpcErr)
+		}
+		return order.ParseFromProto(data), nil
+	}
+	err = u.get(ctx, u.orderURL+"/"+url.PathEscape(id), &res)
+	return
+}
+
+func (u *Upstream) ListOrders(ctx context.Context) (res []order.Response, err error) {
+	ctx, cancel := context.WithTimeout(ctx, upstreamTimeout)
+	defer cancel()
+
+	if u.orders != nil {
+		data, rpcErr := u.orders.ListOrders(ctx, &orderpb.ListOrdersRequest{})
+		if rpcErr != nil {
+			return nil, emptyList(rpcError(rpcErr))
+		}
+		return order.ParseFromProtos(data.GetOrders()), nil
+	}
+	err = emptyList(u.get(ctx, u.orderURL+"/", &res))
+	return
+}
+
+func (u *Upstream) SearchOrders(ctx context.Context, filter, value string) (res []order.Response, err error) {
+	ctx, cancel := context.WithTimeout(ctx, upstreamTimeout)
+	defer cancel()
+
+	if u.orders != nil {
+		data, rpcErr := u.orders.SearchOrders(ctx, &orderpb.SearchOrdersRequest{Filter: filter, Value: value})
+		if rpcErr != nil {
+			return nil, emptyList(rpcError(rpcErr))
+		}
+		return order.ParseFromProtos(data.GetOrders()), nil
+	}
+	err = emptyList(u.get(ctx, u.orderURL+"/search?"+searchQuery(filter, value), &res))
+	return
+}
+
+func (u *Upstream) GetPayment(ctx context.Context, id string) (res payment.Response, err error) {
+	ctx, cancel := context.WithTimeout(ctx, upstreamTimeout)
+	defer cancel()
+
+	if u.payments != nil {
+		data, rpcErr := u.payments.GetPayment(ctx, &paymentpb.GetPaymentRequest{Id: id})
+		if rpcErr != nil {
+			return res, rpcError(rpcErr)
+		}
+		return payment.ParseFromProto(data), nil
+	}
+	err = u.get(ctx, u.paymentURL+"/"+url.PathEscape(id), &res)
+	return
+}
+
+func (u *Upstream) ListPayments(ctx context.Context) (res []payment.Response, err error) {
+	ctx, cancel := context.WithTimeout(ctx, upstreamTimeout)
+	defer cancel()
+
+	if u.payments != nil {
+		data, rpcErr := u.payments.ListPayments(ctx, &paymentpb.ListPaymentsRequest{})
+		if rpcErr != nil {
+			return nil, emptyList(rpcError(rpcErr))
+		}
+		return payment.ParseFromProtos(data.GetPayments()), nil
+	}
+	err = emptyList(u.get(ctx, u.paymentURL+"/", &res))
+	return
+}
+
+func (u *Upstream) SearchPayments(ctx context.Context, filter, value string) (res []payment.Response, err error) {
+	ctx, cancel := context.WithTimeout(ctx, upstreamTimeout)
+	defer cancel()
+
+	if u.payments != nil {
+		data, rpcErr := u.payments.SearchPayments(ctx, &paymentpb.SearchPaymentsRequest{Filter: filter, Value: value})
+		if rpcErr != nil {
+			return nil, emptyList(rpcError(rpcErr))
+		}
+		return payment.ParseFromProtos(data.GetPayments()), nil
+	}
+	err = emptyList(u.get(ctx, u.paymentURL+"/search?"+searchQuery(filter, value), &res))
+	return
+}
+
+// get requests url and decodes the data field of the response envelope into
+// dest. A 404 or 400 answer, or an empty data field, is errNotFound.
+func (u *Upstream) get(ctx context.Context, url string, dest any) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := u.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+	case http.StatusNotFound, http.StatusBadRequest:
+		return errNotFound
+	default:
+		return fmt.Errorf("%s: %s", req.URL.Path, resp.Status)
+	}
+
+	body := struct {
+		Data json.RawMessage `json:"data"`
+	}{}
+	if err = json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return err
+	}
+	// the services answer 200 with empty data when nothing matched
+	if data := string(body.Data); data == "" || data == "null" || data == `""` {
+		return errNotFound
+	}
+	return json.Unmarshal(body.Data, dest)
+}
+
+func rpcError(err error) error {
+	if status.Code(err) == codes.NotFound {
+		return errNotFound
+	}
+	return err
+}
+
+// emptyList drops errNotFound, the services use it for an empty list.
+func emptyList(err error) error {
+	if errors.Is(err, errNotFound) {
+		return nil
+	}
+	return err
+}
+
+func searchQuery(filter, value string) string {
+	return url.Values{"filter": {filter}, "value": {value}}.Encode()
+}
@@ -0,0 +1,114 @@
+// Package auth checks the access token of a request against the permissions
+// of the route and passes it on to the services. The services check it
+// again, with what the gateway does not know, e.g. whose an order is.
+package auth
+
+import (
+	"api-gateway-service/pkg/apperror"
+	"context"
+	"github.com/gin-gonic/gin"
+	"slices"
+	"strings"
+	"time"
+)
+
+// ErrorPermissionDenied is the answer to a token without the permission a
+// route needs, its details list the permissions that would do.
+var ErrorPermissionDenied = apperror.Forbidden("permission_denied", "access denied")
+
+type claimsKey struct{}
+
+// WithClaims returns a copy of ctx carrying claims.
+func WithClaims(ctx context.Context, claims Claims) context.Context {
+	return context.WithValue(ctx, claimsKey{}, claims)
+}
+
+// FromContext returns the claims of the token ctx was authenticated with,
+// ok is false for an anonymous request.
+func FromContext(ctx context.Context) (claims Claims, ok bool) {
+	claims, ok = ctx.Value(claimsKey{}).(Claims)
+	return
+}
+
+// Middleware checks the bearer token of a request, if it has one, and puts
+// its claims and the Authorization header to pass on in the request context.
+// A request without a token goes on anonymous, one with a bad or expired
+// token is refused.
+func Middleware(secret string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		authorization := c.GetHeader("Authorization")
+		token := bearer(authorization)
+		if token == "" {
+			c.Next()
+			return
+		}
+		claims, err := Verify(token, secret, time.Now())
+		if err != nil {
+			c.Error(err)
+			c.Abort()
+			return
+		}
+		ctx := WithAuthorization(c.Request.Context(), authorization)
+		c.Request = c.Request.WithContext(WithClaims(ctx, claims))
+		c.Next()
+	}
+}
+
+// Authorize returns nil when ctx carries the token of owner, unless owner is
+// empty, or a token granting one of permissions. It is ErrorTokenRequired
+// for an anonymous request and ErrorPermissionDenied for any other.
+func Authorize(ctx context.Context, owner string, permissions ...string) error {
+	claims, ok := FromContext(ctx)
+	if !ok {
+		return ErrorTokenRequired
+	}
+	if (owner == "" || claims.Subject != owner) && !slices.ContainsFunc(permissions, claims.Can) {
+		return ErrorPermissionDenied.WithDetail("permissions", permissions)
+	}
+	return nil
+}
+
+// RequireToken lets a request through only with a token, the service then
+// checks what its user may do, e.g. read only its own orders.
+func RequireToken() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if _, ok := FromContext(c.Request.Context()); !ok {
+			c.Error(ErrorTokenRequired)
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
+
+// RequirePermission lets a request through only when its token grants one
+// of permissions.
+func RequirePermission(permissions ...string) gin.HandlerFunc {
+	return require(func(c *gin.Context) string { return "" }, permissions)
+}
+
+// RequireSelfOrPermission lets a request through when its token belongs to
+// the user named by the param path parameter, or grants one of permissions.
+func RequireSelfOrPermission(param string, permissions ...string) gin.HandlerFunc {
+	return require(func(c *gin.Context) string { return c.Param(param) }, permissions)
+}
+
+func require(owner func(c *gin.Context) string, permissions []string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if err := Authorize(c.Request.Context(), owner(c), permissions...); err != nil {
+			c.Error(err)
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
+
+// bearer returns the token of an Authorization header, empty for none.
+func bearer(value string) string {
+	scheme, token, ok := strings.Cut(value, " ")
+	if !ok || !strings.EqualFold(scheme, "Bearer") {
+		return ""
+	}
+	return strings.TrimSpace(token)
+}
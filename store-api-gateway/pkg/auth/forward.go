@@ -0,0 +1,57 @@
+package auth
+
+import (
+	"context"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"net/http"
+)
+
+// metadataKey is the Authorization header as gRPC metadata, whose keys are
+// lower case.
+const metadataKey = "authorization"
+
+type authorizationKey struct{}
+
+// WithAuthorization returns a copy of ctx carrying the Authorization header
+// of the client.
+func WithAuthorization(ctx context.Context, authorization string) context.Context {
+	return context.WithValue(ctx, authorizationKey{}, authorization)
+}
+
+// Authorization returns the Authorization header of the client of ctx, empty
+// for an anonymous request.
+func Authorization(ctx context.Context) string {
+	authorization, _ := ctx.Value(authorizationKey{}).(string)
+	return authorization
+}
+
+// UnaryClientInterceptor sends the Authorization of the call context along
+// as metadata.
+func UnaryClientInterceptor() grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		if authorization := Authorization(ctx); authorization != "" {
+			ctx = metadata.AppendToOutgoingContext(ctx, metadataKey, authorization)
+		}
+		return invoker(ctx, method, req, reply, cc, opts...)
+	}
+}
+
+// Transport is an http.RoundTripper that sends the Authorization of the
+// request context along to the upstream service.
+type Transport struct {
+	// Base makes the requests, http.DefaultTransport when nil.
+	Base http.RoundTripper
+}
+
+func (t Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	base := t.Base
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	if authorization := Authorization(req.Context()); authorization != "" && req.Header.Get("Authorization") == "" {
+		req = req.Clone(req.Context())
+		req.Header.Set("Authorization", authorization)
+	}
+	return base.RoundTrip(req)
+}
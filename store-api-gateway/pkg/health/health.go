@@ -0,0 +1,126 @@
+// Package health serves the liveness and readiness probes of a service.
+// Liveness only says the process serves HTTP, readiness runs the checks of
+// the dependencies the service cannot work without.
+package health
+
+import (
+	"context"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"sync"
+	"time"
+)
+
+const (
+	StatusOK          = "ok"
+	StatusUnavailable = "unavailable"
+)
+
+// checkTimeout bounds every check, a dependency that hangs is unavailable.
+const checkTimeout = 2 * time.Second
+
+// Check probes one dependency. detail is shown next to the result, such as
+// the migration version, and may be empty.
+type Check func(ctx context.Context) (detail string, err error)
+
+// Result is the outcome of one check.
+type Result struct {
+	Status  string `json:"status" example:"ok"`
+	Detail  string `json:"detail,omitempty"`
+	Error   string `json:"error,omitempty"`
+	Latency string `json:"latency" example:"1.2ms"`
+}
+
+// Report is the body of the probes, Checks is keyed by dependency.
+type Report struct {
+	Status string            `json:"status" example:"ok"`
+	Checks map[string]Result `json:"checks,omitempty"`
+}
+
+type Checker struct {
+	names  []string
+	checks []Check
+}
+
+func NewChecker() *Checker {
+	return &Checker{}
+}
+
+// Add registers check under name. Checks are added while the service is
+// wired, before it serves.
+func (c *Checker) Add(name string, check Check) {
+	c.names = append(c.names, name)
+	c.checks = append(c.checks, check)
+}
+
+// Run runs all checks concurrently, the report is ok when every check is.
+func (c *Checker) Run(ctx context.Context) Report {
+	results := make([]Result, len(c.checks))
+	var wg sync.WaitGroup
+	for i, check := range c.checks {
+		wg.Add(1)
+		go func(i int, check Check) {
+			defer wg.Done()
+			results[i] = run(ctx, check)
+		}(i, check)
+	}
+	wg.Wait()
+
+	report := Report{Status: StatusOK, Checks: make(map[string]Result, len(results))}
+	for i, result := range results {
+		if result.Status != StatusOK {
+			report.Status = StatusUnavailable
+		}
+		report.Checks[c.names[i]] = result
+	}
+	return report
+}
+
+func run(ctx context.Context, check Check) Result {
+	ctx, cancel := context.WithTimeout(ctx, checkTimeout)
+	defer cancel()
+
+	start := time.Now()
+	detail, err := check(ctx)
+	result := Result{Status: StatusOK, Detail: detail, Latency: time.Since(start).String()}
+	if err != nil {
+		result.Status = StatusUnavailable
+		result.Error = err.Error()
+	}
+	return result
+}
+
+// Live answers the liveness probe.
+func (c *Checker) Live(ctx *gin.Context) {
+	ctx.JSON(http.StatusOK, Report{Status: StatusOK})
+}
+
+// Ready answers the readiness probe, 503 when a check fails.
+func (c *Checker) Ready(ctx *gin.Context) {
+	report := c.Run(ctx.Request.Context())
+	code := http.StatusOK
+	if report.Status != StatusOK {
+		code = http.StatusServiceUnavailable
+	}
+	ctx.JSON(code, report)
+}
+
+// HTTP checks that url, the readiness probe of another service, answers 200.
+func HTTP(client *http.Client, url string) Check {
+	return func(ctx context.Context) (string, error) {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+		if err != nil {
+			return "", err
+		}
+		resp, err := client.Do(req)
+		if err != nil {
+			return "", err
+		}
+		defer resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			return "", fmt.Errorf("%s answered %s", url, resp.Status)
+		}
+		return "", nil
+	}
+}
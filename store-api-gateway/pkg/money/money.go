@@ -0,0 +1,271 @@
+// Package money holds amounts as integer minor units with their ISO 4217
+// currency. The gateway and the products, orders and payments services carry
+// identical copies of it, its tests live in store-orders-service.
+package money
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+var (
+	ErrorInvalidAmount    = errors.New("invalid amount")
+	ErrorInvalidCurrency  = errors.New("invalid currency")
+	ErrorCurrencyMismatch = errors.New("currency mismatch")
+	ErrorInvalidRate      = errors.New("invalid exchange rate")
+)
+
+// exponents holds the number of minor unit digits of the supported ISO 4217
+// currencies.
+var exponents = map[string]int{
+	"KZT": 2,
+	"RUB": 2,
+	"USD": 2,
+	"EUR": 2,
+	"GBP": 2,
+	"CNY": 2,
+	"JPY": 0,
+	"KRW": 0,
+}
+
+// Money is an amount in the minor units of its currency (tiyn for KZT, cents
+// for USD). It is encoded in JSON as a decimal string of major units, e.g.
+// {"amount": "1999.90", "currency": "KZT"}, so no client ever parses it as a
+// float.
+type Money struct {
+	Amount   int64  `json:"amount" swaggertype:"string" example:"1999.90"`
+	Currency string `json:"currency" example:"KZT"`
+}
+
+func New(amount int64, currency string) Money {
+	return Money{Amount: amount, Currency: currency}
+}
+
+// Parse reads a decimal amount in major units such as "1999.9". More
+// fraction digits than the currency has is an error rather than a rounding.
+func Parse(amount, currency string) (m Money, err error) {
+	exp, ok := Exponent(currency)
+	if !ok {
+		err = ErrorInvalidCurrency
+		return
+	}
+
+	amount = strings.TrimSpace(amount)
+	negative := strings.HasPrefix(amount, "-")
+	amount = strings.TrimPrefix(strings.TrimPrefix(amount, "-"), "+")
+
+	whole, fraction, _ := strings.Cut(amount, ".")
+	if whole == "" && fraction == "" || len(fraction) > exp {
+		err = ErrorInvalidAmount
+		return
+	}
+	if whole == "" {
+		whole = "0"
+	}
+	fraction += strings.Repeat("0", exp-len(fraction))
+
+	digits := whole + fraction
+	for _, r := range digits {
+		if r < '0' || r > '9' {
+			err = ErrorInvalidAmount
+			return
+		}
+	}
+	minor, parseErr := strconv.ParseInt(digits, 10, 64)
+	if parseErr != nil {
+		err = ErrorInvalidAmount
+		return
+	}
+	if negative {
+		minor = -minor
+	}
+	m = New(minor, currency)
+	return
+}
+
+// Exponent returns the number of minor unit digits of currency.
+func Exponent(currency string) (int, bool) {
+	exp, ok := exponents[currency]
+	return exp, ok
+}
+
+func IsValidCurrency(currency string) bool {
+	_, ok := exponents[currency]
+	return ok
+}
+
+func (m Money) Validate() error {
+	if !IsValidCurrency(m.Currency) {
+		return ErrorInvalidCurrency
+	}
+	return nil
+}
+
+func (m Money) IsZero() bool {
+	return m.Amount == 0
+}
+
+func (m Money) IsPositive() bool {
+	return m.Amount > 0
+}
+
+func (m Money) Add(other Money) (Money, error) {
+	if m.Currency != other.Currency {
+		return m, ErrorCurrencyMismatch
+	}
+	if (other.Amount > 0 && m.Amount > math.MaxInt64-other.Amount) ||
+		(other.Amount < 0 && m.Amount < math.MinInt64-other.Amount) {
+		return m, ErrorInvalidAmount
+	}
+	return New(m.Amount+other.Amount, m.Currency), nil
+}
+
+func (m Money) Sub(other Money) (Money, error) {
+	if m.Currency != other.Currency {
+		return m, ErrorCurrencyMismatch
+	}
+	if (other.Amount < 0 && m.Amount > math.MaxInt64+other.Amount) ||
+		(other.Amount > 0 && m.Amount < math.MinInt64+other.Amount) {
+		return m, ErrorInvalidAmount
+	}
+	return New(m.Amount-other.Amount, m.Currency), nil
+}
+
+func (m Money) Mul(n int64) (Money, error) {
+	// MinInt64 * -1 wraps back to MinInt64, which the division check misses.
+	if (m.Amount == math.MinInt64 && n == -1) || (n != 0 && (m.Amount*n)/n != m.Amount) {
+		return m, ErrorInvalidAmount
+	}
+	return New(m.Amount*n, m.Currency), nil
+}
+
+// Convert turns m into currency using rate, the price of one major unit of
+// m.Currency expressed in major units of currency. The result is rounded half
+// away from zero to the minor unit of currency.
+func Convert(m Money, currency, rate string) (res Money, err error) {
+	fromExp, ok := Exponent(m.Currency)
+	if !ok {
+		err = ErrorInvalidCurrency
+		return
+	}
+	toExp, ok := Exponent(currency)
+	if !ok {
+		err = ErrorInvalidCurrency
+		return
+	}
+	r, ok := new(big.Rat).SetString(rate)
+	if !ok || r.Sign() <= 0 {
+		err = ErrorInvalidRate
+		return
+	}
+
+	value := new(big.Rat).Mul(new(big.Rat).SetInt64(m.Amount), r)
+	scale := new(big.Rat).SetInt(new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(abs(toExp-fromExp))), nil))
+	if toExp >= fromExp {
+		value.Mul(value, scale)
+	} else {
+		value.Quo(value, scale)
+	}
+
+	quo, rem := new(big.Int).QuoRem(value.Num(), value.Denom(), new(big.Int))
+	if rem.Sign() != 0 && new(big.Int).Mul(new(big.Int).Abs(rem), big.NewInt(2)).Cmp(value.Denom()) >= 0 {
+		quo.Add(quo, big.NewInt(int64(value.Sign())))
+	}
+	if !quo.IsInt64() {
+		err = ErrorInvalidAmount
+		return
+	}
+	res = New(quo.Int64(), currency)
+	return
+}
+
+// IsValidRate reports whether rate is a positive decimal number.
+func IsValidRate(rate string) bool {
+	r, ok := new(big.Rat).SetString(rate)
+	return ok && r.Sign() > 0
+}
+
+// InvertRate returns 1/rate with the given number of decimal places.
+func InvertRate(rate string, precision int) (string, error) {
+	r, ok := new(big.Rat).SetString(rate)
+	if !ok || r.Sign() <= 0 {
+		return "", ErrorInvalidRate
+	}
+	return new(big.Rat).Inv(r).FloatString(precision), nil
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
+
+// Decimal formats the amount in major units, e.g. "1999.90".
+func (m Money) Decimal() string {
+	exp, ok := Exponent(m.Currency)
+	if !ok {
+		exp = 2
+	}
+	digits := strconv.FormatInt(m.Amount, 10)
+	sign := ""
+	if m.Amount < 0 {
+		sign, digits = "-", digits[1:]
+	}
+	if exp == 0 {
+		return sign + digits
+	}
+	if len(digits) <= exp {
+		digits = strings.Repeat("0", exp-len(digits)+1) + digits
+	}
+	return sign + digits[:len(digits)-exp] + "." + digits[len(digits)-exp:]
+}
+
+func (m Money) String() string {
+	return fmt.Sprintf("%s %s", m.Decimal(), m.Currency)
+}
+
+func (m Money) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		Amount   string `json:"amount"`
+		Currency string `json:"currency"`
+	}{m.Decimal(), m.Currency})
+}
+
+// UnmarshalJSON accepts the amount either as a decimal string or as a bare
+// JSON number; both are parsed from their literal text, never via float64.
+func (m *Money) UnmarshalJSON(data []byte) error {
+	if string(bytes.TrimSpace(data)) == "null" {
+		return nil
+	}
+	var raw struct {
+		Amount   json.RawMessage `json:"amount"`
+		Currency string          `json:"currency"`
+	}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+
+	amount := string(bytes.TrimSpace(raw.Amount))
+	if strings.HasPrefix(amount, `"`) {
+		if err := json.Unmarshal(raw.Amount, &amount); err != nil {
+			return err
+		}
+	}
+	if amount == "" || amount == "null" {
+		return ErrorInvalidAmount
+	}
+
+	parsed, err := Parse(amount, strings.ToUpper(raw.Currency))
+	if err != nil {
+		return err
+	}
+	*m = parsed
+	return nil
+}
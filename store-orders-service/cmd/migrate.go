@@ -0,0 +1,53 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"order-service/internal/config"
+	"order-service/internal/db"
+)
+
+const migrateUsage = "usage: migrate up|down|status|redo [flags] or migrate create <name>"
+
+// migrate runs the migrate subcommand. The command comes first, the
+// configuration flags of the service after it:
+//
+//	app migrate status -db-host localhost
+//	app migrate create add_index
+//
+// create writes to migrations/postgres and is run from the root of the
+// module, the new migration is embedded on the next build.
+func migrate(args []string) error {
+	if len(args) == 0 {
+		return errors.New(migrateUsage)
+	}
+	command, args := args[0], args[1:]
+
+	switch command {
+	case "create":
+		if len(args) != 1 {
+			return errors.New("usage: migrate create <name>")
+		}
+		return db.CreateMigration(args[0])
+	case "up", "down", "status", "redo":
+	default:
+		return fmt.Errorf("unknown command %q, %s", command, migrateUsage)
+	}
+
+	cfg, err := config.LoadConfig(args)
+	if errors.Is(err, flag.ErrHelp) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("invalid configuration:\n%w", err)
+	}
+
+	sqlxDB, err := db.ConnectDatabase(cfg)
+	if err != nil {
+		return err
+	}
+	defer sqlxDB.Close()
+
+	return db.RunMigrations(sqlxDB, command)
+}
@@ -0,0 +1,39 @@
+package handler
+
+import (
+	"github.com/gin-gonic/gin"
+	"order-service/pkg/health"
+)
+
+type HealthHandler struct {
+	checker *health.Checker
+}
+
+func NewHealthHandler(checker *health.Checker) *HealthHandler {
+	return &HealthHandler{
+		checker: checker,
+	}
+}
+
+// Live godoc
+// @Summary Liveness probe
+// @Description Answers 200 as long as the process serves requests
+// @Tags health
+// @Produce json
+// @Success 200 {object} health.Report
+// @Router /healthz [get]
+func (hh *HealthHandler) Live(c *gin.Context) {
+	hh.checker.Live(c)
+}
+
+// Ready godoc
+// @Summary Readiness probe
+// @Description Checks the dependencies of the service, 503 with the failing ones when it cannot serve
+// @Tags health
+// @Produce json
+// @Success 200 {object} health.Report
+// @Failure 503 {object} health.Report
+// @Router /readyz [get]
+func (hh *HealthHandler) Ready(c *gin.Context) {
+	hh.checker.Ready(c)
+}
@@ -0,0 +1,32 @@
+package handler
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus"
+	"order-service/pkg/events"
+	"order-service/pkg/metrics"
+)
+
+type MetricsHandler struct {
+	handler gin.HandlerFunc
+}
+
+// NewMetricsHandler adds the event consumer stats to the metrics every
+// service serves.
+func NewMetricsHandler(stats *events.Stats) *MetricsHandler {
+	prometheus.MustRegister(stats.Collector("order_service"))
+	return &MetricsHandler{
+		handler: metrics.Handler(),
+	}
+}
+
+// Metrics godoc
+// @Summary Service metrics
+// @Description Request, upstream, database pool, business and event consumer metrics in the Prometheus text format
+// @Tags metrics
+// @Produce plain
+// @Success 200 {string} string
+// @Router /metrics [get]
+func (mh *MetricsHandler) Metrics(c *gin.Context) {
+	mh.handler(c)
+}
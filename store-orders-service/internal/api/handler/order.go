@@ -6,6 +6,8 @@ import (
 	"net/http"
 	"order-service/internal/domain/order"
 	interfaces "order-service/internal/service/interface"
+	"order-service/pkg/apperror"
+	"order-service/pkg/auth"
 	"order-service/pkg/response"
 )
 
@@ -21,38 +23,39 @@ func NewOrderHandler(service interfaces.OrderService) *OrderHandler {
 
 // CreateOrder godoc
 // @Summary Create a new order
-// @Description Create a new order with the input payload
+// @Description Create a new order with the input payload. A customer orders for itself, ordering for another user requires the orders:write permission.
 // @Tags orders
 // @Accept json
 // @Produce json
+// @Security BearerAuth
 // @Param payment body order.Request true "Order Request"
 // @Success 201 {object} response.Response
 // @Failure 400 {object} response.Response
+// @Failure 401 {object} response.Response
+// @Failure 403 {object} response.Response
+// @Failure 409 {object} response.Response
+// @Failure 422 {object} response.Response
 // @Failure 500 {object} response.Response
 // @Router /orders [post]
 func (th *OrderHandler) CreateOrder(c *gin.Context) {
 	req := order.Request{}
-	if err := c.BindJSON(&req); err != nil {
-		errRes := response.ClientResponse(http.StatusBadRequest, "fields provided are wrong", nil, err.Error())
-		c.JSON(http.StatusBadRequest, errRes)
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(apperror.InvalidBody(err))
 		return
 	}
 
 	if err := req.Validate(); err != nil {
-		errRes := response.ClientResponse(http.StatusBadRequest, "fields provided are wrong", nil, err.Error())
-		c.JSON(http.StatusBadRequest, errRes)
+		c.Error(err)
+		return
+	}
+	if err := auth.Authorize(c.Request.Context(), req.UserID, "orders:write"); err != nil {
+		c.Error(err)
 		return
 	}
 
 	res, err := th.orderService.CreateOrder(c.Request.Context(), req)
 	if err != nil {
-		if errors.Is(err, order.ErrorNotFound) {
-			errRes := response.ClientResponse(http.StatusBadRequest, "fields must be unique", nil, err.Error())
-			c.JSON(http.StatusBadRequest, errRes)
-			return
-		}
-		errRes := response.ClientResponse(http.StatusInternalServerError, "failed to create order", nil, err.Error())
-		c.JSON(http.StatusInternalServerError, errRes)
+		c.Error(err)
 		return
 	}
 	successRes := response.ClientResponse(http.StatusCreated, "the order was successfully created", res, nil)
@@ -61,10 +64,13 @@ func (th *OrderHandler) CreateOrder(c *gin.Context) {
 
 // ListOrders godoc
 // @Summary List all orders
-// @Description Get a list of orders
+// @Description Get a list of orders. Requires the orders:read:any permission.
 // @Tags orders
 // @Produce json
+// @Security BearerAuth
 // @Success 200 {object} response.Response
+// @Failure 401 {object} response.Response
+// @Failure 403 {object} response.Response
 // @Failure 500 {object} response.Response
 // @Router /orders [get]
 func (th *OrderHandler) ListOrders(c *gin.Context) {
@@ -75,8 +81,7 @@ func (th *OrderHandler) ListOrders(c *gin.Context) {
 			c.JSON(http.StatusOK, errRes)
 			return
 		}
-		errRes := response.ClientResponse(http.StatusInternalServerError, "failed to list orders", nil, err.Error())
-		c.JSON(http.StatusInternalServerError, errRes)
+		c.Error(err)
 		return
 	}
 
@@ -86,81 +91,169 @@ func (th *OrderHandler) ListOrders(c *gin.Context) {
 
 // GetOrder godoc
 // @Summary Get a order by ID
-// @Description Get details of a order by its ID
+// @Description Get details of a order by its ID. A customer reads its own orders, any other requires the orders:read:any permission.
 // @Tags orders
 // @Produce json
+// @Security BearerAuth
 // @Param id path string true "Order ID"
 // @Success 200 {object} response.Response
+// @Header 200 {string} ETag "Version of the order"
+// @Failure 401 {object} response.Response
+// @Failure 403 {object} response.Response
+// @Failure 404 {object} response.Response
 // @Failure 500 {object} response.Response
 // @Router /orders/{id} [get]
 func (th *OrderHandler) GetOrder(c *gin.Context) {
 	id := c.Param("id")
 	res, err := th.orderService.GetOrder(c.Request.Context(), id)
 	if err != nil {
-		if errors.Is(err, order.ErrorNotFound) {
-			errRes := response.ClientResponse(http.StatusOK, "no orders found", "", nil)
-			c.JSON(http.StatusOK, errRes)
-			return
-		}
-		errRes := response.ClientResponse(http.StatusInternalServerError, "failed to get order", nil, err.Error())
-		c.JSON(http.StatusInternalServerError, errRes)
+		c.Error(err)
+		return
+	}
+	if err = auth.Authorize(c.Request.Context(), res.UserID, "orders:read:any"); err != nil {
+		c.Error(err)
 		return
 	}
+	setETag(c, res.Version)
 	successRes := response.ClientResponse(http.StatusOK, "the order details", res, nil)
 	c.JSON(http.StatusOK, successRes)
 }
 
+// GetInvoice godoc
+// @Summary Get the invoice of an order
+// @Description Get the subtotal, discount, tax breakdown and total of an order in the currency it was charged in. A customer reads its own orders, any other requires the orders:read:any permission.
+// @Tags orders
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Order ID"
+// @Success 200 {object} response.Response
+// @Failure 401 {object} response.Response
+// @Failure 403 {object} response.Response
+// @Failure 404 {object} response.Response
+// @Failure 500 {object} response.Response
+// @Router /orders/{id}/invoice [get]
+func (th *OrderHandler) GetInvoice(c *gin.Context) {
+	id := c.Param("id")
+	data, err := th.orderService.GetOrder(c.Request.Context(), id)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	if err = auth.Authorize(c.Request.Context(), data.UserID, "orders:read:any"); err != nil {
+		c.Error(err)
+		return
+	}
+
+	res, err := th.orderService.GetInvoice(c.Request.Context(), id)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	successRes := response.ClientResponse(http.StatusOK, "the order invoice", res, nil)
+	c.JSON(http.StatusOK, successRes)
+}
+
 // UpdateOrder godoc
 // @Summary Update a order by ID
-// @Description Update details of a order by its ID
+// @Description Update details of a order by its ID. With If-Match only the version the ETag names is updated. Requires the orders:write permission.
 // @Tags orders
 // @Accept json
 // @Produce json
+// @Security BearerAuth
 // @Param id path string true "Order ID"
+// @Param If-Match header string false "ETag of the order"
 // @Param order body order.Request true "Order Request"
 // @Success 200 {object} response.Response
+// @Header 200 {string} ETag "Version of the order"
 // @Failure 400 {object} response.Response
+// @Failure 401 {object} response.Response
+// @Failure 403 {object} response.Response
 // @Failure 404 {object} response.Response
+// @Failure 412 {object} response.Response
+// @Failure 422 {object} response.Response
 // @Failure 500 {object} response.Response
 // @Router /orders/{id} [put]
 func (th *OrderHandler) UpdateOrder(c *gin.Context) {
 	id := c.Param("id")
+	version, err := ifMatch(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
 	req := order.Request{}
-	if err := c.BindJSON(&req); err != nil {
-		errRes := response.ClientResponse(http.StatusBadRequest, "fields provided are wrong", nil, err.Error())
-		c.JSON(http.StatusBadRequest, errRes)
+	if err = c.ShouldBindJSON(&req); err != nil {
+		c.Error(apperror.InvalidBody(err))
 		return
 	}
 
-	if err := req.Validate(); err != nil {
-		errRes := response.ClientResponse(http.StatusBadRequest, "fields provided are wrong", nil, err.Error())
-		c.JSON(http.StatusBadRequest, errRes)
+	if err = req.Validate(); err != nil {
+		c.Error(err)
 		return
 	}
 
-	err := th.orderService.UpdateOrder(c.Request.Context(), id, req)
+	res, err := th.orderService.UpdateOrder(c.Request.Context(), id, version, req)
 	if err != nil {
-		if errors.Is(err, order.ErrorNotFound) {
-			errRes := response.ClientResponse(http.StatusNotFound, "order not found", nil, err.Error())
-			c.JSON(http.StatusNotFound, errRes)
-			return
+		c.Error(err)
+		return
+	}
+	setETag(c, res.Version)
+	successRes := response.ClientResponse(http.StatusOK, "the order was successfully updated", res, nil)
+	c.JSON(http.StatusOK, successRes)
+}
 
-		}
-		errRes := response.ClientResponse(http.StatusInternalServerError, "failed to update order", nil, err.Error())
-		c.JSON(http.StatusInternalServerError, errRes)
+// PatchOrder godoc
+// @Summary Patch a order by ID
+// @Description Change the fields of a order given in a JSON merge patch, the total is priced again only when Pricing is in the patch. With If-Match only the version the ETag names is updated. Requires the orders:write permission.
+// @Tags orders
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Order ID"
+// @Param If-Match header string false "ETag of the order"
+// @Param order body order.Patch true "Order Patch"
+// @Success 200 {object} response.Response
+// @Header 200 {string} ETag "Version of the order"
+// @Failure 400 {object} response.Response
+// @Failure 401 {object} response.Response
+// @Failure 403 {object} response.Response
+// @Failure 404 {object} response.Response
+// @Failure 412 {object} response.Response
+// @Failure 422 {object} response.Response
+// @Failure 500 {object} response.Response
+// @Router /orders/{id} [patch]
+func (th *OrderHandler) PatchOrder(c *gin.Context) {
+	id := c.Param("id")
+	version, err := ifMatch(c)
+	if err != nil {
+		c.Error(err)
 		return
 	}
-	successRes := response.ClientResponse(http.StatusOK, "the order was successfully updated", nil, nil)
+	patch := order.Patch{}
+	if err = bindPatch(c, &patch); err != nil {
+		c.Error(err)
+		return
+	}
+
+	res, err := th.orderService.PatchOrder(c.Request.Context(), id, version, patch)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	setETag(c, res.Version)
+	successRes := response.ClientResponse(http.StatusOK, "the order was successfully updated", res, nil)
 	c.JSON(http.StatusOK, successRes)
 }
 
 // DeleteOrder godoc
 // @Summary Delete a order by ID
-// @Description Delete a order by its ID
+// @Description Delete a order by its ID. Requires the orders:write permission.
 // @Tags orders
 // @Produce json
+// @Security BearerAuth
 // @Param id path string true "Order ID"
 // @Success 200 {object} response.Response
+// @Failure 401 {object} response.Response
+// @Failure 403 {object} response.Response
 // @Failure 404 {object} response.Response
 // @Failure 500 {object} response.Response
 // @Router /orders/{id} [delete]
@@ -168,27 +261,51 @@ func (th *OrderHandler) DeleteOrder(c *gin.Context) {
 	id := c.Param("id")
 	err := th.orderService.DeleteOrder(c.Request.Context(), id)
 	if err != nil {
-		if errors.Is(err, order.ErrorNotFound) {
-			errRes := response.ClientResponse(http.StatusNotFound, "order not found", nil, err.Error())
-			c.JSON(http.StatusNotFound, errRes)
-			return
-		}
-		errRes := response.ClientResponse(http.StatusInternalServerError, "failed to delete order", nil, err.Error())
-		c.JSON(http.StatusInternalServerError, errRes)
+		c.Error(err)
 		return
 	}
 	successRes := response.ClientResponse(http.StatusOK, "the order was successfully deleted", nil, nil)
 	c.JSON(http.StatusOK, successRes)
 }
 
+// RestoreOrder godoc
+// @Summary Restore a deleted order
+// @Description Bring a deleted order back with its discounts and taxes, for admins
+// @Tags orders
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Order ID"
+// @Success 200 {object} response.Response
+// @Header 200 {string} ETag "Version of the order"
+// @Failure 401 {object} response.Response
+// @Failure 403 {object} response.Response
+// @Failure 404 {object} response.Response
+// @Failure 409 {object} response.Response
+// @Failure 500 {object} response.Response
+// @Router /orders/{id}/restore [post]
+func (th *OrderHandler) RestoreOrder(c *gin.Context) {
+	id := c.Param("id")
+	res, err := th.orderService.RestoreOrder(c.Request.Context(), id)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	setETag(c, res.Version)
+	successRes := response.ClientResponse(http.StatusOK, "the order was successfully restored", res, nil)
+	c.JSON(http.StatusOK, successRes)
+}
+
 // SearchOrders godoc
 // @Summary Search orders by filter
-// @Description Search orders by filter
+// @Description Search orders by filter. Requires the orders:read:any permission.
 // @Tags orders
 // @Produce json
+// @Security BearerAuth
 // @Param filter query string true "Filter"
 // @Param value query string true "Value"
 // @Success 200 {object} response.Response
+// @Failure 401 {object} response.Response
+// @Failure 403 {object} response.Response
 // @Failure 404 {object} response.Response
 // @Failure 500 {object} response.Response
 // @Router /orders/search [get]
@@ -202,8 +319,7 @@ func (th *OrderHandler) SearchOrders(c *gin.Context) {
 			c.JSON(http.StatusOK, errRes)
 			return
 		}
-		errRes := response.ClientResponse(http.StatusInternalServerError, "failed to search orders", nil, err.Error())
-		c.JSON(http.StatusInternalServerError, errRes)
+		c.Error(err)
 		return
 	}
 	successRes := response.ClientResponse(http.StatusOK, "the orders list", res, nil)
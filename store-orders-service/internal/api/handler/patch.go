@@ -0,0 +1,49 @@
+package handler
+
+import (
+	"github.com/gin-gonic/gin"
+	"io"
+	"order-service/pkg/apperror"
+	"order-service/pkg/mergepatch"
+	"strconv"
+	"strings"
+)
+
+var errInvalidIfMatch = apperror.BadRequest("invalid_if_match", "If-Match must be an ETag sent by the service")
+
+// setETag tags a response with the version of the resource. Clients send it
+// back in If-Match, so an update only applies to the version they read.
+func setETag(c *gin.Context, version int) {
+	c.Header("ETag", strconv.Quote(strconv.Itoa(version)))
+}
+
+// ifMatch returns the version If-Match asks to update, 0 when any version
+// may be updated.
+func ifMatch(c *gin.Context) (int, error) {
+	header := strings.TrimSpace(c.GetHeader("If-Match"))
+	if header == "" || header == "*" {
+		return 0, nil
+	}
+	unquoted, err := strconv.Unquote(header)
+	if err != nil {
+		return 0, errInvalidIfMatch
+	}
+	version, err := strconv.Atoi(unquoted)
+	if err != nil || version <= 0 {
+		return 0, errInvalidIfMatch
+	}
+	return version, nil
+}
+
+// bindPatch reads the merge patch in the request body into patch, a pointer
+// to a partial DTO.
+func bindPatch(c *gin.Context, patch any) error {
+	body, err := io.ReadAll(c.Request.Body)
+	if err != nil {
+		return apperror.InvalidBody(err)
+	}
+	if err = mergepatch.Decode(body, patch); err != nil {
+		return apperror.InvalidBody(err)
+	}
+	return nil
+}
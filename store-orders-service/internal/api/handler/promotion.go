@@ -0,0 +1,166 @@
+package handler
+
+import (
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"order-service/internal/domain/promotion"
+	interfaces "order-service/internal/service/interface"
+	"order-service/pkg/apperror"
+	"order-service/pkg/response"
+)
+
+type PromotionHandler struct {
+	promotionService interfaces.PromotionService
+}
+
+func NewPromotionHandler(service interfaces.PromotionService) *PromotionHandler {
+	return &PromotionHandler{
+		promotionService: service,
+	}
+}
+
+// CreatePromotion godoc
+// @Summary Create a new promotion
+// @Description Create a promotion, an empty code makes it apply automatically to every order. Requires the promotions:write permission.
+// @Tags promotions
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param promotion body promotion.Request true "Promotion Request"
+// @Success 201 {object} response.Response
+// @Failure 400 {object} response.Response
+// @Failure 401 {object} response.Response
+// @Failure 403 {object} response.Response
+// @Failure 409 {object} response.Response
+// @Failure 422 {object} response.Response
+// @Failure 500 {object} response.Response
+// @Router /promotions [post]
+func (ph *PromotionHandler) CreatePromotion(c *gin.Context) {
+	req := promotion.Request{}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(apperror.InvalidBody(err))
+		return
+	}
+
+	if err := req.Validate(); err != nil {
+		c.Error(err)
+		return
+	}
+
+	res, err := ph.promotionService.CreatePromotion(c.Request.Context(), req)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	successRes := response.ClientResponse(http.StatusCreated, "the promotion was successfully created", res, nil)
+	c.JSON(http.StatusCreated, successRes)
+}
+
+// ListPromotions godoc
+// @Summary List all promotions
+// @Description Get a list of promotions. Requires the promotions:read permission.
+// @Tags promotions
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} response.Response
+// @Failure 401 {object} response.Response
+// @Failure 403 {object} response.Response
+// @Failure 500 {object} response.Response
+// @Router /promotions [get]
+func (ph *PromotionHandler) ListPromotions(c *gin.Context) {
+	res, err := ph.promotionService.ListPromotions(c.Request.Context())
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	successRes := response.ClientResponse(http.StatusOK, "the promotions list", res, nil)
+	c.JSON(http.StatusOK, successRes)
+}
+
+// GetPromotion godoc
+// @Summary Get a promotion by ID
+// @Description Get details of a promotion by its ID. Requires the promotions:read permission.
+// @Tags promotions
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Promotion ID"
+// @Success 200 {object} response.Response
+// @Failure 401 {object} response.Response
+// @Failure 403 {object} response.Response
+// @Failure 404 {object} response.Response
+// @Failure 500 {object} response.Response
+// @Router /promotions/{id} [get]
+func (ph *PromotionHandler) GetPromotion(c *gin.Context) {
+	id := c.Param("id")
+	res, err := ph.promotionService.GetPromotion(c.Request.Context(), id)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	successRes := response.ClientResponse(http.StatusOK, "the promotion details", res, nil)
+	c.JSON(http.StatusOK, successRes)
+}
+
+// UpdatePromotion godoc
+// @Summary Update a promotion by ID
+// @Description Replace the rules of a promotion by its ID. Requires the promotions:write permission.
+// @Tags promotions
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Promotion ID"
+// @Param promotion body promotion.Request true "Promotion Request"
+// @Success 200 {object} response.Response
+// @Failure 400 {object} response.Response
+// @Failure 401 {object} response.Response
+// @Failure 403 {object} response.Response
+// @Failure 404 {object} response.Response
+// @Failure 409 {object} response.Response
+// @Failure 422 {object} response.Response
+// @Failure 500 {object} response.Response
+// @Router /promotions/{id} [put]
+func (ph *PromotionHandler) UpdatePromotion(c *gin.Context) {
+	id := c.Param("id")
+	req := promotion.Request{}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(apperror.InvalidBody(err))
+		return
+	}
+
+	if err := req.Validate(); err != nil {
+		c.Error(err)
+		return
+	}
+
+	err := ph.promotionService.UpdatePromotion(c.Request.Context(), id, req)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	successRes := response.ClientResponse(http.StatusOK, "the promotion was successfully updated", nil, nil)
+	c.JSON(http.StatusOK, successRes)
+}
+
+// DeletePromotion godoc
+// @Summary Delete a promotion by ID
+// @Description Delete a promotion by its ID, discounts already granted stay on their orders. Requires the promotions:write permission.
+// @Tags promotions
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Promotion ID"
+// @Success 200 {object} response.Response
+// @Failure 401 {object} response.Response
+// @Failure 403 {object} response.Response
+// @Failure 404 {object} response.Response
+// @Failure 500 {object} response.Response
+// @Router /promotions/{id} [delete]
+func (ph *PromotionHandler) DeletePromotion(c *gin.Context) {
+	id := c.Param("id")
+	err := ph.promotionService.DeletePromotion(c.Request.Context(), id)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	successRes := response.ClientResponse(http.StatusOK, "the promotion was successfully deleted", nil, nil)
+	c.JSON(http.StatusOK, successRes)
+}
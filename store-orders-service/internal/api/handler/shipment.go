@@ -0,0 +1,144 @@
+package handler
+
+import (
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"order-service/internal/domain/shipment"
+	interfaces "order-service/internal/service/interface"
+	"order-service/pkg/apperror"
+	"order-service/pkg/response"
+)
+
+type ShipmentHandler struct {
+	shipmentService interfaces.ShipmentService
+}
+
+func NewShipmentHandler(service interfaces.ShipmentService) *ShipmentHandler {
+	return &ShipmentHandler{
+		shipmentService: service,
+	}
+}
+
+// CreateShipment godoc
+// @Summary Create a shipment of an order
+// @Description Pack ordered items into a shipment, without items every unit not yet shipped is taken. Requires the shipments:write permission.
+// @Tags shipments
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Order ID"
+// @Param shipment body shipment.Request true "Shipment Request"
+// @Success 201 {object} response.Response
+// @Failure 400 {object} response.Response
+// @Failure 401 {object} response.Response
+// @Failure 403 {object} response.Response
+// @Failure 404 {object} response.Response
+// @Failure 422 {object} response.Response
+// @Failure 500 {object} response.Response
+// @Router /orders/{id}/shipments [post]
+func (sh *ShipmentHandler) CreateShipment(c *gin.Context) {
+	req := shipment.Request{}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(apperror.InvalidBody(err))
+		return
+	}
+
+	if err := req.Validate(); err != nil {
+		c.Error(err)
+		return
+	}
+
+	res, err := sh.shipmentService.CreateShipment(c.Request.Context(), c.Param("id"), req)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	successRes := response.ClientResponse(http.StatusCreated, "the shipment was successfully created", res, nil)
+	c.JSON(http.StatusCreated, successRes)
+}
+
+// ListShipments godoc
+// @Summary List shipments of an order
+// @Description Get the shipments of an order with their items and tracking details. Requires the shipments:read permission.
+// @Tags shipments
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Order ID"
+// @Success 200 {object} response.Response
+// @Failure 401 {object} response.Response
+// @Failure 403 {object} response.Response
+// @Failure 500 {object} response.Response
+// @Router /orders/{id}/shipments [get]
+func (sh *ShipmentHandler) ListShipments(c *gin.Context) {
+	res, err := sh.shipmentService.ListShipments(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	successRes := response.ClientResponse(http.StatusOK, "the shipments list", res, nil)
+	c.JSON(http.StatusOK, successRes)
+}
+
+// GetShipment godoc
+// @Summary Get a shipment by ID
+// @Description Get a shipment of an order with its items and tracking details. Requires the shipments:read permission.
+// @Tags shipments
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Order ID"
+// @Param shipment_id path string true "Shipment ID"
+// @Success 200 {object} response.Response
+// @Failure 401 {object} response.Response
+// @Failure 403 {object} response.Response
+// @Failure 404 {object} response.Response
+// @Failure 500 {object} response.Response
+// @Router /orders/{id}/shipments/{shipment_id} [get]
+func (sh *ShipmentHandler) GetShipment(c *gin.Context) {
+	res, err := sh.shipmentService.GetShipment(c.Request.Context(), c.Param("id"), c.Param("shipment_id"))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	successRes := response.ClientResponse(http.StatusOK, "the shipment details", res, nil)
+	c.JSON(http.StatusOK, successRes)
+}
+
+// UpdateShipment godoc
+// @Summary Update a shipment by ID
+// @Description Set the carrier and tracking number or move the shipment to packed, shipped or delivered. The order status follows once all of its items are shipped or delivered. Requires the shipments:write permission.
+// @Tags shipments
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Order ID"
+// @Param shipment_id path string true "Shipment ID"
+// @Param shipment body shipment.UpdateRequest true "Shipment Update Request"
+// @Success 200 {object} response.Response
+// @Failure 400 {object} response.Response
+// @Failure 401 {object} response.Response
+// @Failure 403 {object} response.Response
+// @Failure 404 {object} response.Response
+// @Failure 409 {object} response.Response
+// @Failure 422 {object} response.Response
+// @Failure 500 {object} response.Response
+// @Router /orders/{id}/shipments/{shipment_id} [put]
+func (sh *ShipmentHandler) UpdateShipment(c *gin.Context) {
+	req := shipment.UpdateRequest{}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(apperror.InvalidBody(err))
+		return
+	}
+
+	if err := req.Validate(); err != nil {
+		c.Error(err)
+		return
+	}
+
+	err := sh.shipmentService.UpdateShipment(c.Request.Context(), c.Param("id"), c.Param("shipment_id"), req)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	successRes := response.ClientResponse(http.StatusOK, "the shipment was successfully updated", nil, nil)
+	c.JSON(http.StatusOK, successRes)
+}
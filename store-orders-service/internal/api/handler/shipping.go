@@ -0,0 +1,100 @@
+package handler
+
+import (
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"order-service/internal/domain/shipping"
+	interfaces "order-service/internal/service/interface"
+	"order-service/pkg/apperror"
+	"order-service/pkg/response"
+)
+
+type ShippingHandler struct {
+	shippingService interfaces.ShippingService
+}
+
+func NewShippingHandler(service interfaces.ShippingService) *ShippingHandler {
+	return &ShippingHandler{
+		shippingService: service,
+	}
+}
+
+// SetShippingRate godoc
+// @Summary Create or update a shipping rate
+// @Description Set the delivery price of a method in a zone for a weight band, in grams. Requires the shipping:write permission.
+// @Tags shipping
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param rate body shipping.Request true "Shipping Rate Request"
+// @Success 200 {object} response.Response
+// @Failure 400 {object} response.Response
+// @Failure 401 {object} response.Response
+// @Failure 403 {object} response.Response
+// @Failure 422 {object} response.Response
+// @Failure 500 {object} response.Response
+// @Router /shipping/rates [put]
+func (sh *ShippingHandler) SetShippingRate(c *gin.Context) {
+	req := shipping.Request{}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(apperror.InvalidBody(err))
+		return
+	}
+
+	if err := req.Validate(); err != nil {
+		c.Error(err)
+		return
+	}
+
+	res, err := sh.shippingService.SetRate(c.Request.Context(), req)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	successRes := response.ClientResponse(http.StatusOK, "the shipping rate was successfully set", res, nil)
+	c.JSON(http.StatusOK, successRes)
+}
+
+// ListShippingRates godoc
+// @Summary List shipping rates
+// @Description Get the shipping rate table, optionally for one method or country
+// @Tags shipping
+// @Produce json
+// @Param method query string false "Shipping method"
+// @Param country query string false "Country code"
+// @Success 200 {object} response.Response
+// @Failure 500 {object} response.Response
+// @Router /shipping/rates [get]
+func (sh *ShippingHandler) ListShippingRates(c *gin.Context) {
+	res, err := sh.shippingService.ListRates(c.Request.Context(), c.Query("method"), c.Query("country"))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	successRes := response.ClientResponse(http.StatusOK, "the shipping rates list", res, nil)
+	c.JSON(http.StatusOK, successRes)
+}
+
+// DeleteShippingRate godoc
+// @Summary Delete a shipping rate by ID
+// @Description Delete a shipping rate, orders already quoted keep their shipping cost. Requires the shipping:write permission.
+// @Tags shipping
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Shipping Rate ID"
+// @Success 200 {object} response.Response
+// @Failure 401 {object} response.Response
+// @Failure 403 {object} response.Response
+// @Failure 404 {object} response.Response
+// @Failure 500 {object} response.Response
+// @Router /shipping/rates/{id} [delete]
+func (sh *ShippingHandler) DeleteShippingRate(c *gin.Context) {
+	id := c.Param("id")
+	err := sh.shippingService.DeleteRate(c.Request.Context(), id)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	successRes := response.ClientResponse(http.StatusOK, "the shipping rate was successfully deleted", nil, nil)
+	c.JSON(http.StatusOK, successRes)
+}
@@ -0,0 +1,99 @@
+package handler
+
+import (
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"order-service/internal/domain/tax"
+	interfaces "order-service/internal/service/interface"
+	"order-service/pkg/apperror"
+	"order-service/pkg/response"
+)
+
+type TaxHandler struct {
+	taxService interfaces.TaxService
+}
+
+func NewTaxHandler(service interfaces.TaxService) *TaxHandler {
+	return &TaxHandler{
+		taxService: service,
+	}
+}
+
+// SetTaxRate godoc
+// @Summary Create or update a tax rate
+// @Description Set the rate charged in a country, optionally narrowed to a region and a product category. Requires the taxes:write permission.
+// @Tags taxes
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param rate body tax.Request true "Tax Rate Request"
+// @Success 200 {object} response.Response
+// @Failure 400 {object} response.Response
+// @Failure 401 {object} response.Response
+// @Failure 403 {object} response.Response
+// @Failure 422 {object} response.Response
+// @Failure 500 {object} response.Response
+// @Router /taxes [put]
+func (th *TaxHandler) SetTaxRate(c *gin.Context) {
+	req := tax.Request{}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(apperror.InvalidBody(err))
+		return
+	}
+
+	if err := req.Validate(); err != nil {
+		c.Error(err)
+		return
+	}
+
+	res, err := th.taxService.SetRate(c.Request.Context(), req)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	successRes := response.ClientResponse(http.StatusOK, "the tax rate was successfully set", res, nil)
+	c.JSON(http.StatusOK, successRes)
+}
+
+// ListTaxRates godoc
+// @Summary List tax rates
+// @Description Get the tax table, optionally for one country
+// @Tags taxes
+// @Produce json
+// @Param country query string false "Country code"
+// @Success 200 {object} response.Response
+// @Failure 500 {object} response.Response
+// @Router /taxes [get]
+func (th *TaxHandler) ListTaxRates(c *gin.Context) {
+	res, err := th.taxService.ListRates(c.Request.Context(), c.Query("country"))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	successRes := response.ClientResponse(http.StatusOK, "the tax rates list", res, nil)
+	c.JSON(http.StatusOK, successRes)
+}
+
+// DeleteTaxRate godoc
+// @Summary Delete a tax rate by ID
+// @Description Delete a tax rate, orders already taxed keep their tax lines. Requires the taxes:write permission.
+// @Tags taxes
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Tax Rate ID"
+// @Success 200 {object} response.Response
+// @Failure 401 {object} response.Response
+// @Failure 403 {object} response.Response
+// @Failure 404 {object} response.Response
+// @Failure 500 {object} response.Response
+// @Router /taxes/{id} [delete]
+func (th *TaxHandler) DeleteTaxRate(c *gin.Context) {
+	id := c.Param("id")
+	err := th.taxService.DeleteRate(c.Request.Context(), id)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	successRes := response.ClientResponse(http.StatusOK, "the tax rate was successfully deleted", nil, nil)
+	c.JSON(http.StatusOK, successRes)
+}
@@ -3,14 +3,55 @@ package routes
 import (
 	"github.com/gin-gonic/gin"
 	"order-service/internal/api/handler"
+	"order-service/pkg/auth"
 )
 
 func InitRoutes(router *gin.RouterGroup, orderHandler *handler.OrderHandler) {
-	router.GET("/", orderHandler.ListOrders)
+	// a customer places and reads its own orders, the handlers check whose
+	// they are
+	readAny := auth.RequirePermission("orders:read:any")
+	write := auth.RequirePermission("orders:write")
+	router.GET("/", readAny, orderHandler.ListOrders)
 	router.POST("/", orderHandler.CreateOrder)
 	router.GET("/:id", orderHandler.GetOrder)
-	router.PUT("/:id", orderHandler.UpdateOrder)
-	router.DELETE("/:id", orderHandler.DeleteOrder)
-	router.GET("/search", orderHandler.SearchOrders)
+	router.GET("/:id/invoice", orderHandler.GetInvoice)
+	router.PUT("/:id", write, orderHandler.UpdateOrder)
+	router.PATCH("/:id", write, orderHandler.PatchOrder)
+	router.DELETE("/:id", write, orderHandler.DeleteOrder)
+	router.POST("/:id/restore", auth.RequirePermission("orders:restore"), orderHandler.RestoreOrder)
+	router.GET("/search", readAny, orderHandler.SearchOrders)
 
 }
+
+func InitPromotionRoutes(router *gin.RouterGroup, promotionHandler *handler.PromotionHandler) {
+	read := auth.RequirePermission("promotions:read", "promotions:write")
+	write := auth.RequirePermission("promotions:write")
+	router.GET("/", read, promotionHandler.ListPromotions)
+	router.POST("/", write, promotionHandler.CreatePromotion)
+	router.GET("/:id", read, promotionHandler.GetPromotion)
+	router.PUT("/:id", write, promotionHandler.UpdatePromotion)
+	router.DELETE("/:id", write, promotionHandler.DeletePromotion)
+}
+
+func InitTaxRoutes(router *gin.RouterGroup, taxHandler *handler.TaxHandler) {
+	write := auth.RequirePermission("taxes:write")
+	router.GET("/", taxHandler.ListTaxRates)
+	router.PUT("/", write, taxHandler.SetTaxRate)
+	router.DELETE("/:id", write, taxHandler.DeleteTaxRate)
+}
+
+func InitShippingRoutes(router *gin.RouterGroup, shippingHandler *handler.ShippingHandler) {
+	write := auth.RequirePermission("shipping:write")
+	router.GET("/rates", shippingHandler.ListShippingRates)
+	router.PUT("/rates", write, shippingHandler.SetShippingRate)
+	router.DELETE("/rates/:id", write, shippingHandler.DeleteShippingRate)
+}
+
+func InitShipmentRoutes(router *gin.RouterGroup, shipmentHandler *handler.ShipmentHandler) {
+	read := auth.RequirePermission("shipments:read", "shipments:write")
+	write := auth.RequirePermission("shipments:write")
+	router.GET("/", read, shipmentHandler.ListShipments)
+	router.POST("/", write, shipmentHandler.CreateShipment)
+	router.GET("/:shipment_id", read, shipmentHandler.GetShipment)
+	router.PUT("/:shipment_id", write, shipmentHandler.UpdateShipment)
+}
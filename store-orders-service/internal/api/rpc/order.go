@@ -0,0 +1,180 @@
+package rpc
+
+import (
+	"context"
+	"google.golang.org/protobuf/types/known/timestamppb"
+	"order-service/internal/domain/order"
+	services "order-service/internal/service/interface"
+	"order-service/pkg/auth"
+	"order-service/pkg/money"
+	"order-service/pkg/pb/orderpb"
+)
+
+// OrderServer checks the permissions the REST routes check, a customer
+// places and reads its own orders only.
+type OrderServer struct {
+	orderpb.UnimplementedOrderServiceServer
+	orderService services.OrderService
+}
+
+func NewOrderServer(orderService services.OrderService) *OrderServer {
+	return &OrderServer{orderService: orderService}
+}
+
+func (s *OrderServer) CreateOrder(ctx context.Context, in *orderpb.CreateOrderRequest) (*orderpb.CreateOrderResponse, error) {
+	req, err := parseInput(in.GetOrder())
+	if err != nil {
+		return nil, statusError(err)
+	}
+	if err = auth.Authorize(ctx, req.UserID, "orders:write"); err != nil {
+		return nil, statusError(err)
+	}
+
+	id, err := s.orderService.CreateOrder(ctx, req)
+	if err != nil {
+		return nil, statusError(err)
+	}
+	return &orderpb.CreateOrderResponse{Id: id}, nil
+}
+
+func (s *OrderServer) ListOrders(ctx context.Context, in *orderpb.ListOrdersRequest) (*orderpb.ListOrdersResponse, error) {
+	if err := auth.Authorize(ctx, "", "orders:read:any"); err != nil {
+		return nil, statusError(err)
+	}
+
+	res, err := s.orderService.ListOrders(ctx)
+	if err != nil {
+		return nil, statusError(err)
+	}
+	return &orderpb.ListOrdersResponse{Orders: parseOrders(res)}, nil
+}
+
+func (s *OrderServer) GetOrder(ctx context.Context, in *orderpb.GetOrderRequest) (*orderpb.Order, error) {
+	res, err := s.orderService.GetOrder(ctx, in.GetId())
+	if err != nil {
+		return nil, statusError(err)
+	}
+	if err = auth.Authorize(ctx, res.UserID, "orders:read:any"); err != nil {
+		return nil, statusError(err)
+	}
+	return parseOrder(res), nil
+}
+
+func (s *OrderServer) UpdateOrder(ctx context.Context, in *orderpb.UpdateOrderRequest) (*orderpb.UpdateOrderResponse, error) {
+	if err := auth.Authorize(ctx, "", "orders:write"); err != nil {
+		return nil, statusError(err)
+	}
+
+	req, err := parseInput(in.GetOrder())
+	if err != nil {
+		return nil, statusError(err)
+	}
+
+	// the gRPC API has no version, updates are unconditional
+	if _, err = s.orderService.UpdateOrder(ctx, in.GetId(), 0, req); err != nil {
+		return nil, statusError(err)
+	}
+	return &orderpb.UpdateOrderResponse{}, nil
+}
+
+func (s *OrderServer) DeleteOrder(ctx context.Context, in *orderpb.DeleteOrderRequest) (*orderpb.DeleteOrderResponse, error) {
+	if err := auth.Authorize(ctx, "", "orders:write"); err != nil {
+		return nil, statusError(err)
+	}
+
+	if err := s.orderService.DeleteOrder(ctx, in.GetId()); err != nil {
+		return nil, statusError(err)
+	}
+	return &orderpb.DeleteOrderResponse{}, nil
+}
+
+func (s *OrderServer) SearchOrders(ctx context.Context, in *orderpb.SearchOrdersRequest) (*orderpb.ListOrdersResponse, error) {
+	if err := auth.Authorize(ctx, "", "orders:read:any"); err != nil {
+		return nil, statusError(err)
+	}
+
+	res, err := s.orderService.SearchOrder(ctx, in.GetFilter(), in.GetValue())
+	if err != nil {
+		return nil, statusError(err)
+	}
+	return &orderpb.ListOrdersResponse{Orders: parseOrders(res)}, nil
+}
+
+// parseInput builds a validated order request, the same checks the REST
+// handlers run before calling the service.
+func parseInput(in *orderpb.OrderInput) (req order.Request, err error) {
+	pricing, err := money.Parse(in.GetPricing().GetAmount(), in.GetPricing().GetCurrency())
+	if err != nil {
+		return req, order.ErrorInvalidPrice
+	}
+	req = order.Request{
+		UserID:            in.GetUserId(),
+		ProductID:         in.GetProductId(),
+		Pricing:           pricing,
+		Currency:          in.GetCurrency(),
+		PromoCodes:        in.GetPromoCodes(),
+		Country:           in.GetCountry(),
+		Region:            in.GetRegion(),
+		ShippingAddressID: in.GetShippingAddressId(),
+		ShippingMethod:    in.GetShippingMethod(),
+		Status:            in.GetStatus(),
+	}
+	err = req.Validate()
+	return
+}
+
+func formatMoney(m money.Money) *orderpb.Money {
+	return &orderpb.Money{Amount: m.Decimal(), Currency: m.Currency}
+}
+
+func parseOrder(res order.Response) *orderpb.Order {
+	data := &orderpb.Order{
+		Id:                res.ID,
+		UserId:            res.UserID,
+		ProductId:         res.ProductID,
+		Pricing:           formatMoney(res.Pricing),
+		BasePricing:       formatMoney(res.BasePricing),
+		ExchangeRate:      res.ExchangeRate,
+		Discount:          formatMoney(res.Discount),
+		Discounts:         make([]*orderpb.Discount, 0, len(res.Discounts)),
+		Country:           res.Country,
+		Region:            res.Region,
+		Tax:               formatMoney(res.Tax),
+		Taxes:             make([]*orderpb.Tax, 0, len(res.Taxes)),
+		ShippingAddressId: res.ShippingAddressID,
+		ShippingAddress:   res.ShippingAddress,
+		ShippingMethod:    res.ShippingMethod,
+		Shipping:          formatMoney(res.Shipping),
+		Status:            res.Status,
+		CreatedAt:         timestamppb.New(res.CreatedAt),
+	}
+	for _, discount := range res.Discounts {
+		data.Discounts = append(data.Discounts, &orderpb.Discount{
+			PromotionId: discount.PromotionID,
+			Code:        discount.Code,
+			Type:        discount.Type,
+			Amount:      formatMoney(discount.Amount),
+		})
+	}
+	for _, line := range res.Taxes {
+		data.Taxes = append(data.Taxes, &orderpb.Tax{
+			Name:      line.Name,
+			Country:   line.Country,
+			Region:    line.Region,
+			Category:  line.Category,
+			Rate:      line.Rate,
+			Inclusive: line.Inclusive,
+			Taxable:   formatMoney(line.Taxable),
+			Amount:    formatMoney(line.Amount),
+		})
+	}
+	return data
+}
+
+func parseOrders(data []order.Response) (res []*orderpb.Order) {
+	res = make([]*orderpb.Order, 0, len(data))
+	for _, item := range data {
+		res = append(res, parseOrder(item))
+	}
+	return
+}
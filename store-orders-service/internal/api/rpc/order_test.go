@@ -0,0 +1,233 @@
+package rpc
+
+import (
+	"context"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+	"net"
+	"order-service/internal/config"
+	"order-service/internal/domain/customer"
+	"order-service/internal/domain/order"
+	services "order-service/internal/service/interface"
+	"order-service/pkg/audit"
+	"order-service/pkg/auth"
+	"order-service/pkg/money"
+	"order-service/pkg/pb/orderpb"
+	"reflect"
+	"testing"
+	"time"
+)
+
+const (
+	userID      = "5b0c7e2a-3f4d-4a8b-9c1e-2d3f4a5b6c7d"
+	productID   = "8e9f0a1b-2c3d-4e5f-8a7b-9c0d1e2f3a4b"
+	tokenSecret = "0123456789abcdef0123456789abcdef"
+)
+
+type fakeOrders struct {
+	services.OrderService
+	orders  map[string]order.Response
+	created []order.Request
+	// actors are the users the orders were created by
+	actors []string
+	err    error
+}
+
+func (fo *fakeOrders) CreateOrder(ctx context.Context, req order.Request) (string, error) {
+	if fo.err != nil {
+		return "", fo.err
+	}
+	fo.created = append(fo.created, req)
+	fo.actors = append(fo.actors, audit.Actor(ctx))
+	return "o2", nil
+}
+
+func (fo *fakeOrders) GetOrder(ctx context.Context, id string) (order.Response, error) {
+	res, ok := fo.orders[id]
+	if !ok {
+		return res, order.ErrorNotFound
+	}
+	return res, nil
+}
+
+// withToken returns a context whose calls carry a token of subject with
+// permissions.
+func withToken(t *testing.T, subject string, permissions ...string) context.Context {
+	t.Helper()
+	claims := auth.Claims{Subject: subject, Permissions: permissions, ExpiresAt: time.Now().Add(time.Minute).Unix()}
+	signed, err := auth.Sign(claims, tokenSecret)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return metadata.AppendToOutgoingContext(context.Background(), "authorization", "Bearer "+signed)
+}
+
+// dialOrders serves orderService on an in-memory listener and returns a
+// client connected to it.
+func dialOrders(t *testing.T, orderService services.OrderService) orderpb.OrderServiceClient {
+	client, _ := dialServer(t, orderService)
+	return client
+}
+
+// dialServer is dialOrders that also returns the server, to stop it.
+func dialServer(t *testing.T, orderService services.OrderService) (orderpb.OrderServiceClient, *Server) {
+	listener := bufconn.Listen(1 << 20)
+	server := NewServer(config.Config{TokenSecret: tokenSecret}, orderService)
+	go server.server.Serve(listener)
+	t.Cleanup(server.server.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return listener.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return orderpb.NewOrderServiceClient(conn), server
+}
+
+func TestGetOrder(t *testing.T) {
+	orders := &fakeOrders{orders: map[string]order.Response{
+		"o1": {ID: "o1", UserID: "u1", ProductID: []string{"p1"}, Pricing: money.New(1250, "USD"), Status: order.StatusPaid},
+	}}
+	client := dialOrders(t, orders)
+	ctx := withToken(t, "u1")
+
+	got, err := client.GetOrder(ctx, &orderpb.GetOrderRequest{Id: "o1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.GetId() != "o1" || got.GetUserId() != "u1" || got.GetStatus() != order.StatusPaid {
+		t.Errorf("got %v", got)
+	}
+	if pricing := got.GetPricing(); pricing.GetAmount() != "12.50" || pricing.GetCurrency() != "USD" {
+		t.Errorf("pricing = %v, want 12.50 USD", pricing)
+	}
+
+	_, err = client.GetOrder(ctx, &orderpb.GetOrderRequest{Id: "nope"})
+	if code := status.Code(err); code != codes.NotFound {
+		t.Errorf("code = %s, want NotFound", code)
+	}
+
+	_, err = client.GetOrder(withToken(t, "u2"), &orderpb.GetOrderRequest{Id: "o1"})
+	if code := status.Code(err); code != codes.PermissionDenied {
+		t.Errorf("order of another user: code = %s, want PermissionDenied", code)
+	}
+	_, err = client.GetOrder(withToken(t, "u2", "orders:read:any"), &orderpb.GetOrderRequest{Id: "o1"})
+	if err != nil {
+		t.Errorf("order of another user with orders:read:any: %v", err)
+	}
+}
+
+func TestCreateOrder(t *testing.T) {
+	input := func(amount string) *orderpb.OrderInput {
+		return &orderpb.OrderInput{
+			UserId:    userID,
+			ProductId: []string{productID},
+			Pricing:   &orderpb.Money{Amount: amount, Currency: "USD"},
+			Status:    order.StatusNew,
+		}
+	}
+
+	tests := []struct {
+		name    string
+		in      *orderpb.OrderInput
+		err     error
+		code    codes.Code
+		created []order.Request
+	}{
+		{
+			name: "created",
+			in:   input("12.50"),
+			code: codes.OK,
+			created: []order.Request{{
+				UserID:    userID,
+				ProductID: []string{productID},
+				Pricing:   money.New(1250, "USD"),
+				Status:    order.StatusNew,
+			}},
+		},
+		{
+			name: "invalid request never reaches the service",
+			in:   input("-1"),
+			code: codes.InvalidArgument,
+		},
+		{
+			name: "missing address",
+			in:   input("12.50"),
+			err:  customer.ErrorAddressNotFound,
+			code: codes.FailedPrecondition,
+		},
+		{
+			name: "user service down",
+			in:   input("12.50"),
+			err:  customer.ErrorUnavailable,
+			code: codes.Unavailable,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			orders := &fakeOrders{err: tt.err}
+			client := dialOrders(t, orders)
+
+			_, err := client.CreateOrder(withToken(t, userID), &orderpb.CreateOrderRequest{Order: tt.in})
+			if code := status.Code(err); code != tt.code {
+				t.Fatalf("code = %s, want %s", code, tt.code)
+			}
+			if !reflect.DeepEqual(orders.created, tt.created) {
+				t.Errorf("created %+v, want %+v", orders.created, tt.created)
+			}
+		})
+	}
+}
+
+func TestCallsAreMadeByTheTokenUser(t *testing.T) {
+	forged, err := auth.Sign(auth.Claims{Subject: userID, ExpiresAt: time.Now().Add(time.Minute).Unix()}, "another secret of at least 32 bytes")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name  string
+		ctx   context.Context
+		code  codes.Code
+		actor string
+	}{
+		{"token", withToken(t, userID), codes.OK, userID},
+		{"for another user with orders:write", withToken(t, "admin", "orders:write"), codes.OK, "admin"},
+		{"for another user", withToken(t, "u2"), codes.PermissionDenied, ""},
+		{"anonymous", context.Background(), codes.Unauthenticated, ""},
+		{"user ID metadata is not an identity", metadata.AppendToOutgoingContext(context.Background(), "x-user-id", userID), codes.Unauthenticated, ""},
+		{"token signed with another secret", metadata.AppendToOutgoingContext(context.Background(), "authorization", "Bearer "+forged), codes.Unauthenticated, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			orders := &fakeOrders{}
+			client := dialOrders(t, orders)
+
+			in := &orderpb.OrderInput{
+				UserId:    userID,
+				ProductId: []string{productID},
+				Pricing:   &orderpb.Money{Amount: "12.50", Currency: "USD"},
+				Status:    order.StatusNew,
+			}
+			_, err := client.CreateOrder(tt.ctx, &orderpb.CreateOrderRequest{Order: in})
+			if code := status.Code(err); code != tt.code {
+				t.Fatalf("code = %v, want %v: %v", code, tt.code, err)
+			}
+			if tt.code != codes.OK {
+				if len(orders.created) != 0 {
+					t.Error("order created without the right token")
+				}
+				return
+			}
+			if !reflect.DeepEqual(orders.actors, []string{tt.actor}) {
+				t.Errorf("actors = %q, want %q", orders.actors, tt.actor)
+			}
+		})
+	}
+}
@@ -0,0 +1,79 @@
+package rpc
+
+import (
+	"context"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"order-service/internal/domain/order"
+	services "order-service/internal/service/interface"
+	"order-service/pkg/pb/orderpb"
+	"testing"
+	"time"
+)
+
+// blockingOrders holds GetOrder until release is closed or the call is
+// cancelled.
+type blockingOrders struct {
+	services.OrderService
+	started chan struct{}
+	release chan struct{}
+}
+
+func (bo *blockingOrders) GetOrder(ctx context.Context, id string) (order.Response, error) {
+	close(bo.started)
+	select {
+	case <-bo.release:
+		return order.Response{ID: id}, nil
+	case <-ctx.Done():
+		return order.Response{}, ctx.Err()
+	}
+}
+
+// serveBlocking starts a GetOrder call on a server whose service blocks and
+// returns the server and the result of the call once it is in flight.
+func serveBlocking(t *testing.T) (*Server, *blockingOrders, chan error) {
+	orders := &blockingOrders{started: make(chan struct{}), release: make(chan struct{})}
+	client, server := dialServer(t, orders)
+
+	ctx := withToken(t, "admin", "orders:read:any")
+	result := make(chan error, 1)
+	go func() {
+		_, err := client.GetOrder(ctx, &orderpb.GetOrderRequest{Id: "o1"})
+		result <- err
+	}()
+	<-orders.started
+	return server, orders, result
+}
+
+func TestStopWaitsForCallsInFlight(t *testing.T) {
+	server, orders, result := serveBlocking(t)
+
+	stopped := make(chan struct{})
+	go func() {
+		server.Stop(context.Background())
+		close(stopped)
+	}()
+	select {
+	case <-stopped:
+		t.Fatal("Stop returned before the call in flight finished")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	close(orders.release)
+	if err := <-result; err != nil {
+		t.Errorf("call in flight failed: %v", err)
+	}
+	<-stopped
+}
+
+func TestStopCancelsCallsAfterTimeout(t *testing.T) {
+	server, _, result := serveBlocking(t)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	server.Stop(ctx)
+
+	if code := status.Code(<-result); code == codes.OK {
+		t.Error("call still running at the timeout succeeded")
+	}
+}
@@ -0,0 +1,76 @@
+package rpc
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"order-service/internal/domain/catalog"
+	"order-service/internal/domain/customer"
+	"order-service/internal/domain/order"
+	"order-service/internal/domain/promotion"
+	"order-service/internal/domain/shipping"
+	"order-service/pkg/apperror"
+	"order-service/pkg/auth"
+	"order-service/pkg/validate"
+)
+
+// statusError maps a service error to the gRPC status a client can act on,
+// anything unexpected is Internal. The code of the error goes along as an
+// ErrorInfo, so clients see the same code as over REST.
+func statusError(err error) error {
+	switch {
+	case errors.Is(err, order.ErrorNotFound):
+		return errorStatus(codes.NotFound, err)
+	case errors.Is(err, validate.ErrorFailed), errors.Is(err, order.ErrorInvalidPrice),
+		errors.Is(err, order.ErrorInvalidSearch), errors.Is(err, order.ErrorInvalidCurrency),
+		errors.Is(err, catalog.ErrorRateNotFound), errors.Is(err, catalog.ErrorProductNotFound),
+		errors.Is(err, customer.ErrorUserNotFound):
+		return errorStatus(codes.InvalidArgument, err)
+	case errors.Is(err, customer.ErrorAddressNotFound), errors.Is(err, shipping.ErrorMissingDestination),
+		errors.Is(err, shipping.ErrorNotAvailable), errors.Is(err, promotion.ErrorUnknownCode),
+		errors.Is(err, promotion.ErrorInactive), errors.Is(err, promotion.ErrorLimitReached),
+		errors.Is(err, promotion.ErrorNotApplicable), errors.Is(err, promotion.ErrorNotFound),
+		errors.Is(err, catalog.ErrorOutOfStock):
+		return errorStatus(codes.FailedPrecondition, err)
+	case errors.Is(err, catalog.ErrorUnavailable), errors.Is(err, customer.ErrorUnavailable):
+		return errorStatus(codes.Unavailable, err)
+	case errors.Is(err, auth.ErrorTokenRequired):
+		return errorStatus(codes.Unauthenticated, err)
+	case errors.Is(err, auth.ErrorPermissionDenied):
+		return errorStatus(codes.PermissionDenied, err)
+	}
+	return errorStatus(codes.Internal, err)
+}
+
+func errorStatus(code codes.Code, err error) error {
+	appErr := apperror.From(err)
+	info := &errdetails.ErrorInfo{Reason: appErr.Code, Domain: "order-service"}
+	if len(appErr.Details) > 0 {
+		info.Metadata = make(map[string]string, len(appErr.Details))
+		for key, value := range appErr.Details {
+			info.Metadata[key] = metadataValue(value)
+		}
+	}
+	st, detailsErr := status.New(code, err.Error()).WithDetails(info)
+	if detailsErr != nil {
+		return status.Error(code, err.Error())
+	}
+	return st.Err()
+}
+
+// metadataValue turns a detail into ErrorInfo metadata, which only holds
+// strings. Lists and objects, such as validation violations, are JSON.
+func metadataValue(value any) string {
+	switch value.(type) {
+	case string, int, int64, bool:
+		return fmt.Sprint(value)
+	}
+	b, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Sprint(value)
+	}
+	return string(b)
+}
@@ -0,0 +1,43 @@
+package http
+
+import (
+	"encoding/json"
+	"errors"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"net/http/httptest"
+	"order-service/internal/domain/order"
+	"strings"
+	"testing"
+)
+
+func TestErrorMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(ErrorMiddleware())
+	router.GET("/known", func(c *gin.Context) {
+		c.Error(order.ErrorNotFound.WithDetail("id", "o1"))
+	})
+	router.GET("/unknown", func(c *gin.Context) {
+		c.Error(errors.New("pq: password authentication failed"))
+	})
+
+	tests := []struct {
+		path   string
+		status int
+		body   string
+	}{
+		{"/known", http.StatusNotFound, `"error":{"code":"order_not_found","message":"order not found","details":{"id":"o1"}}`},
+		{"/unknown", http.StatusInternalServerError, `"error":{"code":"internal","message":"internal server error"}`},
+	}
+	for _, tt := range tests {
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, tt.path, nil))
+		if rec.Code != tt.status || !strings.Contains(rec.Body.String(), tt.body) {
+			t.Errorf("%s: %d %s, want %d with %s", tt.path, rec.Code, rec.Body, tt.status, tt.body)
+		}
+		if !json.Valid(rec.Body.Bytes()) || strings.Contains(rec.Body.String(), "password") {
+			t.Errorf("%s: body %s", tt.path, rec.Body)
+		}
+	}
+}
@@ -0,0 +1,30 @@
+package db
+
+import (
+	"order-service/internal/config"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestConnectDatabaseGivesUp(t *testing.T) {
+	cfg := config.Default()
+	cfg.DBHost, cfg.DBPort = "127.0.0.1", "1"
+	cfg.DBUser, cfg.DBPassword, cfg.DBName = "store", "hunter2", "orders"
+	cfg.DBConnectTimeout = time.Second
+
+	start := time.Now()
+	_, err := ConnectDatabase(cfg)
+	if err == nil {
+		t.Fatal("connected to a closed port")
+	}
+	if elapsed := time.Since(start); elapsed < 500*time.Millisecond || elapsed > 5*time.Second {
+		t.Errorf("gave up after %s, want a retry within the connect timeout", elapsed)
+	}
+	if !strings.Contains(err.Error(), "after 2 attempts") {
+		t.Errorf("error = %v, want the number of attempts", err)
+	}
+	if strings.Contains(err.Error(), "hunter2") {
+		t.Errorf("error has the password: %v", err)
+	}
+}
@@ -0,0 +1,53 @@
+package db
+
+import (
+	"context"
+	"fmt"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jmoiron/sqlx"
+	"github.com/pressly/goose/v3"
+	"io/fs"
+	"order-service/migrations"
+	"testing"
+)
+
+func TestEmbeddedMigrations(t *testing.T) {
+	files, err := fs.Glob(migrations.FS, migrationsDir+"/*.sql")
+	if err != nil {
+		t.Fatal(err)
+	}
+	collected, err := goose.CollectMigrations(migrationsDir, 0, goose.MaxVersion)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files) == 0 || len(collected) != len(files) {
+		t.Errorf("collected %d migrations from %d embedded files", len(collected), len(files))
+	}
+}
+
+func TestMigrationsCheck(t *testing.T) {
+	collected, err := goose.CollectMigrations(migrationsDir, 0, goose.MaxVersion)
+	if err != nil {
+		t.Fatal(err)
+	}
+	last, _ := collected.Last()
+
+	conn, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	check := Migrations(sqlx.NewDb(conn, "postgres"))
+
+	mock.ExpectQuery("SELECT is_applied FROM goose_db_version").WithArgs(last.Version).
+		WillReturnRows(sqlmock.NewRows([]string{"is_applied"}).AddRow(true))
+	if detail, err := check(context.Background()); err != nil || detail != fmt.Sprintf("version %d", last.Version) {
+		t.Errorf("applied: %q, %v", detail, err)
+	}
+
+	mock.ExpectQuery("SELECT is_applied FROM goose_db_version").WithArgs(last.Version).
+		WillReturnRows(sqlmock.NewRows([]string{"is_applied"}))
+	if _, err := check(context.Background()); err == nil {
+		t.Error("a database without the last migration is ready")
+	}
+}
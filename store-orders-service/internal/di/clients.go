@@ -0,0 +1,47 @@
+package di
+
+import (
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"order-service/internal/config"
+	"order-service/internal/service"
+	"order-service/pkg/logging"
+	"order-service/pkg/metrics"
+	"order-service/pkg/pb/productpb"
+	"order-service/pkg/pb/userpb"
+	"order-service/pkg/tracing"
+)
+
+// NewProductClient connects to the gRPC API of the product service at
+// cfg.ProductServiceGRPC, without an address the catalog is read over REST.
+func NewProductClient(cfg config.Config) (productpb.ProductServiceClient, error) {
+	if cfg.ProductServiceGRPC == "" {
+		return nil, nil
+	}
+	conn, err := grpc.NewClient(cfg.ProductServiceGRPC,
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithChainUnaryInterceptor(logging.UnaryClientInterceptor(), metrics.UnaryClientInterceptor()),
+		tracing.DialOption(),
+	)
+	if err != nil {
+		return nil, err
+	}
+	return productpb.NewProductServiceClient(conn), nil
+}
+
+// NewUserClient connects to the gRPC API of the user service at
+// cfg.UserServiceGRPC, without an address customers are read over REST.
+func NewUserClient(cfg config.Config) (userpb.UserServiceClient, error) {
+	if cfg.UserServiceGRPC == "" {
+		return nil, nil
+	}
+	conn, err := grpc.NewClient(cfg.UserServiceGRPC,
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithChainUnaryInterceptor(logging.UnaryClientInterceptor(), metrics.UnaryClientInterceptor(), service.Identity(cfg).UnaryClientInterceptor()),
+		tracing.DialOption(),
+	)
+	if err != nil {
+		return nil, err
+	}
+	return userpb.NewUserServiceClient(conn), nil
+}
@@ -0,0 +1,17 @@
+package di
+
+import (
+	"github.com/jmoiron/sqlx"
+	"order-service/internal/config"
+	"order-service/pkg/events"
+)
+
+// NewRelay relays the outbox of the service to the broker at cfg.EventsURL.
+func NewRelay(cfg config.Config, db *sqlx.DB) *events.Relay {
+	return events.NewRelay(db, events.NewPublisher(cfg.EventsURL), "order-service")
+}
+
+// NewSubscriber consumes the event stream of the broker at cfg.EventsURL.
+func NewSubscriber(cfg config.Config, stats *events.Stats) events.Subscriber {
+	return events.NewSubscriber(cfg.EventsURL, stats)
+}
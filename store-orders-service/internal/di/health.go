@@ -0,0 +1,18 @@
+package di
+
+import (
+	"github.com/jmoiron/sqlx"
+	"order-service/internal/db"
+	"order-service/pkg/health"
+)
+
+// NewHealthChecker checks the dependencies the service cannot serve without.
+func NewHealthChecker(sqlxDB *sqlx.DB, replica db.Replica) *health.Checker {
+	checker := health.NewChecker()
+	checker.Add("database", db.Ping(sqlxDB))
+	if replica.DB != sqlxDB {
+		checker.Add("replica", db.Ping(replica.DB))
+	}
+	checker.Add("migrations", db.Migrations(sqlxDB))
+	return checker
+}
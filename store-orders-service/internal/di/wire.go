@@ -8,18 +8,47 @@ import (
 	_ "github.com/lib/pq"
 	http "order-service/internal/api"
 	"order-service/internal/api/handler"
+	"order-service/internal/api/rpc"
 	"order-service/internal/config"
 	"order-service/internal/db"
 	"order-service/internal/repository"
 	"order-service/internal/service"
+	"order-service/pkg/events"
 )
 
 func InitializeAPI(cfg config.Config) (*http.Server, error) {
 	wire.Build(
 		db.ConnectDatabase,
+		db.ConnectReplica,
 		handler.NewOrderHandler,
+		handler.NewPromotionHandler,
+		handler.NewTaxHandler,
+		handler.NewShippingHandler,
+		handler.NewShipmentHandler,
+		handler.NewMetricsHandler,
+		handler.NewHealthHandler,
 		repository.NewOrderRepository,
+		repository.NewPromotionRepository,
+		repository.NewTaxRepository,
+		repository.NewShippingRepository,
+		repository.NewShipmentRepository,
 		service.NewOrderService,
+		service.NewPromotionService,
+		service.NewTaxService,
+		service.NewTaxCalculator,
+		service.NewShippingService,
+		service.NewShippingRateProvider,
+		service.NewShipmentService,
+		service.NewPaymentEventConsumer,
+		service.NewCatalogService,
+		service.NewCustomerService,
+		NewHealthChecker,
+		NewRelay,
+		NewSubscriber,
+		NewProductClient,
+		NewUserClient,
+		events.NewStats,
+		rpc.NewServer,
 		http.NewServer,
 	)
 	return &http.Server{}, nil
@@ -8,10 +8,12 @@ import (
 	_ "github.com/lib/pq"
 	"order-service/internal/api"
 	"order-service/internal/api/handler"
+	"order-service/internal/api/rpc"
 	"order-service/internal/config"
 	"order-service/internal/db"
 	"order-service/internal/repository"
 	"order-service/internal/service"
+	"order-service/pkg/events"
 )
 
 func InitializeAPI(cfg config.Config) (*http.Server, error) {
@@ -19,13 +21,51 @@ func InitializeAPI(cfg config.Config) (*http.Server, error) {
 	if err != nil {
 		return nil, err
 	}
-	err = db.Migrate(sqlxDB)
+	if cfg.AutoMigrate {
+		err = db.Migrate(sqlxDB)
+		if err != nil {
+			return nil, err
+		}
+	}
+	replica, err := db.ConnectReplica(cfg, sqlxDB)
+	if err != nil {
+		return nil, err
+	}
+	orderRepository := repository.NewOrderRepository(sqlxDB, replica)
+	productServiceClient, err := NewProductClient(cfg)
+	if err != nil {
+		return nil, err
+	}
+	catalogService := service.NewCatalogService(cfg, productServiceClient)
+	promotionRepository := repository.NewPromotionRepository(sqlxDB, replica)
+	promotionService := service.NewPromotionService(promotionRepository, catalogService)
+	taxRepository := repository.NewTaxRepository(sqlxDB)
+	taxCalculator := service.NewTaxCalculator(taxRepository, catalogService)
+	userServiceClient, err := NewUserClient(cfg)
 	if err != nil {
 		return nil, err
 	}
-	orderRepository := repository.NewOrderRepository(sqlxDB)
-	orderService := service.NewOrderService(orderRepository)
+	customerService := service.NewCustomerService(cfg, userServiceClient)
+	shippingRepository := repository.NewShippingRepository(sqlxDB)
+	shippingRateProvider := service.NewShippingRateProvider(shippingRepository)
+	orderService := service.NewOrderService(orderRepository, catalogService, customerService, promotionService, taxCalculator, shippingRateProvider)
 	orderHandler := handler.NewOrderHandler(orderService)
-	server := http.NewServer(orderHandler)
+	promotionHandler := handler.NewPromotionHandler(promotionService)
+	taxService := service.NewTaxService(taxRepository)
+	taxHandler := handler.NewTaxHandler(taxService)
+	shippingService := service.NewShippingService(shippingRepository)
+	shippingHandler := handler.NewShippingHandler(shippingService)
+	shipmentRepository := repository.NewShipmentRepository(sqlxDB, replica)
+	shipmentService := service.NewShipmentService(shipmentRepository)
+	shipmentHandler := handler.NewShipmentHandler(shipmentService)
+	stats := events.NewStats()
+	metricsHandler := handler.NewMetricsHandler(stats)
+	checker := NewHealthChecker(sqlxDB, replica)
+	healthHandler := handler.NewHealthHandler(checker)
+	relay := NewRelay(cfg, sqlxDB)
+	subscriber := NewSubscriber(cfg, stats)
+	paymentEventConsumer := service.NewPaymentEventConsumer(orderRepository, subscriber, stats)
+	rpcServer := rpc.NewServer(cfg, orderService)
+	server := http.NewServer(cfg, sqlxDB, replica, orderHandler, promotionHandler, taxHandler, shippingHandler, shipmentHandler, metricsHandler, healthHandler, relay, paymentEventConsumer, rpcServer)
 	return server, nil
 }
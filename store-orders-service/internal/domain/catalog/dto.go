@@ -0,0 +1,42 @@
+package catalog
+
+import (
+	"order-service/pkg/apperror"
+	"order-service/pkg/money"
+)
+
+var (
+	ErrorNotFound        = apperror.BadRequest("catalog_entry_not_found", "catalog entry not found")
+	ErrorRateNotFound    = apperror.BadRequest("currency_not_supported", "currency is not supported")
+	ErrorProductNotFound = apperror.BadRequest("product_not_found", "product not found")
+	ErrorUnavailable     = apperror.Unavailable("product_service_unavailable", "product service unavailable")
+	ErrorOutOfStock      = apperror.Conflict("out_of_stock", "product is out of stock")
+)
+
+// Rate is the exchange rate as served by the product service.
+type Rate struct {
+	BaseCurrency  string `json:"base_currency"`
+	QuoteCurrency string `json:"quote_currency"`
+	Rate          string `json:"rate"`
+}
+
+// Product is the part of a product served by the product service that
+// orders need to price a cart.
+type Product struct {
+	ID       string      `json:"id"`
+	Title    string      `json:"title"`
+	Price    money.Money `json:"price"`
+	Category string      `json:"category"`
+	Weight   int         `json:"weight"`
+	Quantity int         `json:"quantity"`
+}
+
+// Item is a cart line priced with the catalog, a product ordered several
+// times is one item with a bigger quantity.
+type Item struct {
+	ProductID string
+	Category  string
+	UnitPrice money.Money
+	Weight    int
+	Quantity  int
+}
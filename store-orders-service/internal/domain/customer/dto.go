@@ -0,0 +1,47 @@
+package customer
+
+import (
+	"order-service/pkg/apperror"
+	"strings"
+)
+
+var (
+	ErrorAddressNotFound = apperror.BadRequest("address_not_found", "shipping address not found")
+	ErrorUserNotFound    = apperror.BadRequest("user_not_found", "user not found")
+	ErrorUnavailable     = apperror.Unavailable("user_service_unavailable", "user service unavailable")
+)
+
+// User is the account of a customer or a member of staff as served by the
+// user service.
+type User struct {
+	ID    string `json:"id"`
+	Name  string `json:"name"`
+	Email string `json:"email"`
+}
+
+// Address is an entry of a user's address book as served by the user
+// service.
+type Address struct {
+	ID         string `json:"id"`
+	UserID     string `json:"user_id"`
+	Recipient  string `json:"recipient"`
+	Line1      string `json:"line1"`
+	Line2      string `json:"line2"`
+	City       string `json:"city"`
+	Region     string `json:"region"`
+	PostalCode string `json:"postal_code"`
+	Country    string `json:"country"`
+	Phone      string `json:"phone"`
+}
+
+// String formats the address on one line, it is stored with the order so
+// later edits of the address book do not change where an order went.
+func (a Address) String() string {
+	parts := make([]string, 0, 7)
+	for _, part := range []string{a.Recipient, a.Line1, a.Line2, a.City, a.Region, a.PostalCode, a.Country} {
+		if part = strings.TrimSpace(part); part != "" {
+			parts = append(parts, part)
+		}
+	}
+	return strings.Join(parts, ", ")
+}
@@ -2,43 +2,158 @@ package order
 
 import (
 	"errors"
+	"order-service/internal/domain/shipping"
+	"order-service/internal/domain/tax"
+	"order-service/pkg/apperror"
+	"order-service/pkg/money"
+	"order-service/pkg/validate"
+	"strings"
 	"time"
 )
 
 var (
-	ErrorNotFound         = errors.New("payment not found")
-	ErrorInvalidStatus    = errors.New("invalid status")
-	ErrorInvalidPrice     = errors.New("invalid price")
-	ErrorInvalidSearch    = errors.New("invalid search filter")
-	ErrorInvalidUserID    = errors.New("invalid user id")
-	ErrorInvalidProductID = errors.New("invalid product id")
+	ErrorNotFound        = apperror.NotFound("order_not_found", "order not found")
+	ErrorInvalidPrice    = apperror.BadRequest("invalid_price", "invalid price")
+	ErrorInvalidSearch   = apperror.BadRequest("invalid_search", "invalid search filter")
+	ErrorInvalidCurrency = apperror.BadRequest("invalid_currency", "invalid currency")
+	ErrorVersionMismatch = apperror.PreconditionFailed("version_mismatch", "order was changed since it was read")
+	ErrorNotDeleted      = apperror.Conflict("order_not_deleted", "order is not deleted")
+	ErrorEventProcessed  = errors.New("event already processed")
+)
+
+// Statuses the payment events and the fulfillment workflow move an order to.
+const (
+	StatusNew              = "new"
+	StatusPaid             = "paid"
+	StatusPaymentFailed    = "payment_failed"
+	StatusRefunded         = "refunded"
+	StatusPartiallyShipped = "partially_shipped"
+	StatusShipped          = "shipped"
+	StatusDelivered        = "delivered"
 )
 
 type Request struct {
-	UserID    string   `db:"user_id" bson:"user_id"`
-	ProductID []string `db:"product_id" bson:"product_id"`
-	Pricing   float64  `db:"pricing" bson:"pricing"`
-	Status    string   `db:"status" bson:"status"`
+	UserID            string      `db:"user_id" bson:"user_id" validate:"required,uuid"`
+	ProductID         []string    `db:"product_id" bson:"product_id" validate:"required,dive,uuid"`
+	Pricing           money.Money `db:"pricing" bson:"pricing" validate:"money,positive"`
+	Currency          string      `db:"currency" bson:"currency" validate:"omitempty,currency"`
+	PromoCodes        []string    `db:"promo_codes" bson:"promo_codes"`
+	Country           string      `db:"country" bson:"country" validate:"omitempty,country"`
+	Region            string      `db:"region" bson:"region"`
+	ShippingAddressID string      `db:"shipping_address_id" bson:"shipping_address_id" validate:"omitempty,uuid"`
+	ShippingMethod    string      `db:"shipping_method" bson:"shipping_method" validate:"omitempty,shipping_method"`
+	Status            string      `db:"status" bson:"status" validate:"required,oneof=new paid payment_failed refunded in_progress partially_shipped shipped delivered done"`
+}
+
+// Patch is a merge patch of an order, nil fields are left as they are.
+type Patch struct {
+	UserID    *string      `db:"user_id" bson:"user_id"`
+	ProductID *[]string    `db:"product_id" bson:"product_id"`
+	Pricing   *money.Money `db:"pricing" bson:"pricing"`
+	Status    *string      `db:"status" bson:"status"`
 }
 
 type Response struct {
-	ID        string    `json:"id"`
-	UserID    string    `db:"user_id" bson:"user_id"`
-	ProductID []string  `db:"product_id" bson:"product_id"`
-	Pricing   float64   `db:"pricing" bson:"pricing"`
-	Status    string    `db:"status" bson:"status"`
-	CreatedAt time.Time `db:"created_at" bson:"created_at"`
+	ID                string             `json:"id"`
+	UserID            string             `db:"user_id" bson:"user_id"`
+	ProductID         []string           `db:"product_id" bson:"product_id"`
+	Pricing           money.Money        `db:"pricing" bson:"pricing"`
+	BasePricing       money.Money        `db:"base_pricing" bson:"base_pricing"`
+	ExchangeRate      string             `db:"exchange_rate" bson:"exchange_rate"`
+	Discount          money.Money        `db:"discount" bson:"discount"`
+	Discounts         []DiscountResponse `db:"discounts" bson:"discounts"`
+	Country           string             `db:"country" bson:"country"`
+	Region            string             `db:"region" bson:"region"`
+	Tax               money.Money        `db:"tax" bson:"tax"`
+	Taxes             []TaxResponse      `db:"taxes" bson:"taxes"`
+	ShippingAddressID string             `db:"shipping_address_id" bson:"shipping_address_id"`
+	ShippingAddress   string             `db:"shipping_address" bson:"shipping_address"`
+	ShippingMethod    string             `db:"shipping_method" bson:"shipping_method"`
+	Shipping          money.Money        `db:"shipping" bson:"shipping"`
+	Status            string             `db:"status" bson:"status"`
+	CreatedAt         time.Time          `db:"created_at" bson:"created_at"`
+	Version           int                `db:"version" bson:"version"`
+	// DeletedAt is only set for a deleted order, e.g. in the audit log
+	DeletedAt *time.Time `json:"deleted_at,omitempty" bson:"deleted_at"`
+}
+
+type DiscountResponse struct {
+	PromotionID string      `db:"promotion_id" bson:"promotion_id"`
+	Code        string      `db:"code" bson:"code"`
+	Type        string      `db:"type" bson:"type"`
+	Amount      money.Money `db:"amount" bson:"amount"`
+}
+
+type TaxResponse struct {
+	Name      string      `db:"name" bson:"name"`
+	Country   string      `db:"country" bson:"country"`
+	Region    string      `db:"region" bson:"region"`
+	Category  string      `db:"category" bson:"category"`
+	Rate      string      `db:"rate" bson:"rate"`
+	Inclusive bool        `db:"inclusive" bson:"inclusive"`
+	Taxable   money.Money `db:"taxable" bson:"taxable"`
+	Amount    money.Money `db:"amount" bson:"amount"`
+}
+
+// Invoice is the bill of an order in the currency it was charged in:
+// Subtotal - Discount = Net, Net + exclusive taxes + Shipping = Total.
+type Invoice struct {
+	OrderID         string        `db:"order_id" bson:"order_id"`
+	UserID          string        `db:"user_id" bson:"user_id"`
+	IssuedAt        time.Time     `db:"issued_at" bson:"issued_at"`
+	Country         string        `db:"country" bson:"country"`
+	Region          string        `db:"region" bson:"region"`
+	ExchangeRate    string        `db:"exchange_rate" bson:"exchange_rate"`
+	Subtotal        money.Money   `db:"subtotal" bson:"subtotal"`
+	Discount        money.Money   `db:"discount" bson:"discount"`
+	Net             money.Money   `db:"net" bson:"net"`
+	Tax             money.Money   `db:"tax" bson:"tax"`
+	Taxes           []TaxResponse `db:"taxes" bson:"taxes"`
+	ShippingAddress string        `db:"shipping_address" bson:"shipping_address"`
+	ShippingMethod  string        `db:"shipping_method" bson:"shipping_method"`
+	Shipping        money.Money   `db:"shipping" bson:"shipping"`
+	Total           money.Money   `db:"total" bson:"total"`
 }
 
 func ParseFromEntity(entity Entity) Response {
-	return Response{
-		ID:        entity.ID,
-		UserID:    entity.UserID,
-		ProductID: entity.ProductID,
-		Pricing:   entity.Pricing,
-		Status:    entity.Status,
-		CreatedAt: entity.CreatedAt,
+	res := Response{
+		ID:                entity.ID,
+		UserID:            entity.UserID,
+		ProductID:         entity.ProductID,
+		Pricing:           money.New(entity.Pricing, entity.Currency),
+		BasePricing:       money.New(entity.BasePricing, entity.BaseCurrency),
+		ExchangeRate:      entity.ExchangeRate,
+		Discount:          money.New(entity.DiscountTotal, entity.BaseCurrency),
+		Discounts:         parseDiscounts(entity.Discounts),
+		Country:           entity.Country,
+		Region:            entity.Region,
+		Tax:               money.New(entity.TaxTotal, entity.Currency),
+		Taxes:             parseTaxes(entity.Taxes),
+		ShippingAddressID: entity.ShippingAddressID.String,
+		ShippingAddress:   entity.ShippingAddress,
+		ShippingMethod:    entity.ShippingMethod,
+		Shipping:          money.New(entity.ShippingCost, entity.Currency),
+		Status:            entity.Status,
+		CreatedAt:         entity.CreatedAt,
+		Version:           entity.Version,
 	}
+	if entity.DeletedAt.Valid {
+		res.DeletedAt = &entity.DeletedAt.Time
+	}
+	return res
+}
+
+func parseDiscounts(data []Discount) (res []DiscountResponse) {
+	res = make([]DiscountResponse, 0, len(data))
+	for _, discount := range data {
+		res = append(res, DiscountResponse{
+			PromotionID: discount.PromotionID.String,
+			Code:        discount.Code,
+			Type:        discount.Type,
+			Amount:      money.New(discount.Amount, discount.Currency),
+		})
+	}
+	return
 }
 
 func ParseFromEntities(data []Entity) (res []Response) {
@@ -49,27 +164,90 @@ func ParseFromEntities(data []Entity) (res []Response) {
 	return
 }
 
-func (r *Request) Validate() error {
-	if r.UserID == "" {
-		return ErrorInvalidUserID
+func parseTaxes(data []Tax) (res []TaxResponse) {
+	res = make([]TaxResponse, 0, len(data))
+	for _, line := range data {
+		res = append(res, TaxResponse{
+			Name:      line.Name,
+			Country:   line.Country,
+			Region:    line.Region,
+			Category:  line.Category,
+			Rate:      line.Rate,
+			Inclusive: line.Inclusive,
+			Taxable:   money.New(line.Taxable, line.Currency),
+			Amount:    money.New(line.Amount, line.Currency),
+		})
 	}
-	if len(r.ProductID) == 0 {
-		return ErrorInvalidProductID
+	return
+}
 
+// ParseInvoice builds the invoice of an order. The subtotal is converted
+// with the rate locked at checkout and the discount is whatever separates it
+// from the taxed amount, so the invoice always adds up to the charged total.
+func ParseInvoice(entity Entity) (res Invoice, err error) {
+	subtotal, err := money.Convert(money.New(entity.BasePricing, entity.BaseCurrency), entity.Currency, entity.ExchangeRate)
+	if err != nil {
+		return
 	}
-	if r.Pricing <= 0 {
-		return ErrorInvalidPrice
+	var exclusive int64
+	for _, line := range entity.Taxes {
+		if !line.Inclusive {
+			exclusive += line.Amount
+		}
 	}
-	if !isValidStatus(r.Status) {
-		return ErrorInvalidStatus
+	net := money.New(entity.Pricing-exclusive-entity.ShippingCost, entity.Currency)
+
+	res = Invoice{
+		OrderID:         entity.ID,
+		UserID:          entity.UserID,
+		IssuedAt:        entity.CreatedAt,
+		Country:         entity.Country,
+		Region:          entity.Region,
+		ExchangeRate:    entity.ExchangeRate,
+		Subtotal:        subtotal,
+		Discount:        money.New(max(subtotal.Amount-net.Amount, 0), entity.Currency),
+		Net:             net,
+		Tax:             money.New(entity.TaxTotal, entity.Currency),
+		Taxes:           parseTaxes(entity.Taxes),
+		ShippingAddress: entity.ShippingAddress,
+		ShippingMethod:  entity.ShippingMethod,
+		Shipping:        money.New(entity.ShippingCost, entity.Currency),
+		Total:           money.New(entity.Pricing, entity.Currency),
 	}
-	return nil
+	return
 }
 
-func IsValidFilter(filter string) bool {
-	return filter == "user_id" || filter == "status"
+func (r *Request) Validate() error {
+	r.Currency = strings.ToUpper(r.Currency)
+	r.Country = tax.NormalizeCountry(r.Country)
+	r.ShippingMethod = shipping.NormalizeMethod(r.ShippingMethod)
+	return validate.Struct(r)
 }
 
-func isValidStatus(status string) bool {
-	return status == "new" || status == "in_progress" || status == "done"
+// Apply returns the request that sets entity to what the patch asks for. The
+// pricing is the base total, so an unpatched one is priced again unchanged.
+func (p Patch) Apply(entity Entity) Request {
+	req := Request{
+		UserID:    entity.UserID,
+		ProductID: entity.ProductID,
+		Pricing:   money.New(entity.BasePricing, entity.BaseCurrency),
+		Status:    entity.Status,
+	}
+	if p.UserID != nil {
+		req.UserID = *p.UserID
+	}
+	if p.ProductID != nil {
+		req.ProductID = *p.ProductID
+	}
+	if p.Pricing != nil {
+		req.Pricing = *p.Pricing
+	}
+	if p.Status != nil {
+		req.Status = *p.Status
+	}
+	return req
+}
+
+func IsValidFilter(filter string) bool {
+	return filter == "user_id" || filter == "status"
 }
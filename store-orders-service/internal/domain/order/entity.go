@@ -1,15 +1,61 @@
 package order
 
 import (
+	"database/sql"
 	"github.com/lib/pq"
 	"time"
 )
 
 type Entity struct {
-	ID        string         `db:"id" bson:"_id"`
-	UserID    string         `db:"user_id" bson:"user_id"`
-	ProductID pq.StringArray `db:"product_id" bson:"product_id"`
-	Pricing   float64        `db:"pricing" bson:"pricing"`
-	Status    string         `db:"status" bson:"status"`
-	CreatedAt time.Time      `db:"created_at" bson:"created_at"`
+	ID                string         `db:"id" bson:"_id"`
+	UserID            string         `db:"user_id" bson:"user_id"`
+	ProductID         pq.StringArray `db:"product_id" bson:"product_id"`
+	Pricing           int64          `db:"pricing" bson:"pricing"`
+	Currency          string         `db:"currency" bson:"currency"`
+	BasePricing       int64          `db:"base_pricing" bson:"base_pricing"`
+	BaseCurrency      string         `db:"base_currency" bson:"base_currency"`
+	ExchangeRate      string         `db:"exchange_rate" bson:"exchange_rate"`
+	DiscountTotal     int64          `db:"discount_total" bson:"discount_total"`
+	Country           string         `db:"country" bson:"country"`
+	Region            string         `db:"region" bson:"region"`
+	TaxTotal          int64          `db:"tax_total" bson:"tax_total"`
+	ShippingAddressID sql.NullString `db:"shipping_address_id" bson:"shipping_address_id"`
+	ShippingAddress   string         `db:"shipping_address" bson:"shipping_address"`
+	ShippingMethod    string         `db:"shipping_method" bson:"shipping_method"`
+	ShippingCost      int64          `db:"shipping_cost" bson:"shipping_cost"`
+	Status            string         `db:"status" bson:"status"`
+	CreatedAt         time.Time      `db:"created_at" bson:"created_at"`
+	Version           int            `db:"version" bson:"version"`
+	DeletedAt         sql.NullTime   `db:"deleted_at" bson:"deleted_at"`
+	Discounts         []Discount     `db:"-" bson:"discounts"`
+	Taxes             []Tax          `db:"-" bson:"taxes"`
+}
+
+// Discount is a promotion applied to an order, Amount is in the order base
+// currency.
+type Discount struct {
+	ID          string         `db:"id" bson:"_id"`
+	OrderID     string         `db:"order_id" bson:"order_id"`
+	PromotionID sql.NullString `db:"promotion_id" bson:"promotion_id"`
+	Code        string         `db:"code" bson:"code"`
+	Type        string         `db:"type" bson:"type"`
+	Amount      int64          `db:"amount" bson:"amount"`
+	Currency    string         `db:"currency" bson:"currency"`
+}
+
+// Tax is a tax line of an order, Amount and Taxable are in the order
+// currency. Inclusive taxes are part of Pricing already, exclusive ones were
+// added on top of it.
+type Tax struct {
+	ID        string `db:"id" bson:"_id"`
+	OrderID   string `db:"order_id" bson:"order_id"`
+	Name      string `db:"name" bson:"name"`
+	Country   string `db:"country" bson:"country"`
+	Region    string `db:"region" bson:"region"`
+	Category  string `db:"category" bson:"category"`
+	Rate      string `db:"rate" bson:"rate"`
+	Inclusive bool   `db:"inclusive" bson:"inclusive"`
+	Taxable   int64  `db:"taxable" bson:"taxable"`
+	Amount    int64  `db:"amount" bson:"amount"`
+	Currency  string `db:"currency" bson:"currency"`
 }
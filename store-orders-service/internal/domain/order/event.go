@@ -0,0 +1,32 @@
+package order
+
+// Events written to the outbox when orders change.
+const (
+	EventCreated       = "OrderCreated"
+	EventUpdated       = "OrderUpdated"
+	EventStatusChanged = "OrderStatusChanged"
+	EventDeleted       = "OrderDeleted"
+	EventRestored      = "OrderRestored"
+)
+
+// AuditEntity names orders in the audit log.
+const AuditEntity = "order"
+
+// AuditStatus is what the audit log keeps of an order whose status alone
+// was moved by an event or a shipment.
+type AuditStatus struct {
+	Status string `json:"status"`
+}
+
+// StatusChanged is the payload of OrderStatusChanged.
+type StatusChanged struct {
+	OrderID   string `json:"order_id"`
+	UserID    string `json:"user_id"`
+	OldStatus string `json:"old_status"`
+	NewStatus string `json:"new_status"`
+}
+
+// Deleted is the payload of OrderDeleted.
+type Deleted struct {
+	OrderID string `json:"order_id"`
+}
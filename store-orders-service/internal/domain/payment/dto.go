@@ -0,0 +1,24 @@
+package payment
+
+import (
+	"order-service/pkg/money"
+	"time"
+)
+
+// Events of the payment service the order service consumes.
+const (
+	EventCaptured = "PaymentCaptured"
+	EventFailed   = "PaymentFailed"
+	EventRefunded = "PaymentRefunded"
+)
+
+// Payment is the payload of the payment events as sent by the payment
+// service.
+type Payment struct {
+	ID        string      `json:"id"`
+	UserID    string      `json:"user_id"`
+	OrderID   string      `json:"order_id"`
+	Amount    money.Money `json:"amount"`
+	Status    string      `json:"status"`
+	CreatedAt time.Time   `json:"created_at"`
+}
@@ -0,0 +1,145 @@
+package promotion
+
+import (
+	"order-service/pkg/apperror"
+	"order-service/pkg/money"
+	"order-service/pkg/validate"
+	"regexp"
+	"strings"
+	"time"
+)
+
+const (
+	TypePercentage   = "percentage"
+	TypeFixedAmount  = "fixed_amount"
+	TypeBuyXGetY     = "buy_x_get_y"
+	TypeFreeShipping = "free_shipping"
+)
+
+var (
+	ErrorNotFound      = apperror.NotFound("promotion_not_found", "promotion not found")
+	ErrorDuplicateCode = apperror.Conflict("promotion_code_taken", "promotion code already exists")
+	ErrorUnknownCode   = apperror.BadRequest("unknown_promotion_code", "unknown promotion code")
+	ErrorInactive      = apperror.BadRequest("promotion_inactive", "promotion is not active")
+	ErrorLimitReached  = apperror.BadRequest("promotion_limit_reached", "promotion usage limit reached")
+	ErrorNotApplicable = apperror.BadRequest("promotion_not_applicable", "promotion does not apply to this order")
+)
+
+var codePattern = regexp.MustCompile(`^[A-Z0-9_-]{3,32}$`)
+
+func init() {
+	validate.Pattern("promo_code", "must be 3 to 32 letters, digits, _ or -", codePattern)
+}
+
+type Request struct {
+	Code         string      `json:"code" validate:"omitempty,promo_code"`
+	Name         string      `json:"name" validate:"required,max=100"`
+	Type         string      `json:"type" validate:"required,oneof=percentage fixed_amount buy_x_get_y free_shipping"`
+	PercentOff   int         `json:"percent_off" validate:"required_if=Type percentage,gte=0,lte=100"`
+	AmountOff    money.Money `json:"amount_off" validate:"required_if=Type fixed_amount,omitempty,money"`
+	Category     string      `json:"category" validate:"max=100"`
+	BuyQuantity  int         `json:"buy_quantity" validate:"required_if=Type buy_x_get_y,gte=0"`
+	GetQuantity  int         `json:"get_quantity" validate:"required_if=Type buy_x_get_y,gte=0"`
+	UsageLimit   int         `json:"usage_limit" validate:"gte=0"`
+	PerUserLimit int         `json:"per_user_limit" validate:"gte=0"`
+	StartsAt     *time.Time  `json:"starts_at"`
+	EndsAt       *time.Time  `json:"ends_at"`
+	Active       *bool       `json:"active"`
+}
+
+type Response struct {
+	ID           string      `json:"id"`
+	Code         string      `json:"code" validate:"omitempty,promo_code"`
+	Name         string      `json:"name" validate:"required,max=100"`
+	Type         string      `json:"type" validate:"required,oneof=percentage fixed_amount buy_x_get_y free_shipping"`
+	PercentOff   int         `json:"percent_off" validate:"required_if=Type percentage,gte=0,lte=100"`
+	AmountOff    money.Money `json:"amount_off" validate:"required_if=Type fixed_amount,omitempty,money"`
+	Category     string      `json:"category" validate:"max=100"`
+	BuyQuantity  int         `json:"buy_quantity" validate:"required_if=Type buy_x_get_y,gte=0"`
+	GetQuantity  int         `json:"get_quantity" validate:"required_if=Type buy_x_get_y,gte=0"`
+	UsageLimit   int         `json:"usage_limit" validate:"gte=0"`
+	PerUserLimit int         `json:"per_user_limit" validate:"gte=0"`
+	StartsAt     time.Time   `json:"starts_at"`
+	EndsAt       *time.Time  `json:"ends_at"`
+	Active       bool        `json:"active"`
+	CreatedAt    time.Time   `json:"created_at"`
+}
+
+// Cart is what ApplyPromotions prices: the ordered product IDs, where a
+// repeated ID is one more unit, and the subtotal before discounts.
+type Cart struct {
+	UserID     string
+	ProductIDs []string
+	Subtotal   money.Money
+	Codes      []string
+}
+
+// Applied is a discount granted by a promotion. Amount is in the cart
+// currency and is zero for free shipping.
+type Applied struct {
+	PromotionID string
+	Code        string
+	Type        string
+	Amount      money.Money
+}
+
+func ParseFromEntity(entity Entity) Response {
+	return Response{
+		ID:           entity.ID,
+		Code:         entity.Code,
+		Name:         entity.Name,
+		Type:         entity.Type,
+		PercentOff:   entity.PercentOff,
+		AmountOff:    money.New(entity.AmountOff, entity.Currency),
+		Category:     entity.Category,
+		BuyQuantity:  entity.BuyQuantity,
+		GetQuantity:  entity.GetQuantity,
+		UsageLimit:   entity.UsageLimit,
+		PerUserLimit: entity.PerUserLimit,
+		StartsAt:     entity.StartsAt,
+		EndsAt:       entity.EndsAt,
+		Active:       entity.Active,
+		CreatedAt:    entity.CreatedAt,
+	}
+}
+
+func ParseFromEntities(data []Entity) (res []Response) {
+	res = make([]Response, 0)
+	for _, entity := range data {
+		res = append(res, ParseFromEntity(entity))
+	}
+	return
+}
+
+func (r *Request) Validate() error {
+	r.Code = NormalizeCode(r.Code)
+	violations := validate.Check(r)
+	if r.Type == TypeFixedAmount && r.AmountOff.Currency != "" && !r.AmountOff.IsPositive() {
+		violations = append(violations, validate.Violation{
+			Field:   "amount_off",
+			Rule:    "positive",
+			Message: "must be more than zero",
+		})
+	}
+	if r.StartsAt != nil && r.EndsAt != nil && !r.EndsAt.After(*r.StartsAt) {
+		violations = append(violations, validate.Violation{
+			Field:   "ends_at",
+			Rule:    "gtfield",
+			Message: "must be after starts_at",
+		})
+	}
+	return validate.Error(violations)
+}
+
+func NormalizeCode(code string) string {
+	return strings.ToUpper(strings.TrimSpace(code))
+}
+
+// IsActive reports whether the promotion is switched on and inside its
+// validity window at the given moment.
+func (e Entity) IsActive(at time.Time) bool {
+	if !e.Active || at.Before(e.StartsAt) {
+		return false
+	}
+	return e.EndsAt == nil || at.Before(*e.EndsAt)
+}
@@ -0,0 +1,22 @@
+package promotion
+
+import "time"
+
+type Entity struct {
+	ID           string     `db:"id" bson:"_id"`
+	Code         string     `db:"code" bson:"code"`
+	Name         string     `db:"name" bson:"name"`
+	Type         string     `db:"type" bson:"type"`
+	PercentOff   int        `db:"percent_off" bson:"percent_off"`
+	AmountOff    int64      `db:"amount_off" bson:"amount_off"`
+	Currency     string     `db:"currency" bson:"currency"`
+	Category     string     `db:"category" bson:"category"`
+	BuyQuantity  int        `db:"buy_quantity" bson:"buy_quantity"`
+	GetQuantity  int        `db:"get_quantity" bson:"get_quantity"`
+	UsageLimit   int        `db:"usage_limit" bson:"usage_limit"`
+	PerUserLimit int        `db:"per_user_limit" bson:"per_user_limit"`
+	StartsAt     time.Time  `db:"starts_at" bson:"starts_at"`
+	EndsAt       *time.Time `db:"ends_at" bson:"ends_at"`
+	Active       bool       `db:"active" bson:"active"`
+	CreatedAt    time.Time  `db:"created_at" bson:"created_at"`
+}
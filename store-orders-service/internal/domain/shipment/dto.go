@@ -0,0 +1,113 @@
+package shipment
+
+import (
+	"order-service/pkg/apperror"
+	"order-service/pkg/validate"
+	"time"
+)
+
+const (
+	StatusPending   = "pending"
+	StatusPacked    = "packed"
+	StatusShipped   = "shipped"
+	StatusDelivered = "delivered"
+)
+
+var (
+	ErrorNotFound          = apperror.NotFound("shipment_not_found", "shipment not found")
+	ErrorInvalidItem       = apperror.BadRequest("invalid_item", "invalid shipment item")
+	ErrorInvalidTransition = apperror.Conflict("invalid_transition", "shipment status cannot go back")
+	ErrorNothingToShip     = apperror.BadRequest("nothing_to_ship", "all items of the order are already shipped")
+	ErrorTooManyItems      = apperror.BadRequest("too_many_items", "shipment holds more units than are left to ship")
+)
+
+var statusRank = map[string]int{
+	StatusPending:   0,
+	StatusPacked:    1,
+	StatusShipped:   2,
+	StatusDelivered: 3,
+}
+
+type ItemRequest struct {
+	ProductID string `json:"product_id" validate:"required,uuid"`
+	Quantity  int    `json:"quantity" validate:"gt=0"`
+}
+
+// Request creates a shipment. Without items the shipment takes every unit of
+// the order that is not in another shipment yet.
+type Request struct {
+	Carrier        string        `json:"carrier" validate:"required,max=100"`
+	TrackingNumber string        `json:"tracking_number" validate:"max=100"`
+	Items          []ItemRequest `json:"items" validate:"unique=ProductID,dive"`
+}
+
+// UpdateRequest changes the carrier details or moves the shipment forward.
+type UpdateRequest struct {
+	Carrier        string `json:"carrier" validate:"max=100"`
+	TrackingNumber string `json:"tracking_number" validate:"max=100"`
+	Status         string `json:"status" example:"shipped" validate:"omitempty,oneof=pending packed shipped delivered"`
+}
+
+type ItemResponse struct {
+	ProductID string `json:"product_id"`
+	Quantity  int    `json:"quantity"`
+}
+
+type Response struct {
+	ID             string         `json:"id"`
+	OrderID        string         `json:"order_id"`
+	Carrier        string         `json:"carrier"`
+	TrackingNumber string         `json:"tracking_number"`
+	Status         string         `json:"status"`
+	Items          []ItemResponse `json:"items"`
+	PackedAt       *time.Time     `json:"packed_at"`
+	ShippedAt      *time.Time     `json:"shipped_at"`
+	DeliveredAt    *time.Time     `json:"delivered_at"`
+	CreatedAt      time.Time      `json:"created_at"`
+}
+
+func ParseFromEntity(entity Entity) Response {
+	items := make([]ItemResponse, 0, len(entity.Items))
+	for _, item := range entity.Items {
+		items = append(items, ItemResponse{ProductID: item.ProductID, Quantity: item.Quantity})
+	}
+	return Response{
+		ID:             entity.ID,
+		OrderID:        entity.OrderID,
+		Carrier:        entity.Carrier,
+		TrackingNumber: entity.TrackingNumber,
+		Status:         entity.Status,
+		Items:          items,
+		PackedAt:       entity.PackedAt,
+		ShippedAt:      entity.ShippedAt,
+		DeliveredAt:    entity.DeliveredAt,
+		CreatedAt:      entity.CreatedAt,
+	}
+}
+
+func ParseFromEntities(data []Entity) (res []Response) {
+	res = make([]Response, 0)
+	for _, entity := range data {
+		res = append(res, ParseFromEntity(entity))
+	}
+	return
+}
+
+func (r *Request) Validate() error {
+	return validate.Struct(r)
+}
+
+func (r *UpdateRequest) Validate() error {
+	return validate.Struct(r)
+}
+
+// CanTransition reports whether a shipment may move from one status to
+// another. Steps may be skipped, but a shipment never goes back.
+func CanTransition(from, to string) bool {
+	return statusRank[to] >= statusRank[from]
+}
+
+// Reached reports whether status is at or past step.
+func Reached(status, step string) bool {
+	return statusRank[status] >= statusRank[step]
+}
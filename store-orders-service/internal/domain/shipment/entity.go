@@ -0,0 +1,23 @@
+package shipment
+
+import "time"
+
+type Entity struct {
+	ID             string     `db:"id" bson:"_id"`
+	OrderID        string     `db:"order_id" bson:"order_id"`
+	Carrier        string     `db:"carrier" bson:"carrier"`
+	TrackingNumber string     `db:"tracking_number" bson:"tracking_number"`
+	Status         string     `db:"status" bson:"status"`
+	PackedAt       *time.Time `db:"packed_at" bson:"packed_at"`
+	ShippedAt      *time.Time `db:"shipped_at" bson:"shipped_at"`
+	DeliveredAt    *time.Time `db:"delivered_at" bson:"delivered_at"`
+	CreatedAt      time.Time  `db:"created_at" bson:"created_at"`
+	Items          []Item     `db:"-" bson:"items"`
+}
+
+// Item is the number of units of an ordered product packed in a shipment.
+type Item struct {
+	ShipmentID string `db:"shipment_id" bson:"shipment_id"`
+	ProductID  string `db:"product_id" bson:"product_id"`
+	Quantity   int    `db:"quantity" bson:"quantity"`
+}
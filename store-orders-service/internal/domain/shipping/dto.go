@@ -0,0 +1,118 @@
+package shipping
+
+import (
+	"order-service/pkg/apperror"
+	"order-service/pkg/money"
+	"order-service/pkg/validate"
+	"regexp"
+	"strings"
+	"time"
+)
+
+var (
+	ErrorNotFound           = apperror.NotFound("shipping_rate_not_found", "shipping rate not found")
+	ErrorMissingDestination = apperror.BadRequest("missing_destination", "shipping address is required")
+	ErrorNotAvailable       = apperror.BadRequest("shipping_not_available", "shipping method is not available for this address")
+)
+
+var methodPattern = regexp.MustCompile(`^[a-z0-9_]{2,32}$`)
+
+func init() {
+	validate.Pattern("shipping_method", "must be 2 to 32 lowercase letters, digits or _", methodPattern)
+}
+
+type Request struct {
+	Method        string      `json:"method" example:"standard" validate:"required,shipping_method"`
+	Country       string      `json:"country" example:"KZ" validate:"required,country"`
+	Region        string      `json:"region" validate:"max=100"`
+	MinWeight     int         `json:"min_weight" validate:"gte=0"`
+	MaxWeight     int         `json:"max_weight" validate:"gte=0"`
+	Price         money.Money `json:"price" validate:"money"`
+	PerKg         money.Money `json:"per_kg" validate:"money"`
+	EstimatedDays int         `json:"estimated_days" validate:"gte=0"`
+}
+
+type Response struct {
+	ID            string      `json:"id"`
+	Method        string      `json:"method"`
+	Country       string      `json:"country"`
+	Region        string      `json:"region"`
+	MinWeight     int         `json:"min_weight"`
+	MaxWeight     int         `json:"max_weight"`
+	Price         money.Money `json:"price"`
+	PerKg         money.Money `json:"per_kg"`
+	EstimatedDays int         `json:"estimated_days"`
+	CreatedAt     time.Time   `json:"created_at"`
+}
+
+// QuoteRequest describes a parcel, Weight is in grams.
+type QuoteRequest struct {
+	Method  string
+	Country string
+	Region  string
+	Weight  int
+}
+
+// Quote is the delivery cost in the currency of the matching rate.
+type Quote struct {
+	Method        string
+	Cost          money.Money
+	EstimatedDays int
+}
+
+func ParseFromEntity(entity Entity) Response {
+	return Response{
+		ID:            entity.ID,
+		Method:        entity.Method,
+		Country:       entity.Country,
+		Region:        entity.Region,
+		MinWeight:     entity.MinWeight,
+		MaxWeight:     entity.MaxWeight,
+		Price:         money.New(entity.Price, entity.Currency),
+		PerKg:         money.New(entity.PerKg, entity.Currency),
+		EstimatedDays: entity.EstimatedDays,
+		CreatedAt:     entity.CreatedAt,
+	}
+}
+
+func ParseFromEntities(data []Entity) (res []Response) {
+	res = make([]Response, 0)
+	for _, entity := range data {
+		res = append(res, ParseFromEntity(entity))
+	}
+	return
+}
+
+func (r *Request) Validate() error {
+	r.Method = NormalizeMethod(r.Method)
+	r.Country = strings.ToUpper(strings.TrimSpace(r.Country))
+	r.Region = strings.ToUpper(strings.TrimSpace(r.Region))
+	if r.PerKg.IsZero() {
+		r.PerKg.Currency = r.Price.Currency
+	}
+
+	violations := validate.Check(r)
+	if r.MaxWeight > 0 && r.MaxWeight <= r.MinWeight {
+		violations = append(violations, validate.Violation{
+			Field:   "max_weight",
+			Rule:    "gtfield",
+			Message: "must be more than min_weight",
+		})
+	}
+	if r.PerKg.Currency != r.Price.Currency {
+		violations = append(violations, validate.Violation{
+			Field:   "per_kg.currency",
+			Rule:    "eqfield",
+			Message: "must be the currency of price",
+		})
+	}
+	return validate.Error(violations)
+}
+
+func NormalizeMethod(method string) string {
+	return strings.ToLower(strings.TrimSpace(method))
+}
+
+func IsValidMethod(method string) bool {
+	return methodPattern.MatchString(method)
+}
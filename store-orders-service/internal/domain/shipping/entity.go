@@ -0,0 +1,20 @@
+package shipping
+
+import "time"
+
+// Entity is a row of the shipping rate table. A rate covers one method in a
+// zone, the country and optionally a region of it, for parcels weighing at
+// least MinWeight and below MaxWeight grams (0 means no upper bound).
+type Entity struct {
+	ID            string    `db:"id" bson:"_id"`
+	Method        string    `db:"method" bson:"method"`
+	Country       string    `db:"country" bson:"country"`
+	Region        string    `db:"region" bson:"region"`
+	MinWeight     int       `db:"min_weight" bson:"min_weight"`
+	MaxWeight     int       `db:"max_weight" bson:"max_weight"`
+	Price         int64     `db:"price" bson:"price"`
+	PerKg         int64     `db:"per_kg" bson:"per_kg"`
+	Currency      string    `db:"currency" bson:"currency"`
+	EstimatedDays int       `db:"estimated_days" bson:"estimated_days"`
+	CreatedAt     time.Time `db:"created_at" bson:"created_at"`
+}
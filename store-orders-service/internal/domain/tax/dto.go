@@ -0,0 +1,109 @@
+package tax
+
+import (
+	"math/big"
+	"order-service/pkg/apperror"
+	"order-service/pkg/money"
+	"order-service/pkg/validate"
+	"regexp"
+	"strings"
+	"time"
+)
+
+var (
+	ErrorNotFound    = apperror.NotFound("tax_rate_not_found", "tax rate not found")
+	ErrorInvalidRate = apperror.BadRequest("invalid_rate", "invalid tax rate")
+)
+
+var countryPattern = regexp.MustCompile(`^[A-Z]{2}$`)
+
+type Request struct {
+	Country   string `json:"country" validate:"required,country"`
+	Region    string `json:"region" validate:"max=100"`
+	Category  string `json:"category" validate:"max=100"`
+	Name      string `json:"name" validate:"required,max=100"`
+	Rate      string `json:"rate" example:"0.12" validate:"required"`
+	Inclusive bool   `json:"inclusive"`
+}
+
+type Response struct {
+	ID        string    `json:"id"`
+	Country   string    `json:"country"`
+	Region    string    `json:"region"`
+	Category  string    `json:"category"`
+	Name      string    `json:"name"`
+	Rate      string    `json:"rate"`
+	Inclusive bool      `json:"inclusive"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// Calculation is the input of a TaxCalculator. Amount is the order total
+// after discounts, it is split between product categories in proportion to
+// their catalog prices.
+type Calculation struct {
+	Country    string
+	Region     string
+	ProductIDs []string
+	Amount     money.Money
+}
+
+// Line is the tax charged by one rule on its share of the order.
+type Line struct {
+	Name      string
+	Country   string
+	Region    string
+	Category  string
+	Rate      string
+	Inclusive bool
+	Taxable   money.Money
+	Amount    money.Money
+}
+
+func ParseFromEntity(entity Entity) Response {
+	return Response{
+		ID:        entity.ID,
+		Country:   entity.Country,
+		Region:    entity.Region,
+		Category:  entity.Category,
+		Name:      entity.Name,
+		Rate:      entity.Rate,
+		Inclusive: entity.Inclusive,
+		CreatedAt: entity.CreatedAt,
+	}
+}
+
+func ParseFromEntities(data []Entity) (res []Response) {
+	res = make([]Response, 0)
+	for _, entity := range data {
+		res = append(res, ParseFromEntity(entity))
+	}
+	return
+}
+
+func (r *Request) Validate() error {
+	r.Country = NormalizeCountry(r.Country)
+	r.Region = NormalizeRegion(r.Region)
+	violations := validate.Check(r)
+	rate, ok := new(big.Rat).SetString(r.Rate)
+	if r.Rate != "" && (!ok || rate.Sign() < 0 || rate.Cmp(big.NewRat(1, 1)) > 0) {
+		violations = append(violations, validate.Violation{
+			Field:   "rate",
+			Rule:    "tax_rate",
+			Message: "must be a decimal number from 0 to 1",
+		})
+	}
+	return validate.Error(violations)
+}
+
+func NormalizeCountry(country string) string {
+	return strings.ToUpper(strings.TrimSpace(country))
+}
+
+func NormalizeRegion(region string) string {
+	return strings.ToUpper(strings.TrimSpace(region))
+}
+
+// IsValidCountry reports whether country is an ISO 3166-1 alpha-2 code.
+func IsValidCountry(country string) bool {
+	return countryPattern.MatchString(country)
+}
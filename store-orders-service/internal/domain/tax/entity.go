@@ -0,0 +1,16 @@
+package tax
+
+import "time"
+
+// Entity is a row of the tax table. Empty Region or Category match any
+// region or category of the country.
+type Entity struct {
+	ID        string    `db:"id" bson:"_id"`
+	Country   string    `db:"country" bson:"country"`
+	Region    string    `db:"region" bson:"region"`
+	Category  string    `db:"category" bson:"category"`
+	Name      string    `db:"name" bson:"name"`
+	Rate      string    `db:"rate" bson:"rate"`
+	Inclusive bool      `db:"inclusive" bson:"inclusive"`
+	CreatedAt time.Time `db:"created_at" bson:"created_at"`
+}
@@ -10,6 +10,8 @@ type OrderRepository interface {
 	List(ctx context.Context) (res []order.Entity, err error)
 	Get(ctx context.Context, id string) (res order.Entity, err error)
 	Delete(ctx context.Context, id string) (err error)
-	Update(ctx context.Context, id string, entity order.Entity) (err error)
+	Restore(ctx context.Context, id string) (dest order.Entity, err error)
+	Update(ctx context.Context, id string, version int, entity order.Entity) (dest order.Entity, err error)
 	Search(ctx context.Context, filter, value string) (res []order.Entity, err error)
+	SetStatusOnce(ctx context.Context, consumer, eventID, orderID, status string, from []string) (changed bool, err error)
 }
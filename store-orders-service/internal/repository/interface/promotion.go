@@ -0,0 +1,17 @@
+package interfaces
+
+import (
+	"context"
+	"order-service/internal/domain/promotion"
+	"time"
+)
+
+type PromotionRepository interface {
+	Create(ctx context.Context, entity promotion.Entity) (id string, err error)
+	List(ctx context.Context) (res []promotion.Entity, err error)
+	Get(ctx context.Context, id string) (res promotion.Entity, err error)
+	Update(ctx context.Context, id string, entity promotion.Entity) (err error)
+	Delete(ctx context.Context, id string) (err error)
+	ListCandidates(ctx context.Context, codes []string, at time.Time) (res []promotion.Entity, err error)
+	CountUsages(ctx context.Context, id, userID string) (total, byUser int, err error)
+}
@@ -0,0 +1,13 @@
+package interfaces
+
+import (
+	"context"
+	"order-service/internal/domain/shipment"
+)
+
+type ShipmentRepository interface {
+	Create(ctx context.Context, data shipment.Entity) (id string, err error)
+	List(ctx context.Context, orderID string) (res []shipment.Entity, err error)
+	Get(ctx context.Context, orderID, id string) (dest shipment.Entity, err error)
+	Update(ctx context.Context, orderID, id string, data shipment.Entity) (err error)
+}
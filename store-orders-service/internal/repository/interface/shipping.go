@@ -0,0 +1,12 @@
+package interfaces
+
+import (
+	"context"
+	"order-service/internal/domain/shipping"
+)
+
+type ShippingRepository interface {
+	Upsert(ctx context.Context, entity shipping.Entity) (id string, err error)
+	List(ctx context.Context, method, country string) (res []shipping.Entity, err error)
+	Delete(ctx context.Context, id string) (err error)
+}
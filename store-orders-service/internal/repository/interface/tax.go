@@ -0,0 +1,12 @@
+package interfaces
+
+import (
+	"context"
+	"order-service/internal/domain/tax"
+)
+
+type TaxRepository interface {
+	Upsert(ctx context.Context, entity tax.Entity) (id string, err error)
+	List(ctx context.Context, country string) (res []tax.Entity, err error)
+	Delete(ctx context.Context, id string) (err error)
+}
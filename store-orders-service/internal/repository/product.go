@@ -7,87 +7,350 @@ import (
 	"fmt"
 	"github.com/jmoiron/sqlx"
 	"github.com/lib/pq"
+	"order-service/internal/db"
 	"order-service/internal/domain/order"
+	"order-service/internal/domain/promotion"
 	interfaces "order-service/internal/repository/interface"
+	"order-service/pkg/audit"
+	"order-service/pkg/events"
+	"slices"
 	"strings"
 )
 
 type OrderRepository struct {
-	db *sqlx.DB
+	db      *sqlx.DB
+	replica *sqlx.DB
 }
 
-func NewOrderRepository(db *sqlx.DB) interfaces.OrderRepository {
+func NewOrderRepository(db *sqlx.DB, replica db.Replica) interfaces.OrderRepository {
 	return &OrderRepository{
-		db: db,
+		db:      db,
+		replica: replica.DB,
 	}
 }
 
+// Create stores the order together with its discounts and an OrderCreated
+// event. The promotions are locked and their usage limits checked again in
+// the same transaction, so concurrent checkouts cannot use a coupon more
+// often than allowed.
 func (pr *OrderRepository) Create(ctx context.Context, data order.Entity) (id string, err error) {
+	tx, err := pr.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+		}
+	}()
+
 	query := `
-		INSERT INTO orders (user_id, product_id, pricing, status)
-		VALUES ($1, $2, $3, $4) RETURNING id;`
+		INSERT INTO orders (user_id, product_id, pricing, currency, base_pricing, base_currency, exchange_rate, discount_total,
+			country, region, tax_total, shipping_address_id, shipping_address, shipping_method, shipping_cost, status)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16) RETURNING id, created_at;`
 	args := []any{
 		data.UserID,
 		pq.Array(data.ProductID),
 		data.Pricing,
+		data.Currency,
+		data.BasePricing,
+		data.BaseCurrency,
+		data.ExchangeRate,
+		data.DiscountTotal,
+		data.Country,
+		data.Region,
+		data.TaxTotal,
+		data.ShippingAddressID,
+		data.ShippingAddress,
+		data.ShippingMethod,
+		data.ShippingCost,
 		data.Status,
 	}
-	if err = pr.db.QueryRowContext(ctx, query, args...).Scan(&id); err != nil {
+	if err = tx.QueryRowContext(ctx, query, args...).Scan(&id, &data.CreatedAt); err != nil {
+		err = pr.mapError(err)
+		return
+	}
+	data.ID = id
+
+	for _, discount := range data.Discounts {
+		if discount.PromotionID.Valid {
+			if err = pr.usePromotion(ctx, tx, discount.PromotionID.String, data.UserID, id); err != nil {
+				return
+			}
+		}
+		query = `
+			INSERT INTO order_discounts (order_id, promotion_id, code, type, amount, currency)
+			VALUES ($1, $2, $3, $4, $5, $6);`
+		args = []any{id, discount.PromotionID, discount.Code, discount.Type, discount.Amount, discount.Currency}
+		if _, err = tx.ExecContext(ctx, query, args...); err != nil {
+			return
+		}
+	}
+	if err = pr.insertTaxes(ctx, tx, id, data.Taxes); err != nil {
+		return
+	}
+	if err = events.Store(ctx, tx, order.EventCreated, id, order.ParseFromEntity(data)); err != nil {
+		return
+	}
+	if err = audit.Record(ctx, tx, order.AuditEntity, id, audit.ActionCreate, nil, order.ParseFromEntity(data)); err != nil {
+		return
+	}
+	err = tx.Commit()
+	return
+}
+
+func (pr *OrderRepository) insertTaxes(ctx context.Context, tx *sqlx.Tx, orderID string, taxes []order.Tax) (err error) {
+	query := `
+		INSERT INTO order_taxes (order_id, name, country, region, category, rate, inclusive, taxable, amount, currency)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10);`
+	for _, line := range taxes {
+		args := []any{orderID, line.Name, line.Country, line.Region, line.Category, line.Rate, line.Inclusive, line.Taxable, line.Amount, line.Currency}
+		if _, err = tx.ExecContext(ctx, query, args...); err != nil {
+			return
+		}
+	}
+	return
+}
+
+func (pr *OrderRepository) usePromotion(ctx context.Context, tx *sqlx.Tx, promotionID, userID, orderID string) (err error) {
+	var usageLimit, perUserLimit int
+	query := `SELECT usage_limit, per_user_limit FROM promotions WHERE id = $1 FOR UPDATE;`
+	if err = tx.QueryRowContext(ctx, query, promotionID).Scan(&usageLimit, &perUserLimit); err != nil {
+		// the promotion was deleted since the order was priced
 		if errors.Is(err, sql.ErrNoRows) {
-			err = order.ErrorNotFound
+			err = promotion.ErrorUnknownCode
 		}
+		return
+	}
+
+	var total, byUser int
+	query = `
+		SELECT COUNT(*), COUNT(*) FILTER (WHERE user_id = $2)
+		FROM promotion_usages WHERE promotion_id = $1;`
+	if err = tx.QueryRowContext(ctx, query, promotionID, userID).Scan(&total, &byUser); err != nil {
+		return
+	}
+	if (usageLimit > 0 && total >= usageLimit) || (perUserLimit > 0 && byUser >= perUserLimit) {
+		return promotion.ErrorLimitReached
 	}
+
+	query = `INSERT INTO promotion_usages (promotion_id, user_id, order_id) VALUES ($1, $2, $3);`
+	_, err = tx.ExecContext(ctx, query, promotionID, userID, orderID)
 	return
 }
 
 func (pr *OrderRepository) List(ctx context.Context) (projects []order.Entity, err error) {
-	query := `SELECT * FROM orders ORDER BY id;`
-	err = pr.db.SelectContext(ctx, &projects, query)
+	query := `SELECT * FROM orders WHERE deleted_at IS NULL ORDER BY id;`
+	if err = pr.replica.SelectContext(ctx, &projects, query); err != nil {
+		return
+	}
+	err = pr.loadLines(ctx, pr.replica, pointers(projects))
 	return
 }
 
 func (pr *OrderRepository) Get(ctx context.Context, id string) (dest order.Entity, err error) {
-	query := `SELECT * FROM orders WHERE id = $1;`
+	query := `SELECT * FROM orders WHERE id = $1 AND deleted_at IS NULL;`
 	args := []any{id}
 	err = pr.db.GetContext(ctx, &dest, query, args...)
 	if err != nil {
-		if errors.Is(err, sql.ErrNoRows) {
-			err = order.ErrorNotFound
-		}
+		err = pr.mapError(err)
+		return
 	}
+	err = pr.loadLines(ctx, pr.db, []*order.Entity{&dest})
 	return
 }
 
 func (pr *OrderRepository) Delete(ctx context.Context, id string) (err error) {
-	query := `DELETE FROM orders WHERE id = $1 RETURNING id;`
-	args := []any{id}
-	if err = pr.db.QueryRowContext(ctx, query, args...).Scan(&id); err != nil {
-		if errors.Is(err, sql.ErrNoRows) {
-			err = order.ErrorNotFound
+	tx, err := pr.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
 		}
+	}()
+
+	before, err := pr.lock(ctx, tx, id)
+	if err != nil {
+		return
+	}
+	var dest order.Entity
+	query := `UPDATE orders SET deleted_at = CURRENT_TIMESTAMP, version = version + 1 WHERE id = $1 RETURNING *;`
+	if err = tx.GetContext(ctx, &dest, query, id); err != nil {
+		return
+	}
+	dest.Discounts, dest.Taxes = before.Discounts, before.Taxes
+	if err = events.Store(ctx, tx, order.EventDeleted, id, order.Deleted{OrderID: id}); err != nil {
+		return
+	}
+	if err = audit.Record(ctx, tx, order.AuditEntity, id, audit.ActionDelete, order.ParseFromEntity(before), order.ParseFromEntity(dest)); err != nil {
+		return
+	}
+	err = tx.Commit()
+	return
+}
+
+// Restore brings a deleted order back together with its discounts and tax
+// lines, which are kept while it is deleted.
+func (pr *OrderRepository) Restore(ctx context.Context, id string) (dest order.Entity, err error) {
+	tx, err := pr.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return
+	}
+	defer tx.Rollback()
+
+	var before order.Entity
+	if err = tx.GetContext(ctx, &before, `SELECT * FROM orders WHERE id = $1 FOR UPDATE;`, id); err != nil {
+		err = pr.mapError(err)
+		return
+	}
+	if !before.DeletedAt.Valid {
+		err = order.ErrorNotDeleted
+		return
 	}
+	query := `UPDATE orders SET deleted_at = NULL, version = version + 1 WHERE id = $1 RETURNING *;`
+	if err = tx.GetContext(ctx, &dest, query, id); err != nil {
+		return
+	}
+	if err = pr.loadLines(ctx, tx, []*order.Entity{&dest}); err != nil {
+		return
+	}
+	before.Discounts, before.Taxes = dest.Discounts, dest.Taxes
+	if err = events.Store(ctx, tx, order.EventRestored, id, order.ParseFromEntity(dest)); err != nil {
+		return
+	}
+	if err = audit.Record(ctx, tx, order.AuditEntity, id, audit.ActionRestore, order.ParseFromEntity(before), order.ParseFromEntity(dest)); err != nil {
+		return
+	}
+	err = tx.Commit()
 	return
 }
 
-func (pr *OrderRepository) Update(ctx context.Context, id string, data order.Entity) (err error) {
+// Update changes the given fields of an order. A non-nil Taxes replaces the
+// tax lines and the tax total. A version other than 0 must be the current
+// one, the stored version is bumped either way. OrderUpdated is written for
+// every update and OrderStatusChanged when the status moves.
+func (pr *OrderRepository) Update(ctx context.Context, id string, version int, data order.Entity) (dest order.Entity, err error) {
 	sets, args := pr.prepareArgs(data)
-	if len(args) > 0 {
-		args = append(args, id)
-		query := fmt.Sprintf("UPDATE orders SET %s WHERE id = $%d RETURNING id;", strings.Join(sets, ","), len(args))
-		if err = pr.db.QueryRowContext(ctx, query, args...).Scan(&id); err != nil {
-			if errors.Is(err, sql.ErrNoRows) {
-				err = order.ErrorNotFound
-			}
+	sets = append(sets, "version = version + 1")
+
+	tx, err := pr.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+		}
+	}()
+
+	before, err := pr.lock(ctx, tx, id)
+	if err != nil {
+		return
+	}
+	if version != 0 && version != before.Version {
+		err = order.ErrorVersionMismatch
+		return
+	}
+
+	args = append(args, id)
+	query := fmt.Sprintf("UPDATE orders SET %s WHERE id = $%d RETURNING *;", strings.Join(sets, ","), len(args))
+	if err = tx.GetContext(ctx, &dest, query, args...); err != nil {
+		return
+	}
+	if data.Taxes != nil {
+		if _, err = tx.ExecContext(ctx, `DELETE FROM order_taxes WHERE order_id = $1;`, id); err != nil {
+			return
+		}
+		if err = pr.insertTaxes(ctx, tx, id, data.Taxes); err != nil {
+			return
+		}
+	}
+	if err = pr.loadLines(ctx, tx, []*order.Entity{&dest}); err != nil {
+		return
+	}
+
+	if err = events.Store(ctx, tx, order.EventUpdated, id, order.ParseFromEntity(dest)); err != nil {
+		return
+	}
+	if err = audit.Record(ctx, tx, order.AuditEntity, id, audit.ActionUpdate, order.ParseFromEntity(before), order.ParseFromEntity(dest)); err != nil {
+		return
+	}
+	if dest.Status != before.Status {
+		changed := order.StatusChanged{OrderID: id, UserID: dest.UserID, OldStatus: before.Status, NewStatus: dest.Status}
+		if err = events.Store(ctx, tx, order.EventStatusChanged, id, changed); err != nil {
+			return
+		}
+	}
+	err = tx.Commit()
+	return
+}
+
+// lock reads an order that is not deleted with its discounts and tax lines
+// and locks it until tx ends.
+func (pr *OrderRepository) lock(ctx context.Context, tx *sqlx.Tx, id string) (dest order.Entity, err error) {
+	if err = tx.GetContext(ctx, &dest, `SELECT * FROM orders WHERE id = $1 AND deleted_at IS NULL FOR UPDATE;`, id); err != nil {
+		err = pr.mapError(err)
+		return
+	}
+	err = pr.loadLines(ctx, tx, []*order.Entity{&dest})
+	return
+}
+
+// SetStatusOnce moves the order to status when it is in one of from. The
+// event is recorded as processed by consumer in the same transaction, so a
+// redelivered event fails with order.ErrorEventProcessed and changes nothing.
+// An unknown or deleted order or one in another status is not an error, the
+// event is just recorded.
+func (pr *OrderRepository) SetStatusOnce(ctx context.Context, consumer, eventID, orderID, status string, from []string) (changed bool, err error) {
+	tx, err := pr.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return
+	}
+	defer tx.Rollback()
+
+	query := `INSERT INTO processed_events (consumer, event_id) VALUES ($1, $2) ON CONFLICT DO NOTHING;`
+	result, err := tx.ExecContext(ctx, query, consumer, eventID)
+	if err != nil {
+		return
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		err = order.ErrorEventProcessed
+		return
+	}
+
+	var current, userID string
+	query = `SELECT status, user_id FROM orders WHERE id = $1 AND deleted_at IS NULL FOR UPDATE;`
+	if err = tx.QueryRowContext(ctx, query, orderID).Scan(&current, &userID); err != nil {
+		if !errors.Is(err, sql.ErrNoRows) {
+			return
+		}
+		err = tx.Commit()
+		return
+	}
+	if slices.Contains(from, current) {
+		if _, err = tx.ExecContext(ctx, `UPDATE orders SET status = $1, version = version + 1 WHERE id = $2;`, status, orderID); err != nil {
+			return
+		}
+		statusChanged := order.StatusChanged{OrderID: orderID, UserID: userID, OldStatus: current, NewStatus: status}
+		if err = events.Store(ctx, tx, order.EventStatusChanged, orderID, statusChanged); err != nil {
+			return
 		}
+		if err = audit.Record(ctx, tx, order.AuditEntity, orderID, audit.ActionUpdate, order.AuditStatus{Status: current}, order.AuditStatus{Status: status}); err != nil {
+			return
+		}
+		changed = true
 	}
+	err = tx.Commit()
 	return
 }
 
 func (pr *OrderRepository) Search(ctx context.Context, filter, value string) (dest []order.Entity, err error) {
 	dest = []order.Entity{}
 	filter = pr.prepareFilter(filter)
-	query := fmt.Sprintf("SELECT * FROM orders WHERE %s = $1", filter)
-	err = pr.db.SelectContext(ctx, &dest, query, value)
+	query := fmt.Sprintf("SELECT * FROM orders WHERE %s = $1 AND deleted_at IS NULL", filter)
+	err = pr.replica.SelectContext(ctx, &dest, query, value)
 	if err != nil {
 		return
 	}
@@ -95,9 +358,53 @@ func (pr *OrderRepository) Search(ctx context.Context, filter, value string) (de
 		err = order.ErrorNotFound
 		return
 	}
+	err = pr.loadLines(ctx, pr.replica, pointers(dest))
+	return
+}
+
+// loadLines attaches the discounts and tax lines to the orders, reading them
+// from the database the orders were read from.
+func (pr *OrderRepository) loadLines(ctx context.Context, q sqlx.QueryerContext, orders []*order.Entity) (err error) {
+	if len(orders) == 0 {
+		return
+	}
+	ids := make([]string, len(orders))
+	index := make(map[string]*order.Entity, len(orders))
+	for i, entity := range orders {
+		ids[i] = entity.ID
+		index[entity.ID] = entity
+	}
+
+	var discounts []order.Discount
+	query := `SELECT * FROM order_discounts WHERE order_id = ANY($1);`
+	if err = sqlx.SelectContext(ctx, q, &discounts, query, pq.Array(ids)); err != nil {
+		return
+	}
+	for _, discount := range discounts {
+		entity := index[discount.OrderID]
+		entity.Discounts = append(entity.Discounts, discount)
+	}
+
+	var taxes []order.Tax
+	query = `SELECT * FROM order_taxes WHERE order_id = ANY($1);`
+	if err = sqlx.SelectContext(ctx, q, &taxes, query, pq.Array(ids)); err != nil {
+		return
+	}
+	for _, line := range taxes {
+		entity := index[line.OrderID]
+		entity.Taxes = append(entity.Taxes, line)
+	}
 	return
 }
 
+func pointers(orders []order.Entity) []*order.Entity {
+	res := make([]*order.Entity, len(orders))
+	for i := range orders {
+		res[i] = &orders[i]
+	}
+	return res
+}
+
 func (pr *OrderRepository) prepareArgs(data order.Entity) (sets []string, args []any) {
 	if data.UserID != "" {
 		sets = append(sets, fmt.Sprintf("user_id = $%d", len(args)+1))
@@ -111,6 +418,18 @@ func (pr *OrderRepository) prepareArgs(data order.Entity) (sets []string, args [
 		sets = append(sets, fmt.Sprintf("pricing = $%d", len(args)+1))
 		args = append(args, data.Pricing)
 	}
+	if data.Currency != "" {
+		sets = append(sets, fmt.Sprintf("currency = $%d", len(args)+1))
+		args = append(args, data.Currency)
+	}
+	if data.BasePricing != 0 {
+		sets = append(sets, fmt.Sprintf("base_pricing = $%d", len(args)+1))
+		args = append(args, data.BasePricing)
+	}
+	if data.Taxes != nil {
+		sets = append(sets, fmt.Sprintf("tax_total = $%d", len(args)+1))
+		args = append(args, data.TaxTotal)
+	}
 	if data.Status != "" {
 		sets = append(sets, fmt.Sprintf("status = $%d", len(args)+1))
 		args = append(args, data.Status)
@@ -129,3 +448,15 @@ func (pr *OrderRepository) prepareFilter(arg string) string {
 		return ""
 	}
 }
+
+func (pr *OrderRepository) mapError(err error) error {
+	switch {
+	case errors.Is(err, sql.ErrNoRows), db.IsInvalidText(err):
+		// no such row, or a malformed UUID in the path
+		return order.ErrorNotFound
+	case db.IsForeignKeyViolation(err):
+		// the promotion of a discount was deleted since the order was priced
+		return promotion.ErrorUnknownCode
+	}
+	return err
+}
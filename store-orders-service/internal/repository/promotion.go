@@ -0,0 +1,121 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"order-service/internal/db"
+	"order-service/internal/domain/promotion"
+	interfaces "order-service/internal/repository/interface"
+	"time"
+)
+
+type PromotionRepository struct {
+	db      *sqlx.DB
+	replica *sqlx.DB
+}
+
+func NewPromotionRepository(db *sqlx.DB, replica db.Replica) interfaces.PromotionRepository {
+	return &PromotionRepository{
+		db:      db,
+		replica: replica.DB,
+	}
+}
+
+func (pr *PromotionRepository) Create(ctx context.Context, data promotion.Entity) (id string, err error) {
+	query := `
+		INSERT INTO promotions (code, name, type, percent_off, amount_off, currency, category, buy_quantity, get_quantity, usage_limit, per_user_limit, starts_at, ends_at, active)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14) RETURNING id;`
+	args := pr.prepareArgs(data)
+	if err = pr.db.QueryRowContext(ctx, query, args...).Scan(&id); err != nil {
+		err = pr.mapError(err)
+	}
+	return
+}
+
+func (pr *PromotionRepository) List(ctx context.Context) (dest []promotion.Entity, err error) {
+	query := `SELECT * FROM promotions ORDER BY created_at;`
+	err = pr.replica.SelectContext(ctx, &dest, query)
+	return
+}
+
+func (pr *PromotionRepository) Get(ctx context.Context, id string) (dest promotion.Entity, err error) {
+	query := `SELECT * FROM promotions WHERE id = $1;`
+	if err = pr.db.GetContext(ctx, &dest, query, id); err != nil {
+		err = pr.mapError(err)
+	}
+	return
+}
+
+func (pr *PromotionRepository) Update(ctx context.Context, id string, data promotion.Entity) (err error) {
+	query := `
+		UPDATE promotions SET code = $1, name = $2, type = $3, percent_off = $4, amount_off = $5, currency = $6,
+			category = $7, buy_quantity = $8, get_quantity = $9, usage_limit = $10, per_user_limit = $11,
+			starts_at = $12, ends_at = $13, active = $14
+		WHERE id = $15 RETURNING id;`
+	args := append(pr.prepareArgs(data), id)
+	if err = pr.db.QueryRowContext(ctx, query, args...).Scan(&id); err != nil {
+		err = pr.mapError(err)
+	}
+	return
+}
+
+func (pr *PromotionRepository) Delete(ctx context.Context, id string) (err error) {
+	query := `DELETE FROM promotions WHERE id = $1 RETURNING id;`
+	if err = pr.db.QueryRowContext(ctx, query, id).Scan(&id); err != nil {
+		err = pr.mapError(err)
+	}
+	return
+}
+
+// ListCandidates returns the promotions requested by code, whatever their
+// state, together with the automatic promotions running at the given time.
+func (pr *PromotionRepository) ListCandidates(ctx context.Context, codes []string, at time.Time) (dest []promotion.Entity, err error) {
+	query := `
+		SELECT * FROM promotions
+		WHERE (code <> '' AND code = ANY($1))
+			OR (code = '' AND active AND starts_at <= $2 AND (ends_at IS NULL OR ends_at > $2))
+		ORDER BY created_at;`
+	err = pr.db.SelectContext(ctx, &dest, query, pq.Array(codes), at)
+	return
+}
+
+func (pr *PromotionRepository) CountUsages(ctx context.Context, id, userID string) (total, byUser int, err error) {
+	query := `
+		SELECT COUNT(*), COUNT(*) FILTER (WHERE user_id = $2)
+		FROM promotion_usages WHERE promotion_id = $1;`
+	err = pr.db.QueryRowContext(ctx, query, id, userID).Scan(&total, &byUser)
+	return
+}
+
+func (pr *PromotionRepository) prepareArgs(data promotion.Entity) []any {
+	return []any{
+		data.Code,
+		data.Name,
+		data.Type,
+		data.PercentOff,
+		data.AmountOff,
+		data.Currency,
+		data.Category,
+		data.BuyQuantity,
+		data.GetQuantity,
+		data.UsageLimit,
+		data.PerUserLimit,
+		data.StartsAt,
+		data.EndsAt,
+		data.Active,
+	}
+}
+
+func (pr *PromotionRepository) mapError(err error) error {
+	switch {
+	case errors.Is(err, sql.ErrNoRows), db.IsInvalidText(err):
+		// no such row, or a malformed UUID in the path
+		return promotion.ErrorNotFound
+	case db.IsUniqueViolation(err):
+		return promotion.ErrorDuplicateCode
+	}
+	return err
+}
@@ -0,0 +1,66 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"net/http"
+	"order-service/internal/db"
+	"order-service/internal/domain/promotion"
+	"order-service/pkg/apperror"
+	"testing"
+)
+
+func newMock(t *testing.T) (*sqlx.DB, sqlmock.Sqlmock) {
+	conn, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return sqlx.NewDb(conn, "postgres"), mock
+}
+
+// TestPromotionReadsFromReplica checks that lists go to the replica, while a
+// read by id, which may follow a write, goes to the primary.
+func TestPromotionReadsFromReplica(t *testing.T) {
+	primary, primaryMock := newMock(t)
+	replica, replicaMock := newMock(t)
+	repo := NewPromotionRepository(primary, db.Replica{DB: replica})
+
+	replicaMock.ExpectQuery(`SELECT \* FROM promotions ORDER BY created_at`).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "code"}).AddRow("p1", "SPRING"))
+	primaryMock.ExpectQuery(`SELECT \* FROM promotions WHERE id = \$1`).WithArgs("p1").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "code"}).AddRow("p1", "SPRING"))
+
+	list, err := repo.List(context.Background())
+	if err != nil || len(list) != 1 || list[0].Code != "SPRING" {
+		t.Errorf("List = %+v, %v", list, err)
+	}
+	if _, err = repo.Get(context.Background(), "p1"); err != nil {
+		t.Errorf("Get: %v", err)
+	}
+	for name, mock := range map[string]sqlmock.Sqlmock{"primary": primaryMock, "replica": replicaMock} {
+		if err = mock.ExpectationsWereMet(); err != nil {
+			t.Errorf("%s: %v", name, err)
+		}
+	}
+}
+
+func TestPromotionMapsPostgresErrors(t *testing.T) {
+	primary, mock := newMock(t)
+	repo := NewPromotionRepository(primary, db.Replica{DB: primary})
+
+	mock.ExpectQuery(`INSERT INTO promotions`).WillReturnError(&pq.Error{Code: "23505"})
+	if _, err := repo.Create(context.Background(), promotion.Entity{Code: "SPRING"}); !errors.Is(err, promotion.ErrorDuplicateCode) {
+		t.Errorf("Create error = %v, want %v", err, promotion.ErrorDuplicateCode)
+	}
+	mock.ExpectQuery(`SELECT \* FROM promotions WHERE id = \$1`).WillReturnError(&pq.Error{Code: "22P02"})
+	if _, err := repo.Get(context.Background(), "not-a-uuid"); !errors.Is(err, promotion.ErrorNotFound) {
+		t.Errorf("Get error = %v, want %v", err, promotion.ErrorNotFound)
+	}
+	if code := apperror.From(promotion.ErrorDuplicateCode).Status; code != http.StatusConflict {
+		t.Errorf("duplicate code is served with %d, want 409", code)
+	}
+}
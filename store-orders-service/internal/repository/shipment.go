@@ -0,0 +1,299 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"order-service/internal/db"
+	"order-service/internal/domain/order"
+	"order-service/internal/domain/shipment"
+	interfaces "order-service/internal/repository/interface"
+	"order-service/pkg/audit"
+	"order-service/pkg/events"
+	"strings"
+)
+
+type ShipmentRepository struct {
+	db      *sqlx.DB
+	replica *sqlx.DB
+}
+
+func NewShipmentRepository(db *sqlx.DB, replica db.Replica) interfaces.ShipmentRepository {
+	return &ShipmentRepository{
+		db:      db,
+		replica: replica.DB,
+	}
+}
+
+// Create stores a shipment of the order. The order is locked while the units
+// left to ship are counted, so two shipments cannot both take the last unit.
+// A shipment without items takes everything that is left.
+func (sr *ShipmentRepository) Create(ctx context.Context, data shipment.Entity) (id string, err error) {
+	tx, err := sr.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+		}
+	}()
+
+	ordered, status, err := sr.lockOrder(ctx, tx, data.OrderID)
+	if err != nil {
+		return
+	}
+	remaining := make(map[string]int, len(ordered))
+	for productID, quantity := range ordered {
+		remaining[productID] = quantity
+	}
+	var packed []shipment.Item
+	query := `
+		SELECT si.product_id, si.quantity FROM shipment_items si
+		JOIN shipments s ON s.id = si.shipment_id WHERE s.order_id = $1;`
+	if err = tx.SelectContext(ctx, &packed, query, data.OrderID); err != nil {
+		return
+	}
+	for _, item := range packed {
+		remaining[item.ProductID] -= item.Quantity
+	}
+
+	items := data.Items
+	if len(items) == 0 {
+		for productID, quantity := range remaining {
+			if quantity > 0 {
+				items = append(items, shipment.Item{ProductID: productID, Quantity: quantity})
+			}
+		}
+		if len(items) == 0 {
+			err = shipment.ErrorNothingToShip
+			return
+		}
+	}
+	for i, item := range items {
+		items[i].ProductID = strings.ToLower(item.ProductID)
+		left, ok := remaining[items[i].ProductID]
+		if !ok {
+			err = shipment.ErrorInvalidItem
+			return
+		}
+		if item.Quantity > left {
+			err = shipment.ErrorTooManyItems
+			return
+		}
+		remaining[items[i].ProductID] -= item.Quantity
+	}
+
+	query = `
+		INSERT INTO shipments (order_id, carrier, tracking_number, status)
+		VALUES ($1, $2, $3, $4) RETURNING id;`
+	args := []any{data.OrderID, data.Carrier, data.TrackingNumber, shipment.StatusPending}
+	if err = tx.QueryRowContext(ctx, query, args...).Scan(&id); err != nil {
+		return
+	}
+	query = `INSERT INTO shipment_items (shipment_id, product_id, quantity) VALUES ($1, $2, $3);`
+	for _, item := range items {
+		if _, err = tx.ExecContext(ctx, query, id, item.ProductID, item.Quantity); err != nil {
+			return
+		}
+	}
+	if err = sr.syncOrderStatus(ctx, tx, data.OrderID, ordered, status); err != nil {
+		return
+	}
+	err = tx.Commit()
+	return
+}
+
+func (sr *ShipmentRepository) List(ctx context.Context, orderID string) (res []shipment.Entity, err error) {
+	res = []shipment.Entity{}
+	query := `SELECT * FROM shipments WHERE order_id = $1 ORDER BY created_at, id;`
+	if err = sr.replica.SelectContext(ctx, &res, query, orderID); err != nil {
+		return
+	}
+	err = sr.loadItems(ctx, sr.replica, res)
+	return
+}
+
+func (sr *ShipmentRepository) Get(ctx context.Context, orderID, id string) (dest shipment.Entity, err error) {
+	query := `SELECT * FROM shipments WHERE id = $1 AND order_id = $2;`
+	if err = sr.db.GetContext(ctx, &dest, query, id, orderID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) || db.IsInvalidText(err) {
+			err = shipment.ErrorNotFound
+		}
+		return
+	}
+	entities := []shipment.Entity{dest}
+	if err = sr.loadItems(ctx, sr.db, entities); err != nil {
+		return
+	}
+	dest = entities[0]
+	return
+}
+
+// Update changes the carrier details of a shipment and moves it forward.
+// Reaching a status stamps it and every status skipped on the way, then the
+// order status follows its shipments.
+func (sr *ShipmentRepository) Update(ctx context.Context, orderID, id string, data shipment.Entity) (err error) {
+	tx, err := sr.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+		}
+	}()
+
+	ordered, status, err := sr.lockOrder(ctx, tx, orderID)
+	if err != nil {
+		return
+	}
+	var current string
+	query := `SELECT status FROM shipments WHERE id = $1 AND order_id = $2 FOR UPDATE;`
+	if err = tx.QueryRowContext(ctx, query, id, orderID).Scan(&current); err != nil {
+		if errors.Is(err, sql.ErrNoRows) || db.IsInvalidText(err) {
+			err = shipment.ErrorNotFound
+		}
+		return
+	}
+	next := data.Status
+	if next == "" {
+		next = current
+	}
+	if !shipment.CanTransition(current, next) {
+		err = shipment.ErrorInvalidTransition
+		return
+	}
+
+	query = `
+		UPDATE shipments SET
+			carrier = COALESCE(NULLIF($1, ''), carrier),
+			tracking_number = COALESCE(NULLIF($2, ''), tracking_number),
+			status = $3,
+			packed_at = CASE WHEN $4 THEN COALESCE(packed_at, CURRENT_TIMESTAMP) ELSE packed_at END,
+			shipped_at = CASE WHEN $5 THEN COALESCE(shipped_at, CURRENT_TIMESTAMP) ELSE shipped_at END,
+			delivered_at = CASE WHEN $6 THEN COALESCE(delivered_at, CURRENT_TIMESTAMP) ELSE delivered_at END
+		WHERE id = $7;`
+	args := []any{
+		data.Carrier,
+		data.TrackingNumber,
+		next,
+		shipment.Reached(next, shipment.StatusPacked),
+		shipment.Reached(next, shipment.StatusShipped),
+		shipment.Reached(next, shipment.StatusDelivered),
+		id,
+	}
+	if _, err = tx.ExecContext(ctx, query, args...); err != nil {
+		return
+	}
+	if err = sr.syncOrderStatus(ctx, tx, orderID, ordered, status); err != nil {
+		return
+	}
+	err = tx.Commit()
+	return
+}
+
+// lockOrder locks the order row, unless the order is deleted, and returns how many units of every product
+// were ordered together with the order status.
+func (sr *ShipmentRepository) lockOrder(ctx context.Context, tx *sqlx.Tx, orderID string) (ordered map[string]int, status string, err error) {
+	var productIDs []string
+	query := `SELECT product_id, status FROM orders WHERE id = $1 AND deleted_at IS NULL FOR UPDATE;`
+	if err = tx.QueryRowContext(ctx, query, orderID).Scan(pq.Array(&productIDs), &status); err != nil {
+		if errors.Is(err, sql.ErrNoRows) || db.IsInvalidText(err) {
+			err = order.ErrorNotFound
+		}
+		return
+	}
+	ordered = make(map[string]int, len(productIDs))
+	for _, productID := range productIDs {
+		ordered[strings.ToLower(productID)]++
+	}
+	return
+}
+
+// syncOrderStatus moves the order to delivered once every unit is delivered,
+// to shipped once every unit has left the warehouse and to partially shipped
+// while only some have, and writes OrderStatusChanged. A done order is left
+// alone.
+func (sr *ShipmentRepository) syncOrderStatus(ctx context.Context, tx *sqlx.Tx, orderID string, ordered map[string]int, status string) (err error) {
+	if status == "done" {
+		return
+	}
+	rows := []struct {
+		ProductID string `db:"product_id"`
+		Shipped   int    `db:"shipped"`
+		Delivered int    `db:"delivered"`
+	}{}
+	query := `
+		SELECT si.product_id,
+			COALESCE(SUM(si.quantity) FILTER (WHERE s.status IN ('shipped', 'delivered')), 0) AS shipped,
+			COALESCE(SUM(si.quantity) FILTER (WHERE s.status = 'delivered'), 0) AS delivered
+		FROM shipment_items si JOIN shipments s ON s.id = si.shipment_id
+		WHERE s.order_id = $1 GROUP BY si.product_id;`
+	if err = tx.SelectContext(ctx, &rows, query, orderID); err != nil {
+		return
+	}
+
+	shipped := make(map[string]int, len(rows))
+	delivered := make(map[string]int, len(rows))
+	anyShipped := false
+	for _, row := range rows {
+		shipped[row.ProductID] = row.Shipped
+		delivered[row.ProductID] = row.Delivered
+		anyShipped = anyShipped || row.Shipped > 0
+	}
+	allShipped, allDelivered := true, true
+	for productID, quantity := range ordered {
+		allShipped = allShipped && shipped[productID] >= quantity
+		allDelivered = allDelivered && delivered[productID] >= quantity
+	}
+
+	next := status
+	switch {
+	case allDelivered:
+		next = order.StatusDelivered
+	case allShipped:
+		next = order.StatusShipped
+	case anyShipped:
+		next = order.StatusPartiallyShipped
+	}
+	if next == status {
+		return
+	}
+	var userID string
+	query = `UPDATE orders SET status = $1, version = version + 1 WHERE id = $2 RETURNING user_id;`
+	if err = tx.QueryRowContext(ctx, query, next, orderID).Scan(&userID); err != nil {
+		return
+	}
+	changed := order.StatusChanged{OrderID: orderID, UserID: userID, OldStatus: status, NewStatus: next}
+	if err = events.Store(ctx, tx, order.EventStatusChanged, orderID, changed); err != nil {
+		return
+	}
+	err = audit.Record(ctx, tx, order.AuditEntity, orderID, audit.ActionUpdate, order.AuditStatus{Status: status}, order.AuditStatus{Status: next})
+	return
+}
+
+func (sr *ShipmentRepository) loadItems(ctx context.Context, q sqlx.QueryerContext, shipments []shipment.Entity) (err error) {
+	if len(shipments) == 0 {
+		return
+	}
+	ids := make([]string, len(shipments))
+	index := make(map[string]*shipment.Entity, len(shipments))
+	for i := range shipments {
+		ids[i] = shipments[i].ID
+		index[shipments[i].ID] = &shipments[i]
+	}
+
+	var items []shipment.Item
+	query := `SELECT * FROM shipment_items WHERE shipment_id = ANY($1) ORDER BY product_id;`
+	if err = sqlx.SelectContext(ctx, q, &items, query, pq.Array(ids)); err != nil {
+		return
+	}
+	for _, item := range items {
+		entity := index[item.ShipmentID]
+		entity.Items = append(entity.Items, item)
+	}
+	return
+}
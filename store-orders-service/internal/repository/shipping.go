@@ -0,0 +1,66 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"github.com/jmoiron/sqlx"
+	"order-service/internal/db"
+	"order-service/internal/domain/shipping"
+	interfaces "order-service/internal/repository/interface"
+)
+
+type ShippingRepository struct {
+	db *sqlx.DB
+}
+
+func NewShippingRepository(db *sqlx.DB) interfaces.ShippingRepository {
+	return &ShippingRepository{
+		db: db,
+	}
+}
+
+func (sr *ShippingRepository) Upsert(ctx context.Context, data shipping.Entity) (id string, err error) {
+	query := `
+		INSERT INTO shipping_rates (method, country, region, min_weight, max_weight, price, per_kg, currency, estimated_days)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		ON CONFLICT (method, country, region, min_weight) DO UPDATE
+		SET max_weight = EXCLUDED.max_weight, price = EXCLUDED.price, per_kg = EXCLUDED.per_kg,
+			currency = EXCLUDED.currency, estimated_days = EXCLUDED.estimated_days
+		RETURNING id;`
+	args := []any{
+		data.Method,
+		data.Country,
+		data.Region,
+		data.MinWeight,
+		data.MaxWeight,
+		data.Price,
+		data.PerKg,
+		data.Currency,
+		data.EstimatedDays,
+	}
+	err = sr.db.QueryRowContext(ctx, query, args...).Scan(&id)
+	return
+}
+
+// List returns the rates of a method in a country, an empty method or
+// country matches all of them.
+func (sr *ShippingRepository) List(ctx context.Context, method, country string) (dest []shipping.Entity, err error) {
+	dest = []shipping.Entity{}
+	query := `
+		SELECT * FROM shipping_rates
+		WHERE ($1 = '' OR method = $1) AND ($2 = '' OR country = $2)
+		ORDER BY method, country, region, min_weight;`
+	err = sr.db.SelectContext(ctx, &dest, query, method, country)
+	return
+}
+
+func (sr *ShippingRepository) Delete(ctx context.Context, id string) (err error) {
+	query := `DELETE FROM shipping_rates WHERE id = $1 RETURNING id;`
+	if err = sr.db.QueryRowContext(ctx, query, id).Scan(&id); err != nil {
+		if errors.Is(err, sql.ErrNoRows) || db.IsInvalidText(err) {
+			err = shipping.ErrorNotFound
+		}
+	}
+	return
+}
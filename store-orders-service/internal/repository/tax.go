@@ -0,0 +1,54 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"github.com/jmoiron/sqlx"
+	"order-service/internal/db"
+	"order-service/internal/domain/tax"
+	interfaces "order-service/internal/repository/interface"
+)
+
+type TaxRepository struct {
+	db *sqlx.DB
+}
+
+func NewTaxRepository(db *sqlx.DB) interfaces.TaxRepository {
+	return &TaxRepository{
+		db: db,
+	}
+}
+
+func (tr *TaxRepository) Upsert(ctx context.Context, data tax.Entity) (id string, err error) {
+	query := `
+		INSERT INTO tax_rates (country, region, category, name, rate, inclusive)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		ON CONFLICT (country, region, category) DO UPDATE
+		SET name = EXCLUDED.name, rate = EXCLUDED.rate, inclusive = EXCLUDED.inclusive
+		RETURNING id;`
+	args := []any{data.Country, data.Region, data.Category, data.Name, data.Rate, data.Inclusive}
+	err = tr.db.QueryRowContext(ctx, query, args...).Scan(&id)
+	return
+}
+
+// List returns the rates of a country, or every rate when country is empty.
+func (tr *TaxRepository) List(ctx context.Context, country string) (dest []tax.Entity, err error) {
+	dest = []tax.Entity{}
+	query := `
+		SELECT * FROM tax_rates
+		WHERE $1 = '' OR country = $1
+		ORDER BY country, region, category;`
+	err = tr.db.SelectContext(ctx, &dest, query, country)
+	return
+}
+
+func (tr *TaxRepository) Delete(ctx context.Context, id string) (err error) {
+	query := `DELETE FROM tax_rates WHERE id = $1 RETURNING id;`
+	if err = tr.db.QueryRowContext(ctx, query, id).Scan(&id); err != nil {
+		if errors.Is(err, sql.ErrNoRows) || db.IsInvalidText(err) {
+			err = tax.ErrorNotFound
+		}
+	}
+	return
+}
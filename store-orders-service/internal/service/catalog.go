@@ -0,0 +1,161 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"order-service/internal/config"
+	"order-service/internal/domain/catalog"
+	services "order-service/internal/service/interface"
+	"order-service/pkg/logging"
+	"order-service/pkg/metrics"
+	"order-service/pkg/money"
+	"order-service/pkg/pb/productpb"
+	"order-service/pkg/tracing"
+	"time"
+)
+
+// CatalogService reads the product service over gRPC when products is set
+// and over its REST API otherwise.
+type CatalogService struct {
+	productServiceURL string
+	client            *http.Client
+	products          productpb.ProductServiceClient
+}
+
+func NewCatalogService(cfg config.Config, products productpb.ProductServiceClient) services.CatalogService {
+	return &CatalogService{
+		productServiceURL: cfg.ProductServiceURL,
+		client:            &http.Client{Timeout: 5 * time.Second, Transport: tracing.Transport(metrics.Transport{Base: logging.Transport{}})},
+		products:          products,
+	}
+}
+
+func (cs *CatalogService) GetRate(ctx context.Context, base, quote string) (rate string, err error) {
+	if base == quote {
+		return "1", nil
+	}
+
+	var res catalog.Rate
+	if cs.products != nil {
+		err = cs.getRate(ctx, base, quote, &res)
+	} else {
+		err = cs.get(ctx, fmt.Sprintf("%s/rates/%s/%s", cs.productServiceURL, base, quote), &res)
+	}
+	if err != nil {
+		if errors.Is(err, catalog.ErrorNotFound) {
+			err = catalog.ErrorRateNotFound
+		}
+		return
+	}
+	rate = res.Rate
+	return
+}
+
+func (cs *CatalogService) GetProduct(ctx context.Context, id string) (res catalog.Product, err error) {
+	if cs.products != nil {
+		err = cs.getProduct(ctx, id, &res)
+	} else {
+		err = cs.get(ctx, fmt.Sprintf("%s/products/%s", cs.productServiceURL, id), &res)
+	}
+	if err != nil {
+		if errors.Is(err, catalog.ErrorNotFound) {
+			err = catalog.ErrorProductNotFound
+		}
+		return
+	}
+	return
+}
+
+// GetItems prices the ordered product IDs in currency, each occurrence of an
+// ID is one unit.
+func (cs *CatalogService) GetItems(ctx context.Context, productIDs []string, currency string) (res []catalog.Item, err error) {
+	index := make(map[string]int)
+	rates := make(map[string]string)
+	res = make([]catalog.Item, 0)
+	for _, id := range productIDs {
+		if i, ok := index[id]; ok {
+			res[i].Quantity++
+			continue
+		}
+		product, getErr := cs.GetProduct(ctx, id)
+		if getErr != nil {
+			return nil, getErr
+		}
+		rate, ok := rates[product.Price.Currency]
+		if !ok {
+			if rate, err = cs.GetRate(ctx, product.Price.Currency, currency); err != nil {
+				return nil, err
+			}
+			rates[product.Price.Currency] = rate
+		}
+		price, convErr := money.Convert(product.Price, currency, rate)
+		if convErr != nil {
+			return nil, convErr
+		}
+		index[id] = len(res)
+		res = append(res, catalog.Item{
+			ProductID: id,
+			Category:  product.Category,
+			UnitPrice: price,
+			Weight:    product.Weight,
+			Quantity:  1,
+		})
+	}
+	return
+}
+
+func (cs *CatalogService) get(ctx context.Context, url string, dest any) error {
+	return cs.wrap(getData(ctx, cs.client, url, dest))
+}
+
+func (cs *CatalogService) getRate(ctx context.Context, base, quote string, dest *catalog.Rate) error {
+	ctx, cancel := context.WithTimeout(ctx, rpcTimeout)
+	defer cancel()
+
+	res, err := cs.products.GetRate(ctx, &productpb.GetRateRequest{BaseCurrency: base, QuoteCurrency: quote})
+	if err != nil {
+		return cs.wrap(rpcError(err))
+	}
+	*dest = catalog.Rate{
+		BaseCurrency:  res.GetBaseCurrency(),
+		QuoteCurrency: res.GetQuoteCurrency(),
+		Rate:          res.GetRate(),
+	}
+	return nil
+}
+
+func (cs *CatalogService) getProduct(ctx context.Context, id string, dest *catalog.Product) error {
+	ctx, cancel := context.WithTimeout(ctx, rpcTimeout)
+	defer cancel()
+
+	res, err := cs.products.GetProduct(ctx, &productpb.GetProductRequest{Id: id})
+	if err != nil {
+		return cs.wrap(rpcError(err))
+	}
+	price, err := money.Parse(res.GetPrice().GetAmount(), res.GetPrice().GetCurrency())
+	if err != nil {
+		return err
+	}
+	*dest = catalog.Product{
+		ID:       res.GetId(),
+		Title:    res.GetTitle(),
+		Price:    price,
+		Category: res.GetCategory(),
+		Weight:   int(res.GetWeight()),
+		Quantity: int(res.GetQuantity()),
+	}
+	return nil
+}
+
+// wrap turns the errors of both transports into catalog errors.
+func (cs *CatalogService) wrap(err error) error {
+	switch {
+	case errors.Is(err, errNoData):
+		return catalog.ErrorNotFound
+	case errors.Is(err, errUnreachable):
+		return fmt.Errorf("%w: %v", catalog.ErrorUnavailable, err)
+	}
+	return err
+}
@@ -0,0 +1,119 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"order-service/internal/config"
+	"order-service/internal/domain/customer"
+	services "order-service/internal/service/interface"
+	"order-service/pkg/auth"
+	"order-service/pkg/logging"
+	"order-service/pkg/metrics"
+	"order-service/pkg/pb/userpb"
+	"order-service/pkg/tracing"
+	"time"
+)
+
+// CustomerService reads the user service over gRPC when users is set and
+// over its REST API otherwise.
+type CustomerService struct {
+	userServiceURL string
+	client         *http.Client
+	users          userpb.UserServiceClient
+}
+
+func NewCustomerService(cfg config.Config, users userpb.UserServiceClient) services.CustomerService {
+	return &CustomerService{
+		userServiceURL: cfg.UserServiceURL,
+		client:         &http.Client{Timeout: 5 * time.Second, Transport: tracing.Transport(metrics.Transport{Base: auth.Transport{Service: Identity(cfg), Base: logging.Transport{}}})},
+		users:          users,
+	}
+}
+
+func (cs *CustomerService) GetUser(ctx context.Context, id string) (res customer.User, err error) {
+	if cs.users != nil {
+		ctx, cancel := context.WithTimeout(ctx, rpcTimeout)
+		defer cancel()
+
+		data, rpcErr := cs.users.GetUser(ctx, &userpb.GetUserRequest{Id: id})
+		if rpcErr != nil {
+			return res, cs.wrap(rpcError(rpcErr), customer.ErrorUserNotFound)
+		}
+		res = customer.User{
+			ID:    data.GetId(),
+			Name:  data.GetName(),
+			Email: data.GetEmail(),
+		}
+		return
+	}
+
+	url := fmt.Sprintf("%s/users/%s", cs.userServiceURL, id)
+	err = cs.get(ctx, url, &res, customer.ErrorUserNotFound)
+	return
+}
+
+func (cs *CustomerService) GetAddress(ctx context.Context, userID, id string) (res customer.Address, err error) {
+	if cs.users != nil {
+		ctx, cancel := context.WithTimeout(ctx, rpcTimeout)
+		defer cancel()
+
+		data, rpcErr := cs.users.GetAddress(ctx, &userpb.GetAddressRequest{UserId: userID, Id: id})
+		if rpcErr != nil {
+			return res, cs.wrap(rpcError(rpcErr), customer.ErrorAddressNotFound)
+		}
+		return parseAddress(data), nil
+	}
+
+	url := fmt.Sprintf("%s/users/%s/addresses/%s", cs.userServiceURL, userID, id)
+	err = cs.get(ctx, url, &res, customer.ErrorAddressNotFound)
+	return
+}
+
+func (cs *CustomerService) GetDefaultAddress(ctx context.Context, userID string) (res customer.Address, err error) {
+	if cs.users != nil {
+		ctx, cancel := context.WithTimeout(ctx, rpcTimeout)
+		defer cancel()
+
+		data, rpcErr := cs.users.GetDefaultAddress(ctx, &userpb.GetDefaultAddressRequest{UserId: userID})
+		if rpcErr != nil {
+			return res, cs.wrap(rpcError(rpcErr), customer.ErrorAddressNotFound)
+		}
+		return parseAddress(data), nil
+	}
+
+	url := fmt.Sprintf("%s/users/%s/addresses/default", cs.userServiceURL, userID)
+	err = cs.get(ctx, url, &res, customer.ErrorAddressNotFound)
+	return
+}
+
+func (cs *CustomerService) get(ctx context.Context, url string, dest any, notFound error) error {
+	return cs.wrap(getData(ctx, cs.client, url, dest), notFound)
+}
+
+// wrap turns the errors of both transports into customer errors.
+func (cs *CustomerService) wrap(err error, notFound error) error {
+	switch {
+	case errors.Is(err, errNoData):
+		return notFound
+	case errors.Is(err, errUnreachable):
+		return fmt.Errorf("%w: %v", customer.ErrorUnavailable, err)
+	}
+	return err
+}
+
+func parseAddress(data *userpb.Address) customer.Address {
+	return customer.Address{
+		ID:         data.GetId(),
+		UserID:     data.GetUserId(),
+		Recipient:  data.GetRecipient(),
+		Line1:      data.GetLine1(),
+		Line2:      data.GetLine2(),
+		City:       data.GetCity(),
+		Region:     data.GetRegion(),
+		PostalCode: data.GetPostalCode(),
+		Country:    data.GetCountry(),
+		Phone:      data.GetPhone(),
+	}
+}
@@ -0,0 +1,41 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"order-service/internal/config"
+	"order-service/internal/domain/customer"
+	"testing"
+)
+
+func TestCustomerServiceREST(t *testing.T) {
+	users := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/users/u1":
+			w.Write([]byte(`{"status_code":200,"data":{"id":"u1","name":"Alice","email":"alice@example.com"}}`))
+		case "/users/down":
+			w.WriteHeader(http.StatusBadGateway)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer users.Close()
+	cs := NewCustomerService(config.Config{UserServiceURL: users.URL}, nil)
+	ctx := context.Background()
+
+	user, err := cs.GetUser(ctx, "u1")
+	if err != nil || user != (customer.User{ID: "u1", Name: "Alice", Email: "alice@example.com"}) {
+		t.Errorf("GetUser(u1) = %+v, %v", user, err)
+	}
+	if _, err = cs.GetUser(ctx, "gone"); !errors.Is(err, customer.ErrorUserNotFound) {
+		t.Errorf("GetUser(gone) error = %v, want %v", err, customer.ErrorUserNotFound)
+	}
+	if _, err = cs.GetUser(ctx, "down"); !errors.Is(err, customer.ErrorUnavailable) {
+		t.Errorf("GetUser(down) error = %v, want %v", err, customer.ErrorUnavailable)
+	}
+	if _, err = cs.GetAddress(ctx, "u1", "a1"); !errors.Is(err, customer.ErrorAddressNotFound) {
+		t.Errorf("GetAddress error = %v, want %v", err, customer.ErrorAddressNotFound)
+	}
+}
@@ -0,0 +1,13 @@
+package interfaces
+
+import (
+	"context"
+	"order-service/internal/domain/catalog"
+)
+
+// CatalogService reads catalog data owned by the product service.
+type CatalogService interface {
+	GetRate(ctx context.Context, base, quote string) (rate string, err error)
+	GetProduct(ctx context.Context, id string) (res catalog.Product, err error)
+	GetItems(ctx context.Context, productIDs []string, currency string) (res []catalog.Item, err error)
+}
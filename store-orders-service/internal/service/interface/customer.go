@@ -0,0 +1,13 @@
+package interfaces
+
+import (
+	"context"
+	"order-service/internal/domain/customer"
+)
+
+// CustomerService reads customer data owned by the user service.
+type CustomerService interface {
+	GetUser(ctx context.Context, id string) (res customer.User, err error)
+	GetAddress(ctx context.Context, userID, id string) (res customer.Address, err error)
+	GetDefaultAddress(ctx context.Context, userID string) (res customer.Address, err error)
+}
@@ -9,7 +9,10 @@ type OrderService interface {
 	CreateOrder(ctx context.Context, req order.Request) (id string, err error)
 	ListOrders(ctx context.Context) (res []order.Response, err error)
 	GetOrder(ctx context.Context, id string) (res order.Response, err error)
+	GetInvoice(ctx context.Context, id string) (res order.Invoice, err error)
 	DeleteOrder(ctx context.Context, id string) (err error)
-	UpdateOrder(ctx context.Context, id string, req order.Request) (err error)
+	RestoreOrder(ctx context.Context, id string) (res order.Response, err error)
+	UpdateOrder(ctx context.Context, id string, version int, req order.Request) (res order.Response, err error)
+	PatchOrder(ctx context.Context, id string, version int, patch order.Patch) (res order.Response, err error)
 	SearchOrder(ctx context.Context, filter, value string) (res []order.Response, err error)
 }
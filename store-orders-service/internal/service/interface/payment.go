@@ -0,0 +1,13 @@
+package interfaces
+
+import (
+	"context"
+	"log/slog"
+	"order-service/pkg/events"
+)
+
+// PaymentEventConsumer keeps order statuses in sync with the payment service.
+type PaymentEventConsumer interface {
+	Run(ctx context.Context, logger *slog.Logger)
+	Handle(ctx context.Context, event events.Event) (result string, err error)
+}
@@ -0,0 +1,15 @@
+package interfaces
+
+import (
+	"context"
+	"order-service/internal/domain/promotion"
+)
+
+type PromotionService interface {
+	CreatePromotion(ctx context.Context, req promotion.Request) (id string, err error)
+	ListPromotions(ctx context.Context) (res []promotion.Response, err error)
+	GetPromotion(ctx context.Context, id string) (res promotion.Response, err error)
+	UpdatePromotion(ctx context.Context, id string, req promotion.Request) (err error)
+	DeletePromotion(ctx context.Context, id string) (err error)
+	ApplyPromotions(ctx context.Context, cart promotion.Cart) (res []promotion.Applied, err error)
+}
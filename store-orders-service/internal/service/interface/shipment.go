@@ -0,0 +1,13 @@
+package interfaces
+
+import (
+	"context"
+	"order-service/internal/domain/shipment"
+)
+
+type ShipmentService interface {
+	CreateShipment(ctx context.Context, orderID string, req shipment.Request) (id string, err error)
+	ListShipments(ctx context.Context, orderID string) (res []shipment.Response, err error)
+	GetShipment(ctx context.Context, orderID, id string) (res shipment.Response, err error)
+	UpdateShipment(ctx context.Context, orderID, id string, req shipment.UpdateRequest) (err error)
+}
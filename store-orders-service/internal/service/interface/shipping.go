@@ -0,0 +1,17 @@
+package interfaces
+
+import (
+	"context"
+	"order-service/internal/domain/shipping"
+)
+
+// ShippingRateProvider quotes the delivery cost of a parcel.
+type ShippingRateProvider interface {
+	Quote(ctx context.Context, req shipping.QuoteRequest) (res shipping.Quote, err error)
+}
+
+type ShippingService interface {
+	SetRate(ctx context.Context, req shipping.Request) (id string, err error)
+	ListRates(ctx context.Context, method, country string) (res []shipping.Response, err error)
+	DeleteRate(ctx context.Context, id string) (err error)
+}
@@ -0,0 +1,17 @@
+package interfaces
+
+import (
+	"context"
+	"order-service/internal/domain/tax"
+)
+
+// TaxCalculator works out the taxes due on an order.
+type TaxCalculator interface {
+	Calculate(ctx context.Context, req tax.Calculation) (res []tax.Line, err error)
+}
+
+type TaxService interface {
+	SetRate(ctx context.Context, req tax.Request) (id string, err error)
+	ListRates(ctx context.Context, country string) (res []tax.Response, err error)
+	DeleteRate(ctx context.Context, id string) (err error)
+}
@@ -2,29 +2,289 @@ package service
 
 import (
 	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"order-service/internal/domain/catalog"
+	"order-service/internal/domain/customer"
 	"order-service/internal/domain/order"
+	"order-service/internal/domain/promotion"
+	"order-service/internal/domain/shipping"
+	"order-service/internal/domain/tax"
 	interfaces "order-service/internal/repository/interface"
 	services "order-service/internal/service/interface"
+	"order-service/pkg/money"
+	"strings"
+)
+
+var (
+	ordersCreated = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "order_service",
+		Name:      "orders_created_total",
+		Help:      "Orders created by the currency they are charged in.",
+	}, []string{"currency"})
+	stockOutRejections = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: "order_service",
+		Name:      "stock_out_rejections_total",
+		Help:      "Orders rejected for more units of a product than are in stock.",
+	})
 )
 
 type OrderService struct {
-	orderRepository interfaces.OrderRepository
+	orderRepository      interfaces.OrderRepository
+	catalogService       services.CatalogService
+	customerService      services.CustomerService
+	promotionService     services.PromotionService
+	taxCalculator        services.TaxCalculator
+	shippingRateProvider services.ShippingRateProvider
 }
 
-func NewOrderService(orderRepository interfaces.OrderRepository) services.OrderService {
+func NewOrderService(
+	orderRepository interfaces.OrderRepository,
+	catalogService services.CatalogService,
+	customerService services.CustomerService,
+	promotionService services.PromotionService,
+	taxCalculator services.TaxCalculator,
+	shippingRateProvider services.ShippingRateProvider,
+) services.OrderService {
 	return &OrderService{
-		orderRepository: orderRepository,
+		orderRepository:      orderRepository,
+		catalogService:       catalogService,
+		customerService:      customerService,
+		promotionService:     promotionService,
+		taxCalculator:        taxCalculator,
+		shippingRateProvider: shippingRateProvider,
 	}
 }
 
+// CreateOrder charges the order in req.Currency. The exchange rate from the
+// catalog currency is looked up once and stored with the order, so the total
+// can always be reproduced from BasePricing, the discounts, ExchangeRate, the
+// exclusive taxes and the shipping cost, the last two being charged in the
+// order currency. With a shipping address the destination decides the
+// taxes, otherwise req.Country and req.Region do.
 func (ps *OrderService) CreateOrder(ctx context.Context, req order.Request) (id string, err error) {
+	if _, err = ps.customerService.GetUser(ctx, req.UserID); err != nil {
+		return
+	}
+	if err = ps.checkStock(ctx, req.ProductID); err != nil {
+		return
+	}
+	currency := strings.ToUpper(req.Currency)
+	if currency == "" {
+		currency = req.Pricing.Currency
+	}
+	rate, err := ps.catalogService.GetRate(ctx, req.Pricing.Currency, currency)
+	if err != nil {
+		return
+	}
+
+	applied, err := ps.promotionService.ApplyPromotions(ctx, promotion.Cart{
+		UserID:     req.UserID,
+		ProductIDs: req.ProductID,
+		Subtotal:   req.Pricing,
+		Codes:      req.PromoCodes,
+	})
+	if err != nil {
+		return
+	}
+	discounts := make([]order.Discount, 0, len(applied))
+	total := money.New(0, req.Pricing.Currency)
+	freeShipping := false
+	for _, discount := range applied {
+		if discount.Type == promotion.TypeFreeShipping {
+			freeShipping = true
+		}
+		if total, err = total.Add(discount.Amount); err != nil {
+			return
+		}
+		discounts = append(discounts, order.Discount{
+			PromotionID: sql.NullString{String: discount.PromotionID, Valid: discount.PromotionID != ""},
+			Code:        discount.Code,
+			Type:        discount.Type,
+			Amount:      discount.Amount.Amount,
+			Currency:    discount.Amount.Currency,
+		})
+	}
+	net, err := req.Pricing.Sub(total)
+	if err != nil {
+		return
+	}
+	pricing, err := money.Convert(net, currency, rate)
+	if err != nil {
+		return
+	}
+
+	country := tax.NormalizeCountry(req.Country)
+	region := tax.NormalizeRegion(req.Region)
+	address, err := ps.shippingAddress(ctx, req)
+	if err != nil {
+		return
+	}
+	if address.ID != "" {
+		country = tax.NormalizeCountry(address.Country)
+		region = tax.NormalizeRegion(address.Region)
+	}
+	taxes, taxTotal, exclusive, err := ps.calculateTaxes(ctx, country, region, req.ProductID, pricing)
+	if err != nil {
+		return
+	}
+
+	method := shipping.NormalizeMethod(req.ShippingMethod)
+	shippingCost := money.New(0, currency)
+	if method != "" && !freeShipping {
+		if shippingCost, err = ps.quoteShipping(ctx, method, country, region, req.ProductID, currency); err != nil {
+			return
+		}
+	}
+
 	data := order.Entity{
-		UserID:    req.UserID,
-		ProductID: req.ProductID,
-		Pricing:   req.Pricing,
-		Status:    req.Status,
+		UserID:            req.UserID,
+		ProductID:         req.ProductID,
+		Pricing:           pricing.Amount + exclusive + shippingCost.Amount,
+		Currency:          pricing.Currency,
+		BasePricing:       req.Pricing.Amount,
+		BaseCurrency:      req.Pricing.Currency,
+		ExchangeRate:      rate,
+		DiscountTotal:     total.Amount,
+		Discounts:         discounts,
+		Country:           country,
+		Region:            region,
+		TaxTotal:          taxTotal,
+		Taxes:             taxes,
+		ShippingAddressID: sql.NullString{String: address.ID, Valid: address.ID != ""},
+		ShippingAddress:   address.String(),
+		ShippingMethod:    method,
+		ShippingCost:      shippingCost.Amount,
+		Status:            req.Status,
 	}
 	id, err = ps.orderRepository.Create(ctx, data)
+	if err != nil {
+		return
+	}
+	ordersCreated.WithLabelValues(data.Currency).Inc()
+	return
+}
+
+// checkStock rejects an order for more units of a product than the catalog
+// has in stock. Stock is not reserved, the product service stays the owner
+// of the quantity.
+func (ps *OrderService) checkStock(ctx context.Context, productIDs []string) error {
+	units := make(map[string]int)
+	for _, id := range productIDs {
+		units[id]++
+	}
+	for _, id := range productIDs {
+		n, ok := units[id]
+		if !ok {
+			continue
+		}
+		delete(units, id)
+		product, err := ps.catalogService.GetProduct(ctx, id)
+		if err != nil {
+			return err
+		}
+		if product.Quantity < n {
+			stockOutRejections.Inc()
+			return fmt.Errorf("%w: %s has %d of %d units", catalog.ErrorOutOfStock, id, product.Quantity, n)
+		}
+	}
+	return nil
+}
+
+// checkReferences makes sure the user and the products an order is changed
+// to exist. They live in the databases of the user and product services, so
+// those are asked over their APIs instead of foreign keys.
+func (ps *OrderService) checkReferences(ctx context.Context, userID string, productIDs []string) error {
+	if userID != "" {
+		if _, err := ps.customerService.GetUser(ctx, userID); err != nil {
+			return err
+		}
+	}
+	for _, id := range productIDs {
+		if _, err := ps.catalogService.GetProduct(ctx, id); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// shippingAddress looks up the address the order ships to: the one named by
+// the request, or the user's default when a shipping method is chosen without
+// one. An empty address means the order is not shipped to an address.
+func (ps *OrderService) shippingAddress(ctx context.Context, req order.Request) (res customer.Address, err error) {
+	switch {
+	case req.ShippingAddressID != "":
+		res, err = ps.customerService.GetAddress(ctx, req.UserID, req.ShippingAddressID)
+	case req.ShippingMethod != "" && req.Country == "":
+		res, err = ps.customerService.GetDefaultAddress(ctx, req.UserID)
+		if errors.Is(err, customer.ErrorAddressNotFound) {
+			err = shipping.ErrorMissingDestination
+		}
+	}
+	return
+}
+
+// quoteShipping prices the delivery of the ordered products in currency.
+func (ps *OrderService) quoteShipping(ctx context.Context, method, country, region string, productIDs []string, currency string) (res money.Money, err error) {
+	items, err := ps.catalogService.GetItems(ctx, productIDs, currency)
+	if err != nil {
+		return
+	}
+	weight := 0
+	for _, item := range items {
+		weight += item.Weight * item.Quantity
+	}
+
+	quote, err := ps.shippingRateProvider.Quote(ctx, shipping.QuoteRequest{
+		Method:  method,
+		Country: country,
+		Region:  region,
+		Weight:  weight,
+	})
+	if err != nil {
+		return
+	}
+	rate, err := ps.catalogService.GetRate(ctx, quote.Cost.Currency, currency)
+	if err != nil {
+		return
+	}
+	res, err = money.Convert(quote.Cost, currency, rate)
+	return
+}
+
+// calculateTaxes taxes amount and returns the tax lines, the total tax and
+// the part of it that is added on top of amount.
+func (ps *OrderService) calculateTaxes(ctx context.Context, country, region string, productIDs []string, amount money.Money) (taxes []order.Tax, total, exclusive int64, err error) {
+	lines, err := ps.taxCalculator.Calculate(ctx, tax.Calculation{
+		Country:    country,
+		Region:     region,
+		ProductIDs: productIDs,
+		Amount:     amount,
+	})
+	if err != nil {
+		return
+	}
+	taxes = make([]order.Tax, 0, len(lines))
+	for _, line := range lines {
+		total += line.Amount.Amount
+		if !line.Inclusive {
+			exclusive += line.Amount.Amount
+		}
+		taxes = append(taxes, order.Tax{
+			Name:      line.Name,
+			Country:   line.Country,
+			Region:    line.Region,
+			Category:  line.Category,
+			Rate:      line.Rate,
+			Inclusive: line.Inclusive,
+			Taxable:   line.Taxable.Amount,
+			Amount:    line.Amount.Amount,
+			Currency:  line.Amount.Currency,
+		})
+	}
 	return
 }
 
@@ -46,22 +306,101 @@ func (ps *OrderService) GetOrder(ctx context.Context, id string) (res order.Resp
 	return
 }
 
+func (ps *OrderService) GetInvoice(ctx context.Context, id string) (res order.Invoice, err error) {
+	data, err := ps.orderRepository.Get(ctx, id)
+	if err != nil {
+		return
+	}
+	res, err = order.ParseInvoice(data)
+	return
+}
+
 func (ps *OrderService) DeleteOrder(ctx context.Context, id string) (err error) {
 	err = ps.orderRepository.Delete(ctx, id)
 	return
 }
 
-func (ps *OrderService) UpdateOrder(ctx context.Context, id string, req order.Request) (err error) {
+func (ps *OrderService) RestoreOrder(ctx context.Context, id string) (res order.Response, err error) {
+	data, err := ps.orderRepository.Restore(ctx, id)
+	if err != nil {
+		return
+	}
+	res = order.ParseFromEntity(data)
+	return
+}
+
+// UpdateOrder keeps the currency, exchange rate and discounts locked at
+// checkout, a new base total is converted with the stored rate and taxed
+// again. version is the one the client read or 0.
+func (ps *OrderService) UpdateOrder(ctx context.Context, id string, version int, req order.Request) (res order.Response, err error) {
+	if err = ps.checkReferences(ctx, req.UserID, req.ProductID); err != nil {
+		return
+	}
 	data := order.Entity{
 		UserID:    req.UserID,
 		ProductID: req.ProductID,
-		Pricing:   req.Pricing,
 		Status:    req.Status,
 	}
-	err = ps.orderRepository.Update(ctx, id, data)
+	if !req.Pricing.IsZero() {
+		current, getErr := ps.orderRepository.Get(ctx, id)
+		if getErr != nil {
+			err = getErr
+			return
+		}
+		if req.Pricing.Currency != current.BaseCurrency {
+			err = order.ErrorInvalidCurrency
+			return
+		}
+		net := money.New(max(req.Pricing.Amount-current.DiscountTotal, 0), req.Pricing.Currency)
+		pricing, convErr := money.Convert(net, current.Currency, current.ExchangeRate)
+		if convErr != nil {
+			err = convErr
+			return
+		}
+		productIDs := req.ProductID
+		if len(productIDs) == 0 {
+			productIDs = current.ProductID
+		}
+		taxes, taxTotal, exclusive, taxErr := ps.calculateTaxes(ctx, current.Country, current.Region, productIDs, pricing)
+		if taxErr != nil {
+			err = taxErr
+			return
+		}
+		data.Pricing = pricing.Amount + exclusive + current.ShippingCost
+		data.BasePricing = req.Pricing.Amount
+		data.TaxTotal = taxTotal
+		data.Taxes = taxes
+	}
+	data, err = ps.orderRepository.Update(ctx, id, version, data)
+	if err != nil {
+		return
+	}
+	res = order.ParseFromEntity(data)
 	return
 }
 
+// PatchOrder applies a merge patch to the order. The patched order must be
+// as valid as a new one, and only a patched pricing is priced again.
+func (ps *OrderService) PatchOrder(ctx context.Context, id string, version int, patch order.Patch) (res order.Response, err error) {
+	current, err := ps.orderRepository.Get(ctx, id)
+	if err != nil {
+		return
+	}
+	if version != 0 && version != current.Version {
+		err = order.ErrorVersionMismatch
+		return
+	}
+
+	req := patch.Apply(current)
+	if err = req.Validate(); err != nil {
+		return
+	}
+	if patch.Pricing == nil {
+		req.Pricing = money.Money{}
+	}
+	return ps.UpdateOrder(ctx, id, current.Version, req)
+}
+
 func (ps *OrderService) SearchOrder(ctx context.Context, filter, value string) (res []order.Response, err error) {
 	if !order.IsValidFilter(filter) || value == "" {
 		err = order.ErrorInvalidSearch
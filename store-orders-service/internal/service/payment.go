@@ -0,0 +1,124 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"order-service/internal/domain/order"
+	"order-service/internal/domain/payment"
+	interfaces "order-service/internal/repository/interface"
+	services "order-service/internal/service/interface"
+	"order-service/pkg/events"
+	"time"
+)
+
+// paymentConsumer names the consumer in processed_events, paymentDurable
+// names its durable consumer in the stream, where names cannot have dots.
+const (
+	paymentConsumer = "order-service.payments"
+	paymentDurable  = "order-service-payments"
+)
+
+const maxConsumeBackoff = 30 * time.Second
+
+type transition struct {
+	status string
+	from   []string
+}
+
+// paymentTransitions maps a payment event to the order status it moves an
+// order to and the statuses it may move it from. A capture does not pull an
+// order back once fulfillment started, a refund wins over everything.
+var paymentTransitions = map[string]transition{
+	payment.EventCaptured: {order.StatusPaid, []string{order.StatusNew, order.StatusPaymentFailed}},
+	payment.EventFailed:   {order.StatusPaymentFailed, []string{order.StatusNew}},
+	payment.EventRefunded: {order.StatusRefunded, []string{
+		order.StatusPaid, "in_progress", order.StatusPartiallyShipped, order.StatusShipped, order.StatusDelivered, "done",
+	}},
+}
+
+type PaymentEventConsumer struct {
+	orderRepository interfaces.OrderRepository
+	subscriber      events.Subscriber
+	stats           *events.Stats
+}
+
+func NewPaymentEventConsumer(orderRepository interfaces.OrderRepository, subscriber events.Subscriber, stats *events.Stats) services.PaymentEventConsumer {
+	return &PaymentEventConsumer{
+		orderRepository: orderRepository,
+		subscriber:      subscriber,
+		stats:           stats,
+	}
+}
+
+// Run consumes payment events until ctx is done, subscribing again with a
+// growing backoff whenever the connection to the broker drops.
+func (pc *PaymentEventConsumer) Run(ctx context.Context, logger *slog.Logger) {
+	if pc.subscriber == nil {
+		logger.Info("no events url set, payment events are not consumed")
+		return
+	}
+
+	eventTypes := make([]string, 0, len(paymentTransitions))
+	for eventType := range paymentTransitions {
+		eventTypes = append(eventTypes, eventType)
+	}
+	handler := func(ctx context.Context, event events.Event) error {
+		result, err := pc.Handle(ctx, event)
+		pc.stats.Observe(event, result)
+		if err != nil {
+			logger.ErrorContext(ctx, "failed to handle payment event", "type", event.Type, "event_id", event.ID, "error", err)
+		}
+		return err
+	}
+
+	backoff := time.Second
+	for {
+		started := time.Now()
+		err := pc.subscriber.Subscribe(ctx, paymentDurable, eventTypes, handler)
+		if ctx.Err() != nil {
+			return
+		}
+		if time.Since(started) > maxConsumeBackoff {
+			backoff = time.Second
+		}
+		logger.Error("payment events subscription failed", "retry_in", backoff, "error", err)
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(backoff):
+		}
+		backoff = min(backoff*2, maxConsumeBackoff)
+	}
+}
+
+// Handle applies one payment event to its order and reports the outcome as
+// one of the events.Result values. An event that failed is delivered again
+// until it is handled, processed_events keeps a redelivered one from being
+// applied twice.
+func (pc *PaymentEventConsumer) Handle(ctx context.Context, event events.Event) (result string, err error) {
+	next, ok := paymentTransitions[event.Type]
+	if !ok {
+		return events.ResultSkipped, nil
+	}
+	var data payment.Payment
+	if err = json.Unmarshal(event.Payload, &data); err != nil {
+		return events.ResultFailed, fmt.Errorf("%w: %v", events.ErrMalformed, err)
+	}
+	if data.OrderID == "" {
+		return events.ResultSkipped, nil
+	}
+
+	changed, err := pc.orderRepository.SetStatusOnce(ctx, paymentConsumer, event.ID, data.OrderID, next.status, next.from)
+	switch {
+	case errors.Is(err, order.ErrorEventProcessed):
+		return events.ResultDuplicate, nil
+	case err != nil:
+		return events.ResultFailed, err
+	case changed:
+		return events.ResultProcessed, nil
+	}
+	return events.ResultSkipped, nil
+}
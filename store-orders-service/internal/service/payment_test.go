@@ -0,0 +1,138 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"order-service/internal/domain/order"
+	"order-service/internal/domain/payment"
+	interfaces "order-service/internal/repository/interface"
+	"order-service/pkg/events"
+	"slices"
+	"testing"
+)
+
+// fakeOrderStatuses keeps order statuses and processed events the way
+// SetStatusOnce does in one transaction.
+type fakeOrderStatuses struct {
+	interfaces.OrderRepository
+	statuses  map[string]string
+	processed map[string]bool
+	err       error
+}
+
+func (fo *fakeOrderStatuses) SetStatusOnce(ctx context.Context, consumer, eventID, orderID, status string, from []string) (changed bool, err error) {
+	if fo.err != nil {
+		return false, fo.err
+	}
+	key := consumer + "/" + eventID
+	if fo.processed[key] {
+		return false, order.ErrorEventProcessed
+	}
+	fo.processed[key] = true
+	current, ok := fo.statuses[orderID]
+	if !ok || !slices.Contains(from, current) {
+		return false, nil
+	}
+	fo.statuses[orderID] = status
+	return true, nil
+}
+
+func TestPaymentEventConsumerHandle(t *testing.T) {
+	event := func(id, eventType, payload string) events.Event {
+		return events.Event{ID: id, Type: eventType, Payload: []byte(payload)}
+	}
+	captured := event("e1", payment.EventCaptured, `{"id":"p1","order_id":"o1"}`)
+
+	tests := []struct {
+		name     string
+		statuses map[string]string
+		err      error
+		events   []events.Event
+		results  []string
+		status   string
+		wantErr  error
+	}{
+		{
+			name:     "capture pays a new order",
+			statuses: map[string]string{"o1": order.StatusNew},
+			events:   []events.Event{captured},
+			results:  []string{events.ResultProcessed},
+			status:   order.StatusPaid,
+		},
+		{
+			name:     "redelivered event is applied once",
+			statuses: map[string]string{"o1": order.StatusNew},
+			events:   []events.Event{captured, captured},
+			results:  []string{events.ResultProcessed, events.ResultDuplicate},
+			status:   order.StatusPaid,
+		},
+		{
+			name:     "failure after capture does not unpay the order",
+			statuses: map[string]string{"o1": order.StatusNew},
+			events:   []events.Event{captured, event("e2", payment.EventFailed, `{"id":"p1","order_id":"o1"}`)},
+			results:  []string{events.ResultProcessed, events.ResultSkipped},
+			status:   order.StatusPaid,
+		},
+		{
+			name:     "refund wins over a shipped order",
+			statuses: map[string]string{"o1": order.StatusShipped},
+			events:   []events.Event{event("e3", payment.EventRefunded, `{"id":"p1","order_id":"o1"}`)},
+			results:  []string{events.ResultProcessed},
+			status:   order.StatusRefunded,
+		},
+		{
+			name:     "other event types are skipped",
+			statuses: map[string]string{"o1": order.StatusNew},
+			events:   []events.Event{event("e4", "PaymentCreated", `{"id":"p1","order_id":"o1"}`)},
+			results:  []string{events.ResultSkipped},
+			status:   order.StatusNew,
+		},
+		{
+			name:     "payment without an order is skipped",
+			statuses: map[string]string{"o1": order.StatusNew},
+			events:   []events.Event{event("e5", payment.EventCaptured, `{"id":"p1"}`)},
+			results:  []string{events.ResultSkipped},
+			status:   order.StatusNew,
+		},
+		{
+			name:     "unreadable payload is dropped",
+			statuses: map[string]string{"o1": order.StatusNew},
+			events:   []events.Event{event("e6", payment.EventCaptured, `not json`)},
+			results:  []string{events.ResultFailed},
+			status:   order.StatusNew,
+			wantErr:  events.ErrMalformed,
+		},
+		{
+			name:     "database error is redelivered",
+			statuses: map[string]string{"o1": order.StatusNew},
+			err:      errors.New("connection refused"),
+			events:   []events.Event{captured},
+			results:  []string{events.ResultFailed},
+			status:   order.StatusNew,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repository := &fakeOrderStatuses{statuses: tt.statuses, processed: make(map[string]bool), err: tt.err}
+			consumer := NewPaymentEventConsumer(repository, nil, events.NewStats()).(*PaymentEventConsumer)
+
+			for i, e := range tt.events {
+				result, err := consumer.Handle(context.Background(), e)
+				if result != tt.results[i] {
+					t.Errorf("event %d: result = %q, want %q", i, result, tt.results[i])
+				}
+				switch {
+				case tt.err != nil:
+					if !errors.Is(err, tt.err) || errors.Is(err, events.ErrMalformed) {
+						t.Errorf("event %d: error = %v, want a retryable %v", i, err, tt.err)
+					}
+				case !errors.Is(err, tt.wantErr):
+					t.Errorf("event %d: error = %v, want %v", i, err, tt.wantErr)
+				}
+			}
+			if got := repository.statuses["o1"]; got != tt.status {
+				t.Errorf("status = %q, want %q", got, tt.status)
+			}
+		})
+	}
+}
@@ -0,0 +1,220 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"order-service/internal/domain/catalog"
+	"order-service/internal/domain/promotion"
+	interfaces "order-service/internal/repository/interface"
+	services "order-service/internal/service/interface"
+	"order-service/pkg/money"
+	"sort"
+	"strings"
+	"time"
+)
+
+type PromotionService struct {
+	promotionRepository interfaces.PromotionRepository
+	catalogService      services.CatalogService
+}
+
+func NewPromotionService(promotionRepository interfaces.PromotionRepository, catalogService services.CatalogService) services.PromotionService {
+	return &PromotionService{
+		promotionRepository: promotionRepository,
+		catalogService:      catalogService,
+	}
+}
+
+func (ps *PromotionService) CreatePromotion(ctx context.Context, req promotion.Request) (id string, err error) {
+	id, err = ps.promotionRepository.Create(ctx, ps.parseRequest(req))
+	return
+}
+
+func (ps *PromotionService) ListPromotions(ctx context.Context) (res []promotion.Response, err error) {
+	data, err := ps.promotionRepository.List(ctx)
+	if err != nil {
+		return
+	}
+	res = promotion.ParseFromEntities(data)
+	return
+}
+
+func (ps *PromotionService) GetPromotion(ctx context.Context, id string) (res promotion.Response, err error) {
+	data, err := ps.promotionRepository.Get(ctx, id)
+	if err != nil {
+		return
+	}
+	res = promotion.ParseFromEntity(data)
+	return
+}
+
+func (ps *PromotionService) UpdatePromotion(ctx context.Context, id string, req promotion.Request) (err error) {
+	err = ps.promotionRepository.Update(ctx, id, ps.parseRequest(req))
+	return
+}
+
+func (ps *PromotionService) DeletePromotion(ctx context.Context, id string) (err error) {
+	err = ps.promotionRepository.Delete(ctx, id)
+	return
+}
+
+// ApplyPromotions returns the discounts granted to the cart, in the cart
+// currency. Automatic promotions (without a code) that do not apply are
+// skipped, while a coupon the customer asked for must apply or the whole
+// call fails. The sum of the discounts never exceeds the subtotal.
+func (ps *PromotionService) ApplyPromotions(ctx context.Context, cart promotion.Cart) (res []promotion.Applied, err error) {
+	res = make([]promotion.Applied, 0)
+	codes := make([]string, 0, len(cart.Codes))
+	for _, code := range cart.Codes {
+		if code = promotion.NormalizeCode(code); code != "" {
+			codes = append(codes, code)
+		}
+	}
+
+	candidates, err := ps.promotionRepository.ListCandidates(ctx, codes, time.Now())
+	if err != nil {
+		return
+	}
+	found := make(map[string]bool, len(candidates))
+	for _, entity := range candidates {
+		found[entity.Code] = true
+	}
+	for _, code := range codes {
+		if !found[code] {
+			return nil, fmt.Errorf("%w: %s", promotion.ErrorUnknownCode, code)
+		}
+	}
+
+	var items []catalog.Item
+	applied := make(map[string]bool, len(candidates))
+	remaining := cart.Subtotal.Amount
+	for _, entity := range candidates {
+		if applied[entity.ID] {
+			continue
+		}
+		amount, applyErr := ps.apply(ctx, entity, cart, &items)
+		if applyErr != nil {
+			if entity.Code != "" {
+				return nil, fmt.Errorf("%w: %s", applyErr, entity.Code)
+			}
+			if isPromotionError(applyErr) {
+				continue
+			}
+			return nil, applyErr
+		}
+		if amount > remaining {
+			amount = remaining
+		}
+		remaining -= amount
+		applied[entity.ID] = true
+		res = append(res, promotion.Applied{
+			PromotionID: entity.ID,
+			Code:        entity.Code,
+			Type:        entity.Type,
+			Amount:      money.New(amount, cart.Subtotal.Currency),
+		})
+	}
+	return
+}
+
+func (ps *PromotionService) apply(ctx context.Context, entity promotion.Entity, cart promotion.Cart, items *[]catalog.Item) (amount int64, err error) {
+	if !entity.IsActive(time.Now()) {
+		return 0, promotion.ErrorInactive
+	}
+	if entity.UsageLimit > 0 || entity.PerUserLimit > 0 {
+		total, byUser, countErr := ps.promotionRepository.CountUsages(ctx, entity.ID, cart.UserID)
+		if countErr != nil {
+			return 0, countErr
+		}
+		if (entity.UsageLimit > 0 && total >= entity.UsageLimit) || (entity.PerUserLimit > 0 && byUser >= entity.PerUserLimit) {
+			return 0, promotion.ErrorLimitReached
+		}
+	}
+	if entity.Type == promotion.TypeFreeShipping {
+		return 0, nil
+	}
+
+	eligible := cart.Subtotal.Amount
+	var units []int64
+	if entity.Category != "" || entity.Type == promotion.TypeBuyXGetY {
+		if *items == nil {
+			if *items, err = ps.catalogService.GetItems(ctx, cart.ProductIDs, cart.Subtotal.Currency); err != nil {
+				return
+			}
+		}
+		eligible = 0
+		for _, item := range *items {
+			if entity.Category != "" && !strings.EqualFold(item.Category, entity.Category) {
+				continue
+			}
+			eligible += item.UnitPrice.Amount * int64(item.Quantity)
+			for i := 0; i < item.Quantity; i++ {
+				units = append(units, item.UnitPrice.Amount)
+			}
+		}
+		eligible = min(eligible, cart.Subtotal.Amount)
+	}
+
+	switch entity.Type {
+	case promotion.TypePercentage:
+		amount = (eligible*int64(entity.PercentOff) + 50) / 100
+	case promotion.TypeFixedAmount:
+		rate, rateErr := ps.catalogService.GetRate(ctx, entity.Currency, cart.Subtotal.Currency)
+		if errors.Is(rateErr, catalog.ErrorRateNotFound) {
+			return 0, promotion.ErrorNotApplicable
+		}
+		if rateErr != nil {
+			return 0, rateErr
+		}
+		off, convErr := money.Convert(money.New(entity.AmountOff, entity.Currency), cart.Subtotal.Currency, rate)
+		if convErr != nil {
+			return 0, convErr
+		}
+		amount = min(off.Amount, eligible)
+	case promotion.TypeBuyXGetY:
+		// every group of buy+get units, most expensive first, gets its
+		// cheapest get units for free
+		sort.Slice(units, func(i, j int) bool { return units[i] > units[j] })
+		group := entity.BuyQuantity + entity.GetQuantity
+		for start := 0; start+group <= len(units); start += group {
+			for _, price := range units[start+entity.BuyQuantity : start+group] {
+				amount += price
+			}
+		}
+		amount = min(amount, eligible)
+	}
+	if amount <= 0 {
+		err = promotion.ErrorNotApplicable
+	}
+	return
+}
+
+func (ps *PromotionService) parseRequest(req promotion.Request) promotion.Entity {
+	data := promotion.Entity{
+		Code:         req.Code,
+		Name:         req.Name,
+		Type:         req.Type,
+		PercentOff:   req.PercentOff,
+		AmountOff:    req.AmountOff.Amount,
+		Currency:     req.AmountOff.Currency,
+		Category:     req.Category,
+		BuyQuantity:  req.BuyQuantity,
+		GetQuantity:  req.GetQuantity,
+		UsageLimit:   req.UsageLimit,
+		PerUserLimit: req.PerUserLimit,
+		StartsAt:     time.Now(),
+		EndsAt:       req.EndsAt,
+		Active:       req.Active == nil || *req.Active,
+	}
+	if req.StartsAt != nil {
+		data.StartsAt = *req.StartsAt
+	}
+	return data
+}
+
+func isPromotionError(err error) bool {
+	return errors.Is(err, promotion.ErrorInactive) ||
+		errors.Is(err, promotion.ErrorLimitReached) ||
+		errors.Is(err, promotion.ErrorNotApplicable)
+}
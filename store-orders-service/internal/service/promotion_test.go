@@ -0,0 +1,266 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"order-service/internal/domain/catalog"
+	"order-service/internal/domain/promotion"
+	interfaces "order-service/internal/repository/interface"
+	"order-service/pkg/money"
+	"reflect"
+	"slices"
+	"testing"
+	"time"
+)
+
+// fakeCatalog prices every product in the requested currency with the
+// same amount, rates are keyed by "BASE/QUOTE".
+type fakeCatalog struct {
+	products map[string]catalog.Item
+	rates    map[string]string
+}
+
+func (fc *fakeCatalog) GetRate(ctx context.Context, base, quote string) (rate string, err error) {
+	if base == quote {
+		return "1", nil
+	}
+	rate, ok := fc.rates[base+"/"+quote]
+	if !ok {
+		err = catalog.ErrorRateNotFound
+	}
+	return
+}
+
+func (fc *fakeCatalog) GetProduct(ctx context.Context, id string) (res catalog.Product, err error) {
+	err = catalog.ErrorProductNotFound
+	return
+}
+
+func (fc *fakeCatalog) GetItems(ctx context.Context, productIDs []string, currency string) (res []catalog.Item, err error) {
+	index := make(map[string]int)
+	for _, id := range productIDs {
+		if i, ok := index[id]; ok {
+			res[i].Quantity++
+			continue
+		}
+		item, ok := fc.products[id]
+		if !ok {
+			return nil, catalog.ErrorProductNotFound
+		}
+		item.ProductID = id
+		item.UnitPrice = money.New(item.UnitPrice.Amount, currency)
+		item.Quantity = 1
+		index[id] = len(res)
+		res = append(res, item)
+	}
+	return
+}
+
+// fakePromotions serves the promotions it holds the way the SQL repository
+// does, except that automatic promotions are returned whatever their state.
+type fakePromotions struct {
+	interfaces.PromotionRepository
+	promotions []promotion.Entity
+	total      map[string]int
+	byUser     map[string]int
+}
+
+func (fp *fakePromotions) ListCandidates(ctx context.Context, codes []string, at time.Time) (res []promotion.Entity, err error) {
+	for _, entity := range fp.promotions {
+		if entity.Code == "" || slices.Contains(codes, entity.Code) {
+			res = append(res, entity)
+		}
+	}
+	return
+}
+
+func (fp *fakePromotions) CountUsages(ctx context.Context, id, userID string) (total, byUser int, err error) {
+	return fp.total[id], fp.byUser[id+"/"+userID], nil
+}
+
+func TestApplyPromotions(t *testing.T) {
+	catalogService := &fakeCatalog{
+		products: map[string]catalog.Item{
+			"book":  {Category: "books", UnitPrice: money.New(1000, "")},
+			"pen":   {Category: "office", UnitPrice: money.New(200, "")},
+			"mug":   {Category: "kitchen", UnitPrice: money.New(300, "")},
+			"plate": {Category: "kitchen", UnitPrice: money.New(100, "")},
+		},
+		rates: map[string]string{"USD/KZT": "500"},
+	}
+	yesterday := time.Now().Add(-24 * time.Hour)
+	percent := func(id, code string, off int) promotion.Entity {
+		return promotion.Entity{ID: id, Code: code, Type: promotion.TypePercentage, PercentOff: off, StartsAt: yesterday, Active: true}
+	}
+	usd := func(amount int64) money.Money { return money.New(amount, "USD") }
+
+	tests := []struct {
+		name       string
+		promotions []promotion.Entity
+		total      map[string]int
+		byUser     map[string]int
+		cart       promotion.Cart
+		want       []promotion.Applied
+		err        error
+	}{
+		{
+			name: "nothing to apply",
+			cart: promotion.Cart{Subtotal: usd(1000)},
+			want: []promotion.Applied{},
+		},
+		{
+			name:       "percentage rounds half up",
+			promotions: []promotion.Entity{percent("p1", "", 15)},
+			cart:       promotion.Cart{Subtotal: usd(333)},
+			want:       []promotion.Applied{{PromotionID: "p1", Type: promotion.TypePercentage, Amount: usd(50)}},
+		},
+		{
+			name: "category percentage counts only that category",
+			promotions: []promotion.Entity{func() promotion.Entity {
+				p := percent("p1", "", 10)
+				p.Category = "Kitchen"
+				return p
+			}()},
+			cart: promotion.Cart{ProductIDs: []string{"book", "mug", "mug", "plate"}, Subtotal: usd(1700)},
+			want: []promotion.Applied{{PromotionID: "p1", Type: promotion.TypePercentage, Amount: usd(70)}},
+		},
+		{
+			name:       "fixed amount is converted to the cart currency",
+			promotions: []promotion.Entity{{ID: "p1", Type: promotion.TypeFixedAmount, AmountOff: 500, Currency: "USD", StartsAt: yesterday, Active: true}},
+			cart:       promotion.Cart{Subtotal: money.New(1000000, "KZT")},
+			want:       []promotion.Applied{{PromotionID: "p1", Type: promotion.TypeFixedAmount, Amount: money.New(250000, "KZT")}},
+		},
+		{
+			name:       "fixed amount never exceeds the subtotal",
+			promotions: []promotion.Entity{{ID: "p1", Type: promotion.TypeFixedAmount, AmountOff: 5000, Currency: "USD", StartsAt: yesterday, Active: true}},
+			cart:       promotion.Cart{Subtotal: usd(1200)},
+			want:       []promotion.Applied{{PromotionID: "p1", Type: promotion.TypeFixedAmount, Amount: usd(1200)}},
+		},
+		{
+			name:       "automatic fixed amount without a rate is skipped",
+			promotions: []promotion.Entity{{ID: "p1", Type: promotion.TypeFixedAmount, AmountOff: 500, Currency: "EUR", StartsAt: yesterday, Active: true}},
+			cart:       promotion.Cart{Subtotal: usd(1000)},
+			want:       []promotion.Applied{},
+		},
+		{
+			name:       "buy two get one gives the cheapest unit of each group",
+			promotions: []promotion.Entity{{ID: "p1", Type: promotion.TypeBuyXGetY, BuyQuantity: 2, GetQuantity: 1, StartsAt: yesterday, Active: true}},
+			cart:       promotion.Cart{ProductIDs: []string{"book", "mug", "plate", "pen", "pen"}, Subtotal: usd(1800)},
+			want:       []promotion.Applied{{PromotionID: "p1", Type: promotion.TypeBuyXGetY, Amount: usd(200)}},
+		},
+		{
+			name:       "free shipping applies with a zero amount",
+			promotions: []promotion.Entity{{ID: "p1", Code: "SHIP", Type: promotion.TypeFreeShipping, StartsAt: yesterday, Active: true}},
+			cart:       promotion.Cart{Subtotal: usd(1000), Codes: []string{"ship"}},
+			want:       []promotion.Applied{{PromotionID: "p1", Code: "SHIP", Type: promotion.TypeFreeShipping, Amount: usd(0)}},
+		},
+		{
+			name:       "stacked discounts are capped at the subtotal",
+			promotions: []promotion.Entity{percent("p1", "", 80), percent("p2", "HALF", 50)},
+			cart:       promotion.Cart{Subtotal: usd(1000), Codes: []string{" half "}},
+			want: []promotion.Applied{
+				{PromotionID: "p1", Type: promotion.TypePercentage, Amount: usd(800)},
+				{PromotionID: "p2", Code: "HALF", Type: promotion.TypePercentage, Amount: usd(200)},
+			},
+		},
+		{
+			name:       "coupon not asked for is ignored",
+			promotions: []promotion.Entity{percent("p1", "HALF", 50)},
+			cart:       promotion.Cart{Subtotal: usd(1000)},
+			want:       []promotion.Applied{},
+		},
+		{
+			name: "unknown code",
+			cart: promotion.Cart{Subtotal: usd(1000), Codes: []string{"nope"}},
+			err:  promotion.ErrorUnknownCode,
+		},
+		{
+			name: "inactive automatic promotion is skipped",
+			promotions: []promotion.Entity{func() promotion.Entity {
+				p := percent("p1", "", 10)
+				p.Active = false
+				return p
+			}()},
+			cart: promotion.Cart{Subtotal: usd(1000)},
+			want: []promotion.Applied{},
+		},
+		{
+			name: "expired coupon",
+			promotions: []promotion.Entity{func() promotion.Entity {
+				p := percent("p1", "OLD", 10)
+				p.EndsAt = &yesterday
+				return p
+			}()},
+			cart: promotion.Cart{Subtotal: usd(1000), Codes: []string{"OLD"}},
+			err:  promotion.ErrorInactive,
+		},
+		{
+			name: "coupon used up",
+			promotions: []promotion.Entity{func() promotion.Entity {
+				p := percent("p1", "ONCE", 10)
+				p.UsageLimit = 3
+				return p
+			}()},
+			total: map[string]int{"p1": 3},
+			cart:  promotion.Cart{UserID: "u1", Subtotal: usd(1000), Codes: []string{"ONCE"}},
+			err:   promotion.ErrorLimitReached,
+		},
+		{
+			name: "coupon under its usage limit",
+			promotions: []promotion.Entity{func() promotion.Entity {
+				p := percent("p1", "ONCE", 10)
+				p.UsageLimit = 3
+				return p
+			}()},
+			total: map[string]int{"p1": 2},
+			cart:  promotion.Cart{UserID: "u1", Subtotal: usd(1000), Codes: []string{"ONCE"}},
+			want:  []promotion.Applied{{PromotionID: "p1", Code: "ONCE", Type: promotion.TypePercentage, Amount: usd(100)}},
+		},
+		{
+			name: "automatic promotion used up by the customer is skipped",
+			promotions: []promotion.Entity{func() promotion.Entity {
+				p := percent("p1", "", 10)
+				p.PerUserLimit = 1
+				return p
+			}(), percent("p2", "", 5)},
+			total:  map[string]int{"p1": 1},
+			byUser: map[string]int{"p1/u1": 1},
+			cart:   promotion.Cart{UserID: "u1", Subtotal: usd(1000)},
+			want:   []promotion.Applied{{PromotionID: "p2", Type: promotion.TypePercentage, Amount: usd(50)}},
+		},
+		{
+			name: "per user limit counts only that customer",
+			promotions: []promotion.Entity{func() promotion.Entity {
+				p := percent("p1", "", 10)
+				p.PerUserLimit = 1
+				return p
+			}()},
+			total:  map[string]int{"p1": 1},
+			byUser: map[string]int{"p1/u1": 1},
+			cart:   promotion.Cart{UserID: "u2", Subtotal: usd(1000)},
+			want:   []promotion.Applied{{PromotionID: "p1", Type: promotion.TypePercentage, Amount: usd(100)}},
+		},
+		{
+			name: "coupon for a category missing from the cart",
+			promotions: []promotion.Entity{func() promotion.Entity {
+				p := percent("p1", "BOOKS", 10)
+				p.Category = "books"
+				return p
+			}()},
+			cart: promotion.Cart{ProductIDs: []string{"pen"}, Subtotal: usd(200), Codes: []string{"BOOKS"}},
+			err:  promotion.ErrorNotApplicable,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repository := &fakePromotions{promotions: tt.promotions, total: tt.total, byUser: tt.byUser}
+			got, err := NewPromotionService(repository, catalogService).ApplyPromotions(context.Background(), tt.cart)
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("error = %v, want %v", err, tt.err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
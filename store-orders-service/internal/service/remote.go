@@ -0,0 +1,76 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"net/http"
+	"order-service/internal/config"
+	"order-service/pkg/auth"
+	"time"
+)
+
+var (
+	errNoData      = errors.New("no data")
+	errUnreachable = errors.New("service unreachable")
+)
+
+// Identity is who the order service calls the others as, it reads the
+// users orders are placed for.
+func Identity(cfg config.Config) auth.Service {
+	return auth.Service{Name: "order-service", Secret: cfg.TokenSecret, Permissions: []string{"users:read:any"}}
+}
+
+// rpcTimeout bounds a gRPC call to another service, a shorter deadline of
+// the incoming request still wins.
+const rpcTimeout = 5 * time.Second
+
+// getData requests url from another service of the store and decodes the
+// data field of its response envelope into dest. A 404 or 400 answer, or an
+// empty data field, is errNoData; transport failures and other statuses wrap
+// errUnreachable.
+func getData(ctx context.Context, client *http.Client, url string, dest any) (err error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("%w: %v", errUnreachable, err)
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+	case http.StatusNotFound, http.StatusBadRequest:
+		return errNoData
+	default:
+		return fmt.Errorf("%w: status %s", errUnreachable, resp.Status)
+	}
+
+	body := struct {
+		Data json.RawMessage `json:"data"`
+	}{}
+	if err = json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return
+	}
+	// the services answer 200 with empty data when nothing matched
+	if data := string(body.Data); data == "" || data == "null" || data == `""` {
+		return errNoData
+	}
+	return json.Unmarshal(body.Data, dest)
+}
+
+// rpcError maps the status of a failed gRPC call the way getData maps HTTP
+// answers: NotFound and InvalidArgument are errNoData, transport failures
+// and other codes wrap errUnreachable.
+func rpcError(err error) error {
+	switch status.Code(err) {
+	case codes.NotFound, codes.InvalidArgument:
+		return errNoData
+	}
+	return fmt.Errorf("%w: %v", errUnreachable, err)
+}
@@ -0,0 +1,62 @@
+package service
+
+import (
+	"context"
+	"order-service/internal/domain/shipment"
+	interfaces "order-service/internal/repository/interface"
+	services "order-service/internal/service/interface"
+	"strings"
+)
+
+type ShipmentService struct {
+	shipmentRepository interfaces.ShipmentRepository
+}
+
+func NewShipmentService(shipmentRepository interfaces.ShipmentRepository) services.ShipmentService {
+	return &ShipmentService{
+		shipmentRepository: shipmentRepository,
+	}
+}
+
+func (ss *ShipmentService) CreateShipment(ctx context.Context, orderID string, req shipment.Request) (id string, err error) {
+	items := make([]shipment.Item, 0, len(req.Items))
+	for _, item := range req.Items {
+		items = append(items, shipment.Item{ProductID: item.ProductID, Quantity: item.Quantity})
+	}
+	data := shipment.Entity{
+		OrderID:        orderID,
+		Carrier:        strings.TrimSpace(req.Carrier),
+		TrackingNumber: strings.TrimSpace(req.TrackingNumber),
+		Items:          items,
+	}
+	id, err = ss.shipmentRepository.Create(ctx, data)
+	return
+}
+
+func (ss *ShipmentService) ListShipments(ctx context.Context, orderID string) (res []shipment.Response, err error) {
+	data, err := ss.shipmentRepository.List(ctx, orderID)
+	if err != nil {
+		return
+	}
+	res = shipment.ParseFromEntities(data)
+	return
+}
+
+func (ss *ShipmentService) GetShipment(ctx context.Context, orderID, id string) (res shipment.Response, err error) {
+	data, err := ss.shipmentRepository.Get(ctx, orderID, id)
+	if err != nil {
+		return
+	}
+	res = shipment.ParseFromEntity(data)
+	return
+}
+
+func (ss *ShipmentService) UpdateShipment(ctx context.Context, orderID, id string, req shipment.UpdateRequest) (err error) {
+	data := shipment.Entity{
+		Carrier:        strings.TrimSpace(req.Carrier),
+		TrackingNumber: strings.TrimSpace(req.TrackingNumber),
+		Status:         req.Status,
+	}
+	err = ss.shipmentRepository.Update(ctx, orderID, id, data)
+	return
+}
@@ -0,0 +1,107 @@
+package service
+
+import (
+	"context"
+	"order-service/internal/domain/shipping"
+	interfaces "order-service/internal/repository/interface"
+	services "order-service/internal/service/interface"
+	"order-service/pkg/money"
+	"strings"
+)
+
+type ShippingService struct {
+	shippingRepository interfaces.ShippingRepository
+}
+
+func NewShippingService(shippingRepository interfaces.ShippingRepository) services.ShippingService {
+	return &ShippingService{
+		shippingRepository: shippingRepository,
+	}
+}
+
+func (ss *ShippingService) SetRate(ctx context.Context, req shipping.Request) (id string, err error) {
+	data := shipping.Entity{
+		Method:        req.Method,
+		Country:       req.Country,
+		Region:        req.Region,
+		MinWeight:     req.MinWeight,
+		MaxWeight:     req.MaxWeight,
+		Price:         req.Price.Amount,
+		PerKg:         req.PerKg.Amount,
+		Currency:      req.Price.Currency,
+		EstimatedDays: req.EstimatedDays,
+	}
+	id, err = ss.shippingRepository.Upsert(ctx, data)
+	return
+}
+
+func (ss *ShippingService) ListRates(ctx context.Context, method, country string) (res []shipping.Response, err error) {
+	data, err := ss.shippingRepository.List(ctx, shipping.NormalizeMethod(method), strings.ToUpper(country))
+	if err != nil {
+		return
+	}
+	res = shipping.ParseFromEntities(data)
+	return
+}
+
+func (ss *ShippingService) DeleteRate(ctx context.Context, id string) (err error) {
+	err = ss.shippingRepository.Delete(ctx, id)
+	return
+}
+
+// TableShippingRateProvider quotes from the shipping_rates table. Among the
+// rates of the method whose weight band holds the parcel, a rate for the
+// region wins over the country-wide one. The cost is the rate price plus
+// PerKg for every started kilogram.
+type TableShippingRateProvider struct {
+	shippingRepository interfaces.ShippingRepository
+}
+
+func NewShippingRateProvider(shippingRepository interfaces.ShippingRepository) services.ShippingRateProvider {
+	return &TableShippingRateProvider{
+		shippingRepository: shippingRepository,
+	}
+}
+
+func (sp *TableShippingRateProvider) Quote(ctx context.Context, req shipping.QuoteRequest) (res shipping.Quote, err error) {
+	if req.Country == "" {
+		err = shipping.ErrorMissingDestination
+		return
+	}
+	rates, err := sp.shippingRepository.List(ctx, shipping.NormalizeMethod(req.Method), strings.ToUpper(req.Country))
+	if err != nil {
+		return
+	}
+
+	var best *shipping.Entity
+	for i, rate := range rates {
+		if rate.Region != "" && !strings.EqualFold(rate.Region, req.Region) {
+			continue
+		}
+		if req.Weight < rate.MinWeight || (rate.MaxWeight > 0 && req.Weight >= rate.MaxWeight) {
+			continue
+		}
+		if best == nil || (best.Region == "" && rate.Region != "") {
+			best = &rates[i]
+		}
+	}
+	if best == nil {
+		err = shipping.ErrorNotAvailable
+		return
+	}
+
+	kilograms := int64((req.Weight + 999) / 1000)
+	cost, err := money.New(best.PerKg, best.Currency).Mul(kilograms)
+	if err != nil {
+		return
+	}
+	if cost, err = cost.Add(money.New(best.Price, best.Currency)); err != nil {
+		return
+	}
+	res = shipping.Quote{
+		Method:        best.Method,
+		Cost:          cost,
+		EstimatedDays: best.EstimatedDays,
+	}
+	return
+}
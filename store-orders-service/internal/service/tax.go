@@ -0,0 +1,201 @@
+package service
+
+import (
+	"context"
+	"math/big"
+	"order-service/internal/domain/tax"
+	interfaces "order-service/internal/repository/interface"
+	services "order-service/internal/service/interface"
+	"order-service/pkg/money"
+	"strings"
+)
+
+type TaxService struct {
+	taxRepository interfaces.TaxRepository
+}
+
+func NewTaxService(taxRepository interfaces.TaxRepository) services.TaxService {
+	return &TaxService{
+		taxRepository: taxRepository,
+	}
+}
+
+func (ts *TaxService) SetRate(ctx context.Context, req tax.Request) (id string, err error) {
+	data := tax.Entity{
+		Country:   req.Country,
+		Region:    req.Region,
+		Category:  req.Category,
+		Name:      req.Name,
+		Rate:      req.Rate,
+		Inclusive: req.Inclusive,
+	}
+	id, err = ts.taxRepository.Upsert(ctx, data)
+	return
+}
+
+func (ts *TaxService) ListRates(ctx context.Context, country string) (res []tax.Response, err error) {
+	data, err := ts.taxRepository.List(ctx, tax.NormalizeCountry(country))
+	if err != nil {
+		return
+	}
+	res = tax.ParseFromEntities(data)
+	return
+}
+
+func (ts *TaxService) DeleteRate(ctx context.Context, id string) (err error) {
+	err = ts.taxRepository.Delete(ctx, id)
+	return
+}
+
+// TableTaxCalculator charges the rates of the tax_rates table. For every
+// product category of the order the most specific row of the country wins:
+// region and category, then region only, then category only, then the
+// country default.
+type TableTaxCalculator struct {
+	taxRepository  interfaces.TaxRepository
+	catalogService services.CatalogService
+}
+
+func NewTaxCalculator(taxRepository interfaces.TaxRepository, catalogService services.CatalogService) services.TaxCalculator {
+	return &TableTaxCalculator{
+		taxRepository:  taxRepository,
+		catalogService: catalogService,
+	}
+}
+
+type taxShare struct {
+	category string
+	amount   int64
+}
+
+func (tc *TableTaxCalculator) Calculate(ctx context.Context, req tax.Calculation) (res []tax.Line, err error) {
+	res = make([]tax.Line, 0)
+	country := tax.NormalizeCountry(req.Country)
+	if country == "" || !req.Amount.IsPositive() {
+		return
+	}
+	rates, err := tc.taxRepository.List(ctx, country)
+	if err != nil || len(rates) == 0 {
+		return
+	}
+
+	shares := []taxShare{{amount: req.Amount.Amount}}
+	for _, rate := range rates {
+		if rate.Category != "" {
+			if shares, err = tc.splitByCategory(ctx, req); err != nil {
+				return nil, err
+			}
+			break
+		}
+	}
+
+	region := tax.NormalizeRegion(req.Region)
+	index := make(map[string]int)
+	for _, share := range shares {
+		rate, ok := matchRate(rates, region, share.category)
+		if !ok {
+			continue
+		}
+		i, ok := index[rate.ID]
+		if !ok {
+			i = len(res)
+			index[rate.ID] = i
+			res = append(res, tax.Line{
+				Name:      rate.Name,
+				Country:   rate.Country,
+				Region:    rate.Region,
+				Category:  rate.Category,
+				Rate:      rate.Rate,
+				Inclusive: rate.Inclusive,
+				Taxable:   money.New(0, req.Amount.Currency),
+			})
+		}
+		res[i].Taxable.Amount += share.amount
+	}
+
+	for i := range res {
+		if res[i].Amount, err = taxAmount(res[i].Taxable, res[i].Rate, res[i].Inclusive); err != nil {
+			return nil, err
+		}
+	}
+	return
+}
+
+// splitByCategory spreads the order amount over the product categories in
+// proportion to their catalog prices, the last category takes the rounding
+// remainder.
+func (tc *TableTaxCalculator) splitByCategory(ctx context.Context, req tax.Calculation) (shares []taxShare, err error) {
+	items, err := tc.catalogService.GetItems(ctx, req.ProductIDs, req.Amount.Currency)
+	if err != nil {
+		return
+	}
+
+	index := make(map[string]int)
+	var total int64
+	for _, item := range items {
+		i, ok := index[item.Category]
+		if !ok {
+			i = len(shares)
+			index[item.Category] = i
+			shares = append(shares, taxShare{category: item.Category})
+		}
+		line := item.UnitPrice.Amount * int64(item.Quantity)
+		shares[i].amount += line
+		total += line
+	}
+	if total <= 0 {
+		return []taxShare{{amount: req.Amount.Amount}}, nil
+	}
+
+	remaining := req.Amount.Amount
+	for i := range shares {
+		if i == len(shares)-1 {
+			shares[i].amount = remaining
+			break
+		}
+		share := new(big.Int).Mul(big.NewInt(req.Amount.Amount), big.NewInt(shares[i].amount))
+		shares[i].amount = share.Quo(share, big.NewInt(total)).Int64()
+		remaining -= shares[i].amount
+	}
+	return
+}
+
+func matchRate(rates []tax.Entity, region, category string) (best tax.Entity, ok bool) {
+	score := -1
+	for _, rate := range rates {
+		if rate.Region != "" && !strings.EqualFold(rate.Region, region) {
+			continue
+		}
+		if rate.Category != "" && !strings.EqualFold(rate.Category, category) {
+			continue
+		}
+		s := 0
+		if rate.Region != "" {
+			s += 2
+		}
+		if rate.Category != "" {
+			s++
+		}
+		if s > score {
+			best, score, ok = rate, s, true
+		}
+	}
+	return
+}
+
+// taxAmount returns the tax due on taxable. An inclusive rate is already part
+// of taxable, so only the rate/(1+rate) share of it is tax.
+func taxAmount(taxable money.Money, rate string, inclusive bool) (res money.Money, err error) {
+	r, ok := new(big.Rat).SetString(rate)
+	if !ok || r.Sign() < 0 {
+		err = tax.ErrorInvalidRate
+		return
+	}
+	if r.Sign() == 0 || taxable.IsZero() {
+		return money.New(0, taxable.Currency), nil
+	}
+	if inclusive {
+		r.Quo(r, new(big.Rat).Add(r, big.NewRat(1, 1)))
+	}
+	return money.Convert(taxable, taxable.Currency, r.RatString())
+}
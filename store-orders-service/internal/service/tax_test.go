@@ -0,0 +1,166 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"order-service/internal/domain/catalog"
+	"order-service/internal/domain/tax"
+	interfaces "order-service/internal/repository/interface"
+	"order-service/pkg/money"
+	"reflect"
+	"testing"
+)
+
+type fakeTaxRates struct {
+	interfaces.TaxRepository
+	rates []tax.Entity
+}
+
+func (fr *fakeTaxRates) List(ctx context.Context, country string) (res []tax.Entity, err error) {
+	for _, rate := range fr.rates {
+		if rate.Country == country {
+			res = append(res, rate)
+		}
+	}
+	return
+}
+
+func TestTableTaxCalculator(t *testing.T) {
+	catalogService := &fakeCatalog{
+		products: map[string]catalog.Item{
+			"book": {Category: "books", UnitPrice: money.New(1000, "")},
+			"pen":  {Category: "office", UnitPrice: money.New(200, "")},
+			"mug":  {Category: "kitchen", UnitPrice: money.New(300, "")},
+		},
+	}
+	vat := tax.Entity{ID: "t1", Country: "KZ", Name: "VAT", Rate: "0.12"}
+	usd := func(amount int64) money.Money { return money.New(amount, "USD") }
+	line := func(rate tax.Entity, taxable, amount int64) tax.Line {
+		return tax.Line{
+			Name:      rate.Name,
+			Country:   rate.Country,
+			Region:    rate.Region,
+			Category:  rate.Category,
+			Rate:      rate.Rate,
+			Inclusive: rate.Inclusive,
+			Taxable:   usd(taxable),
+			Amount:    usd(amount),
+		}
+	}
+
+	bookRate := tax.Entity{ID: "t2", Country: "KZ", Category: "books", Name: "Books", Rate: "0"}
+	regionRate := tax.Entity{ID: "t3", Country: "KZ", Region: "ALA", Name: "City VAT", Rate: "0.1"}
+	regionBookRate := tax.Entity{ID: "t4", Country: "KZ", Region: "ALA", Category: "books", Name: "City books", Rate: "0.05"}
+	inclusive := tax.Entity{ID: "t5", Country: "DE", Name: "MwSt", Rate: "0.2", Inclusive: true}
+
+	tests := []struct {
+		name  string
+		rates []tax.Entity
+		req   tax.Calculation
+		want  []tax.Line
+		err   error
+	}{
+		{
+			name:  "no country",
+			rates: []tax.Entity{vat},
+			req:   tax.Calculation{Amount: usd(1000)},
+			want:  []tax.Line{},
+		},
+		{
+			name:  "nothing to tax",
+			rates: []tax.Entity{vat},
+			req:   tax.Calculation{Country: "KZ", Amount: usd(0)},
+			want:  []tax.Line{},
+		},
+		{
+			name:  "country without rates",
+			rates: []tax.Entity{vat},
+			req:   tax.Calculation{Country: "US", Amount: usd(1000)},
+			want:  []tax.Line{},
+		},
+		{
+			name:  "country default",
+			rates: []tax.Entity{vat},
+			req:   tax.Calculation{Country: " kz ", Amount: usd(10000)},
+			want:  []tax.Line{line(vat, 10000, 1200)},
+		},
+		{
+			name:  "rounds half away from zero",
+			rates: []tax.Entity{regionRate},
+			req:   tax.Calculation{Country: "KZ", Region: "ALA", Amount: usd(125)},
+			want:  []tax.Line{line(regionRate, 125, 13)},
+		},
+		{
+			name:  "inclusive rate takes its share of the amount",
+			rates: []tax.Entity{inclusive},
+			req:   tax.Calculation{Country: "DE", Amount: usd(12000)},
+			want:  []tax.Line{line(inclusive, 12000, 2000)},
+		},
+		{
+			name:  "inclusive rate rounding",
+			rates: []tax.Entity{inclusive},
+			req:   tax.Calculation{Country: "DE", Amount: usd(100)},
+			want:  []tax.Line{line(inclusive, 100, 17)},
+		},
+		{
+			name:  "region beats the country default",
+			rates: []tax.Entity{vat, regionRate},
+			req:   tax.Calculation{Country: "KZ", Region: "ala", Amount: usd(1000)},
+			want:  []tax.Line{line(regionRate, 1000, 100)},
+		},
+		{
+			name:  "other region falls back to the country default",
+			rates: []tax.Entity{vat, regionRate},
+			req:   tax.Calculation{Country: "KZ", Region: "AST", Amount: usd(1000)},
+			want:  []tax.Line{line(vat, 1000, 120)},
+		},
+		{
+			name:  "amount is split by category in proportion to catalog prices",
+			rates: []tax.Entity{vat, bookRate},
+			req:   tax.Calculation{Country: "KZ", ProductIDs: []string{"book", "pen", "pen"}, Amount: usd(700)},
+			want:  []tax.Line{line(bookRate, 500, 0), line(vat, 200, 24)},
+		},
+		{
+			name:  "categories without their own rate share the default line",
+			rates: []tax.Entity{vat, bookRate},
+			req:   tax.Calculation{Country: "KZ", ProductIDs: []string{"pen", "book", "mug"}, Amount: usd(1500)},
+			want:  []tax.Line{line(vat, 500, 60), line(bookRate, 1000, 0)},
+		},
+		{
+			name:  "last category takes the rounding remainder",
+			rates: []tax.Entity{vat, bookRate},
+			req:   tax.Calculation{Country: "KZ", ProductIDs: []string{"book", "pen", "mug"}, Amount: usd(1001)},
+			want:  []tax.Line{line(bookRate, 667, 0), line(vat, 334, 40)},
+		},
+		{
+			name:  "region and category beat category only",
+			rates: []tax.Entity{vat, bookRate, regionRate, regionBookRate},
+			req:   tax.Calculation{Country: "KZ", Region: "ALA", ProductIDs: []string{"book", "pen"}, Amount: usd(1200)},
+			want:  []tax.Line{line(regionBookRate, 1000, 50), line(regionRate, 200, 20)},
+		},
+		{
+			name:  "region only beats category only",
+			rates: []tax.Entity{vat, bookRate, regionRate},
+			req:   tax.Calculation{Country: "KZ", Region: "ALA", ProductIDs: []string{"book"}, Amount: usd(1000)},
+			want:  []tax.Line{line(regionRate, 1000, 100)},
+		},
+		{
+			name:  "invalid rate",
+			rates: []tax.Entity{{ID: "t1", Country: "KZ", Rate: "-0.1"}},
+			req:   tax.Calculation{Country: "KZ", Amount: usd(1000)},
+			err:   tax.ErrorInvalidRate,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			calculator := NewTaxCalculator(&fakeTaxRates{rates: tt.rates}, catalogService)
+			got, err := calculator.Calculate(context.Background(), tt.req)
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("error = %v, want %v", err, tt.err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,18 @@
+-- +goose Up
+-- +goose StatementBegin
+-- The first order schema references users and products, which live in the
+-- databases of the user and product services. In a database of its own
+-- these empty tables stand in for them until the keys are dropped; in a
+-- shared database the real tables already exist and nothing is created.
+-- product_id is an array, so the key it references has to be one too.
+CREATE TABLE IF NOT EXISTS users (
+    id UUID PRIMARY KEY
+);
+
+CREATE TABLE IF NOT EXISTS products (
+    id UUID[] PRIMARY KEY
+);
+-- +goose StatementEnd
+
+-- +goose Down
+-- the tables may be the real ones of a shared database, they are kept
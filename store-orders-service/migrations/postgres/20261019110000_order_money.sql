@@ -0,0 +1,14 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE orders
+    ALTER COLUMN pricing TYPE BIGINT USING ROUND(pricing::NUMERIC * 100)::BIGINT,
+    ADD COLUMN currency CHAR(3) NOT NULL DEFAULT 'KZT';
+ALTER TABLE orders ALTER COLUMN currency DROP DEFAULT;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE orders
+    DROP COLUMN currency,
+    ALTER COLUMN pricing TYPE VARCHAR USING (pricing::NUMERIC / 100)::VARCHAR;
+-- +goose StatementEnd
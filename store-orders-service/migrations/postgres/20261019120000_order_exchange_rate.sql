@@ -0,0 +1,20 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE orders
+    ADD COLUMN base_pricing BIGINT,
+    ADD COLUMN base_currency CHAR(3),
+    ADD COLUMN exchange_rate NUMERIC(20, 10);
+UPDATE orders SET base_pricing = pricing, base_currency = currency, exchange_rate = 1;
+ALTER TABLE orders
+    ALTER COLUMN base_pricing SET NOT NULL,
+    ALTER COLUMN base_currency SET NOT NULL,
+    ALTER COLUMN exchange_rate SET NOT NULL;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE orders
+    DROP COLUMN exchange_rate,
+    DROP COLUMN base_currency,
+    DROP COLUMN base_pricing;
+-- +goose StatementEnd
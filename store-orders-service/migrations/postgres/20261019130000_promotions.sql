@@ -0,0 +1,52 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS promotions (
+    id UUID PRIMARY KEY DEFAULT GEN_RANDOM_UUID(),
+    code VARCHAR NOT NULL DEFAULT '',
+    name VARCHAR NOT NULL,
+    type VARCHAR NOT NULL CHECK (type IN ('percentage', 'fixed_amount', 'buy_x_get_y', 'free_shipping')),
+    percent_off INTEGER NOT NULL DEFAULT 0 CHECK (percent_off BETWEEN 0 AND 100),
+    amount_off BIGINT NOT NULL DEFAULT 0 CHECK (amount_off >= 0),
+    currency CHAR(3) NOT NULL DEFAULT '',
+    category VARCHAR NOT NULL DEFAULT '',
+    buy_quantity INTEGER NOT NULL DEFAULT 0,
+    get_quantity INTEGER NOT NULL DEFAULT 0,
+    usage_limit INTEGER NOT NULL DEFAULT 0,
+    per_user_limit INTEGER NOT NULL DEFAULT 0,
+    starts_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    ends_at TIMESTAMP,
+    active BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+CREATE UNIQUE INDEX IF NOT EXISTS promotions_code_idx ON promotions (code) WHERE code <> '';
+
+CREATE TABLE IF NOT EXISTS promotion_usages (
+    id UUID PRIMARY KEY DEFAULT GEN_RANDOM_UUID(),
+    promotion_id UUID NOT NULL REFERENCES promotions(id) ON DELETE CASCADE,
+    user_id UUID NOT NULL,
+    order_id UUID NOT NULL REFERENCES orders(id) ON DELETE CASCADE,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+CREATE INDEX IF NOT EXISTS promotion_usages_promotion_user_idx ON promotion_usages (promotion_id, user_id);
+
+CREATE TABLE IF NOT EXISTS order_discounts (
+    id UUID PRIMARY KEY DEFAULT GEN_RANDOM_UUID(),
+    order_id UUID NOT NULL REFERENCES orders(id) ON DELETE CASCADE,
+    promotion_id UUID REFERENCES promotions(id) ON DELETE SET NULL,
+    code VARCHAR NOT NULL DEFAULT '',
+    type VARCHAR NOT NULL,
+    amount BIGINT NOT NULL,
+    currency CHAR(3) NOT NULL
+);
+CREATE INDEX IF NOT EXISTS order_discounts_order_idx ON order_discounts (order_id);
+
+ALTER TABLE orders ADD COLUMN discount_total BIGINT NOT NULL DEFAULT 0;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE orders DROP COLUMN discount_total;
+DROP TABLE IF EXISTS order_discounts;
+DROP TABLE IF EXISTS promotion_usages;
+DROP TABLE IF EXISTS promotions;
+-- +goose StatementEnd
@@ -0,0 +1,44 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS tax_rates (
+    id UUID PRIMARY KEY DEFAULT GEN_RANDOM_UUID(),
+    country CHAR(2) NOT NULL,
+    region VARCHAR NOT NULL DEFAULT '',
+    category VARCHAR NOT NULL DEFAULT '',
+    name VARCHAR NOT NULL,
+    rate NUMERIC(7, 6) NOT NULL CHECK (rate BETWEEN 0 AND 1),
+    inclusive BOOLEAN NOT NULL DEFAULT FALSE,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (country, region, category)
+);
+
+INSERT INTO tax_rates (country, name, rate, inclusive) VALUES ('KZ', 'VAT', 0.12, TRUE)
+ON CONFLICT DO NOTHING;
+
+CREATE TABLE IF NOT EXISTS order_taxes (
+    id UUID PRIMARY KEY DEFAULT GEN_RANDOM_UUID(),
+    order_id UUID NOT NULL REFERENCES orders(id) ON DELETE CASCADE,
+    name VARCHAR NOT NULL,
+    country CHAR(2) NOT NULL,
+    region VARCHAR NOT NULL DEFAULT '',
+    category VARCHAR NOT NULL DEFAULT '',
+    rate NUMERIC(7, 6) NOT NULL,
+    inclusive BOOLEAN NOT NULL,
+    taxable BIGINT NOT NULL,
+    amount BIGINT NOT NULL,
+    currency CHAR(3) NOT NULL
+);
+CREATE INDEX IF NOT EXISTS order_taxes_order_idx ON order_taxes (order_id);
+
+ALTER TABLE orders
+    ADD COLUMN country VARCHAR NOT NULL DEFAULT '',
+    ADD COLUMN region VARCHAR NOT NULL DEFAULT '',
+    ADD COLUMN tax_total BIGINT NOT NULL DEFAULT 0;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE orders DROP COLUMN tax_total, DROP COLUMN region, DROP COLUMN country;
+DROP TABLE IF EXISTS order_taxes;
+DROP TABLE IF EXISTS tax_rates;
+-- +goose StatementEnd
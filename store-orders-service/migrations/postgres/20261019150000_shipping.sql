@@ -0,0 +1,37 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS shipping_rates (
+    id UUID PRIMARY KEY DEFAULT GEN_RANDOM_UUID(),
+    method VARCHAR NOT NULL,
+    country CHAR(2) NOT NULL,
+    region VARCHAR NOT NULL DEFAULT '',
+    min_weight INTEGER NOT NULL DEFAULT 0 CHECK (min_weight >= 0),
+    max_weight INTEGER NOT NULL DEFAULT 0 CHECK (max_weight >= 0),
+    price BIGINT NOT NULL CHECK (price >= 0),
+    per_kg BIGINT NOT NULL DEFAULT 0 CHECK (per_kg >= 0),
+    currency CHAR(3) NOT NULL,
+    estimated_days INTEGER NOT NULL DEFAULT 0 CHECK (estimated_days >= 0),
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (method, country, region, min_weight)
+);
+
+INSERT INTO shipping_rates (method, country, price, per_kg, currency, estimated_days)
+VALUES ('standard', 'KZ', 150000, 20000, 'KZT', 5), ('express', 'KZ', 300000, 40000, 'KZT', 2)
+ON CONFLICT DO NOTHING;
+
+ALTER TABLE orders
+    ADD COLUMN shipping_address_id UUID,
+    ADD COLUMN shipping_address VARCHAR NOT NULL DEFAULT '',
+    ADD COLUMN shipping_method VARCHAR NOT NULL DEFAULT '',
+    ADD COLUMN shipping_cost BIGINT NOT NULL DEFAULT 0;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE orders
+    DROP COLUMN shipping_cost,
+    DROP COLUMN shipping_method,
+    DROP COLUMN shipping_address,
+    DROP COLUMN shipping_address_id;
+DROP TABLE IF EXISTS shipping_rates;
+-- +goose StatementEnd
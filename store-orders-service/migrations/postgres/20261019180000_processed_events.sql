@@ -0,0 +1,22 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS processed_events (
+    consumer VARCHAR NOT NULL,
+    event_id UUID NOT NULL,
+    processed_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (consumer, event_id)
+);
+
+ALTER TABLE orders DROP CONSTRAINT IF EXISTS orders_status_check;
+ALTER TABLE orders ADD CONSTRAINT orders_status_check
+    CHECK (status IN ('new', 'paid', 'payment_failed', 'refunded', 'in_progress', 'partially_shipped', 'shipped', 'delivered', 'done'));
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+UPDATE orders SET status = 'new' WHERE status IN ('paid', 'payment_failed', 'refunded');
+ALTER TABLE orders DROP CONSTRAINT IF EXISTS orders_status_check;
+ALTER TABLE orders ADD CONSTRAINT orders_status_check
+    CHECK (status IN ('new', 'in_progress', 'partially_shipped', 'shipped', 'delivered', 'done'));
+DROP TABLE IF EXISTS processed_events;
+-- +goose StatementEnd
@@ -0,0 +1,17 @@
+-- +goose Up
+-- +goose StatementBegin
+-- users and products belong to other services, the order service checks
+-- them over their APIs
+ALTER TABLE orders DROP CONSTRAINT IF EXISTS orders_user_id_fkey;
+ALTER TABLE orders DROP CONSTRAINT IF EXISTS orders_product_id_fkey;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+-- rows written while the keys were gone may point to nothing, the keys only
+-- hold for new rows until they are validated
+ALTER TABLE orders ADD CONSTRAINT orders_user_id_fkey
+    FOREIGN KEY (user_id) REFERENCES users(id) NOT VALID;
+ALTER TABLE orders ADD CONSTRAINT orders_product_id_fkey
+    FOREIGN KEY (product_id) REFERENCES products(id) NOT VALID;
+-- +goose StatementEnd
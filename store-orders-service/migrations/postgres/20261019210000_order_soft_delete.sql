@@ -0,0 +1,10 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE orders ADD COLUMN deleted_at TIMESTAMP;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DELETE FROM orders WHERE deleted_at IS NOT NULL;
+ALTER TABLE orders DROP COLUMN deleted_at;
+-- +goose StatementEnd
@@ -0,0 +1,40 @@
+package audit
+
+import (
+	"context"
+	"github.com/DATA-DOG/go-sqlmock"
+	"order-service/pkg/auth"
+	"testing"
+)
+
+func TestRecordTakesTheActorFromTheToken(t *testing.T) {
+	conn, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	tests := []struct {
+		name  string
+		ctx   context.Context
+		actor string
+	}{
+		{"token", auth.WithClaims(context.Background(), auth.Claims{Subject: "u1", Roles: []string{"admin"}}), "u1"},
+		{"event", context.Background(), ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mock.ExpectExec(`INSERT INTO audit_log`).
+				WithArgs(tt.actor, ActionCreate, "order", "o1", nil, []byte(`{"id":"o1"}`)).
+				WillReturnResult(sqlmock.NewResult(0, 1))
+
+			after := map[string]string{"id": "o1"}
+			if err := Record(tt.ctx, conn, "order", "o1", ActionCreate, nil, after); err != nil {
+				t.Fatal(err)
+			}
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Error(err)
+			}
+		})
+	}
+}
@@ -0,0 +1,86 @@
+package auth
+
+import (
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"net/http/httptest"
+	"order-service/pkg/apperror"
+	"testing"
+	"time"
+)
+
+const secret = "0123456789abcdef0123456789abcdef"
+
+func bearerToken(t *testing.T, secret string, ttl time.Duration, subject string, permissions ...string) string {
+	t.Helper()
+	now := time.Now()
+	claims := Claims{Subject: subject, Permissions: permissions, IssuedAt: now.Unix(), ExpiresAt: now.Add(ttl).Unix()}
+	signed, err := Sign(claims, secret)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return "Bearer " + signed
+}
+
+func TestRequirePermission(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	// answers with the code of the error a middleware refused with
+	router.Use(func(c *gin.Context) {
+		c.Next()
+		if len(c.Errors) > 0 {
+			appErr := apperror.From(c.Errors.Last().Err)
+			c.String(appErr.Status, appErr.Code)
+		}
+	})
+	router.Use(Middleware(secret))
+	ok := func(c *gin.Context) { c.Status(http.StatusOK) }
+	router.GET("/shipments", RequirePermission("shipments:read", "shipments:write"), ok)
+	router.GET("/users/:id", RequireSelfOrPermission("id", "users:read:any"), ok)
+
+	tests := []struct {
+		name          string
+		path          string
+		authorization string
+		want          int
+		code          string
+	}{
+		{"anonymous", "/shipments", "", http.StatusUnauthorized, "token_required"},
+		{"not a bearer token", "/shipments", "Basic dTE6cGFzc3dvcmQ=", http.StatusUnauthorized, "token_required"},
+		{"one of the permissions", "/shipments", bearerToken(t, secret, time.Minute, "u1", "shipments:read"), http.StatusOK, ""},
+		{"without the permission", "/shipments", bearerToken(t, secret, time.Minute, "u1", "orders:write"), http.StatusForbidden, "permission_denied"},
+		{"signed with another secret", "/shipments", bearerToken(t, "another secret of at least 32 bytes", time.Minute, "u1", "shipments:read"), http.StatusUnauthorized, "invalid_token"},
+		{"expired", "/shipments", bearerToken(t, secret, -time.Minute, "u1", "shipments:read"), http.StatusUnauthorized, "token_expired"},
+		{"oneself", "/users/u1", bearerToken(t, secret, time.Minute, "u1"), http.StatusOK, ""},
+		{"another user", "/users/u2", bearerToken(t, secret, time.Minute, "u1"), http.StatusForbidden, "permission_denied"},
+		{"another user with the permission", "/users/u2", bearerToken(t, secret, time.Minute, "u1", "users:read:any"), http.StatusOK, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, tt.path, nil)
+			if tt.authorization != "" {
+				req.Header.Set("Authorization", tt.authorization)
+			}
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, req)
+			if rec.Code != tt.want {
+				t.Errorf("status = %d, want %d: %s", rec.Code, tt.want, rec.Body)
+			}
+			if tt.code != "" && rec.Body.String() != tt.code {
+				t.Errorf("code = %s, want %s", rec.Body, tt.code)
+			}
+		})
+	}
+}
+
+func TestUserIDHeaderIsNotAnIdentity(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("X-User-ID", "u1")
+	ctx, err := authenticate(req.Context(), req.Header.Get("Authorization"), secret)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = Authorize(ctx, "u1"); err != ErrorTokenRequired {
+		t.Errorf("Authorize = %v, want %v", err, ErrorTokenRequired)
+	}
+}
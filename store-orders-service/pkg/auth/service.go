@@ -0,0 +1,68 @@
+package auth
+
+import (
+	"context"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"net/http"
+	"time"
+)
+
+// serviceTokenTTL is how long the token of a call between services is valid,
+// a new one is signed for every call.
+const serviceTokenTTL = time.Minute
+
+// Service is who a service calls the others as. Its token is signed with the
+// secret the user service signs with, so the services it calls trust its
+// permissions the same way.
+type Service struct {
+	// Name is the subject of the token, e.g. order-service.
+	Name        string
+	Secret      string
+	Permissions []string
+}
+
+func (s Service) token() (string, error) {
+	now := time.Now()
+	return Sign(Claims{
+		Subject:     s.Name,
+		Permissions: s.Permissions,
+		IssuedAt:    now.Unix(),
+		ExpiresAt:   now.Add(serviceTokenTTL).Unix(),
+	}, s.Secret)
+}
+
+// UnaryClientInterceptor sends the token of s along as authorization
+// metadata.
+func (s Service) UnaryClientInterceptor() grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		token, err := s.token()
+		if err != nil {
+			return err
+		}
+		ctx = metadata.AppendToOutgoingContext(ctx, metadataKey, "Bearer "+token)
+		return invoker(ctx, method, req, reply, cc, opts...)
+	}
+}
+
+// Transport is an http.RoundTripper that sends the token of Service along
+// in the Authorization header.
+type Transport struct {
+	Service Service
+	// Base makes the requests, http.DefaultTransport when nil.
+	Base http.RoundTripper
+}
+
+func (t Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	base := t.Base
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	token, err := t.Service.token()
+	if err != nil {
+		return nil, err
+	}
+	req = req.Clone(req.Context())
+	req.Header.Set("Authorization", "Bearer "+token)
+	return base.RoundTrip(req)
+}
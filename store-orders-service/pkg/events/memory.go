@@ -0,0 +1,56 @@
+package events
+
+import (
+	"context"
+	"sync"
+)
+
+// MemoryPublisher keeps published events in memory, for tests and local
+// runs without a broker. It is a Subscriber as well and hands every event
+// to the handlers subscribed to its type, whatever they return.
+type MemoryPublisher struct {
+	mu       sync.Mutex
+	events   []Event
+	handlers map[string][]Handler
+}
+
+func NewMemoryPublisher() *MemoryPublisher {
+	return &MemoryPublisher{}
+}
+
+func (mp *MemoryPublisher) Publish(ctx context.Context, event Event) error {
+	mp.mu.Lock()
+	mp.events = append(mp.events, event)
+	handlers := mp.handlers[event.Type]
+	mp.mu.Unlock()
+
+	for _, handler := range handlers {
+		_ = handler(ctx, event)
+	}
+	return nil
+}
+
+func (mp *MemoryPublisher) Subscribe(ctx context.Context, consumer string, eventTypes []string, handler Handler) error {
+	mp.mu.Lock()
+	if mp.handlers == nil {
+		mp.handlers = make(map[string][]Handler)
+	}
+	for _, eventType := range eventTypes {
+		mp.handlers[eventType] = append(mp.handlers[eventType], handler)
+	}
+	mp.mu.Unlock()
+
+	<-ctx.Done()
+	return nil
+}
+
+// Events returns the events published so far in publishing order.
+func (mp *MemoryPublisher) Events() []Event {
+	mp.mu.Lock()
+	defer mp.mu.Unlock()
+	return append([]Event(nil), mp.events...)
+}
+
+func (mp *MemoryPublisher) Close() error {
+	return nil
+}
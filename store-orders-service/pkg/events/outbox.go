@@ -0,0 +1,126 @@
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"log/slog"
+	"time"
+)
+
+const (
+	relayInterval  = time.Second
+	relayBatchSize = 100
+)
+
+// Store writes an event to the outbox table through tx. It is published by
+// the Relay only once tx commits, and never if tx rolls back, so state
+// changes and their events cannot drift apart.
+func Store(ctx context.Context, tx sqlx.ExecerContext, eventType, aggregateID string, payload any) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+	query := `INSERT INTO outbox (type, aggregate_id, payload) VALUES ($1, $2, $3);`
+	_, err = tx.ExecContext(ctx, query, eventType, aggregateID, body)
+	return err
+}
+
+// Relay moves events from the outbox table to a Publisher in the order they
+// were written.
+type Relay struct {
+	db        *sqlx.DB
+	publisher Publisher
+	source    string
+}
+
+func NewRelay(db *sqlx.DB, publisher Publisher, source string) *Relay {
+	return &Relay{
+		db:        db,
+		publisher: publisher,
+		source:    source,
+	}
+}
+
+// Run publishes pending events until ctx is done. Without a publisher it
+// returns at once and the events wait in the outbox.
+func (r *Relay) Run(ctx context.Context, logger *slog.Logger) {
+	if r.publisher == nil {
+		logger.Info("no events url set, outbox relay is disabled")
+		return
+	}
+	defer r.publisher.Close()
+
+	ticker := time.NewTicker(relayInterval)
+	defer ticker.Stop()
+	for {
+		for {
+			n, err := r.Flush(ctx)
+			if err != nil {
+				logger.Error("failed to publish outbox events", "error", err)
+				break
+			}
+			if n < relayBatchSize {
+				break
+			}
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Flush publishes one batch of pending events and marks the published ones.
+// The rows are locked with SKIP LOCKED, so several replicas of a service can
+// relay the same outbox without publishing an event twice at the same time.
+func (r *Relay) Flush(ctx context.Context) (n int, err error) {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return
+	}
+	defer tx.Rollback()
+
+	rows := []struct {
+		ID          string    `db:"id"`
+		Type        string    `db:"type"`
+		AggregateID string    `db:"aggregate_id"`
+		Payload     []byte    `db:"payload"`
+		OccurredAt  time.Time `db:"occurred_at"`
+	}{}
+	query := `
+		SELECT id, type, aggregate_id, payload, occurred_at FROM outbox
+		WHERE published_at IS NULL ORDER BY seq LIMIT $1 FOR UPDATE SKIP LOCKED;`
+	if err = tx.SelectContext(ctx, &rows, query, relayBatchSize); err != nil {
+		return
+	}
+
+	published := make([]string, 0, len(rows))
+	for _, row := range rows {
+		event := Event{
+			ID:          row.ID,
+			Type:        row.Type,
+			Source:      r.source,
+			AggregateID: row.AggregateID,
+			Payload:     row.Payload,
+			OccurredAt:  row.OccurredAt,
+		}
+		if err = r.publisher.Publish(ctx, event); err != nil {
+			break
+		}
+		published = append(published, row.ID)
+	}
+	if len(published) > 0 {
+		query = `UPDATE outbox SET published_at = CURRENT_TIMESTAMP WHERE id = ANY($1);`
+		if _, updateErr := tx.ExecContext(ctx, query, pq.Array(published)); updateErr != nil {
+			return 0, updateErr
+		}
+		if commitErr := tx.Commit(); commitErr != nil {
+			return 0, commitErr
+		}
+	}
+	n = len(published)
+	return
+}
@@ -0,0 +1,126 @@
+package events
+
+import (
+	"context"
+	"errors"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jmoiron/sqlx"
+	"testing"
+	"time"
+)
+
+// failingPublisher accepts the first ok events and fails after that.
+type failingPublisher struct {
+	MemoryPublisher
+	ok int
+}
+
+func (fp *failingPublisher) Publish(ctx context.Context, event Event) error {
+	if len(fp.Events()) == fp.ok {
+		return errors.New("broker is down")
+	}
+	return fp.MemoryPublisher.Publish(ctx, event)
+}
+
+func newMockDB(t *testing.T) (*sqlx.DB, sqlmock.Sqlmock) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+	return sqlx.NewDb(db, "postgres"), mock
+}
+
+func outboxRows() *sqlmock.Rows {
+	at := time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC)
+	return sqlmock.NewRows([]string{"id", "type", "aggregate_id", "payload", "occurred_at"}).
+		AddRow("e1", "OrderCreated", "o1", []byte(`{"id":"o1"}`), at).
+		AddRow("e2", "OrderPaid", "o1", []byte(`{"id":"o1","status":"paid"}`), at)
+}
+
+func TestRelayFlush(t *testing.T) {
+	db, mock := newMockDB(t)
+	mock.ExpectBegin()
+	mock.ExpectQuery(`SELECT id, type, aggregate_id, payload, occurred_at FROM outbox`).
+		WithArgs(relayBatchSize).
+		WillReturnRows(outboxRows())
+	mock.ExpectExec(`UPDATE outbox SET published_at`).
+		WithArgs(`{"e1","e2"}`).
+		WillReturnResult(sqlmock.NewResult(0, 2))
+	mock.ExpectCommit()
+
+	publisher := NewMemoryPublisher()
+	n, err := NewRelay(db, publisher, "order-service").Flush(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != 2 {
+		t.Errorf("flushed %d events, want 2", n)
+	}
+
+	events := publisher.Events()
+	if len(events) != 2 || events[0].ID != "e1" || events[1].ID != "e2" {
+		t.Fatalf("published %+v, want e1 and e2 in order", events)
+	}
+	if events[0].Source != "order-service" || events[0].Type != "OrderCreated" || string(events[0].Payload) != `{"id":"o1"}` {
+		t.Errorf("published %+v", events[0])
+	}
+	if err = mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestRelayFlushMarksOnlyPublished(t *testing.T) {
+	db, mock := newMockDB(t)
+	mock.ExpectBegin()
+	mock.ExpectQuery(`SELECT id, type, aggregate_id, payload, occurred_at FROM outbox`).
+		WithArgs(relayBatchSize).
+		WillReturnRows(outboxRows())
+	mock.ExpectExec(`UPDATE outbox SET published_at`).
+		WithArgs(`{"e1"}`).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	n, err := NewRelay(db, &failingPublisher{ok: 1}, "order-service").Flush(context.Background())
+	if err == nil {
+		t.Fatal("expected the publisher error")
+	}
+	if n != 1 {
+		t.Errorf("flushed %d events, want 1", n)
+	}
+	if err = mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestRelayFlushNothingPublished(t *testing.T) {
+	db, mock := newMockDB(t)
+	mock.ExpectBegin()
+	mock.ExpectQuery(`SELECT id, type, aggregate_id, payload, occurred_at FROM outbox`).
+		WithArgs(relayBatchSize).
+		WillReturnRows(outboxRows())
+	mock.ExpectRollback()
+
+	n, err := NewRelay(db, &failingPublisher{}, "order-service").Flush(context.Background())
+	if err == nil || n != 0 {
+		t.Fatalf("got %d, %v, want the publisher error and nothing flushed", n, err)
+	}
+	if err = mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestStore(t *testing.T) {
+	db, mock := newMockDB(t)
+	mock.ExpectExec(`INSERT INTO outbox \(type, aggregate_id, payload\)`).
+		WithArgs("OrderCreated", "o1", []byte(`{"id":"o1"}`)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	err := Store(context.Background(), db, "OrderCreated", "o1", map[string]string{"id": "o1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
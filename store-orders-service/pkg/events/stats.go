@@ -0,0 +1,91 @@
+package events
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"sync"
+	"time"
+)
+
+// Outcomes of handling a consumed event.
+const (
+	ResultProcessed = "processed"
+	ResultDuplicate = "duplicate"
+	ResultSkipped   = "skipped"
+	ResultFailed    = "failed"
+)
+
+// Stats counts consumed events and keeps the backlog of each consumer, the
+// events in the stream it has not acknowledged yet.
+type Stats struct {
+	mu       sync.Mutex
+	consumed map[[2]string]int64
+	backlog  map[string]uint64
+	last     map[string]time.Time
+}
+
+func NewStats() *Stats {
+	return &Stats{
+		consumed: make(map[[2]string]int64),
+		backlog:  make(map[string]uint64),
+		last:     make(map[string]time.Time),
+	}
+}
+
+// Observe records that event was handled with result.
+func (s *Stats) Observe(event Event, result string) {
+	now := time.Now()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.consumed[[2]string{event.Type, result}]++
+	s.last[event.Type] = now
+}
+
+// SetBacklog records the number of events consumer has not acknowledged.
+func (s *Stats) SetBacklog(consumer string, n uint64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.backlog[consumer] = n
+}
+
+// Collector exports the stats as Prometheus metrics with names starting
+// with namespace.
+func (s *Stats) Collector(namespace string) prometheus.Collector {
+	return &statsCollector{
+		stats: s,
+		consumed: prometheus.NewDesc(namespace+"_events_consumed_total",
+			"Events handled by the consumer by type and result.", []string{"type", "result"}, nil),
+		backlog: prometheus.NewDesc(namespace+"_events_consumer_backlog",
+			"Events in the stream the consumer has not acknowledged yet.", []string{"consumer"}, nil),
+		last: prometheus.NewDesc(namespace+"_events_last_consumed_timestamp_seconds",
+			"Unix time the last event of a type was handled.", []string{"type"}, nil),
+	}
+}
+
+type statsCollector struct {
+	stats    *Stats
+	consumed *prometheus.Desc
+	backlog  *prometheus.Desc
+	last     *prometheus.Desc
+}
+
+func (c *statsCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.consumed
+	ch <- c.backlog
+	ch <- c.last
+}
+
+func (c *statsCollector) Collect(ch chan<- prometheus.Metric) {
+	s := c.stats
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for key, n := range s.consumed {
+		ch <- prometheus.MustNewConstMetric(c.consumed, prometheus.CounterValue, float64(n), key[0], key[1])
+	}
+	for consumer, n := range s.backlog {
+		ch <- prometheus.MustNewConstMetric(c.backlog, prometheus.GaugeValue, float64(n), consumer)
+	}
+	for eventType, last := range s.last {
+		ch <- prometheus.MustNewConstMetric(c.last, prometheus.GaugeValue, float64(last.Unix()), eventType)
+	}
+}
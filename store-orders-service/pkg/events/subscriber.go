@@ -0,0 +1,137 @@
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"github.com/nats-io/nats.go/jetstream"
+	"time"
+)
+
+const (
+	ackWait         = 30 * time.Second
+	maxRedelivery   = time.Minute
+	backlogInterval = 15 * time.Second
+)
+
+// ErrMalformed is wrapped by the error of a handler for an event it can
+// never handle, such as one with a payload it cannot read.
+var ErrMalformed = errors.New("events: malformed event")
+
+// Handler processes one delivered event. A nil error acknowledges the event,
+// any other error has it delivered again later, unless it wraps ErrMalformed
+// and the event is dropped.
+type Handler func(ctx context.Context, event Event) error
+
+// Subscriber delivers events of the given types to a handler. Subscribe
+// blocks until ctx is done, when it returns nil, or the connection to the
+// broker fails.
+type Subscriber interface {
+	Subscribe(ctx context.Context, consumer string, eventTypes []string, handler Handler) error
+}
+
+// NATSSubscriber receives events from the JetStream stream of a NATS server
+// through a durable consumer named by the caller. The consumer remembers
+// what was acknowledged, so events published while the service was down are
+// delivered when it is back, and replicas of the service share its events.
+type NATSSubscriber struct {
+	url   string
+	stats *Stats
+}
+
+func NewNATSSubscriber(url string, stats *Stats) *NATSSubscriber {
+	return &NATSSubscriber{url: url, stats: stats}
+}
+
+// NewSubscriber returns a NATS subscriber for url that reports to stats, or
+// nil when no url is set.
+func NewSubscriber(url string, stats *Stats) Subscriber {
+	if url == "" {
+		return nil
+	}
+	return NewNATSSubscriber(url, stats)
+}
+
+func (ns *NATSSubscriber) Subscribe(ctx context.Context, consumer string, eventTypes []string, handler Handler) (err error) {
+	connectCtx, cancel := context.WithTimeout(ctx, natsTimeout)
+	defer cancel()
+	conn, js, err := connectJetStream(connectCtx, ns.url)
+	if err != nil {
+		return
+	}
+	defer conn.Close()
+
+	subjects := make([]string, 0, len(eventTypes))
+	for _, eventType := range eventTypes {
+		subjects = append(subjects, Subject(eventType))
+	}
+	cons, err := js.CreateOrUpdateConsumer(connectCtx, StreamName, jetstream.ConsumerConfig{
+		Durable:        consumer,
+		FilterSubjects: subjects,
+		DeliverPolicy:  jetstream.DeliverAllPolicy,
+		AckPolicy:      jetstream.AckExplicitPolicy,
+		AckWait:        ackWait,
+		MaxDeliver:     -1,
+	})
+	if err != nil {
+		return
+	}
+
+	consumeCtx, err := cons.Consume(func(msg jetstream.Msg) {
+		var event Event
+		if json.Unmarshal(msg.Data(), &event) != nil {
+			// not an event of the store, nothing to hand over
+			_ = msg.Term()
+			return
+		}
+		switch err := handler(ctx, event); {
+		case err == nil:
+			_ = msg.Ack()
+		case errors.Is(err, ErrMalformed):
+			_ = msg.Term()
+		default:
+			_ = msg.NakWithDelay(redeliveryDelay(msg))
+		}
+	})
+	if err != nil {
+		return
+	}
+	defer consumeCtx.Stop()
+
+	ticker := time.NewTicker(backlogInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-consumeCtx.Closed():
+			return errors.New("events: consumer " + consumer + " stopped")
+		case <-ticker.C:
+			ns.reportBacklog(ctx, cons)
+		}
+	}
+}
+
+// reportBacklog records how many events of the consumer wait in the stream
+// or were delivered but not acknowledged yet.
+func (ns *NATSSubscriber) reportBacklog(ctx context.Context, cons jetstream.Consumer) {
+	infoCtx, cancel := context.WithTimeout(ctx, natsTimeout)
+	defer cancel()
+	info, err := cons.Info(infoCtx)
+	if err != nil {
+		return
+	}
+	ns.stats.SetBacklog(info.Name, info.NumPending+uint64(info.NumAckPending))
+}
+
+// redeliveryDelay doubles the wait before each redelivery of an event that
+// failed, up to maxRedelivery.
+func redeliveryDelay(msg jetstream.Msg) time.Duration {
+	delay := time.Second
+	if meta, err := msg.Metadata(); err == nil {
+		for i := uint64(1); i < meta.NumDelivered && delay < maxRedelivery; i++ {
+			delay *= 2
+		}
+	}
+	return min(delay, maxRedelivery)
+}
@@ -0,0 +1,68 @@
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func ready(t *testing.T, checker *Checker) (int, Report) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.GET("/readyz", checker.Ready)
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+	var report Report
+	if err := json.Unmarshal(rec.Body.Bytes(), &report); err != nil {
+		t.Fatal(err)
+	}
+	return rec.Code, report
+}
+
+func TestReady(t *testing.T) {
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer upstream.Close()
+
+	checker := NewChecker()
+	checker.Add("db", func(ctx context.Context) (string, error) { return "version 5", nil })
+	code, report := ready(t, checker)
+	if code != http.StatusOK || report.Status != StatusOK || report.Checks["db"].Detail != "version 5" {
+		t.Errorf("healthy: %d %+v", code, report)
+	}
+
+	checker.Add("nats", func(ctx context.Context) (string, error) { return "", errors.New("connection closed") })
+	checker.Add("user-service", HTTP(upstream.Client(), upstream.URL+"/readyz"))
+	code, report = ready(t, checker)
+	if code != http.StatusServiceUnavailable || report.Status != StatusUnavailable {
+		t.Fatalf("unhealthy: %d %+v", code, report)
+	}
+	if report.Checks["db"].Status != StatusOK {
+		t.Errorf("db = %+v, want ok", report.Checks["db"])
+	}
+	if got := report.Checks["nats"]; got.Status != StatusUnavailable || got.Error != "connection closed" {
+		t.Errorf("nats = %+v", got)
+	}
+	if got := report.Checks["user-service"]; got.Status != StatusUnavailable || got.Error == "" {
+		t.Errorf("user-service = %+v", got)
+	}
+}
+
+func TestRunStopsAHangingCheck(t *testing.T) {
+	checker := NewChecker()
+	checker.Add("db", func(ctx context.Context) (string, error) {
+		<-ctx.Done()
+		return "", ctx.Err()
+	})
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if report := checker.Run(ctx); report.Checks["db"].Status != StatusUnavailable {
+		t.Errorf("report = %+v, want db unavailable", report)
+	}
+}
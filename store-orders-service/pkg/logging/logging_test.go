@@ -0,0 +1,136 @@
+package logging
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"github.com/gin-gonic/gin"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func decodeLines(t *testing.T, buf *bytes.Buffer) (lines []map[string]any) {
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		var record map[string]any
+		if err := json.Unmarshal([]byte(line), &record); err != nil {
+			t.Fatalf("log line %q: %v", line, err)
+		}
+		lines = append(lines, record)
+	}
+	return
+}
+
+func TestMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name   string
+		header string
+		keep   bool
+	}{
+		{name: "takes the id of the caller", header: "abc-123", keep: true},
+		{name: "makes an id when there is none", header: ""},
+		{name: "replaces an id with a line break", header: "abc\ninjected"},
+		{name: "replaces an id that is too long", header: strings.Repeat("a", 129)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			logger, err := NewWithWriter(&buf, "info")
+			if err != nil {
+				t.Fatal(err)
+			}
+			var seen string
+			router := gin.New()
+			router.Use(Middleware(logger))
+			router.GET("/orders/:id", func(c *gin.Context) {
+				seen = RequestID(c.Request.Context())
+				c.Status(http.StatusNotFound)
+			})
+
+			req := httptest.NewRequest(http.MethodGet, "/orders/1?token=secret", nil)
+			if tt.header != "" {
+				req.Header.Set(RequestIDHeader, tt.header)
+			}
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, req)
+
+			id := rec.Header().Get(RequestIDHeader)
+			if tt.keep && id != tt.header {
+				t.Errorf("request id = %q, want %q", id, tt.header)
+			}
+			if !tt.keep && (id == tt.header || len(id) != 32) {
+				t.Errorf("request id = %q, want a new one", id)
+			}
+			if seen != id {
+				t.Errorf("handler saw request id %q, response has %q", seen, id)
+			}
+
+			lines := decodeLines(t, &buf)
+			if len(lines) != 1 {
+				t.Fatalf("logged %d lines, want 1", len(lines))
+			}
+			line := lines[0]
+			if line["request_id"] != id || line["level"] != "WARN" || line["path"] != "/orders/1" || line["status"] != float64(404) {
+				t.Errorf("logged %v", line)
+			}
+			if strings.Contains(buf.String(), "secret") {
+				t.Errorf("query string was logged: %s", buf.String())
+			}
+		})
+	}
+}
+
+func TestRedact(t *testing.T) {
+	var buf bytes.Buffer
+	logger, err := NewWithWriter(&buf, "debug")
+	if err != nil {
+		t.Fatal(err)
+	}
+	logger.Info("connecting",
+		slog.String("dsn", "postgres://store:hunter2@db:5432/orders"),
+		slog.String("DB_Password", "hunter2"),
+		slog.String("X-Bootstrap-Secret", "hunter2"),
+		slog.String("authorization", "Bearer hunter2"),
+		slog.String("user", "store"),
+	)
+
+	line := decodeLines(t, &buf)[0]
+	if strings.Contains(buf.String(), "hunter2") {
+		t.Errorf("secret was logged: %s", buf.String())
+	}
+	if line["dsn"] != "postgres://store:xxxxx@db:5432/orders" || line["DB_Password"] != redacted || line["user"] != "store" {
+		t.Errorf("logged %v", line)
+	}
+}
+
+func TestNewRejectsUnknownLevel(t *testing.T) {
+	if _, err := NewWithWriter(&bytes.Buffer{}, "verbose"); err == nil {
+		t.Error("expected an error for an unknown level")
+	}
+}
+
+func TestTransport(t *testing.T) {
+	var got string
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = r.Header.Get(RequestIDHeader)
+	}))
+	defer upstream.Close()
+
+	client := &http.Client{Transport: Transport{}}
+	req, _ := http.NewRequestWithContext(WithRequestID(context.Background(), "abc-123"), http.MethodGet, upstream.URL, nil)
+	resp, err := client.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if got != "abc-123" {
+		t.Errorf("upstream got request id %q, want abc-123", got)
+	}
+	if req.Header.Get(RequestIDHeader) != "" {
+		t.Error("the request of the caller was changed")
+	}
+}
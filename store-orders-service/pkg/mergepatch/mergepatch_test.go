@@ -0,0 +1,32 @@
+package mergepatch
+
+import "testing"
+
+type partial struct {
+	Name  *string `json:"name"`
+	Note  *string `json:"note,omitempty"`
+	Count *int    `json:"count"`
+}
+
+func TestDecode(t *testing.T) {
+	var p partial
+	if err := Decode([]byte(`{"name":"box","NOTE":null}`), &p); err != nil {
+		t.Fatal(err)
+	}
+	if p.Name == nil || *p.Name != "box" {
+		t.Errorf("Name = %v, want box", p.Name)
+	}
+	if p.Note == nil || *p.Note != "" {
+		t.Errorf("Note = %v, want cleared", p.Note)
+	}
+	if p.Count != nil {
+		t.Errorf("Count = %v, want left as is", *p.Count)
+	}
+}
+
+func TestDecodeRejectsNonObjects(t *testing.T) {
+	var p partial
+	if err := Decode([]byte(`[1]`), &p); err == nil {
+		t.Error("Decode of an array succeeded")
+	}
+}
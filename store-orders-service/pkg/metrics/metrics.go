@@ -0,0 +1,142 @@
+// Package metrics holds the Prometheus instruments every service shares:
+// served requests, calls to upstream services and the database pool. They
+// live in the default registry next to the Go runtime and process metrics
+// and the business metrics of each service, all served by Handler.
+package metrics
+
+import (
+	"context"
+	"database/sql"
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/status"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	httpRequests = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "http_requests_total",
+		Help: "HTTP requests served by route and status.",
+	}, []string{"method", "route", "status"})
+	httpDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "http_request_duration_seconds",
+		Help:    "Time to serve an HTTP request by route and status.",
+		Buckets: prometheus.DefBuckets,
+	}, []string{"method", "route", "status"})
+
+	grpcRequests = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "grpc_server_requests_total",
+		Help: "gRPC calls served by method and status code.",
+	}, []string{"method", "code"})
+	grpcDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "grpc_server_request_duration_seconds",
+		Help:    "Time to serve a gRPC call by method and status code.",
+		Buckets: prometheus.DefBuckets,
+	}, []string{"method", "code"})
+
+	upstreamRequests = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "upstream_requests_total",
+		Help: "Calls to other services by upstream, method and result code.",
+	}, []string{"upstream", "method", "code"})
+	upstreamDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "upstream_request_duration_seconds",
+		Help:    "Time of a call to another service by upstream, method and result code.",
+		Buckets: prometheus.DefBuckets,
+	}, []string{"upstream", "method", "code"})
+)
+
+// Handler serves the default registry in the Prometheus text format.
+func Handler() gin.HandlerFunc {
+	return gin.WrapH(promhttp.Handler())
+}
+
+// Middleware counts and times the requests to gin routes. Requests matching
+// no route share the route "unmatched", so scanners cannot blow up the
+// number of series.
+func Middleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+		c.Next()
+
+		route := c.FullPath()
+		if route == "" {
+			route = "unmatched"
+		}
+		labels := prometheus.Labels{
+			"method": c.Request.Method,
+			"route":  route,
+			"status": strconv.Itoa(c.Writer.Status()),
+		}
+		httpRequests.With(labels).Inc()
+		httpDuration.With(labels).Observe(time.Since(start).Seconds())
+	}
+}
+
+// UnaryServerInterceptor counts and times the gRPC calls a server serves.
+func UnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		start := time.Now()
+		res, err := handler(ctx, req)
+
+		labels := prometheus.Labels{"method": info.FullMethod, "code": status.Code(err).String()}
+		grpcRequests.With(labels).Inc()
+		grpcDuration.With(labels).Observe(time.Since(start).Seconds())
+		return res, err
+	}
+}
+
+// UnaryClientInterceptor counts and times gRPC calls to other services, the
+// upstream being the gRPC service called.
+func UnaryClientInterceptor() grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		start := time.Now()
+		err := invoker(ctx, method, req, reply, cc, opts...)
+
+		service, name, _ := strings.Cut(strings.TrimPrefix(method, "/"), "/")
+		observeUpstream(service, name, status.Code(err).String(), start)
+		return err
+	}
+}
+
+// Transport is an http.RoundTripper counting and timing HTTP calls to other
+// services, the upstream being the host called.
+type Transport struct {
+	// Base makes the requests, http.DefaultTransport when nil.
+	Base http.RoundTripper
+}
+
+func (t Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	base := t.Base
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	start := time.Now()
+	resp, err := base.RoundTrip(req)
+
+	code := "error"
+	if err == nil {
+		code = strconv.Itoa(resp.StatusCode)
+	}
+	observeUpstream(req.URL.Host, req.Method, code, start)
+	return resp, err
+}
+
+func observeUpstream(upstream, method, code string, start time.Time) {
+	labels := prometheus.Labels{"upstream": upstream, "method": method, "code": code}
+	upstreamRequests.With(labels).Inc()
+	upstreamDuration.With(labels).Observe(time.Since(start).Seconds())
+}
+
+// RegisterDB exports the connection pool stats of db, such as open, in use
+// and idle connections and the time spent waiting for one, as the go_sql_*
+// metrics labelled with name.
+func RegisterDB(db *sql.DB, name string) {
+	prometheus.MustRegister(collectors.NewDBStatsCollector(db, name))
+}
@@ -0,0 +1,77 @@
+package metrics
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+func TestMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(Middleware())
+	router.GET("/metrics", Handler())
+	router.GET("/orders/:id", func(c *gin.Context) {
+		c.Status(http.StatusNotFound)
+	})
+
+	for _, path := range []string{"/orders/1", "/orders/2", "/wp-login.php"} {
+		router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, path, nil))
+	}
+
+	route := prometheus.Labels{"method": "GET", "route": "/orders/:id", "status": "404"}
+	if got := testutil.ToFloat64(httpRequests.With(route)); got != 2 {
+		t.Errorf("requests to /orders/:id = %v, want 2", got)
+	}
+	unmatched := prometheus.Labels{"method": "GET", "route": "unmatched", "status": "404"}
+	if got := testutil.ToFloat64(httpRequests.With(unmatched)); got != 1 {
+		t.Errorf("unmatched requests = %v, want 1", got)
+	}
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	body, _ := io.ReadAll(rec.Body)
+	want := `http_requests_total{method="GET",route="/orders/:id",status="404"} 2`
+	if !strings.Contains(string(body), want) {
+		t.Errorf("/metrics does not have %s", want)
+	}
+	if strings.Contains(string(body), "/wp-login.php") {
+		t.Error("/metrics has a series for an unmatched path")
+	}
+}
+
+func TestTransport(t *testing.T) {
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer upstream.Close()
+	host := strings.TrimPrefix(upstream.URL, "http://")
+
+	client := &http.Client{Transport: Transport{}}
+	resp, err := client.Get(upstream.URL + "/products/1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+
+	labels := prometheus.Labels{"upstream": host, "method": "GET", "code": "503"}
+	if got := testutil.ToFloat64(upstreamRequests.With(labels)); got != 1 {
+		t.Errorf("upstream calls = %v, want 1", got)
+	}
+
+	// a call that never got an answer counts as an error
+	closed := &url.URL{Scheme: "http", Host: "127.0.0.1:1"}
+	if _, err = client.Get(closed.String()); err == nil {
+		t.Fatal("expected a connection error")
+	}
+	labels = prometheus.Labels{"upstream": closed.Host, "method": "GET", "code": "error"}
+	if got := testutil.ToFloat64(upstreamRequests.With(labels)); got != 1 {
+		t.Errorf("failed upstream calls = %v, want 1", got)
+	}
+}
@@ -0,0 +1,168 @@
+package money
+
+import (
+	"encoding/json"
+	"errors"
+	"math"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		amount, currency string
+		want             Money
+		err              error
+	}{
+		{"1999.9", "KZT", New(199990, "KZT"), nil},
+		{"1999.90", "KZT", New(199990, "KZT"), nil},
+		{" 12 ", "USD", New(1200, "USD"), nil},
+		{".5", "USD", New(50, "USD"), nil},
+		{"5.", "USD", New(500, "USD"), nil},
+		{"+1.01", "EUR", New(101, "EUR"), nil},
+		{"-0.01", "EUR", New(-1, "EUR"), nil},
+		{"1500", "JPY", New(1500, "JPY"), nil},
+		{"92233720368547758.07", "USD", New(math.MaxInt64, "USD"), nil},
+		{"1.999", "USD", Money{}, ErrorInvalidAmount},
+		{"1.5", "JPY", Money{}, ErrorInvalidAmount},
+		{"", "USD", Money{}, ErrorInvalidAmount},
+		{".", "USD", Money{}, ErrorInvalidAmount},
+		{"-", "USD", Money{}, ErrorInvalidAmount},
+		{"1e3", "USD", Money{}, ErrorInvalidAmount},
+		{"1,50", "USD", Money{}, ErrorInvalidAmount},
+		{"--1", "USD", Money{}, ErrorInvalidAmount},
+		{"92233720368547758.08", "USD", Money{}, ErrorInvalidAmount},
+		{"1", "usd", Money{}, ErrorInvalidCurrency},
+		{"1", "XXX", Money{}, ErrorInvalidCurrency},
+	}
+	for _, tt := range tests {
+		got, err := Parse(tt.amount, tt.currency)
+		if !errors.Is(err, tt.err) || got != tt.want {
+			t.Errorf("Parse(%q, %q) = %v, %v, want %v, %v", tt.amount, tt.currency, got, err, tt.want, tt.err)
+		}
+	}
+}
+
+func TestDecimal(t *testing.T) {
+	tests := []struct {
+		m    Money
+		want string
+	}{
+		{New(199990, "KZT"), "1999.90"},
+		{New(5, "USD"), "0.05"},
+		{New(0, "USD"), "0.00"},
+		{New(-105, "EUR"), "-1.05"},
+		{New(-5, "EUR"), "-0.05"},
+		{New(1500, "JPY"), "1500"},
+		{New(-3, "KRW"), "-3"},
+		{New(math.MinInt64, "USD"), "-92233720368547758.08"},
+	}
+	for _, tt := range tests {
+		if got := tt.m.Decimal(); got != tt.want {
+			t.Errorf("%#v.Decimal() = %q, want %q", tt.m, got, tt.want)
+		}
+	}
+}
+
+func TestArithmetic(t *testing.T) {
+	tests := []struct {
+		name string
+		op   func() (Money, error)
+		want Money
+		err  error
+	}{
+		{"add", func() (Money, error) { return New(150, "USD").Add(New(275, "USD")) }, New(425, "USD"), nil},
+		{"add negative", func() (Money, error) { return New(150, "USD").Add(New(-275, "USD")) }, New(-125, "USD"), nil},
+		{"add currency mismatch", func() (Money, error) { return New(1, "USD").Add(New(1, "EUR")) }, New(1, "USD"), ErrorCurrencyMismatch},
+		{"add up to max", func() (Money, error) { return New(math.MaxInt64-1, "USD").Add(New(1, "USD")) }, New(math.MaxInt64, "USD"), nil},
+		{"add overflow", func() (Money, error) { return New(math.MaxInt64, "USD").Add(New(1, "USD")) }, New(math.MaxInt64, "USD"), ErrorInvalidAmount},
+		{"add underflow", func() (Money, error) { return New(math.MinInt64, "USD").Add(New(-1, "USD")) }, New(math.MinInt64, "USD"), ErrorInvalidAmount},
+		{"sub", func() (Money, error) { return New(100, "KZT").Sub(New(250, "KZT")) }, New(-150, "KZT"), nil},
+		{"sub currency mismatch", func() (Money, error) { return New(1, "KZT").Sub(New(1, "RUB")) }, New(1, "KZT"), ErrorCurrencyMismatch},
+		{"sub underflow", func() (Money, error) { return New(math.MinInt64, "USD").Sub(New(1, "USD")) }, New(math.MinInt64, "USD"), ErrorInvalidAmount},
+		{"sub min", func() (Money, error) { return New(0, "USD").Sub(New(math.MinInt64, "USD")) }, New(0, "USD"), ErrorInvalidAmount},
+		{"sub min from negative", func() (Money, error) { return New(-1, "USD").Sub(New(math.MinInt64, "USD")) }, New(math.MaxInt64, "USD"), nil},
+		{"mul", func() (Money, error) { return New(1999, "USD").Mul(3) }, New(5997, "USD"), nil},
+		{"mul zero", func() (Money, error) { return New(math.MaxInt64, "USD").Mul(0) }, New(0, "USD"), nil},
+		{"mul negative", func() (Money, error) { return New(25, "USD").Mul(-4) }, New(-100, "USD"), nil},
+		{"mul overflow", func() (Money, error) { return New(math.MaxInt64/2+1, "USD").Mul(2) }, New(math.MaxInt64/2+1, "USD"), ErrorInvalidAmount},
+		{"mul min by minus one", func() (Money, error) { return New(math.MinInt64, "USD").Mul(-1) }, New(math.MinInt64, "USD"), ErrorInvalidAmount},
+		{"mul minus one by min", func() (Money, error) { return New(-1, "USD").Mul(math.MinInt64) }, New(-1, "USD"), ErrorInvalidAmount},
+	}
+	for _, tt := range tests {
+		got, err := tt.op()
+		if !errors.Is(err, tt.err) || got != tt.want {
+			t.Errorf("%s = %v, %v, want %v, %v", tt.name, got, err, tt.want, tt.err)
+		}
+	}
+}
+
+func TestConvert(t *testing.T) {
+	tests := []struct {
+		m        Money
+		currency string
+		rate     string
+		want     Money
+		err      error
+	}{
+		{New(1000, "USD"), "KZT", "478.25", New(478250, "KZT"), nil},
+		// 0.01 USD * 0.333 = 0.00333 EUR, rounded to 0.00
+		{New(1, "USD"), "EUR", "0.333", New(0, "EUR"), nil},
+		// 0.01 USD * 0.5 = 0.005 EUR, half rounds away from zero
+		{New(1, "USD"), "EUR", "0.5", New(1, "EUR"), nil},
+		{New(-1, "USD"), "EUR", "0.5", New(-1, "EUR"), nil},
+		{New(12345, "USD"), "JPY", "150.5", New(18579, "JPY"), nil},
+		{New(100, "JPY"), "USD", "0.0066", New(66, "USD"), nil},
+		{New(1, "USD"), "KZT", "0", Money{}, ErrorInvalidRate},
+		{New(1, "USD"), "KZT", "-1", Money{}, ErrorInvalidRate},
+		{New(1, "USD"), "KZT", "abc", Money{}, ErrorInvalidRate},
+		{New(1, "USD"), "XXX", "1", Money{}, ErrorInvalidCurrency},
+		{New(math.MaxInt64, "USD"), "KZT", "2", Money{}, ErrorInvalidAmount},
+	}
+	for _, tt := range tests {
+		got, err := Convert(tt.m, tt.currency, tt.rate)
+		if !errors.Is(err, tt.err) || got != tt.want {
+			t.Errorf("Convert(%v, %s, %s) = %v, %v, want %v, %v", tt.m, tt.currency, tt.rate, got, err, tt.want, tt.err)
+		}
+	}
+}
+
+func TestInvertRate(t *testing.T) {
+	got, err := InvertRate("478.25", 10)
+	if err != nil || got != "0.0020909566" {
+		t.Errorf("InvertRate(478.25) = %q, %v", got, err)
+	}
+	if _, err = InvertRate("0", 10); !errors.Is(err, ErrorInvalidRate) {
+		t.Errorf("InvertRate(0) error = %v, want %v", err, ErrorInvalidRate)
+	}
+}
+
+func TestJSON(t *testing.T) {
+	body, err := json.Marshal(New(199990, "KZT"))
+	if err != nil || string(body) != `{"amount":"1999.90","currency":"KZT"}` {
+		t.Errorf("Marshal = %s, %v", body, err)
+	}
+
+	tests := []struct {
+		body string
+		want Money
+		err  error
+	}{
+		{`{"amount":"1999.90","currency":"KZT"}`, New(199990, "KZT"), nil},
+		{`{"amount":1999.9,"currency":"kzt"}`, New(199990, "KZT"), nil},
+		{`{"amount":0.1,"currency":"USD"}`, New(10, "USD"), nil},
+		{`{"amount":"12","currency":"JPY"}`, New(12, "JPY"), nil},
+		{`null`, Money{}, nil},
+		{`{"currency":"USD"}`, Money{}, ErrorInvalidAmount},
+		{`{"amount":null,"currency":"USD"}`, Money{}, ErrorInvalidAmount},
+		{`{"amount":"1.001","currency":"USD"}`, Money{}, ErrorInvalidAmount},
+		{`{"amount":1e2,"currency":"USD"}`, Money{}, ErrorInvalidAmount},
+		{`{"amount":"1","currency":"XXX"}`, Money{}, ErrorInvalidCurrency},
+	}
+	for _, tt := range tests {
+		var got Money
+		err := json.Unmarshal([]byte(tt.body), &got)
+		if !errors.Is(err, tt.err) || got != tt.want {
+			t.Errorf("Unmarshal(%s) = %v, %v, want %v, %v", tt.body, got, err, tt.want, tt.err)
+		}
+	}
+}
@@ -0,0 +1,78 @@
+package settings
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+type testConfig struct {
+	Host     string        `yaml:"host" env:"TEST_HOST" required:"true"`
+	Port     int           `yaml:"port" env:"TEST_PORT"`
+	Timeout  time.Duration `yaml:"timeout" env:"TEST_TIMEOUT"`
+	Password string        `yaml:"password" env:"TEST_PASSWORD" secret:"true"`
+}
+
+func TestLoad(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(file, []byte("host: from-file\nport: 1\ntimeout: 5s\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("TEST_PORT", "2")
+	t.Setenv("TEST_TIMEOUT", "")
+
+	cfg := testConfig{Timeout: time.Second}
+	printConfig, err := Load(&cfg, []string{"-config", file, "-timeout", "7s", "-print-config"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := testConfig{Host: "from-file", Port: 2, Timeout: 7 * time.Second}
+	if cfg != want || !printConfig {
+		t.Errorf("cfg = %+v, print %v, want %+v and print", cfg, printConfig, want)
+	}
+}
+
+func TestLoadReportsEveryError(t *testing.T) {
+	t.Setenv("TEST_PORT", "eighty")
+	var cfg testConfig
+	_, err := Load(&cfg, []string{"-timeout", "soon"})
+	if err == nil {
+		t.Fatal("expected an error")
+	}
+	for _, want := range []string{"env TEST_PORT", "flag -timeout", "host is required"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("error %q does not mention %q", err, want)
+		}
+	}
+}
+
+func TestPrintMasksSecrets(t *testing.T) {
+	var out strings.Builder
+	if err := Print(&out, testConfig{Host: "postgres://store:hunter2@db/orders", Password: "hunter2"}); err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(out.String(), "hunter2") {
+		t.Errorf("secret was printed:\n%s", out.String())
+	}
+}
+
+func TestSecret(t *testing.T) {
+	tests := []struct {
+		value string
+		ok    bool
+	}{
+		{value: "", ok: true},
+		{value: "9c1d0d5b7f2a4e6c8b3a1f0e9d7c5b3a", ok: true},
+		{value: "too-short"},
+		{value: "local-development-token-secret-change-me"},
+		{value: "ChangeMe-ChangeMe-ChangeMe-ChangeMe"},
+		{value: "an-example-secret-that-is-long-enough"},
+	}
+	for _, tt := range tests {
+		if err := Secret("token_secret", tt.value, 32); (err == nil) != tt.ok {
+			t.Errorf("Secret(%q) = %v, want ok %v", tt.value, err, tt.ok)
+		}
+	}
+}
@@ -0,0 +1,95 @@
+package tracing
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/otel"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"net/http"
+	"net/http/httptest"
+	"order-service/pkg/logging"
+	"testing"
+)
+
+const (
+	callerTraceID = "4bf92f3577b34da6a3ce929d0e0e4736"
+	callerSpanID  = "00f067aa0ba902b7"
+)
+
+func TestTraceContextPropagation(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	if _, err := Setup(context.Background(), "order-service", ExporterNone, ""); err != nil {
+		t.Fatal(err)
+	}
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	otel.SetTracerProvider(provider)
+	t.Cleanup(func() { provider.Shutdown(context.Background()) })
+
+	var upstreamTraceparent string
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		upstreamTraceparent = r.Header.Get("traceparent")
+	}))
+	defer upstream.Close()
+
+	var logs bytes.Buffer
+	logger, err := logging.NewWithWriter(&logs, "info")
+	if err != nil {
+		t.Fatal(err)
+	}
+	client := &http.Client{Transport: Transport(nil)}
+	router := gin.New()
+	router.Use(Middleware("order-service"))
+	router.GET("/orders/:id", func(c *gin.Context) {
+		req, _ := http.NewRequestWithContext(c.Request.Context(), http.MethodGet, upstream.URL, nil)
+		resp, err := client.Do(req)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		resp.Body.Close()
+		logger.InfoContext(c.Request.Context(), "order read")
+		c.Status(http.StatusOK)
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/orders/1", nil)
+	req.Header.Set("traceparent", "00-"+callerTraceID+"-"+callerSpanID+"-01")
+	router.ServeHTTP(httptest.NewRecorder(), req)
+
+	spans := recorder.Ended()
+	if len(spans) != 2 {
+		t.Fatalf("recorded %d spans, want the client and the server span", len(spans))
+	}
+	clientSpan, serverSpan := spans[0], spans[1]
+	if got := serverSpan.SpanContext().TraceID().String(); got != callerTraceID {
+		t.Errorf("server span trace id = %s, want the trace of the caller", got)
+	}
+	if got := serverSpan.Parent().SpanID().String(); got != callerSpanID {
+		t.Errorf("server span parent = %s, want the span of the caller", got)
+	}
+	if clientSpan.Parent().SpanID() != serverSpan.SpanContext().SpanID() {
+		t.Error("the client span is not a child of the server span")
+	}
+
+	want := "00-" + callerTraceID + "-" + clientSpan.SpanContext().SpanID().String() + "-01"
+	if upstreamTraceparent != want {
+		t.Errorf("upstream got traceparent %q, want %q", upstreamTraceparent, want)
+	}
+
+	var line map[string]any
+	if err = json.Unmarshal(logs.Bytes(), &line); err != nil {
+		t.Fatal(err)
+	}
+	if line["trace_id"] != callerTraceID {
+		t.Errorf("log line has trace_id %v, want %s", line["trace_id"], callerTraceID)
+	}
+}
+
+func TestSetupRejectsUnknownExporter(t *testing.T) {
+	if _, err := Setup(context.Background(), "order-service", "zipkin", ""); err == nil {
+		t.Error("expected an error for an unknown exporter")
+	}
+}
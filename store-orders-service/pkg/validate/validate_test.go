@@ -0,0 +1,53 @@
+package validate
+
+import (
+	"errors"
+	"net/http"
+	"order-service/pkg/apperror"
+	"order-service/pkg/money"
+	"reflect"
+	"testing"
+)
+
+type item struct {
+	ProductID string `json:"product_id" validate:"required,uuid"`
+	Quantity  int    `json:"quantity" validate:"gt=0"`
+}
+
+type request struct {
+	Country string      `json:"country" validate:"omitempty,country"`
+	Total   money.Money `json:"total" validate:"money,positive"`
+	Items   []item      `json:"items" validate:"required,min=1,dive"`
+	Note    string      `json:"note" validate:"max=5"`
+}
+
+func TestStruct(t *testing.T) {
+	valid := request{
+		Country: "KZ",
+		Total:   money.New(1250, "USD"),
+		Items:   []item{{ProductID: "7f8c2a4e-1b3d-4f5a-9c6e-8d7b0a1f2e3c", Quantity: 1}},
+	}
+	if err := Struct(&valid); err != nil {
+		t.Fatalf("valid request: %v", err)
+	}
+
+	err := Struct(&request{
+		Country: "kz",
+		Total:   money.New(0, "USD"),
+		Items:   []item{{ProductID: "p1", Quantity: 0}},
+		Note:    "too long",
+	})
+	if !errors.Is(err, ErrorFailed) || apperror.From(err).Status != http.StatusUnprocessableEntity {
+		t.Fatalf("error = %v, want %v served with 422", err, ErrorFailed)
+	}
+	want := []Violation{
+		{Field: "country", Rule: "country", Message: "must be a two letter ISO 3166 country code"},
+		{Field: "total", Rule: "positive", Message: "must be more than zero"},
+		{Field: "items[0].product_id", Rule: "uuid", Message: "must be a UUID"},
+		{Field: "items[0].quantity", Rule: "gt", Message: "must be more than 0"},
+		{Field: "note", Rule: "max", Message: "must be at most 5 characters"},
+	}
+	if got := Violations(err); !reflect.DeepEqual(got, want) {
+		t.Errorf("violations =\n%+v\nwant\n%+v", got, want)
+	}
+}
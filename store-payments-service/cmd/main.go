@@ -1,10 +1,16 @@
 package main
 
 import (
+	"context"
+	"errors"
+	"flag"
 	"log"
+	"log/slog"
 	"os"
 	"payment-service/internal/config"
 	"payment-service/internal/di"
+	"payment-service/pkg/logging"
+	"payment-service/pkg/tracing"
 
 	_ "payment-service/docs"
 )
@@ -12,18 +18,55 @@ import (
 // @title Payment Service API
 // @version 1.0
 // @description API Server for Payment Service
+// @securityDefinitions.apikey BearerAuth
+// @in header
+// @name Authorization
+// @description Access token issued by POST /users/{id}/token, as "Bearer <token>"
 func main() {
-	config, configErr := config.LoadConfig()
+	args := os.Args[1:]
+	if len(args) > 0 && args[0] == "migrate" {
+		if err := migrate(args[1:]); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+
+	config, configErr := config.LoadConfig(args)
+	if errors.Is(configErr, flag.ErrHelp) {
+		return
+	}
+	// the settings are printed even when they are invalid, to show why
+	if config.PrintConfig {
+		if err := config.Print(os.Stdout); err != nil {
+			log.Fatal(err)
+		}
+	}
 	if configErr != nil {
-		log.Fatal(configErr)
+		log.Fatalf("invalid configuration:\n%v", configErr)
+	}
+	if config.PrintConfig {
+		return
+	}
+
+	logger, logErr := logging.New(config.LogLevel)
+	if logErr != nil {
+		log.Fatal(logErr)
+	}
+	slog.SetDefault(logger)
+
+	shutdown, traceErr := tracing.Setup(context.Background(), "payment-service", config.TraceExporter, config.OTLPEndpoint)
+	if traceErr != nil {
+		logger.Error("failed to set up tracing", "error", traceErr)
+		os.Exit(1)
 	}
+	defer shutdown(context.Background())
 
-	infoLog := log.New(os.Stdout, "INFO: ", log.Ldate|log.Ltime)
-	errorLog := log.New(os.Stderr, "ERROR: ", log.Ldate|log.Ltime|log.Lshortfile)
 	server, diErr := di.InitializeAPI(config)
 	if diErr != nil {
-		log.Fatal(diErr)
-	} else {
-		server.Run(infoLog, errorLog)
+		logger.Error("failed to initialize", "error", diErr)
+		os.Exit(1)
+	} else if runErr := server.Run(logger); runErr != nil {
+		logger.Error("server stopped", "error", runErr)
+		os.Exit(1)
 	}
 }
@@ -6,6 +6,8 @@ import (
 	"net/http"
 	"payment-service/internal/domain/payment"
 	interfaces "payment-service/internal/service/interface"
+	"payment-service/pkg/apperror"
+	"payment-service/pkg/auth"
 	"payment-service/pkg/response"
 )
 
@@ -21,43 +23,41 @@ func NewPaymentHandler(service interfaces.PaymentService) *PaymentHandler {
 
 // CreatePayment godoc
 // @Summary Create a new payment
-// @Description Create a new payment with the input payload
+// @Description Create a new payment with the input payload. A customer pays for itself, paying for another user requires the payments:write permission.
 // @Tags payments
 // @Accept json
 // @Produce json
+// @Security BearerAuth
 // @Param payment body payment.Request true "Payment Request"
 // @Success 201 {object} response.Response
 // @Failure 400 {object} response.Response
+// @Failure 401 {object} response.Response
+// @Failure 403 {object} response.Response
+// @Failure 409 {object} response.Response
+// @Failure 422 {object} response.Response
 // @Failure 500 {object} response.Response
+// @Failure 502 {object} response.Response
+// @Failure 503 {object} response.Response
 // @Router /payments [post]
 func (th *PaymentHandler) CreatePayment(c *gin.Context) {
 	req := payment.Request{}
-	if err := c.BindJSON(&req); err != nil {
-		errRes := response.ClientResponse(http.StatusBadRequest, "fields provided are wrong", nil, err.Error())
-		c.JSON(http.StatusBadRequest, errRes)
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(apperror.InvalidBody(err))
 		return
 	}
 
 	if err := req.Validate(); err != nil {
-		errRes := response.ClientResponse(http.StatusBadRequest, "fields provided are wrong", nil, err.Error())
-		c.JSON(http.StatusBadRequest, errRes)
+		c.Error(err)
+		return
+	}
+	if err := auth.Authorize(c.Request.Context(), req.UserID, "payments:write"); err != nil {
+		c.Error(err)
 		return
 	}
 
 	res, err := th.paymentService.CreatePayment(c.Request.Context(), req)
 	if err != nil {
-		if errors.Is(err, payment.ErrorNotFound) {
-			errRes := response.ClientResponse(http.StatusBadRequest, "fields must be unique", nil, err.Error())
-			c.JSON(http.StatusBadRequest, errRes)
-			return
-		}
-		if errors.Is(err, payment.ErrorInvalidDate) {
-			errRes := response.ClientResponse(http.StatusBadRequest, "fields provided are wrong", nil, err.Error())
-			c.JSON(http.StatusBadRequest, errRes)
-			return
-		}
-		errRes := response.ClientResponse(http.StatusInternalServerError, "failed to create payment", nil, err.Error())
-		c.JSON(http.StatusInternalServerError, errRes)
+		c.Error(err)
 		return
 	}
 	successRes := response.ClientResponse(http.StatusCreated, "the payment was successfully created", res, nil)
@@ -66,10 +66,13 @@ func (th *PaymentHandler) CreatePayment(c *gin.Context) {
 
 // ListPayments godoc
 // @Summary List all payments
-// @Description Get a list of payments
+// @Description Get a list of payments. Requires the payments:read:any permission.
 // @Tags payments
 // @Produce json
+// @Security BearerAuth
 // @Success 200 {object} response.Response
+// @Failure 401 {object} response.Response
+// @Failure 403 {object} response.Response
 // @Failure 500 {object} response.Response
 // @Router /payments [get]
 func (th *PaymentHandler) ListPayments(c *gin.Context) {
@@ -80,8 +83,7 @@ func (th *PaymentHandler) ListPayments(c *gin.Context) {
 			c.JSON(http.StatusOK, errRes)
 			return
 		}
-		errRes := response.ClientResponse(http.StatusInternalServerError, "failed to list tasks", nil, err.Error())
-		c.JSON(http.StatusInternalServerError, errRes)
+		c.Error(err)
 		return
 	}
 
@@ -91,81 +93,137 @@ func (th *PaymentHandler) ListPayments(c *gin.Context) {
 
 // GetPayment godoc
 // @Summary Get a payment by ID
-// @Description Get details of a payment by its ID
+// @Description Get details of a payment by its ID. A customer reads its own payments, any other requires the payments:read:any permission.
 // @Tags payments
 // @Produce json
+// @Security BearerAuth
 // @Param id path string true "Payment ID"
 // @Success 200 {object} response.Response
+// @Header 200 {string} ETag "Version of the payment"
+// @Failure 401 {object} response.Response
+// @Failure 403 {object} response.Response
+// @Failure 404 {object} response.Response
 // @Failure 500 {object} response.Response
 // @Router /payments/{id} [get]
 func (th *PaymentHandler) GetPayment(c *gin.Context) {
 	id := c.Param("id")
 	res, err := th.paymentService.GetPayment(c.Request.Context(), id)
 	if err != nil {
-		if errors.Is(err, payment.ErrorNotFound) {
-			errRes := response.ClientResponse(http.StatusOK, "no tasks found", "", nil)
-			c.JSON(http.StatusOK, errRes)
-			return
-		}
-		errRes := response.ClientResponse(http.StatusInternalServerError, "failed to get payment", nil, err.Error())
-		c.JSON(http.StatusInternalServerError, errRes)
+		c.Error(err)
 		return
 	}
+	if err = auth.Authorize(c.Request.Context(), res.UserID, "payments:read:any"); err != nil {
+		c.Error(err)
+		return
+	}
+	setETag(c, res.Version)
 	successRes := response.ClientResponse(http.StatusOK, "the payment details", res, nil)
 	c.JSON(http.StatusOK, successRes)
 }
 
 // UpdatePayment godoc
 // @Summary Update a payment by ID
-// @Description Update details of a payment by its ID
+// @Description Overwrite a payment by its ID. With If-Match only the version the ETag names is updated. Requires the payments:write permission.
 // @Tags payments
 // @Accept json
 // @Produce json
+// @Security BearerAuth
 // @Param id path string true "Payment ID"
+// @Param If-Match header string false "ETag of the payment"
 // @Param payment body payment.Request true "Task Request"
 // @Success 200 {object} response.Response
+// @Header 200 {string} ETag "Version of the payment"
 // @Failure 400 {object} response.Response
+// @Failure 401 {object} response.Response
+// @Failure 403 {object} response.Response
 // @Failure 404 {object} response.Response
+// @Failure 412 {object} response.Response
+// @Failure 422 {object} response.Response
 // @Failure 500 {object} response.Response
+// @Failure 503 {object} response.Response
 // @Router /payments/{id} [put]
 func (th *PaymentHandler) UpdatePayment(c *gin.Context) {
 	id := c.Param("id")
+	version, err := ifMatch(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
 	req := payment.Request{}
-	if err := c.BindJSON(&req); err != nil {
-		errRes := response.ClientResponse(http.StatusBadRequest, "fields provided are wrong", nil, err.Error())
-		c.JSON(http.StatusBadRequest, errRes)
+	if err = c.ShouldBindJSON(&req); err != nil {
+		c.Error(apperror.InvalidBody(err))
 		return
 	}
 
-	if err := req.Validate(); err != nil {
-		errRes := response.ClientResponse(http.StatusBadRequest, "fields provided are wrong", nil, err.Error())
-		c.JSON(http.StatusBadRequest, errRes)
+	if err = req.Validate(); err != nil {
+		c.Error(err)
 		return
 	}
 
-	err := th.paymentService.UpdatePayment(c.Request.Context(), id, req)
+	res, err := th.paymentService.UpdatePayment(c.Request.Context(), id, version, req)
 	if err != nil {
-		if errors.Is(err, payment.ErrorNotFound) {
-			errRes := response.ClientResponse(http.StatusNotFound, "payment not found", nil, err.Error())
-			c.JSON(http.StatusNotFound, errRes)
-			return
+		c.Error(err)
+		return
+	}
+	setETag(c, res.Version)
+	successRes := response.ClientResponse(http.StatusOK, "the payment was successfully updated", res, nil)
+	c.JSON(http.StatusOK, successRes)
+}
 
-		}
-		errRes := response.ClientResponse(http.StatusInternalServerError, "failed to update payment", nil, err.Error())
-		c.JSON(http.StatusInternalServerError, errRes)
+// PatchPayment godoc
+// @Summary Patch a payment by ID
+// @Description Change the fields of a payment given in a JSON merge patch. With If-Match only the version the ETag names is updated. Requires the payments:write permission.
+// @Tags payments
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Payment ID"
+// @Param If-Match header string false "ETag of the payment"
+// @Param payment body payment.Patch true "Payment Patch"
+// @Success 200 {object} response.Response
+// @Header 200 {string} ETag "Version of the payment"
+// @Failure 400 {object} response.Response
+// @Failure 401 {object} response.Response
+// @Failure 403 {object} response.Response
+// @Failure 404 {object} response.Response
+// @Failure 412 {object} response.Response
+// @Failure 422 {object} response.Response
+// @Failure 500 {object} response.Response
+// @Failure 503 {object} response.Response
+// @Router /payments/{id} [patch]
+func (th *PaymentHandler) PatchPayment(c *gin.Context) {
+	id := c.Param("id")
+	version, err := ifMatch(c)
+	if err != nil {
+		c.Error(err)
 		return
 	}
-	successRes := response.ClientResponse(http.StatusOK, "the payment was successfully updated", nil, nil)
+	patch := payment.Patch{}
+	if err = bindPatch(c, &patch); err != nil {
+		c.Error(err)
+		return
+	}
+
+	res, err := th.paymentService.PatchPayment(c.Request.Context(), id, version, patch)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	setETag(c, res.Version)
+	successRes := response.ClientResponse(http.StatusOK, "the payment was successfully updated", res, nil)
 	c.JSON(http.StatusOK, successRes)
 }
 
 // DeletePayment godoc
 // @Summary Delete a payment by ID
-// @Description Delete a payment by its ID
+// @Description Delete a payment by its ID. Requires the payments:write permission.
 // @Tags payments
 // @Produce json
+// @Security BearerAuth
 // @Param id path string true "Payment ID"
 // @Success 200 {object} response.Response
+// @Failure 401 {object} response.Response
+// @Failure 403 {object} response.Response
 // @Failure 404 {object} response.Response
 // @Failure 500 {object} response.Response
 // @Router /payments/{id} [delete]
@@ -173,27 +231,51 @@ func (th *PaymentHandler) DeletePayment(c *gin.Context) {
 	id := c.Param("id")
 	err := th.paymentService.DeletePayment(c.Request.Context(), id)
 	if err != nil {
-		if errors.Is(err, payment.ErrorNotFound) {
-			errRes := response.ClientResponse(http.StatusNotFound, "payment not found", nil, err.Error())
-			c.JSON(http.StatusNotFound, errRes)
-			return
-		}
-		errRes := response.ClientResponse(http.StatusInternalServerError, "failed to delete payment", nil, err.Error())
-		c.JSON(http.StatusInternalServerError, errRes)
+		c.Error(err)
 		return
 	}
 	successRes := response.ClientResponse(http.StatusOK, "the payment was successfully deleted", nil, nil)
 	c.JSON(http.StatusOK, successRes)
 }
 
+// RestorePayment godoc
+// @Summary Restore a deleted payment
+// @Description Bring a deleted payment back, for admins
+// @Tags payments
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Payment ID"
+// @Success 200 {object} response.Response
+// @Header 200 {string} ETag "Version of the payment"
+// @Failure 401 {object} response.Response
+// @Failure 403 {object} response.Response
+// @Failure 404 {object} response.Response
+// @Failure 409 {object} response.Response
+// @Failure 500 {object} response.Response
+// @Router /payments/{id}/restore [post]
+func (th *PaymentHandler) RestorePayment(c *gin.Context) {
+	id := c.Param("id")
+	res, err := th.paymentService.RestorePayment(c.Request.Context(), id)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	setETag(c, res.Version)
+	successRes := response.ClientResponse(http.StatusOK, "the payment was successfully restored", res, nil)
+	c.JSON(http.StatusOK, successRes)
+}
+
 // SearchPayments godoc
 // @Summary Search payments
-// @Description Search payments by filter and value
+// @Description Search payments by filter and value. Requires the payments:read:any permission.
 // @Tags payments
 // @Produce json
+// @Security BearerAuth
 // @Param filter query string true "Filter"
 // @Param value query string true "Value"
 // @Success 200 {object} response.Response
+// @Failure 401 {object} response.Response
+// @Failure 403 {object} response.Response
 // @Failure 500 {object} response.Response
 // @Router /payments/search [get]
 func (th *PaymentHandler) SearchPayments(c *gin.Context) {
@@ -206,10 +288,34 @@ func (th *PaymentHandler) SearchPayments(c *gin.Context) {
 			c.JSON(http.StatusOK, errRes)
 			return
 		}
-		errRes := response.ClientResponse(http.StatusInternalServerError, "failed to search payments", nil, err.Error())
-		c.JSON(http.StatusInternalServerError, errRes)
+		c.Error(err)
 		return
 	}
 	successRes := response.ClientResponse(http.StatusOK, "the tasks list", res, nil)
 	c.JSON(http.StatusOK, successRes)
 }
+
+// RefundPayment godoc
+// @Summary Refund a payment by ID
+// @Description Refund a successful payment, the order service marks its order refunded. Requires the payments:write permission.
+// @Tags payments
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Payment ID"
+// @Success 200 {object} response.Response
+// @Failure 401 {object} response.Response
+// @Failure 403 {object} response.Response
+// @Failure 404 {object} response.Response
+// @Failure 409 {object} response.Response
+// @Failure 500 {object} response.Response
+// @Router /payments/{id}/refund [post]
+func (th *PaymentHandler) RefundPayment(c *gin.Context) {
+	id := c.Param("id")
+	err := th.paymentService.RefundPayment(c.Request.Context(), id)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	successRes := response.ClientResponse(http.StatusOK, "the payment was successfully refunded", nil, nil)
+	c.JSON(http.StatusOK, successRes)
+}
@@ -3,15 +3,22 @@ package routes
 import (
 	"github.com/gin-gonic/gin"
 	"payment-service/internal/api/handler"
+	"payment-service/pkg/auth"
 )
 
 func InitRoutes(router *gin.RouterGroup, paymentHandler *handler.PaymentHandler) {
-
-	router.GET("/", paymentHandler.ListPayments)
+	// a customer pays and reads its own payments, the handlers check whose
+	// they are
+	readAny := auth.RequirePermission("payments:read:any")
+	write := auth.RequirePermission("payments:write")
+	router.GET("/", readAny, paymentHandler.ListPayments)
 	router.POST("/", paymentHandler.CreatePayment)
 	router.GET("/:id", paymentHandler.GetPayment)
-	router.PUT("/:id", paymentHandler.UpdatePayment)
-	router.DELETE("/:id", paymentHandler.DeletePayment)
-	router.GET("/search", paymentHandler.SearchPayments)
+	router.PUT("/:id", write, paymentHandler.UpdatePayment)
+	router.PATCH("/:id", write, paymentHandler.PatchPayment)
+	router.POST("/:id/refund", write, paymentHandler.RefundPayment)
+	router.DELETE("/:id", write, paymentHandler.DeletePayment)
+	router.POST("/:id/restore", auth.RequirePermission("payments:restore"), paymentHandler.RestorePayment)
+	router.GET("/search", readAny, paymentHandler.SearchPayments)
 
 }
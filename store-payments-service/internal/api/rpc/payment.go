@@ -0,0 +1,151 @@
+package rpc
+
+import (
+	"context"
+	"google.golang.org/protobuf/types/known/timestamppb"
+	"payment-service/internal/domain/payment"
+	services "payment-service/internal/service/interface"
+	"payment-service/pkg/auth"
+	"payment-service/pkg/money"
+	"payment-service/pkg/pb/paymentpb"
+)
+
+// PaymentServer checks the permissions the REST routes check, a customer
+// pays and reads its own payments only.
+type PaymentServer struct {
+	paymentpb.UnimplementedPaymentServiceServer
+	paymentService services.PaymentService
+}
+
+func NewPaymentServer(paymentService services.PaymentService) *PaymentServer {
+	return &PaymentServer{paymentService: paymentService}
+}
+
+func (ps *PaymentServer) CreatePayment(ctx context.Context, in *paymentpb.CreatePaymentRequest) (*paymentpb.CreatePaymentResponse, error) {
+	amount, err := parseMoney(in.GetAmount())
+	if err != nil {
+		return nil, statusError(payment.ErrorInvalidAmount)
+	}
+	req := payment.Request{
+		UserID:  in.GetUserId(),
+		OrderID: in.GetOrderId(),
+		Amount:  amount,
+	}
+	if err = req.Validate(); err != nil {
+		return nil, statusError(err)
+	}
+	if err = auth.Authorize(ctx, req.UserID, "payments:write"); err != nil {
+		return nil, statusError(err)
+	}
+
+	id, err := ps.paymentService.CreatePayment(ctx, req)
+	if err != nil {
+		return nil, statusError(err)
+	}
+	return &paymentpb.CreatePaymentResponse{Id: id}, nil
+}
+
+func (ps *PaymentServer) ListPayments(ctx context.Context, in *paymentpb.ListPaymentsRequest) (*paymentpb.ListPaymentsResponse, error) {
+	if err := auth.Authorize(ctx, "", "payments:read:any"); err != nil {
+		return nil, statusError(err)
+	}
+
+	res, err := ps.paymentService.ListPayments(ctx)
+	if err != nil {
+		return nil, statusError(err)
+	}
+	return &paymentpb.ListPaymentsResponse{Payments: parsePayments(res)}, nil
+}
+
+func (ps *PaymentServer) GetPayment(ctx context.Context, in *paymentpb.GetPaymentRequest) (*paymentpb.Payment, error) {
+	res, err := ps.paymentService.GetPayment(ctx, in.GetId())
+	if err != nil {
+		return nil, statusError(err)
+	}
+	if err = auth.Authorize(ctx, res.UserID, "payments:read:any"); err != nil {
+		return nil, statusError(err)
+	}
+	return parsePayment(res), nil
+}
+
+func (ps *PaymentServer) UpdatePayment(ctx context.Context, in *paymentpb.UpdatePaymentRequest) (*paymentpb.UpdatePaymentResponse, error) {
+	if err := auth.Authorize(ctx, "", "payments:write"); err != nil {
+		return nil, statusError(err)
+	}
+
+	amount, err := parseMoney(in.GetAmount())
+	if err != nil {
+		return nil, statusError(payment.ErrorInvalidAmount)
+	}
+	req := payment.Request{
+		UserID:  in.GetUserId(),
+		OrderID: in.GetOrderId(),
+		Amount:  amount,
+	}
+	if err = req.Validate(); err != nil {
+		return nil, statusError(err)
+	}
+
+	// the gRPC API has no version, updates are unconditional
+	if _, err = ps.paymentService.UpdatePayment(ctx, in.GetId(), 0, req); err != nil {
+		return nil, statusError(err)
+	}
+	return &paymentpb.UpdatePaymentResponse{}, nil
+}
+
+func (ps *PaymentServer) DeletePayment(ctx context.Context, in *paymentpb.DeletePaymentRequest) (*paymentpb.DeletePaymentResponse, error) {
+	if err := auth.Authorize(ctx, "", "payments:write"); err != nil {
+		return nil, statusError(err)
+	}
+
+	if err := ps.paymentService.DeletePayment(ctx, in.GetId()); err != nil {
+		return nil, statusError(err)
+	}
+	return &paymentpb.DeletePaymentResponse{}, nil
+}
+
+func (ps *PaymentServer) RefundPayment(ctx context.Context, in *paymentpb.RefundPaymentRequest) (*paymentpb.RefundPaymentResponse, error) {
+	if err := auth.Authorize(ctx, "", "payments:write"); err != nil {
+		return nil, statusError(err)
+	}
+
+	if err := ps.paymentService.RefundPayment(ctx, in.GetId()); err != nil {
+		return nil, statusError(err)
+	}
+	return &paymentpb.RefundPaymentResponse{}, nil
+}
+
+func (ps *PaymentServer) SearchPayments(ctx context.Context, in *paymentpb.SearchPaymentsRequest) (*paymentpb.ListPaymentsResponse, error) {
+	if err := auth.Authorize(ctx, "", "payments:read:any"); err != nil {
+		return nil, statusError(err)
+	}
+
+	res, err := ps.paymentService.SearchPayments(ctx, in.GetFilter(), in.GetValue())
+	if err != nil {
+		return nil, statusError(err)
+	}
+	return &paymentpb.ListPaymentsResponse{Payments: parsePayments(res)}, nil
+}
+
+func parseMoney(m *paymentpb.Money) (money.Money, error) {
+	return money.Parse(m.GetAmount(), m.GetCurrency())
+}
+
+func parsePayment(res payment.Response) *paymentpb.Payment {
+	return &paymentpb.Payment{
+		Id:        res.ID,
+		UserId:    res.UserID,
+		OrderId:   res.OrderID,
+		Amount:    &paymentpb.Money{Amount: res.Amount.Decimal(), Currency: res.Amount.Currency},
+		Status:    res.Status,
+		CreatedAt: timestamppb.New(res.CreatedAt),
+	}
+}
+
+func parsePayments(data []payment.Response) (res []*paymentpb.Payment) {
+	res = make([]*paymentpb.Payment, 0, len(data))
+	for _, item := range data {
+		res = append(res, parsePayment(item))
+	}
+	return
+}
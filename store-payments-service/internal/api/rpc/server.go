@@ -0,0 +1,60 @@
+package rpc
+
+import (
+	"context"
+	"google.golang.org/grpc"
+	"log/slog"
+	"net"
+	"payment-service/internal/config"
+	services "payment-service/internal/service/interface"
+	"payment-service/pkg/auth"
+	"payment-service/pkg/logging"
+	"payment-service/pkg/metrics"
+	"payment-service/pkg/pb/paymentpb"
+	"payment-service/pkg/tracing"
+)
+
+// Server is the internal gRPC API, it runs next to the REST server and
+// shares its services.
+type Server struct {
+	server *grpc.Server
+}
+
+func NewServer(cfg config.Config, paymentService services.PaymentService) *Server {
+	server := grpc.NewServer(
+		tracing.ServerOption(),
+		grpc.ChainUnaryInterceptor(
+			logging.UnaryServerInterceptor(slog.Default()),
+			metrics.UnaryServerInterceptor(),
+			auth.UnaryServerInterceptor(cfg.TokenSecret),
+		),
+	)
+	paymentpb.RegisterPaymentServiceServer(server, NewPaymentServer(paymentService))
+	return &Server{server}
+}
+
+// Run serves on addr until Stop is called.
+func (s *Server) Run(addr string, logger *slog.Logger) error {
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return err
+	}
+
+	logger.Info("starting grpc server", "addr", addr)
+	return s.server.Serve(listener)
+}
+
+// Stop lets the calls in flight finish, those still running when ctx is done
+// are cancelled.
+func (s *Server) Stop(ctx context.Context) {
+	stopped := make(chan struct{})
+	go func() {
+		s.server.GracefulStop()
+		close(stopped)
+	}()
+	select {
+	case <-stopped:
+	case <-ctx.Done():
+		s.server.Stop()
+	}
+}
@@ -0,0 +1,70 @@
+package rpc
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"payment-service/internal/domain/order"
+	"payment-service/internal/domain/payment"
+	"payment-service/pkg/apperror"
+	"payment-service/pkg/auth"
+	"payment-service/pkg/validate"
+)
+
+// statusError maps a service error to the gRPC status a client can act on,
+// anything unexpected is Internal. The code of the error goes along as an
+// ErrorInfo, so clients see the same code as over REST.
+func statusError(err error) error {
+	switch {
+	case errors.Is(err, payment.ErrorNotFound):
+		return errorStatus(codes.NotFound, err)
+	case errors.Is(err, validate.ErrorFailed), errors.Is(err, payment.ErrorInvalidAmount),
+		errors.Is(err, payment.ErrorInvalidDate), errors.Is(err, payment.ErrorOrderMismatch),
+		errors.Is(err, order.ErrorNotFound):
+		return errorStatus(codes.InvalidArgument, err)
+	case errors.Is(err, payment.ErrorNotRefundable):
+		return errorStatus(codes.FailedPrecondition, err)
+	case errors.Is(err, payment.ErrorDuplicate):
+		return errorStatus(codes.AlreadyExists, err)
+	case errors.Is(err, order.ErrorUnavailable):
+		return errorStatus(codes.Unavailable, err)
+	case errors.Is(err, auth.ErrorTokenRequired):
+		return errorStatus(codes.Unauthenticated, err)
+	case errors.Is(err, auth.ErrorPermissionDenied):
+		return errorStatus(codes.PermissionDenied, err)
+	}
+	return errorStatus(codes.Internal, err)
+}
+
+func errorStatus(code codes.Code, err error) error {
+	appErr := apperror.From(err)
+	info := &errdetails.ErrorInfo{Reason: appErr.Code, Domain: "payment-service"}
+	if len(appErr.Details) > 0 {
+		info.Metadata = make(map[string]string, len(appErr.Details))
+		for key, value := range appErr.Details {
+			info.Metadata[key] = metadataValue(value)
+		}
+	}
+	st, detailsErr := status.New(code, err.Error()).WithDetails(info)
+	if detailsErr != nil {
+		return status.Error(code, err.Error())
+	}
+	return st.Err()
+}
+
+// metadataValue turns a detail into ErrorInfo metadata, which only holds
+// strings. Lists and objects, such as validation violations, are JSON.
+func metadataValue(value any) string {
+	switch value.(type) {
+	case string, int, int64, bool:
+		return fmt.Sprint(value)
+	}
+	b, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Sprint(value)
+	}
+	return string(b)
+}
@@ -0,0 +1,43 @@
+package config
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestValidateEpaymentSecret(t *testing.T) {
+	valid := Default()
+	valid.OrderServiceURL = "http://order-service:8003"
+	valid.EpaymentClientSecret = homebankTestSecret
+
+	tests := []struct {
+		name   string
+		change func(cfg *Config)
+		err    string
+	}{
+		{name: "test secret with the test environment", change: func(cfg *Config) {}},
+		{
+			name:   "test secret with production",
+			change: func(cfg *Config) { cfg.EpaymentTokenURL = "https://epay-oauth.homebank.kz/oauth2/token" },
+			err:    "public secret of the Homebank test environment",
+		},
+		{
+			name:   "placeholder",
+			change: func(cfg *Config) { cfg.EpaymentClientSecret = "change-me" },
+			err:    "epayment_client_secret is a placeholder",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := valid
+			tt.change(&cfg)
+			err := cfg.Validate()
+			switch {
+			case tt.err == "" && err != nil:
+				t.Errorf("unexpected error %v", err)
+			case tt.err != "" && (err == nil || !strings.Contains(err.Error(), tt.err)):
+				t.Errorf("error = %v, want %q", err, tt.err)
+			}
+		})
+	}
+}
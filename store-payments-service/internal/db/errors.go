@@ -0,0 +1,29 @@
+package db
+
+import (
+	"errors"
+	"github.com/lib/pq"
+)
+
+// IsUniqueViolation reports whether err is an insert or update that would
+// duplicate a unique key.
+func IsUniqueViolation(err error) bool {
+	return hasCode(err, "unique_violation")
+}
+
+// IsForeignKeyViolation reports whether err refers to a row that does not
+// exist, or deletes one that is still referred to.
+func IsForeignKeyViolation(err error) bool {
+	return hasCode(err, "foreign_key_violation")
+}
+
+// IsInvalidText reports whether a parameter does not parse as its column
+// type, e.g. a malformed UUID in the path.
+func IsInvalidText(err error) bool {
+	return hasCode(err, "invalid_text_representation")
+}
+
+func hasCode(err error, name string) bool {
+	var pqErr *pq.Error
+	return errors.As(err, &pqErr) && pqErr.Code.Name() == name
+}
@@ -4,16 +4,38 @@ import (
 	"github.com/jmoiron/sqlx"
 	_ "github.com/lib/pq"
 	"github.com/pressly/goose/v3"
+	"payment-service/migrations"
 )
 
+// migrationsDir holds the goose migrations of the service inside
+// migrations.FS. sourceDir is the same directory in the source tree,
+// relative to the root of the module, where CreateMigration writes.
+const (
+	migrationsDir = "postgres"
+	sourceDir     = "migrations/postgres"
+)
+
+func init() {
+	goose.SetBaseFS(migrations.FS)
+}
+
+// Migrate applies the migrations that are not applied yet.
 func Migrate(db *sqlx.DB) error {
+	return RunMigrations(db, "up")
+}
+
+// RunMigrations runs the goose command, one of up, down, status or redo.
+// down and redo roll back the last migration only.
+func RunMigrations(db *sqlx.DB, command string) error {
 	if err := goose.SetDialect("postgres"); err != nil {
 		return err
 	}
 
-	if err := goose.Up(db.DB, "migrations/postgres"); err != nil {
-		return err
-	}
+	return goose.Run(command, db.DB, migrationsDir)
+}
 
-	return nil
+// CreateMigration writes an empty SQL migration named after name, it has
+// to be run from the root of the module.
+func CreateMigration(name string) error {
+	return goose.Create(nil, sourceDir, name, "sql")
 }
@@ -0,0 +1,29 @@
+package di
+
+import (
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"payment-service/internal/config"
+	"payment-service/internal/service"
+	"payment-service/pkg/logging"
+	"payment-service/pkg/metrics"
+	"payment-service/pkg/pb/orderpb"
+	"payment-service/pkg/tracing"
+)
+
+// NewOrderClient connects to the gRPC API of the order service at
+// cfg.OrderServiceGRPC, without an address orders are read over REST.
+func NewOrderClient(cfg config.Config) (orderpb.OrderServiceClient, error) {
+	if cfg.OrderServiceGRPC == "" {
+		return nil, nil
+	}
+	conn, err := grpc.NewClient(cfg.OrderServiceGRPC,
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithChainUnaryInterceptor(logging.UnaryClientInterceptor(), metrics.UnaryClientInterceptor(), service.Identity(cfg).UnaryClientInterceptor()),
+		tracing.DialOption(),
+	)
+	if err != nil {
+		return nil, err
+	}
+	return orderpb.NewOrderServiceClient(conn), nil
+}
@@ -0,0 +1,12 @@
+package di
+
+import (
+	"github.com/jmoiron/sqlx"
+	"payment-service/internal/config"
+	"payment-service/pkg/events"
+)
+
+// NewRelay relays the outbox of the service to the broker at cfg.EventsURL.
+func NewRelay(cfg config.Config, db *sqlx.DB) *events.Relay {
+	return events.NewRelay(db, events.NewPublisher(cfg.EventsURL), "payment-service")
+}
@@ -8,6 +8,7 @@ import (
 	_ "github.com/lib/pq"
 	http "payment-service/internal/api"
 	"payment-service/internal/api/handler"
+	"payment-service/internal/api/rpc"
 	"payment-service/internal/config"
 	"payment-service/internal/db"
 	"payment-service/internal/repository"
@@ -17,9 +18,17 @@ import (
 func InitializeAPI(cfg config.Config) (*http.Server, error) {
 	wire.Build(
 		db.ConnectDatabase,
+		db.ConnectReplica,
 		handler.NewPaymentHandler,
+		handler.NewHealthHandler,
 		repository.NewPaymentRepository,
 		service.NewPaymentService,
+		service.NewOrderService,
+		service.NewEPaymentService,
+		NewOrderClient,
+		NewHealthChecker,
+		NewRelay,
+		rpc.NewServer,
 		http.NewServer,
 	)
 	return &http.Server{}, nil
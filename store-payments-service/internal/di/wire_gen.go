@@ -8,6 +8,7 @@ import (
 	_ "github.com/lib/pq"
 	"payment-service/internal/api"
 	"payment-service/internal/api/handler"
+	"payment-service/internal/api/rpc"
 	"payment-service/internal/config"
 	"payment-service/internal/db"
 	"payment-service/internal/repository"
@@ -19,13 +20,29 @@ func InitializeAPI(cfg config.Config) (*http.Server, error) {
 	if err != nil {
 		return nil, err
 	}
-	err = db.Migrate(sqlxDB)
+	if cfg.AutoMigrate {
+		err = db.Migrate(sqlxDB)
+		if err != nil {
+			return nil, err
+		}
+	}
+	replica, err := db.ConnectReplica(cfg, sqlxDB)
+	if err != nil {
+		return nil, err
+	}
+	paymentRepository := repository.NewPaymentRepository(sqlxDB, replica)
+	orderServiceClient, err := NewOrderClient(cfg)
 	if err != nil {
 		return nil, err
 	}
-	paymentRepository := repository.NewPaymentRepository(sqlxDB)
-	paymentService := service.NewPaymentService(paymentRepository)
+	orderService := service.NewOrderService(cfg, orderServiceClient)
+	ePaymentService := service.NewEPaymentService(cfg)
+	paymentService := service.NewPaymentService(paymentRepository, orderService, ePaymentService)
 	paymentHandler := handler.NewPaymentHandler(paymentService)
-	server := http.NewServer(paymentHandler)
+	checker := NewHealthChecker(sqlxDB, replica)
+	healthHandler := handler.NewHealthHandler(checker)
+	relay := NewRelay(cfg, sqlxDB)
+	rpcServer := rpc.NewServer(cfg, paymentService)
+	server := http.NewServer(cfg, sqlxDB, replica, paymentHandler, healthHandler, relay, rpcServer)
 	return server, nil
 }
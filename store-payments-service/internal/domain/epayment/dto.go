@@ -1,5 +1,14 @@
 package epayment
 
+import "errors"
+
+// The steps of a Homebank payment that can fail.
+var (
+	ErrorToken      = errors.New("payment token request failed")
+	ErrorEncryption = errors.New("card data encryption failed")
+	ErrorRequest    = errors.New("payment request failed")
+)
+
 type TokenResponse struct {
 	AccessToken  string `json:"access_token"`
 	ExpiresIn    string `json:"expires_in"`
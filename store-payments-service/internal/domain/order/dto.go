@@ -0,0 +1,18 @@
+package order
+
+import "payment-service/pkg/apperror"
+
+// ErrorNotFound is an order a payment names that does not exist, the
+// request is wrong rather than the payment missing.
+var (
+	ErrorNotFound    = apperror.BadRequest("order_not_found", "order not found")
+	ErrorUnavailable = apperror.Unavailable("order_service_unavailable", "order service unavailable")
+)
+
+// Order is the part of an order of the order service a payment is checked
+// against.
+type Order struct {
+	ID     string `json:"id"`
+	UserID string `json:"user_id"`
+	Status string `json:"status"`
+}
@@ -1,43 +1,70 @@
 package payment
 
 import (
-	"errors"
+	"net/http"
+	"payment-service/pkg/apperror"
+	"payment-service/pkg/money"
+	"payment-service/pkg/validate"
 	"time"
 )
 
 var (
-	ErrorNotFound            = errors.New("payment not found")
-	ErrorInvalidDate         = errors.New("invalid date format")
-	ErrorFailedToMakePayment = errors.New("failed to make payment")
-	ErrorInvalidAmount       = errors.New("invalid amount")
-	ErrorInvalidUserID       = errors.New("invalid user id")
-	ErrorInvalidOrderID      = errors.New("invalid order id")
+	ErrorNotFound            = apperror.NotFound("payment_not_found", "payment not found")
+	ErrorInvalidDate         = apperror.BadRequest("invalid_date", "invalid date format")
+	ErrorFailedToMakePayment = apperror.New(http.StatusBadGateway, "payment_failed", "failed to make payment")
+	ErrorInvalidAmount       = apperror.BadRequest("invalid_amount", "invalid amount")
+	ErrorNotRefundable       = apperror.Conflict("not_refundable", "only a successful payment can be refunded")
+	ErrorOrderMismatch       = apperror.BadRequest("order_mismatch", "order belongs to another user")
+	ErrorDuplicate           = apperror.Conflict("payment_exists", "payment already exists")
+	ErrorVersionMismatch     = apperror.PreconditionFailed("version_mismatch", "payment was changed since it was read")
+	ErrorNotDeleted          = apperror.Conflict("payment_not_deleted", "payment is not deleted")
+)
+
+const (
+	StatusSuccess  = "success"
+	StatusFailed   = "failed"
+	StatusRefunded = "refunded"
 )
 
 type Request struct {
-	UserID  string  `json:"user_id"`
-	OrderID string  `json:"order_id"`
-	Amount  float64 `json:"amount"`
+	UserID  string      `json:"user_id" validate:"required,uuid"`
+	OrderID string      `json:"order_id" validate:"required,uuid"`
+	Amount  money.Money `json:"amount" validate:"money,positive"`
+}
+
+// Patch is a merge patch of a payment, nil fields are left as they are.
+type Patch struct {
+	UserID  *string      `json:"user_id"`
+	OrderID *string      `json:"order_id"`
+	Amount  *money.Money `json:"amount"`
 }
 
 type Response struct {
-	ID        string    `json:"id"`
-	UserID    string    `json:"user_id"`
-	OrderID   string    `json:"order_id"`
-	Amount    float64   `json:"amount"`
-	Status    string    `json:"status"`
-	CreatedAt time.Time `json:"created_at"`
+	ID        string      `json:"id"`
+	UserID    string      `json:"user_id"`
+	OrderID   string      `json:"order_id"`
+	Amount    money.Money `json:"amount"`
+	Status    string      `json:"status"`
+	CreatedAt time.Time   `json:"created_at"`
+	Version   int         `json:"version"`
+	// DeletedAt is only set for a deleted payment, e.g. in the audit log
+	DeletedAt *time.Time `json:"deleted_at,omitempty"`
 }
 
 func ParseFromEntity(entity Entity) Response {
-	return Response{
+	res := Response{
 		ID:        entity.ID,
 		UserID:    entity.UserID,
 		OrderID:   entity.OrderID,
-		Amount:    entity.Amount,
+		Amount:    money.New(entity.Amount, entity.Currency),
 		Status:    entity.Status,
 		CreatedAt: entity.CreatedAt,
+		Version:   entity.Version,
 	}
+	if entity.DeletedAt.Valid {
+		res.DeletedAt = &entity.DeletedAt.Time
+	}
+	return res
 }
 
 func ParseFromEntities(data []Entity) (res []Response) {
@@ -49,14 +76,24 @@ func ParseFromEntities(data []Entity) (res []Response) {
 }
 
 func (r *Request) Validate() error {
-	if r.UserID == "" {
-		return ErrorInvalidUserID
+	return validate.Struct(r)
+}
+
+// Apply returns the request that sets entity to what the patch asks for.
+func (p Patch) Apply(entity Entity) Request {
+	req := Request{
+		UserID:  entity.UserID,
+		OrderID: entity.OrderID,
+		Amount:  money.New(entity.Amount, entity.Currency),
+	}
+	if p.UserID != nil {
+		req.UserID = *p.UserID
 	}
-	if r.OrderID == "" {
-		return ErrorInvalidOrderID
+	if p.OrderID != nil {
+		req.OrderID = *p.OrderID
 	}
-	if r.Amount <= 0 {
-		return ErrorInvalidAmount
+	if p.Amount != nil {
+		req.Amount = *p.Amount
 	}
-	return nil
+	return req
 }
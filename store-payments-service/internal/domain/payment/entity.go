@@ -1,14 +1,18 @@
 package payment
 
 import (
+	"database/sql"
 	"time"
 )
 
 type Entity struct {
-	ID        string    `db:"id" bson:"_id"`
-	UserID    string    `db:"user_id" bson:"user_id"`
-	OrderID   string    `db:"order_id" bson:"order_id"`
-	Amount    float64   `db:"amount" bson:"amount"`
-	Status    string    `db:"status" bson:"status"`
-	CreatedAt time.Time `db:"created_at" bson:"created_at"`
+	ID        string       `db:"id" bson:"_id"`
+	UserID    string       `db:"user_id" bson:"user_id"`
+	OrderID   string       `db:"order_id" bson:"order_id"`
+	Amount    int64        `db:"amount" bson:"amount"`
+	Currency  string       `db:"currency" bson:"currency"`
+	Status    string       `db:"status" bson:"status"`
+	CreatedAt time.Time    `db:"created_at" bson:"created_at"`
+	Version   int          `db:"version" bson:"version"`
+	DeletedAt sql.NullTime `db:"deleted_at" bson:"deleted_at"`
 }
@@ -0,0 +1,19 @@
+package payment
+
+// Events written to the outbox when payments change.
+const (
+	EventCaptured = "PaymentCaptured"
+	EventFailed   = "PaymentFailed"
+	EventRefunded = "PaymentRefunded"
+	EventUpdated  = "PaymentUpdated"
+	EventDeleted  = "PaymentDeleted"
+	EventRestored = "PaymentRestored"
+)
+
+// AuditEntity names payments in the audit log.
+const AuditEntity = "payment"
+
+// Deleted is the payload of PaymentDeleted.
+type Deleted struct {
+	PaymentID string `json:"payment_id"`
+}
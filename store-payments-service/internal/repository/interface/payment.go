@@ -10,6 +10,8 @@ type PaymentRepository interface {
 	List(ctx context.Context) (res []payment.Entity, err error)
 	Get(ctx context.Context, id string) (res payment.Entity, err error)
 	Delete(ctx context.Context, id string) (err error)
-	Update(ctx context.Context, id string, entity payment.Entity) (err error)
+	Restore(ctx context.Context, id string) (dest payment.Entity, err error)
+	Update(ctx context.Context, id string, version int, entity payment.Entity) (dest payment.Entity, err error)
+	Refund(ctx context.Context, id string) (err error)
 	Search(ctx context.Context, filter, value string) (res []payment.Entity, err error)
 }
@@ -6,68 +6,134 @@ import (
 	"errors"
 	"fmt"
 	"github.com/jmoiron/sqlx"
-	"github.com/lib/pq"
-	"log"
+	"log/slog"
+	"payment-service/internal/db"
 	"payment-service/internal/domain/payment"
-	"strconv"
-	"strings"
+	"payment-service/pkg/audit"
+	"payment-service/pkg/events"
 )
 
 type PaymentRepository struct {
-	db *sqlx.DB
+	db      *sqlx.DB
+	replica *sqlx.DB
 }
 
-func NewPaymentRepository(db *sqlx.DB) *PaymentRepository {
+func NewPaymentRepository(db *sqlx.DB, replica db.Replica) *PaymentRepository {
 	return &PaymentRepository{
-		db: db,
+		db:      db,
+		replica: replica.DB,
 	}
 }
 
+// Create stores the payment with a PaymentCaptured or PaymentFailed event
+// depending on its status.
 func (pr *PaymentRepository) Create(ctx context.Context, data payment.Entity) (id string, err error) {
-	query := `INSERT INTO payments (user_id, order_id, amount, status) VALUES ($1, $2, $3, $4) RETURNING id;`
+	tx, err := pr.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return
+	}
+	defer tx.Rollback()
+
+	var dest payment.Entity
+	query := `INSERT INTO payments (user_id, order_id, amount, currency, status) VALUES ($1, $2, $3, $4, $5) RETURNING *;`
 	args := []any{
 		data.UserID,
 		data.OrderID,
 		data.Amount,
+		data.Currency,
 		data.Status,
 	}
-	if err = pr.db.QueryRowContext(ctx, query, args...).Scan(&id); err != nil {
-		if errors.Is(err, sql.ErrNoRows) {
-			err = payment.ErrorNotFound
-		}
-		if err, ok := err.(*pq.Error); ok && err.Code.Name() == "unique_violation" {
-			return "", payment.ErrorNotFound
-		}
+	if err = tx.GetContext(ctx, &dest, query, args...); err != nil {
+		err = pr.mapError(err)
+		return
+	}
+
+	eventType := payment.EventCaptured
+	if dest.Status != payment.StatusSuccess {
+		eventType = payment.EventFailed
+	}
+	if err = events.Store(ctx, tx, eventType, dest.ID, payment.ParseFromEntity(dest)); err != nil {
+		return
+	}
+	if err = audit.Record(ctx, tx, payment.AuditEntity, dest.ID, audit.ActionCreate, nil, payment.ParseFromEntity(dest)); err != nil {
+		return
+	}
+	if err = tx.Commit(); err != nil {
+		return
 	}
+	id = dest.ID
 	return
 }
 
 func (pr *PaymentRepository) Delete(ctx context.Context, id string) (err error) {
-	query := `DELETE FROM payments WHERE id = $1 RETURNING id;`
-	args := []any{id}
-	if err = pr.db.QueryRowContext(ctx, query, args...).Scan(&id); err != nil {
-		if errors.Is(err, sql.ErrNoRows) {
-			err = payment.ErrorNotFound
-			return
-		}
+	tx, err := pr.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return
 	}
+	defer tx.Rollback()
 
+	before, err := pr.lock(ctx, tx, id)
+	if err != nil {
+		return
+	}
+	var dest payment.Entity
+	query := `UPDATE payments SET deleted_at = CURRENT_TIMESTAMP, version = version + 1 WHERE id = $1 RETURNING *;`
+	if err = tx.GetContext(ctx, &dest, query, id); err != nil {
+		return
+	}
+	if err = events.Store(ctx, tx, payment.EventDeleted, id, payment.Deleted{PaymentID: id}); err != nil {
+		return
+	}
+	if err = audit.Record(ctx, tx, payment.AuditEntity, id, audit.ActionDelete, payment.ParseFromEntity(before), payment.ParseFromEntity(dest)); err != nil {
+		return
+	}
+	err = tx.Commit()
+	return
+}
+
+// Restore brings a deleted payment back.
+func (pr *PaymentRepository) Restore(ctx context.Context, id string) (dest payment.Entity, err error) {
+	tx, err := pr.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return
+	}
+	defer tx.Rollback()
+
+	var before payment.Entity
+	if err = tx.GetContext(ctx, &before, `SELECT * FROM payments WHERE id = $1 FOR UPDATE;`, id); err != nil {
+		err = pr.mapError(err)
+		return
+	}
+	if !before.DeletedAt.Valid {
+		err = payment.ErrorNotDeleted
+		return
+	}
+	query := `UPDATE payments SET deleted_at = NULL, version = version + 1 WHERE id = $1 RETURNING *;`
+	if err = tx.GetContext(ctx, &dest, query, id); err != nil {
+		return
+	}
+	if err = events.Store(ctx, tx, payment.EventRestored, id, payment.ParseFromEntity(dest)); err != nil {
+		return
+	}
+	if err = audit.Record(ctx, tx, payment.AuditEntity, id, audit.ActionRestore, payment.ParseFromEntity(before), payment.ParseFromEntity(dest)); err != nil {
+		return
+	}
+	err = tx.Commit()
 	return
 }
 
 func (pr *PaymentRepository) Get(ctx context.Context, id string) (dest payment.Entity, err error) {
-	query := `SELECT * FROM payments WHERE id = $1;`
+	query := `SELECT * FROM payments WHERE id = $1 AND deleted_at IS NULL;`
 	args := []any{id}
-	err = pr.db.GetContext(ctx, &dest, query, args...)
-	if errors.Is(err, sql.ErrNoRows) {
-		err = payment.ErrorNotFound
+	if err = pr.db.GetContext(ctx, &dest, query, args...); err != nil {
+		err = pr.mapError(err)
 	}
 	return
 }
 
 func (pr *PaymentRepository) List(ctx context.Context) (dest []payment.Entity, err error) {
-	query := `SELECT * FROM payments ORDER BY id;`
-	err = pr.db.SelectContext(ctx, &dest, query)
+	query := `SELECT * FROM payments WHERE deleted_at IS NULL ORDER BY id;`
+	err = pr.replica.SelectContext(ctx, &dest, query)
 	if err != nil {
 		return
 	}
@@ -78,36 +144,78 @@ func (pr *PaymentRepository) List(ctx context.Context) (dest []payment.Entity, e
 	return
 }
 
-func (pr *PaymentRepository) Update(ctx context.Context, id string, data payment.Entity) (err error) {
-	sets, args := pr.prepareArgs(data)
-	if len(args) > 0 {
-		args = append(args, id)
-		query := fmt.Sprintf("UPDATE payments SET %s WHERE id = $%d RETURNING id;", strings.Join(sets, ","), len(args))
-		if err = pr.db.QueryRowContext(ctx, query, args...).Scan(&id); err != nil {
-			if errors.Is(err, sql.ErrNoRows) {
-				err = payment.ErrorNotFound
-			}
-		}
+// Update overwrites a payment. A version other than 0 must be the current
+// one, the stored version is bumped either way.
+func (pr *PaymentRepository) Update(ctx context.Context, id string, version int, data payment.Entity) (dest payment.Entity, err error) {
+	tx, err := pr.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return
+	}
+	defer tx.Rollback()
+
+	before, err := pr.lock(ctx, tx, id)
+	if err != nil {
+		return
+	}
+	if version != 0 && version != before.Version {
+		err = payment.ErrorVersionMismatch
+		return
+	}
+
+	query := `
+		UPDATE payments SET user_id = $1, order_id = $2, amount = $3, currency = $4, version = version + 1
+		WHERE id = $5 RETURNING *;`
+	if err = tx.GetContext(ctx, &dest, query, data.UserID, data.OrderID, data.Amount, data.Currency, id); err != nil {
+		err = pr.mapError(err)
+		return
+	}
+	if err = events.Store(ctx, tx, payment.EventUpdated, id, payment.ParseFromEntity(dest)); err != nil {
+		return
+	}
+	if err = audit.Record(ctx, tx, payment.AuditEntity, id, audit.ActionUpdate, payment.ParseFromEntity(before), payment.ParseFromEntity(dest)); err != nil {
+		return
 	}
+	err = tx.Commit()
 	return
 }
 
-func (pr *PaymentRepository) prepareArgs(data payment.Entity) (sets []string, args []any) {
-	if data.UserID != "" {
-		sets = append(sets, "user_id = $"+strconv.Itoa(len(args)+1))
-		args = append(args, data.UserID)
+// Refund marks a successful payment refunded and writes PaymentRefunded.
+func (pr *PaymentRepository) Refund(ctx context.Context, id string) (err error) {
+	tx, err := pr.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return
+	}
+	defer tx.Rollback()
+
+	before, err := pr.lock(ctx, tx, id)
+	if err != nil {
+		return
 	}
-	if len(data.OrderID) != 0 {
-		sets = append(sets, "order_id = $"+strconv.Itoa(len(args)+1))
-		args = append(args, pq.Array(data.OrderID))
+	if before.Status != payment.StatusSuccess {
+		err = payment.ErrorNotRefundable
+		return
 	}
-	if data.Amount != 0 {
-		sets = append(sets, "amount = $"+strconv.Itoa(len(args)+1))
-		args = append(args, data.Amount)
+
+	dest := before
+	dest.Status = payment.StatusRefunded
+	dest.Version++
+	if _, err = tx.ExecContext(ctx, `UPDATE payments SET status = $1, version = version + 1 WHERE id = $2;`, dest.Status, id); err != nil {
+		return
 	}
-	if data.Status != "" {
-		sets = append(sets, "status = $"+strconv.Itoa(len(args)+1))
-		args = append(args, data.Status)
+	if err = events.Store(ctx, tx, payment.EventRefunded, id, payment.ParseFromEntity(dest)); err != nil {
+		return
+	}
+	if err = audit.Record(ctx, tx, payment.AuditEntity, id, audit.ActionUpdate, payment.ParseFromEntity(before), payment.ParseFromEntity(dest)); err != nil {
+		return
+	}
+	err = tx.Commit()
+	return
+}
+
+// lock reads a payment that is not deleted and locks it until tx ends.
+func (pr *PaymentRepository) lock(ctx context.Context, tx *sqlx.Tx, id string) (dest payment.Entity, err error) {
+	if err = tx.GetContext(ctx, &dest, `SELECT * FROM payments WHERE id = $1 AND deleted_at IS NULL FOR UPDATE;`, id); err != nil {
+		err = pr.mapError(err)
 	}
 	return
 }
@@ -115,10 +223,10 @@ func (pr *PaymentRepository) prepareArgs(data payment.Entity) (sets []string, ar
 func (pr *PaymentRepository) Search(ctx context.Context, filter, value string) (payments []payment.Entity, err error) {
 	payments = []payment.Entity{}
 
-	log.Printf("filter: %s, value: %s", filter, value)
+	slog.DebugContext(ctx, "searching payments", "filter", filter)
 	filter = pr.prepareFilter(filter)
-	query := fmt.Sprintf("SELECT * FROM payments WHERE %s = $1;", filter)
-	err = pr.db.SelectContext(ctx, &payments, query, value)
+	query := fmt.Sprintf("SELECT * FROM payments WHERE %s = $1 AND deleted_at IS NULL;", filter)
+	err = pr.replica.SelectContext(ctx, &payments, query, value)
 	if err != nil {
 		return
 	}
@@ -142,3 +250,14 @@ func (pr *PaymentRepository) prepareFilter(filter string) string {
 		return ""
 	}
 }
+
+func (pr *PaymentRepository) mapError(err error) error {
+	switch {
+	case errors.Is(err, sql.ErrNoRows), db.IsInvalidText(err):
+		// no such row, or a malformed UUID in the path
+		return payment.ErrorNotFound
+	case db.IsUniqueViolation(err):
+		return payment.ErrorDuplicate
+	}
+	return err
+}
@@ -2,6 +2,7 @@ package service
 
 import (
 	"bytes"
+	"context"
 	"crypto/rand"
 	"crypto/rsa"
 	"crypto/x509"
@@ -10,41 +11,73 @@ import (
 	"encoding/pem"
 	"errors"
 	"fmt"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
 	"io"
 	"mime/multipart"
 	"net/http"
+	"payment-service/internal/config"
 	"payment-service/internal/domain/epayment"
+	services "payment-service/internal/service/interface"
+	"payment-service/pkg/metrics"
+	"payment-service/pkg/money"
+	"payment-service/pkg/tracing"
 )
 
-func GetPaymentToken() (*epayment.TokenResponse, error) {
-	tokenUrl := "https://testoauth.homebank.kz/epay2/oauth2/token"
+var tracer = tracing.Tracer("payment-service/internal/service")
+
+// EPaymentService charges cards through Homebank with the credentials of
+// the configuration.
+type EPaymentService struct {
+	tokenURL     string
+	publicKeyURL string
+	paymentURL   string
+	clientID     string
+	clientSecret string
+	terminalID   string
+	client       *http.Client
+}
+
+func NewEPaymentService(cfg config.Config) services.EPaymentService {
+	return &EPaymentService{
+		tokenURL:     cfg.EpaymentTokenURL,
+		publicKeyURL: cfg.EpaymentPublicKeyURL,
+		paymentURL:   cfg.EpaymentPaymentURL,
+		clientID:     cfg.EpaymentClientID,
+		clientSecret: cfg.EpaymentClientSecret,
+		terminalID:   cfg.EpaymentTerminalID,
+		// every call is a client span of the payment that made it
+		client: &http.Client{Timeout: cfg.EpaymentTimeout, Transport: tracing.Transport(metrics.Transport{})},
+	}
+}
 
+func (es *EPaymentService) GetPaymentToken(ctx context.Context, amount money.Money) (*epayment.TokenResponse, error) {
 	body := &bytes.Buffer{}
 	writer := multipart.NewWriter(body)
 
 	// taken test fields from website
 	writer.WriteField("grant_type", "client_credentials")
 	writer.WriteField("scope", "webapi usermanagement email_send verification statement statistics payment")
-	writer.WriteField("client_id", "test")
-	writer.WriteField("client_secret", "yF587AV9Ms94qN2QShFzVR3vFnWkhjbAK3sG")
+	writer.WriteField("client_id", es.clientID)
+	writer.WriteField("client_secret", es.clientSecret)
 	writer.WriteField("invoiceID", "938290483292")
-	writer.WriteField("amount", "100")
-	writer.WriteField("currency", "KZT")
-	writer.WriteField("terminal", "67e34d63-102f-4bd1-898e-370781d0074d")
+	writer.WriteField("amount", amount.Decimal())
+	writer.WriteField("currency", amount.Currency)
+	writer.WriteField("terminal", es.terminalID)
 
 	if err := writer.Close(); err != nil {
 		return nil, err
 	}
 
-	req, err := http.NewRequest(http.MethodPost, tokenUrl, body)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, es.tokenURL, body)
 	if err != nil {
 		return nil, err
 	}
 
 	req.Header.Set("Content-Type", writer.FormDataContentType())
 
-	client := &http.Client{}
-	resp, err := client.Do(req)
+	resp, err := es.client.Do(req)
 	if err != nil {
 		return nil, err
 	}
@@ -71,9 +104,12 @@ func GetPaymentToken() (*epayment.TokenResponse, error) {
 	return &token, nil
 }
 
-func GetPublicKey() (*rsa.PublicKey, error) {
-	publicKeyURL := "https://testepay.homebank.kz/api/public.rsa"
-	resp, err := http.Get(publicKeyURL)
+func (es *EPaymentService) GetPublicKey(ctx context.Context) (*rsa.PublicKey, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, es.publicKeyURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := es.client.Do(req)
 	if err != nil {
 		return nil, err
 	}
@@ -98,8 +134,8 @@ func GetPublicKey() (*rsa.PublicKey, error) {
 	return rsaPublicKey, nil
 }
 
-func encryptData() (string, error) {
-	publicKey, err := GetPublicKey()
+func (es *EPaymentService) encryptData(ctx context.Context) (string, error) {
+	publicKey, err := es.GetPublicKey(ctx)
 	if err != nil {
 		return "", err
 	}
@@ -107,7 +143,7 @@ func encryptData() (string, error) {
 		"hpan":       "4405639704015096",
 		"expDate":    "0125",
 		"cvc":        "815",
-		"terminalId": "67e34d63-102f-4bd1-898e-370781d0074d",
+		"terminalId": es.terminalID,
 	}
 	jsonData, err := json.Marshal(data)
 	if err != nil {
@@ -120,23 +156,35 @@ func encryptData() (string, error) {
 	return base64.StdEncoding.EncodeToString(encryptedData), nil
 }
 
-func MakePayment() (*epayment.EpaymentResponse, error) {
-	paymentUrl := "https://testepay.homebank.kz/api/payment/cryptopay"
-	paymentToken, err := GetPaymentToken()
-	fmt.Println("Payment token", paymentToken.AccessToken)
+// MakePayment charges amount through Homebank. The token, key and payment
+// calls are grouped under one span, so a slow checkout shows which of them
+// took the time.
+func (es *EPaymentService) MakePayment(ctx context.Context, amount money.Money) (_ *epayment.EpaymentResponse, err error) {
+	ctx, span := tracer.Start(ctx, "epayment.MakePayment", trace.WithAttributes(
+		attribute.String("payment.currency", amount.Currency),
+	))
+	defer func() {
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+		}
+		span.End()
+	}()
+
+	paymentToken, err := es.GetPaymentToken(ctx, amount)
 
 	if err != nil {
-		return nil, fmt.Errorf("failed to get payment token: %v", err)
+		return nil, fmt.Errorf("%w: %w", epayment.ErrorToken, err)
 	}
 
-	encryptedData, err := encryptData()
+	encryptedData, err := es.encryptData(ctx)
 	if err != nil {
-		return nil, fmt.Errorf("error when encrypting key: %v", err)
+		return nil, fmt.Errorf("%w: %w", epayment.ErrorEncryption, err)
 	}
 
 	body := map[string]interface{}{
-		"amount":          100,
-		"currency":        "KZT",
+		"amount":          json.Number(amount.Decimal()),
+		"currency":        amount.Currency,
 		"name":            "JON JONSON",
 		"cryptogram":      encryptedData,
 		"invoiceID":       "938290483292",
@@ -153,17 +201,16 @@ func MakePayment() (*epayment.EpaymentResponse, error) {
 
 	jsonBody, _ := json.Marshal(body)
 
-	req, err := http.NewRequest(http.MethodPost, paymentUrl, bytes.NewBuffer(jsonBody))
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, es.paymentURL, bytes.NewBuffer(jsonBody))
 	if err != nil {
-		return nil, fmt.Errorf("failed to create request: %v", err)
+		return nil, fmt.Errorf("%w: %w", epayment.ErrorRequest, err)
 	}
 	req.Header.Set("Content-Type", "application/json")
 	req.Header.Set("Authorization", "Bearer "+paymentToken.AccessToken)
 
-	client := &http.Client{}
-	resp, err := client.Do(req)
+	resp, err := es.client.Do(req)
 	if err != nil {
-		return nil, fmt.Errorf("failed to perform request: %v", err)
+		return nil, fmt.Errorf("%w: %w", epayment.ErrorRequest, err)
 	}
 	defer resp.Body.Close()
 
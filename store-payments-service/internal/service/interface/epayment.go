@@ -1,5 +1,11 @@
 package interfaces
 
+import (
+	"context"
+	"payment-service/internal/domain/epayment"
+	"payment-service/pkg/money"
+)
+
 type EPaymentService interface {
-	MakePayment(amount float64) (string, error)
+	MakePayment(ctx context.Context, amount money.Money) (*epayment.EpaymentResponse, error)
 }
@@ -0,0 +1,11 @@
+package interfaces
+
+import (
+	"context"
+	"payment-service/internal/domain/order"
+)
+
+// OrderService reads orders owned by the order service.
+type OrderService interface {
+	GetOrder(ctx context.Context, id string) (res order.Order, err error)
+}
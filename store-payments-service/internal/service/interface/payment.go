@@ -10,6 +10,9 @@ type PaymentService interface {
 	ListPayments(ctx context.Context) (res []payment.Response, err error)
 	GetPayment(ctx context.Context, id string) (res payment.Response, err error)
 	DeletePayment(ctx context.Context, id string) (err error)
-	UpdatePayment(ctx context.Context, id string, req payment.Request) (err error)
+	RestorePayment(ctx context.Context, id string) (res payment.Response, err error)
+	UpdatePayment(ctx context.Context, id string, version int, req payment.Request) (res payment.Response, err error)
+	PatchPayment(ctx context.Context, id string, version int, patch payment.Patch) (res payment.Response, err error)
+	RefundPayment(ctx context.Context, id string) (err error)
 	SearchPayments(ctx context.Context, filter, value string) (res []payment.Response, err error)
 }
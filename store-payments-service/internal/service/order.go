@@ -0,0 +1,66 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"payment-service/internal/config"
+	"payment-service/internal/domain/order"
+	services "payment-service/internal/service/interface"
+	"payment-service/pkg/auth"
+	"payment-service/pkg/logging"
+	"payment-service/pkg/metrics"
+	"payment-service/pkg/pb/orderpb"
+	"payment-service/pkg/tracing"
+	"time"
+)
+
+// OrderService reads the order service over gRPC when orders is set and
+// over its REST API otherwise.
+type OrderService struct {
+	orderServiceURL string
+	client          *http.Client
+	orders          orderpb.OrderServiceClient
+}
+
+func NewOrderService(cfg config.Config, orders orderpb.OrderServiceClient) services.OrderService {
+	return &OrderService{
+		orderServiceURL: cfg.OrderServiceURL,
+		client:          &http.Client{Timeout: 5 * time.Second, Transport: tracing.Transport(metrics.Transport{Base: auth.Transport{Service: Identity(cfg), Base: logging.Transport{}}})},
+		orders:          orders,
+	}
+}
+
+func (o *OrderService) GetOrder(ctx context.Context, id string) (res order.Order, err error) {
+	if o.orders != nil {
+		ctx, cancel := context.WithTimeout(ctx, rpcTimeout)
+		defer cancel()
+
+		data, rpcErr := o.orders.GetOrder(ctx, &orderpb.GetOrderRequest{Id: id})
+		if rpcErr != nil {
+			return res, o.wrap(rpcError(rpcErr))
+		}
+		res = order.Order{
+			ID:     data.GetId(),
+			UserID: data.GetUserId(),
+			Status: data.GetStatus(),
+		}
+		return
+	}
+
+	url := fmt.Sprintf("%s/orders/%s", o.orderServiceURL, id)
+	err = o.wrap(getData(ctx, o.client, url, &res))
+	return
+}
+
+// wrap turns the errors of both transports into order errors.
+func (o *OrderService) wrap(err error) error {
+	switch {
+	case errors.Is(err, errNoData):
+		return order.ErrorNotFound
+	case errors.Is(err, errUnreachable):
+		return fmt.Errorf("%w: %v", order.ErrorUnavailable, err)
+	}
+	return err
+}
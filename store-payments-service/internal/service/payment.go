@@ -2,43 +2,92 @@ package service
 
 import (
 	"context"
-	"fmt"
-	"log"
+	"errors"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"log/slog"
+	"payment-service/internal/domain/epayment"
 	"payment-service/internal/domain/payment"
 	interfaces "payment-service/internal/repository/interface"
 	services "payment-service/internal/service/interface"
 )
 
+// payments counts payment attempts, a failed one by the step that failed.
+var payments = promauto.NewCounterVec(prometheus.CounterOpts{
+	Namespace: "payment_service",
+	Name:      "payments_total",
+	Help:      "Payment attempts by status and, for failed ones, the reason.",
+}, []string{"status", "reason"})
+
 type PaymentService struct {
 	paymentRepository interfaces.PaymentRepository
+	orderService      services.OrderService
+	ePaymentService   services.EPaymentService
 }
 
-func NewPaymentService(repository interfaces.PaymentRepository) services.PaymentService {
+func NewPaymentService(repository interfaces.PaymentRepository, orderService services.OrderService, ePaymentService services.EPaymentService) services.PaymentService {
 	return &PaymentService{
 		paymentRepository: repository,
+		orderService:      orderService,
+		ePaymentService:   ePaymentService,
 	}
 }
 
 func (ts *PaymentService) CreatePayment(ctx context.Context, req payment.Request) (id string, err error) {
-	_, err = MakePayment()
+	if err = ts.checkOrder(ctx, req); err != nil {
+		return
+	}
+	_, err = ts.ePaymentService.MakePayment(ctx, req.Amount)
 	var status string
 	if err != nil {
-		status = "failed"
-		log.Printf("failed to make payment: %v", fmt.Sprintf("%v", err))
+		status = payment.StatusFailed
+		slog.ErrorContext(ctx, "failed to make payment", "error", err)
+		payments.WithLabelValues(status, failureReason(err)).Inc()
 		err = payment.ErrorFailedToMakePayment
 	} else {
-		status = "success"
+		status = payment.StatusSuccess
+		payments.WithLabelValues(status, "").Inc()
 	}
 	data := payment.Entity{
-		UserID:  req.UserID,
-		OrderID: req.OrderID,
-		Amount:  req.Amount,
-		Status:  status,
+		UserID:   req.UserID,
+		OrderID:  req.OrderID,
+		Amount:   req.Amount.Amount,
+		Currency: req.Amount.Currency,
+		Status:   status,
 	}
 	id, err = ts.paymentRepository.Create(ctx, data)
 	return
 }
 
+// checkOrder makes sure the order of a payment exists and was placed by the
+// paying user. The order lives in the database of the order service, so it
+// is asked over its API instead of a foreign key.
+func (ts *PaymentService) checkOrder(ctx context.Context, req payment.Request) error {
+	data, err := ts.orderService.GetOrder(ctx, req.OrderID)
+	if err != nil {
+		return err
+	}
+	if data.UserID != req.UserID {
+		return payment.ErrorOrderMismatch
+	}
+	return nil
+}
+
+// failureReason names the step of a payment that failed.
+func failureReason(err error) string {
+	switch {
+	case errors.Is(err, context.DeadlineExceeded), errors.Is(err, context.Canceled):
+		return "timeout"
+	case errors.Is(err, epayment.ErrorToken):
+		return "token"
+	case errors.Is(err, epayment.ErrorEncryption):
+		return "encryption"
+	case errors.Is(err, epayment.ErrorRequest):
+		return "request"
+	}
+	return "other"
+}
+
 func (ts *PaymentService) ListPayments(ctx context.Context) (res []payment.Response, err error) {
 	data, err := ts.paymentRepository.List(ctx)
 	if err != nil {
@@ -62,13 +111,56 @@ func (ts *PaymentService) DeletePayment(ctx context.Context, id string) (err err
 	return
 }
 
-func (ts *PaymentService) UpdatePayment(ctx context.Context, id string, req payment.Request) (err error) {
+func (ts *PaymentService) RestorePayment(ctx context.Context, id string) (res payment.Response, err error) {
+	data, err := ts.paymentRepository.Restore(ctx, id)
+	if err != nil {
+		return
+	}
+	res = payment.ParseFromEntity(data)
+	return
+}
+
+// UpdatePayment overwrites a payment, version is the one the client read
+// or 0.
+func (ts *PaymentService) UpdatePayment(ctx context.Context, id string, version int, req payment.Request) (res payment.Response, err error) {
+	if err = ts.checkOrder(ctx, req); err != nil {
+		return
+	}
 	data := payment.Entity{
-		UserID:  req.UserID,
-		OrderID: req.OrderID,
-		Amount:  req.Amount,
+		UserID:   req.UserID,
+		OrderID:  req.OrderID,
+		Amount:   req.Amount.Amount,
+		Currency: req.Amount.Currency,
+	}
+	data, err = ts.paymentRepository.Update(ctx, id, version, data)
+	if err != nil {
+		return
+	}
+	res = payment.ParseFromEntity(data)
+	return
+}
+
+// PatchPayment applies a merge patch to the payment. The patched payment
+// must be as valid as a new one.
+func (ts *PaymentService) PatchPayment(ctx context.Context, id string, version int, patch payment.Patch) (res payment.Response, err error) {
+	current, err := ts.paymentRepository.Get(ctx, id)
+	if err != nil {
+		return
 	}
-	err = ts.paymentRepository.Update(ctx, id, data)
+	if version != 0 && version != current.Version {
+		err = payment.ErrorVersionMismatch
+		return
+	}
+
+	req := patch.Apply(current)
+	if err = req.Validate(); err != nil {
+		return
+	}
+	return ts.UpdatePayment(ctx, id, current.Version, req)
+}
+
+func (ts *PaymentService) RefundPayment(ctx context.Context, id string) (err error) {
+	err = ts.paymentRepository.Refund(ctx, id)
 	return
 }
 
@@ -0,0 +1,10 @@
+// Package migrations embeds the goose migrations of the service, so the
+// binary carries them wherever it runs.
+package migrations
+
+import "embed"
+
+// FS holds the migrations under postgres/.
+//
+//go:embed postgres/*.sql
+var FS embed.FS
@@ -0,0 +1,17 @@
+-- +goose Up
+-- +goose StatementBegin
+-- The first payment schema references users and orders, which live in the
+-- databases of the user and order services. In a database of its own these
+-- empty tables stand in for them until the keys are dropped; in a shared
+-- database the real tables already exist and nothing is created.
+CREATE TABLE IF NOT EXISTS users (
+    id UUID PRIMARY KEY
+);
+
+CREATE TABLE IF NOT EXISTS orders (
+    id UUID PRIMARY KEY
+);
+-- +goose StatementEnd
+
+-- +goose Down
+-- the tables may be the real ones of a shared database, they are kept
@@ -0,0 +1,14 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE payments
+    ALTER COLUMN amount TYPE BIGINT USING ROUND(amount::NUMERIC * 100)::BIGINT,
+    ADD COLUMN currency CHAR(3) NOT NULL DEFAULT 'KZT';
+ALTER TABLE payments ALTER COLUMN currency DROP DEFAULT;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE payments
+    DROP COLUMN currency,
+    ALTER COLUMN amount TYPE VARCHAR USING (amount::NUMERIC / 100)::VARCHAR;
+-- +goose StatementEnd
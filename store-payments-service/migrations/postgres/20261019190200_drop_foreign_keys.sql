@@ -0,0 +1,17 @@
+-- +goose Up
+-- +goose StatementBegin
+-- users and orders belong to other services, the payment service checks
+-- them over the API of the order service
+ALTER TABLE payments DROP CONSTRAINT IF EXISTS payments_user_id_fkey;
+ALTER TABLE payments DROP CONSTRAINT IF EXISTS payments_order_id_fkey;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+-- rows written while the keys were gone may point to nothing, the keys only
+-- hold for new rows until they are validated
+ALTER TABLE payments ADD CONSTRAINT payments_user_id_fkey
+    FOREIGN KEY (user_id) REFERENCES users(id) NOT VALID;
+ALTER TABLE payments ADD CONSTRAINT payments_order_id_fkey
+    FOREIGN KEY (order_id) REFERENCES orders(id) NOT VALID;
+-- +goose StatementEnd
@@ -0,0 +1,10 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE payments ADD COLUMN deleted_at TIMESTAMP;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DELETE FROM payments WHERE deleted_at IS NOT NULL;
+ALTER TABLE payments DROP COLUMN deleted_at;
+-- +goose StatementEnd
@@ -0,0 +1,109 @@
+// Package apperror is the error model of the APIs. An Error carries a
+// stable, machine readable code clients can switch on, the HTTP status it is
+// served with, a message for people and optional details.
+package apperror
+
+import (
+	"errors"
+	"maps"
+	"net/http"
+)
+
+// Error is an error a client can act on. It is served as the error of the
+// response envelope:
+//
+//	{"code": "user_not_found", "message": "user not found", "details": {"id": "..."}}
+type Error struct {
+	Code    string         `json:"code"`
+	Message string         `json:"message"`
+	Details map[string]any `json:"details,omitempty"`
+	Status  int            `json:"-"`
+}
+
+// Internal is what a client gets for any error that is not an Error, the
+// cause is logged instead of shown.
+var Internal = New(http.StatusInternalServerError, "internal", "internal server error")
+
+func New(status int, code, message string) *Error {
+	return &Error{
+		Code:    code,
+		Message: message,
+		Status:  status,
+	}
+}
+
+// BadRequest is for a request that is wrong whatever the state of the
+// service.
+func BadRequest(code, message string) *Error {
+	return New(http.StatusBadRequest, code, message)
+}
+
+// Unauthorized is for a request whose caller is not identified, e.g. it
+// has no access token or a bad one.
+func Unauthorized(code, message string) *Error {
+	return New(http.StatusUnauthorized, code, message)
+}
+
+// Forbidden is for a caller who is identified but not allowed to do what
+// the request asks.
+func Forbidden(code, message string) *Error {
+	return New(http.StatusForbidden, code, message)
+}
+
+func NotFound(code, message string) *Error {
+	return New(http.StatusNotFound, code, message)
+}
+
+// Conflict is for a request the current state of the resource does not
+// allow, e.g. a duplicate or a transition that is not possible.
+func Conflict(code, message string) *Error {
+	return New(http.StatusConflict, code, message)
+}
+
+// PreconditionFailed is for a conditional request, e.g. one with If-Match,
+// whose condition does not hold.
+func PreconditionFailed(code, message string) *Error {
+	return New(http.StatusPreconditionFailed, code, message)
+}
+
+// Unavailable is for a dependency that does not answer, the request may
+// succeed when retried.
+func Unavailable(code, message string) *Error {
+	return New(http.StatusServiceUnavailable, code, message)
+}
+
+// InvalidBody reports a request body that cannot be decoded.
+func InvalidBody(err error) *Error {
+	return BadRequest("invalid_body", "request body is malformed").WithDetail("reason", err.Error())
+}
+
+func (e *Error) Error() string {
+	return e.Message
+}
+
+// Is matches errors by code, so an error with details added is still the
+// error it was made from.
+func (e *Error) Is(target error) bool {
+	t, ok := target.(*Error)
+	return ok && t.Code == e.Code
+}
+
+// WithDetail returns a copy of e with the detail added.
+func (e *Error) WithDetail(key string, value any) *Error {
+	c := *e
+	c.Details = maps.Clone(e.Details)
+	if c.Details == nil {
+		c.Details = map[string]any{}
+	}
+	c.Details[key] = value
+	return &c
+}
+
+// From returns the Error err is or wraps, Internal for any other error.
+func From(err error) *Error {
+	var e *Error
+	if errors.As(err, &e) {
+		return e
+	}
+	return Internal
+}
@@ -0,0 +1,23 @@
+package validate
+
+import (
+	"github.com/go-playground/validator/v10"
+	"payment-service/pkg/money"
+)
+
+func init() {
+	Register("currency", "must be a supported currency", func(fl validator.FieldLevel) bool {
+		return money.IsValidCurrency(fl.Field().String())
+	})
+	Register("money", "must be an amount in a supported currency", func(fl validator.FieldLevel) bool {
+		m, ok := fl.Field().Interface().(money.Money)
+		return ok && m.Amount >= 0 && m.Validate() == nil
+	})
+	Register("positive", "must be more than zero", func(fl validator.FieldLevel) bool {
+		m, ok := fl.Field().Interface().(money.Money)
+		return ok && m.IsPositive()
+	})
+	Register("exchange_rate", "must be a positive decimal number", func(fl validator.FieldLevel) bool {
+		return money.IsValidRate(fl.Field().String())
+	})
+}
@@ -0,0 +1,153 @@
+// Package validate checks request DTOs against the rules in their validate
+// struct tags. It reports every field that breaks a rule rather than the
+// first one, so a client can fix a request in one go.
+package validate
+
+import (
+	"errors"
+	"github.com/go-playground/validator/v10"
+	"net/http"
+	"payment-service/pkg/apperror"
+	"reflect"
+	"regexp"
+	"strings"
+)
+
+// ErrorFailed is the error of a request that breaks rules, the violations
+// are its violations detail.
+var ErrorFailed = apperror.New(http.StatusUnprocessableEntity, "validation_failed", "request validation failed")
+
+// Violation is a rule a field breaks. Field is the JSON path of the field,
+// e.g. items[0].quantity.
+type Violation struct {
+	Field   string `json:"field"`
+	Rule    string `json:"rule"`
+	Message string `json:"message"`
+}
+
+var (
+	validate = validator.New(validator.WithRequiredStructEnabled())
+	messages = map[string]string{}
+
+	countryPattern = regexp.MustCompile(`^[A-Z]{2}$`)
+)
+
+func init() {
+	validate.RegisterTagNameFunc(jsonName)
+	Register("country", "must be a two letter ISO 3166 country code", func(fl validator.FieldLevel) bool {
+		return countryPattern.MatchString(fl.Field().String())
+	})
+}
+
+// Register adds a rule that can be used in validate tags. message is what a
+// client is told about a field that breaks it.
+func Register(tag, message string, fn validator.Func) {
+	if err := validate.RegisterValidation(tag, fn); err != nil {
+		panic(err)
+	}
+	messages[tag] = message
+}
+
+// Pattern registers a rule for strings that must match re.
+func Pattern(tag, message string, re *regexp.Regexp) {
+	Register(tag, message, func(fl validator.FieldLevel) bool {
+		return re.MatchString(fl.Field().String())
+	})
+}
+
+// Check returns the violations of the rules in the tags of s, a pointer to
+// a struct.
+func Check(s any) []Violation {
+	err := validate.Struct(s)
+	var fieldErrs validator.ValidationErrors
+	if !errors.As(err, &fieldErrs) {
+		if err != nil {
+			// s is not a struct, a bug rather than a bad request
+			panic(err)
+		}
+		return nil
+	}
+
+	res := make([]Violation, 0, len(fieldErrs))
+	for _, fieldErr := range fieldErrs {
+		res = append(res, Violation{
+			Field:   fieldPath(fieldErr),
+			Rule:    fieldErr.Tag(),
+			Message: message(fieldErr),
+		})
+	}
+	return res
+}
+
+// Error is ErrorFailed with violations, nil when there are none.
+func Error(violations []Violation) error {
+	if len(violations) == 0 {
+		return nil
+	}
+	return ErrorFailed.WithDetail("violations", violations)
+}
+
+// Struct is Error(Check(s)), for requests all rules of which fit in tags.
+func Struct(s any) error {
+	return Error(Check(s))
+}
+
+// Violations returns the violations err carries, nil when err is not a
+// validation error.
+func Violations(err error) []Violation {
+	var appErr *apperror.Error
+	if !errors.As(err, &appErr) {
+		return nil
+	}
+	violations, _ := appErr.Details["violations"].([]Violation)
+	return violations
+}
+
+func jsonName(field reflect.StructField) string {
+	name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+	return name
+}
+
+// fieldPath drops the name of the validated struct from the namespace of
+// the field.
+func fieldPath(fieldErr validator.FieldError) string {
+	_, path, _ := strings.Cut(fieldErr.Namespace(), ".")
+	return path
+}
+
+func message(fieldErr validator.FieldError) string {
+	if msg, ok := messages[fieldErr.Tag()]; ok {
+		return msg
+	}
+
+	param, unit := fieldErr.Param(), ""
+	switch fieldErr.Kind() {
+	case reflect.String:
+		unit = " characters"
+	case reflect.Slice, reflect.Array, reflect.Map:
+		unit = " items"
+	}
+	switch fieldErr.Tag() {
+	case "required", "required_if":
+		return "is required"
+	case "min", "gte":
+		return "must be at least " + param + unit
+	case "max", "lte":
+		return "must be at most " + param + unit
+	case "gt":
+		return "must be more than " + param + unit
+	case "lt":
+		return "must be less than " + param + unit
+	case "len":
+		return "must be exactly " + param + unit
+	case "oneof":
+		return "must be one of " + strings.Join(strings.Fields(param), ", ")
+	case "email":
+		return "must be an email address"
+	case "uuid":
+		return "must be a UUID"
+	case "unique":
+		return "must not have duplicates"
+	}
+	return "is invalid"
+}
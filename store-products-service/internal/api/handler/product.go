@@ -2,11 +2,16 @@ package handler
 
 import (
 	"errors"
+	"fmt"
 	"github.com/gin-gonic/gin"
+	"log/slog"
 	"net/http"
+	"path/filepath"
 	"product-service/internal/domain/product"
 	interfaces "product-service/internal/service/interface"
+	"product-service/pkg/apperror"
 	"product-service/pkg/response"
+	"strings"
 )
 
 type ProductHandler struct {
@@ -21,43 +26,34 @@ func NewProductHandler(service interfaces.ProductService) *ProductHandler {
 
 // CreateProduct godoc
 // @Summary Create a new order
-// @Description Create a new order with the input payload
+// @Description Create a new order with the input payload. Requires the products:write permission.
 // @Tags products
 // @Accept json
 // @Produce json
+// @Security BearerAuth
 // @Param payment body product.Request true "Product Request"
 // @Success 201 {object} response.Response
 // @Failure 400 {object} response.Response
+// @Failure 401 {object} response.Response
+// @Failure 403 {object} response.Response
+// @Failure 422 {object} response.Response
 // @Failure 500 {object} response.Response
 // @Router /products [post]
 func (th *ProductHandler) CreateProduct(c *gin.Context) {
 	req := product.Request{}
-	if err := c.BindJSON(&req); err != nil {
-		errRes := response.ClientResponse(http.StatusBadRequest, "fields provided are wrong", nil, err.Error())
-		c.JSON(http.StatusBadRequest, errRes)
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(apperror.InvalidBody(err))
 		return
 	}
 
 	if err := req.Validate(); err != nil {
-		errRes := response.ClientResponse(http.StatusBadRequest, "fields provided are wrong", nil, err.Error())
-		c.JSON(http.StatusBadRequest, errRes)
+		c.Error(err)
 		return
 	}
 
 	res, err := th.productService.CreateProduct(c.Request.Context(), req)
 	if err != nil {
-		if errors.Is(err, product.ErrorNotFound) {
-			errRes := response.ClientResponse(http.StatusBadRequest, "fields must be unique", nil, err.Error())
-			c.JSON(http.StatusBadRequest, errRes)
-			return
-		}
-		if errors.Is(err, product.ErrorInvalidDate) {
-			errRes := response.ClientResponse(http.StatusBadRequest, "fields provided are wrong", nil, err.Error())
-			c.JSON(http.StatusBadRequest, errRes)
-			return
-		}
-		errRes := response.ClientResponse(http.StatusInternalServerError, "failed to create order", nil, err.Error())
-		c.JSON(http.StatusInternalServerError, errRes)
+		c.Error(err)
 		return
 	}
 	successRes := response.ClientResponse(http.StatusCreated, "the order was successfully created", res, nil)
@@ -69,19 +65,21 @@ func (th *ProductHandler) CreateProduct(c *gin.Context) {
 // @Description Get a list of products
 // @Tags products
 // @Produce json
+// @Param currency query string false "Currency to show prices in, overrides the Accept-Currency header"
+// @Param Accept-Currency header string false "Currency to show prices in"
 // @Success 200 {object} response.Response
+// @Failure 400 {object} response.Response
 // @Failure 500 {object} response.Response
 // @Router /products [get]
 func (th *ProductHandler) ListProducts(c *gin.Context) {
-	res, err := th.productService.ListProduct(c.Request.Context())
+	res, err := th.productService.ListProduct(c.Request.Context(), requestCurrency(c))
 	if err != nil {
 		if errors.Is(err, product.ErrorNotFound) {
 			errRes := response.ClientResponse(http.StatusOK, "no products found", "", nil)
 			c.JSON(http.StatusOK, errRes)
 			return
 		}
-		errRes := response.ClientResponse(http.StatusInternalServerError, "failed to list products", nil, err.Error())
-		c.JSON(http.StatusInternalServerError, errRes)
+		c.Error(err)
 		return
 	}
 
@@ -95,77 +93,127 @@ func (th *ProductHandler) ListProducts(c *gin.Context) {
 // @Tags products
 // @Produce json
 // @Param id path string true "Product ID"
+// @Param currency query string false "Currency to show prices in, overrides the Accept-Currency header"
+// @Param Accept-Currency header string false "Currency to show prices in"
 // @Success 200 {object} response.Response
+// @Header 200 {string} ETag "Version of the product"
+// @Failure 400 {object} response.Response
+// @Failure 404 {object} response.Response
 // @Failure 500 {object} response.Response
 // @Router /products/{id} [get]
 func (th *ProductHandler) GetProduct(c *gin.Context) {
 	id := c.Param("id")
-	res, err := th.productService.GetProduct(c.Request.Context(), id)
+	res, err := th.productService.GetProduct(c.Request.Context(), id, requestCurrency(c))
 	if err != nil {
-		if errors.Is(err, product.ErrorNotFound) {
-			errRes := response.ClientResponse(http.StatusOK, "no products found", "", nil)
-			c.JSON(http.StatusOK, errRes)
-			return
-		}
-		errRes := response.ClientResponse(http.StatusInternalServerError, "failed to get order", nil, err.Error())
-		c.JSON(http.StatusInternalServerError, errRes)
+		c.Error(err)
 		return
 	}
+	setETag(c, res.Version)
 	successRes := response.ClientResponse(http.StatusOK, "the order details", res, nil)
 	c.JSON(http.StatusOK, successRes)
 }
 
 // UpdateProduct godoc
 // @Summary Update a order by ID
-// @Description Update details of a order by its ID
+// @Description Overwrite a product by its ID. With If-Match only the version the ETag names is updated. Requires the products:write permission.
 // @Tags products
 // @Accept json
 // @Produce json
+// @Security BearerAuth
 // @Param id path string true "Product ID"
+// @Param If-Match header string false "ETag of the product"
 // @Param order body product.Request true "Product Request"
 // @Success 200 {object} response.Response
+// @Header 200 {string} ETag "Version of the product"
 // @Failure 400 {object} response.Response
+// @Failure 401 {object} response.Response
+// @Failure 403 {object} response.Response
 // @Failure 404 {object} response.Response
+// @Failure 412 {object} response.Response
+// @Failure 422 {object} response.Response
 // @Failure 500 {object} response.Response
 // @Router /products/{id} [put]
 func (th *ProductHandler) UpdateProduct(c *gin.Context) {
 	id := c.Param("id")
+	version, err := ifMatch(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
 	req := product.Request{}
-	if err := c.BindJSON(&req); err != nil {
-		errRes := response.ClientResponse(http.StatusBadRequest, "fields provided are wrong", nil, err.Error())
-		c.JSON(http.StatusBadRequest, errRes)
+	if err = c.ShouldBindJSON(&req); err != nil {
+		c.Error(apperror.InvalidBody(err))
 		return
 	}
 
-	if err := req.Validate(); err != nil {
-		errRes := response.ClientResponse(http.StatusBadRequest, "fields provided are wrong", nil, err.Error())
-		c.JSON(http.StatusBadRequest, errRes)
+	if err = req.Validate(); err != nil {
+		c.Error(err)
 		return
 	}
 
-	err := th.productService.UpdateProduct(c.Request.Context(), id, req)
+	res, err := th.productService.UpdateProduct(c.Request.Context(), id, version, req)
 	if err != nil {
-		if errors.Is(err, product.ErrorNotFound) {
-			errRes := response.ClientResponse(http.StatusNotFound, "order not found", nil, err.Error())
-			c.JSON(http.StatusNotFound, errRes)
-			return
+		c.Error(err)
+		return
+	}
+	setETag(c, res.Version)
+	successRes := response.ClientResponse(http.StatusOK, "the order was successfully updated", res, nil)
+	c.JSON(http.StatusOK, successRes)
+}
 
-		}
-		errRes := response.ClientResponse(http.StatusInternalServerError, "failed to update order", nil, err.Error())
-		c.JSON(http.StatusInternalServerError, errRes)
+// PatchProduct godoc
+// @Summary Patch a product by ID
+// @Description Change the fields of a product given in a JSON merge patch, null clears a field. With If-Match only the version the ETag names is updated. Requires the products:write permission.
+// @Tags products
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Product ID"
+// @Param If-Match header string false "ETag of the product"
+// @Param product body product.Patch true "Product Patch"
+// @Success 200 {object} response.Response
+// @Header 200 {string} ETag "Version of the product"
+// @Failure 400 {object} response.Response
+// @Failure 401 {object} response.Response
+// @Failure 403 {object} response.Response
+// @Failure 404 {object} response.Response
+// @Failure 412 {object} response.Response
+// @Failure 422 {object} response.Response
+// @Failure 500 {object} response.Response
+// @Router /products/{id} [patch]
+func (th *ProductHandler) PatchProduct(c *gin.Context) {
+	id := c.Param("id")
+	version, err := ifMatch(c)
+	if err != nil {
+		c.Error(err)
 		return
 	}
-	successRes := response.ClientResponse(http.StatusOK, "the order was successfully updated", nil, nil)
+	patch := product.Patch{}
+	if err = bindPatch(c, &patch); err != nil {
+		c.Error(err)
+		return
+	}
+
+	res, err := th.productService.PatchProduct(c.Request.Context(), id, version, patch)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	setETag(c, res.Version)
+	successRes := response.ClientResponse(http.StatusOK, "the product was successfully updated", res, nil)
 	c.JSON(http.StatusOK, successRes)
 }
 
 // DeleteProduct godoc
 // @Summary Delete a order by ID
-// @Description Delete a order by its ID
+// @Description Delete a order by its ID. Requires the products:write permission.
 // @Tags products
 // @Produce json
+// @Security BearerAuth
 // @Param id path string true "Product ID"
 // @Success 200 {object} response.Response
+// @Failure 401 {object} response.Response
+// @Failure 403 {object} response.Response
 // @Failure 404 {object} response.Response
 // @Failure 500 {object} response.Response
 // @Router /products/{id} [delete]
@@ -173,19 +221,40 @@ func (th *ProductHandler) DeleteProduct(c *gin.Context) {
 	id := c.Param("id")
 	err := th.productService.DeleteProduct(c.Request.Context(), id)
 	if err != nil {
-		if errors.Is(err, product.ErrorNotFound) {
-			errRes := response.ClientResponse(http.StatusNotFound, "order not found", nil, err.Error())
-			c.JSON(http.StatusNotFound, errRes)
-			return
-		}
-		errRes := response.ClientResponse(http.StatusInternalServerError, "failed to delete order", nil, err.Error())
-		c.JSON(http.StatusInternalServerError, errRes)
+		c.Error(err)
 		return
 	}
 	successRes := response.ClientResponse(http.StatusOK, "the order was successfully deleted", nil, nil)
 	c.JSON(http.StatusOK, successRes)
 }
 
+// RestoreProduct godoc
+// @Summary Restore a deleted product
+// @Description Bring a deleted product back, for admins
+// @Tags products
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Product ID"
+// @Success 200 {object} response.Response
+// @Header 200 {string} ETag "Version of the product"
+// @Failure 401 {object} response.Response
+// @Failure 403 {object} response.Response
+// @Failure 404 {object} response.Response
+// @Failure 409 {object} response.Response
+// @Failure 500 {object} response.Response
+// @Router /products/{id}/restore [post]
+func (th *ProductHandler) RestoreProduct(c *gin.Context) {
+	id := c.Param("id")
+	res, err := th.productService.RestoreProduct(c.Request.Context(), id)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	setETag(c, res.Version)
+	successRes := response.ClientResponse(http.StatusOK, "the product was successfully restored", res, nil)
+	c.JSON(http.StatusOK, successRes)
+}
+
 // SearchProduct godoc
 // @Summary Search for a order by filter
 // @Description Search for a order by filter
@@ -193,24 +262,125 @@ func (th *ProductHandler) DeleteProduct(c *gin.Context) {
 // @Produce json
 // @Param filter query string true "Filter"
 // @Param value query string true "Value"
+// @Param currency query string false "Currency to show prices in, overrides the Accept-Currency header"
+// @Param Accept-Currency header string false "Currency to show prices in"
 // @Success 200 {object} response.Response
+// @Failure 400 {object} response.Response
 // @Failure 404 {object} response.Response
 // @Failure 500 {object} response.Response
 // @Router /products/search [get]
 func (th *ProductHandler) SearchProduct(c *gin.Context) {
 	filter := c.Query("filter")
 	value := c.Query("value")
-	res, err := th.productService.SearchProduct(c.Request.Context(), filter, value)
+	res, err := th.productService.SearchProduct(c.Request.Context(), filter, value, requestCurrency(c))
 	if err != nil {
 		if errors.Is(err, product.ErrorNotFound) {
 			errRes := response.ClientResponse(http.StatusOK, "no products found", "", nil)
 			c.JSON(http.StatusOK, errRes)
 			return
 		}
-		errRes := response.ClientResponse(http.StatusInternalServerError, "failed to search products", nil, err.Error())
-		c.JSON(http.StatusInternalServerError, errRes)
+		c.Error(err)
 		return
 	}
 	successRes := response.ClientResponse(http.StatusOK, "the products list", res, nil)
 	c.JSON(http.StatusOK, successRes)
 }
+
+// ImportProducts godoc
+// @Summary Import products in bulk
+// @Description Create or update products from a CSV or NDJSON file. Rows are validated like a single product and the report lists every rejected row in file order. The import is one transaction: rejected rows are skipped, while a broken file or a database failure imports nothing. Requires the products:write permission.
+// @Tags products
+// @Accept text/csv,application/x-ndjson,multipart/form-data
+// @Produce json
+// @Security BearerAuth
+// @Param format query string false "File format (csv or ndjson), detected from the content type when omitted"
+// @Param file formData file false "Import file when sent as multipart/form-data"
+// @Success 200 {object} response.Response{data=product.ImportResponse}
+// @Failure 400 {object} response.Response
+// @Failure 401 {object} response.Response
+// @Failure 403 {object} response.Response
+// @Failure 500 {object} response.Response
+// @Router /products/import [post]
+func (th *ProductHandler) ImportProducts(c *gin.Context) {
+	format := c.Query("format")
+	body := c.Request.Body
+	if c.ContentType() == "multipart/form-data" {
+		header, err := c.FormFile("file")
+		if err != nil {
+			c.Error(apperror.InvalidBody(err))
+			return
+		}
+		file, err := header.Open()
+		if err != nil {
+			c.Error(apperror.InvalidBody(err))
+			return
+		}
+		defer file.Close()
+		body = file
+		if format == "" {
+			format = strings.TrimPrefix(strings.ToLower(filepath.Ext(header.Filename)), ".")
+		}
+	}
+	if format == "" {
+		format = formatFromContentType(c.ContentType())
+	}
+
+	res, err := th.productService.ImportProducts(c.Request.Context(), format, body)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	successRes := response.ClientResponse(http.StatusOK, "the products import report", res, nil)
+	c.JSON(http.StatusOK, successRes)
+}
+
+// ExportProducts godoc
+// @Summary Export all products
+// @Description Stream every product as CSV or NDJSON
+// @Tags products
+// @Produce text/csv,application/x-ndjson
+// @Param format query string false "File format (csv or ndjson)" default(csv)
+// @Success 200 {file} file
+// @Failure 400 {object} response.Response
+// @Router /products/export [get]
+func (th *ProductHandler) ExportProducts(c *gin.Context) {
+	format := c.DefaultQuery("format", product.FormatCSV)
+	if !product.IsValidFormat(format) {
+		c.Error(product.ErrorInvalidFormat)
+		return
+	}
+
+	contentType := "text/csv"
+	if format == product.FormatNDJSON {
+		contentType = "application/x-ndjson"
+	}
+	c.Header("Content-Type", contentType)
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=products.%s", format))
+	c.Status(http.StatusOK)
+
+	// the status line is already sent, so a failure can only cut the stream short
+	if err := th.productService.ExportProducts(c.Request.Context(), format, c.Writer); err != nil {
+		slog.ErrorContext(c.Request.Context(), "failed to export products", "format", format, "error", err)
+		c.Abort()
+	}
+}
+
+// requestCurrency picks the display currency from ?currency= or, failing
+// that, the Accept-Currency header.
+func requestCurrency(c *gin.Context) string {
+	if currency := c.Query("currency"); currency != "" {
+		return currency
+	}
+	return c.GetHeader("Accept-Currency")
+}
+
+func formatFromContentType(contentType string) string {
+	switch contentType {
+	case "text/csv":
+		return product.FormatCSV
+	case "application/x-ndjson", "application/jsonl", "application/x-jsonlines":
+		return product.FormatNDJSON
+	default:
+		return ""
+	}
+}
@@ -0,0 +1,210 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"github.com/gin-gonic/gin"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"product-service/internal/domain/product"
+	interfaces "product-service/internal/repository/interface"
+	"product-service/internal/service"
+	"product-service/pkg/apperror"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// fakeProducts imports into and exports from a slice, rows titled "Gone"
+// fail the way a deleted product does.
+type fakeProducts struct {
+	interfaces.ProductRepository
+	products []product.Entity
+}
+
+func (fp *fakeProducts) Import(ctx context.Context, fn func(upsert interfaces.Upsert) error) error {
+	return fn(func(entity product.Entity) (rowErr, err error) {
+		if entity.Title == "Gone" {
+			return product.ErrorNotFound, nil
+		}
+		fp.products = append(fp.products, entity)
+		return nil, nil
+	})
+}
+
+func (fp *fakeProducts) Export(ctx context.Context, fn func(product.Entity) error) error {
+	for _, entity := range fp.products {
+		if err := fn(entity); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func newTestRouter(products *fakeProducts) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	productHandler := NewProductHandler(service.NewProductService(products, nil))
+	router := gin.New()
+	// answers with the code of the error a handler failed with
+	router.Use(func(c *gin.Context) {
+		c.Next()
+		if len(c.Errors) > 0 {
+			appErr := apperror.From(c.Errors.Last().Err)
+			c.JSON(appErr.Status, gin.H{"error": appErr})
+		}
+	})
+	router.POST("/products/import", productHandler.ImportProducts)
+	router.GET("/products/export", productHandler.ExportProducts)
+	return router
+}
+
+func TestImportProducts(t *testing.T) {
+	const csvFile = "title,description,price,currency,category,quantity\n" +
+		"Lamp,Desk lamp,12.50,USD,home,3\n" +
+		"Gone,Deleted product,1,USD,home,1\n" +
+		"Mug,Tea mug,-4,EUR,kitchen,10\n"
+	const ndjsonFile = `{"title":"Lamp","description":"Desk lamp","price":{"amount":"12.50","currency":"USD"},"category":"home","quantity":3}` + "\n" +
+		`not json` + "\n"
+
+	multipartBody := func(filename, content string) (*bytes.Buffer, string) {
+		var body bytes.Buffer
+		writer := multipart.NewWriter(&body)
+		part, _ := writer.CreateFormFile("file", filename)
+		part.Write([]byte(content))
+		writer.Close()
+		return &body, writer.FormDataContentType()
+	}
+
+	type rowError struct {
+		Row        int `json:"row"`
+		Violations []struct {
+			Field string `json:"field"`
+		} `json:"violations"`
+	}
+	csvErrors := []rowError{{Row: 3}, {Row: 4, Violations: []struct {
+		Field string `json:"field"`
+	}{{Field: "price"}}}}
+
+	tests := []struct {
+		name        string
+		query       string
+		contentType string
+		body        func() (*bytes.Buffer, string)
+		wantCode    int
+		wantErrCode string
+		imported    int
+		errors      []rowError
+	}{
+		{
+			name:     "csv by content type",
+			body:     func() (*bytes.Buffer, string) { return bytes.NewBufferString(csvFile), "text/csv" },
+			wantCode: http.StatusOK,
+			imported: 1,
+			errors:   csvErrors,
+		},
+		{
+			name:     "ndjson by query",
+			query:    "?format=ndjson",
+			body:     func() (*bytes.Buffer, string) { return bytes.NewBufferString(ndjsonFile), "application/octet-stream" },
+			wantCode: http.StatusOK,
+			imported: 1,
+			errors:   []rowError{{Row: 2}},
+		},
+		{
+			name:     "multipart by file extension",
+			body:     func() (*bytes.Buffer, string) { return multipartBody("products.CSV", csvFile) },
+			wantCode: http.StatusOK,
+			imported: 1,
+			errors:   csvErrors,
+		},
+		{
+			name:        "unknown format",
+			body:        func() (*bytes.Buffer, string) { return bytes.NewBufferString(csvFile), "text/plain" },
+			wantCode:    http.StatusBadRequest,
+			wantErrCode: "invalid_format",
+		},
+		{
+			name:        "multipart without a file",
+			body:        func() (*bytes.Buffer, string) { return multipartBody("", "") },
+			wantCode:    http.StatusBadRequest,
+			wantErrCode: "invalid_body",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router := newTestRouter(&fakeProducts{})
+			body, contentType := tt.body()
+			req := httptest.NewRequest(http.MethodPost, "/products/import"+tt.query, body)
+			req.Header.Set("Content-Type", contentType)
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, req)
+
+			if rec.Code != tt.wantCode {
+				t.Fatalf("status = %d, want %d: %s", rec.Code, tt.wantCode, rec.Body)
+			}
+			var res struct {
+				Data struct {
+					Total    int        `json:"total"`
+					Imported int        `json:"imported"`
+					Failed   int        `json:"failed"`
+					Errors   []rowError `json:"errors"`
+				} `json:"data"`
+				Error *apperror.Error `json:"error"`
+			}
+			if err := json.Unmarshal(rec.Body.Bytes(), &res); err != nil {
+				t.Fatal(err)
+			}
+			if tt.wantErrCode != "" {
+				if res.Error == nil || res.Error.Code != tt.wantErrCode {
+					t.Errorf("error = %+v, want code %s", res.Error, tt.wantErrCode)
+				}
+				return
+			}
+			if res.Data.Imported != tt.imported || res.Data.Failed != len(tt.errors) || res.Data.Total != tt.imported+len(tt.errors) {
+				t.Errorf("report = %+v", res.Data)
+			}
+			if !reflect.DeepEqual(res.Data.Errors, tt.errors) {
+				t.Errorf("errors = %+v, want %+v", res.Data.Errors, tt.errors)
+			}
+		})
+	}
+}
+
+func TestExportProducts(t *testing.T) {
+	created := time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC)
+	router := newTestRouter(&fakeProducts{products: []product.Entity{
+		{ID: "p1", Title: "Lamp", Description: "Desk lamp", Price: 1250, Currency: "USD", Category: "home", Quantity: 3, CreatedAt: created},
+		{ID: "p2", Title: "Mug", Description: "Tea mug", Price: 400, Currency: "EUR", Category: "kitchen", Quantity: 10, CreatedAt: created},
+	}})
+
+	tests := []struct {
+		query       string
+		wantCode    int
+		contentType string
+		lines       int
+	}{
+		{"", http.StatusOK, "text/csv", 3},
+		{"?format=ndjson", http.StatusOK, "application/x-ndjson", 2},
+		{"?format=xml", http.StatusBadRequest, "application/json; charset=utf-8", 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/products/export"+tt.query, nil)
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, req)
+
+			if rec.Code != tt.wantCode {
+				t.Fatalf("status = %d, want %d: %s", rec.Code, tt.wantCode, rec.Body)
+			}
+			if got := rec.Header().Get("Content-Type"); got != tt.contentType {
+				t.Errorf("Content-Type = %q, want %q", got, tt.contentType)
+			}
+			if got := strings.Count(strings.TrimSpace(rec.Body.String()), "\n") + 1; got != tt.lines {
+				t.Errorf("%d lines, want %d:\n%s", got, tt.lines, rec.Body)
+			}
+		})
+	}
+}
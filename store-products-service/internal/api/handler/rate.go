@@ -0,0 +1,120 @@
+package handler
+
+import (
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"product-service/internal/domain/rate"
+	interfaces "product-service/internal/service/interface"
+	"product-service/pkg/apperror"
+	"product-service/pkg/response"
+)
+
+type RateHandler struct {
+	rateService interfaces.RateService
+}
+
+func NewRateHandler(service interfaces.RateService) *RateHandler {
+	return &RateHandler{
+		rateService: service,
+	}
+}
+
+// SetRate godoc
+// @Summary Create or update an exchange rate
+// @Description Set the price of one unit of the base currency in the quote currency. Requires the rates:write permission.
+// @Tags rates
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param rate body rate.Request true "Rate Request"
+// @Success 200 {object} response.Response
+// @Failure 400 {object} response.Response
+// @Failure 401 {object} response.Response
+// @Failure 403 {object} response.Response
+// @Failure 422 {object} response.Response
+// @Failure 500 {object} response.Response
+// @Router /rates [put]
+func (rh *RateHandler) SetRate(c *gin.Context) {
+	req := rate.Request{}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(apperror.InvalidBody(err))
+		return
+	}
+
+	if err := req.Validate(); err != nil {
+		c.Error(err)
+		return
+	}
+
+	if err := rh.rateService.SetRate(c.Request.Context(), req); err != nil {
+		c.Error(err)
+		return
+	}
+	successRes := response.ClientResponse(http.StatusOK, "the rate was successfully set", nil, nil)
+	c.JSON(http.StatusOK, successRes)
+}
+
+// ListRates godoc
+// @Summary List all exchange rates
+// @Description Get a list of exchange rates
+// @Tags rates
+// @Produce json
+// @Success 200 {object} response.Response
+// @Failure 500 {object} response.Response
+// @Router /rates [get]
+func (rh *RateHandler) ListRates(c *gin.Context) {
+	res, err := rh.rateService.ListRates(c.Request.Context())
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	successRes := response.ClientResponse(http.StatusOK, "the rates list", res, nil)
+	c.JSON(http.StatusOK, successRes)
+}
+
+// GetRate godoc
+// @Summary Get an exchange rate
+// @Description Get the price of one unit of base in quote, derived from the inverse pair when needed
+// @Tags rates
+// @Produce json
+// @Param base path string true "Base currency"
+// @Param quote path string true "Quote currency"
+// @Success 200 {object} response.Response
+// @Failure 400 {object} response.Response
+// @Failure 404 {object} response.Response
+// @Failure 500 {object} response.Response
+// @Router /rates/{base}/{quote} [get]
+func (rh *RateHandler) GetRate(c *gin.Context) {
+	res, err := rh.rateService.GetRate(c.Request.Context(), c.Param("base"), c.Param("quote"))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	successRes := response.ClientResponse(http.StatusOK, "the rate details", res, nil)
+	c.JSON(http.StatusOK, successRes)
+}
+
+// DeleteRate godoc
+// @Summary Delete an exchange rate
+// @Description Delete the exchange rate of a currency pair. Requires the rates:write permission.
+// @Tags rates
+// @Produce json
+// @Security BearerAuth
+// @Param base path string true "Base currency"
+// @Param quote path string true "Quote currency"
+// @Success 200 {object} response.Response
+// @Failure 400 {object} response.Response
+// @Failure 401 {object} response.Response
+// @Failure 403 {object} response.Response
+// @Failure 404 {object} response.Response
+// @Failure 500 {object} response.Response
+// @Router /rates/{base}/{quote} [delete]
+func (rh *RateHandler) DeleteRate(c *gin.Context) {
+	err := rh.rateService.DeleteRate(c.Request.Context(), c.Param("base"), c.Param("quote"))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	successRes := response.ClientResponse(http.StatusOK, "the rate was successfully deleted", nil, nil)
+	c.JSON(http.StatusOK, successRes)
+}
@@ -3,14 +3,28 @@ package routes
 import (
 	"github.com/gin-gonic/gin"
 	"product-service/internal/api/handler"
+	"product-service/pkg/auth"
 )
 
 func InitRoutes(router *gin.RouterGroup, productHandler *handler.ProductHandler) {
+	write := auth.RequirePermission("products:write")
 	router.GET("/", productHandler.ListProducts)
-	router.POST("/", productHandler.CreateProduct)
+	router.POST("/", write, productHandler.CreateProduct)
 	router.GET("/:id", productHandler.GetProduct)
-	router.PUT("/:id", productHandler.UpdateProduct)
-	router.DELETE("/:id", productHandler.DeleteProduct)
+	router.PUT("/:id", write, productHandler.UpdateProduct)
+	router.PATCH("/:id", write, productHandler.PatchProduct)
+	router.DELETE("/:id", write, productHandler.DeleteProduct)
+	router.POST("/:id/restore", auth.RequirePermission("products:restore"), productHandler.RestoreProduct)
 	router.GET("/search", productHandler.SearchProduct)
+	router.POST("/import", write, productHandler.ImportProducts)
+	router.GET("/export", productHandler.ExportProducts)
 
 }
+
+func InitRateRoutes(router *gin.RouterGroup, rateHandler *handler.RateHandler) {
+	write := auth.RequirePermission("rates:write")
+	router.GET("/", rateHandler.ListRates)
+	router.PUT("/", write, rateHandler.SetRate)
+	router.GET("/:base/:quote", rateHandler.GetRate)
+	router.DELETE("/:base/:quote", write, rateHandler.DeleteRate)
+}
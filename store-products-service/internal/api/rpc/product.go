@@ -0,0 +1,168 @@
+package rpc
+
+import (
+	"context"
+	"google.golang.org/protobuf/types/known/timestamppb"
+	"product-service/internal/domain/product"
+	"product-service/internal/domain/rate"
+	services "product-service/internal/service/interface"
+	"product-service/pkg/auth"
+	"product-service/pkg/money"
+	"product-service/pkg/pb/productpb"
+)
+
+// ProductServer checks the permissions the REST routes check, the catalog
+// is read by anybody.
+type ProductServer struct {
+	productpb.UnimplementedProductServiceServer
+	productService services.ProductService
+	rateService    services.RateService
+}
+
+func NewProductServer(productService services.ProductService, rateService services.RateService) *ProductServer {
+	return &ProductServer{
+		productService: productService,
+		rateService:    rateService,
+	}
+}
+
+func (ps *ProductServer) CreateProduct(ctx context.Context, in *productpb.CreateProductRequest) (*productpb.CreateProductResponse, error) {
+	if err := auth.Authorize(ctx, "", "products:write"); err != nil {
+		return nil, statusError(err)
+	}
+
+	price, err := parseMoney(in.GetPrice())
+	if err != nil {
+		return nil, statusError(product.ErrorInvalidPrice)
+	}
+	req := product.Request{
+		Title:       in.GetTitle(),
+		Description: in.GetDescription(),
+		Price:       price,
+		Category:    in.GetCategory(),
+		Quantity:    int(in.GetQuantity()),
+		Weight:      int(in.GetWeight()),
+	}
+	if err = req.Validate(); err != nil {
+		return nil, statusError(err)
+	}
+
+	id, err := ps.productService.CreateProduct(ctx, req)
+	if err != nil {
+		return nil, statusError(err)
+	}
+	return &productpb.CreateProductResponse{Id: id}, nil
+}
+
+func (ps *ProductServer) ListProducts(ctx context.Context, in *productpb.ListProductsRequest) (*productpb.ListProductsResponse, error) {
+	res, err := ps.productService.ListProduct(ctx, in.GetCurrency())
+	if err != nil {
+		return nil, priceError(err)
+	}
+	return &productpb.ListProductsResponse{Products: parseProducts(res)}, nil
+}
+
+func (ps *ProductServer) GetProduct(ctx context.Context, in *productpb.GetProductRequest) (*productpb.Product, error) {
+	res, err := ps.productService.GetProduct(ctx, in.GetId(), in.GetCurrency())
+	if err != nil {
+		return nil, priceError(err)
+	}
+	return parseProduct(res), nil
+}
+
+func (ps *ProductServer) UpdateProduct(ctx context.Context, in *productpb.UpdateProductRequest) (*productpb.UpdateProductResponse, error) {
+	if err := auth.Authorize(ctx, "", "products:write"); err != nil {
+		return nil, statusError(err)
+	}
+
+	price, err := parseMoney(in.GetPrice())
+	if err != nil {
+		return nil, statusError(product.ErrorInvalidPrice)
+	}
+	req := product.Request{
+		Title:       in.GetTitle(),
+		Description: in.GetDescription(),
+		Price:       price,
+		Category:    in.GetCategory(),
+		Quantity:    int(in.GetQuantity()),
+		Weight:      int(in.GetWeight()),
+	}
+	if err = req.Validate(); err != nil {
+		return nil, statusError(err)
+	}
+
+	// the gRPC API has no version, updates are unconditional
+	if _, err = ps.productService.UpdateProduct(ctx, in.GetId(), 0, req); err != nil {
+		return nil, statusError(err)
+	}
+	return &productpb.UpdateProductResponse{}, nil
+}
+
+func (ps *ProductServer) DeleteProduct(ctx context.Context, in *productpb.DeleteProductRequest) (*productpb.DeleteProductResponse, error) {
+	if err := auth.Authorize(ctx, "", "products:write"); err != nil {
+		return nil, statusError(err)
+	}
+
+	if err := ps.productService.DeleteProduct(ctx, in.GetId()); err != nil {
+		return nil, statusError(err)
+	}
+	return &productpb.DeleteProductResponse{}, nil
+}
+
+func (ps *ProductServer) SearchProducts(ctx context.Context, in *productpb.SearchProductsRequest) (*productpb.ListProductsResponse, error) {
+	res, err := ps.productService.SearchProduct(ctx, in.GetFilter(), in.GetValue(), in.GetCurrency())
+	if err != nil {
+		return nil, priceError(err)
+	}
+	return &productpb.ListProductsResponse{Products: parseProducts(res)}, nil
+}
+
+func (ps *ProductServer) GetRate(ctx context.Context, in *productpb.GetRateRequest) (*productpb.Rate, error) {
+	res, err := ps.rateService.GetRate(ctx, in.GetBaseCurrency(), in.GetQuoteCurrency())
+	if err != nil {
+		return nil, statusError(err)
+	}
+	return parseRate(res), nil
+}
+
+func parseMoney(m *productpb.Money) (money.Money, error) {
+	return money.Parse(m.GetAmount(), m.GetCurrency())
+}
+
+func formatMoney(m money.Money) *productpb.Money {
+	return &productpb.Money{Amount: m.Decimal(), Currency: m.Currency}
+}
+
+func parseProduct(res product.Response) *productpb.Product {
+	data := &productpb.Product{
+		Id:          res.ID,
+		Title:       res.Title,
+		Description: res.Description,
+		Price:       formatMoney(res.Price),
+		Category:    res.Category,
+		Quantity:    int64(res.Quantity),
+		Weight:      int64(res.Weight),
+		CreatedAt:   timestamppb.New(res.CreatedAt),
+	}
+	if res.BasePrice != nil {
+		data.BasePrice = formatMoney(*res.BasePrice)
+	}
+	return data
+}
+
+func parseProducts(data []product.Response) (res []*productpb.Product) {
+	res = make([]*productpb.Product, 0, len(data))
+	for _, item := range data {
+		res = append(res, parseProduct(item))
+	}
+	return
+}
+
+func parseRate(res rate.Response) *productpb.Rate {
+	return &productpb.Rate{
+		BaseCurrency:  res.BaseCurrency,
+		QuoteCurrency: res.QuoteCurrency,
+		Rate:          res.Rate,
+		UpdatedAt:     timestamppb.New(res.UpdatedAt),
+	}
+}
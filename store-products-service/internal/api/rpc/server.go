@@ -0,0 +1,60 @@
+package rpc
+
+import (
+	"context"
+	"google.golang.org/grpc"
+	"log/slog"
+	"net"
+	"product-service/internal/config"
+	services "product-service/internal/service/interface"
+	"product-service/pkg/auth"
+	"product-service/pkg/logging"
+	"product-service/pkg/metrics"
+	"product-service/pkg/pb/productpb"
+	"product-service/pkg/tracing"
+)
+
+// Server is the internal gRPC API, it runs next to the REST server and
+// shares its services.
+type Server struct {
+	server *grpc.Server
+}
+
+func NewServer(cfg config.Config, productService services.ProductService, rateService services.RateService) *Server {
+	server := grpc.NewServer(
+		tracing.ServerOption(),
+		grpc.ChainUnaryInterceptor(
+			logging.UnaryServerInterceptor(slog.Default()),
+			metrics.UnaryServerInterceptor(),
+			auth.UnaryServerInterceptor(cfg.TokenSecret),
+		),
+	)
+	productpb.RegisterProductServiceServer(server, NewProductServer(productService, rateService))
+	return &Server{server}
+}
+
+// Run serves on addr until Stop is called.
+func (s *Server) Run(addr string, logger *slog.Logger) error {
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return err
+	}
+
+	logger.Info("starting grpc server", "addr", addr)
+	return s.server.Serve(listener)
+}
+
+// Stop lets the calls in flight finish, those still running when ctx is done
+// are cancelled.
+func (s *Server) Stop(ctx context.Context) {
+	stopped := make(chan struct{})
+	go func() {
+		s.server.GracefulStop()
+		close(stopped)
+	}()
+	select {
+	case <-stopped:
+	case <-ctx.Done():
+		s.server.Stop()
+	}
+}
@@ -0,0 +1,75 @@
+package rpc
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"product-service/internal/domain/product"
+	"product-service/internal/domain/rate"
+	"product-service/pkg/apperror"
+	"product-service/pkg/auth"
+	"product-service/pkg/money"
+	"product-service/pkg/validate"
+)
+
+// statusError maps a service error to the gRPC status a client can act on,
+// anything unexpected is Internal. The code of the error goes along as an
+// ErrorInfo, so clients see the same code as over REST.
+func statusError(err error) error {
+	switch {
+	case errors.Is(err, product.ErrorNotFound), errors.Is(err, rate.ErrorNotFound):
+		return errorStatus(codes.NotFound, err)
+	case errors.Is(err, validate.ErrorFailed), errors.Is(err, product.ErrorInvalidPrice),
+		errors.Is(err, product.ErrorInvalidSearch), errors.Is(err, rate.ErrorInvalidCurrency),
+		errors.Is(err, money.ErrorInvalidCurrency), errors.Is(err, money.ErrorInvalidAmount),
+		errors.Is(err, product.ErrorUnsupportedCurrency):
+		return errorStatus(codes.InvalidArgument, err)
+	case errors.Is(err, auth.ErrorTokenRequired):
+		return errorStatus(codes.Unauthenticated, err)
+	case errors.Is(err, auth.ErrorPermissionDenied):
+		return errorStatus(codes.PermissionDenied, err)
+	}
+	return errorStatus(codes.Internal, err)
+}
+
+// priceError is statusError for calls that show prices in a requested
+// currency, a missing exchange rate means the currency is not supported.
+func priceError(err error) error {
+	if errors.Is(err, rate.ErrorNotFound) {
+		return errorStatus(codes.InvalidArgument, err)
+	}
+	return statusError(err)
+}
+
+func errorStatus(code codes.Code, err error) error {
+	appErr := apperror.From(err)
+	info := &errdetails.ErrorInfo{Reason: appErr.Code, Domain: "product-service"}
+	if len(appErr.Details) > 0 {
+		info.Metadata = make(map[string]string, len(appErr.Details))
+		for key, value := range appErr.Details {
+			info.Metadata[key] = metadataValue(value)
+		}
+	}
+	st, detailsErr := status.New(code, err.Error()).WithDetails(info)
+	if detailsErr != nil {
+		return status.Error(code, err.Error())
+	}
+	return st.Err()
+}
+
+// metadataValue turns a detail into ErrorInfo metadata, which only holds
+// strings. Lists and objects, such as validation violations, are JSON.
+func metadataValue(value any) string {
+	switch value.(type) {
+	case string, int, int64, bool:
+		return fmt.Sprint(value)
+	}
+	b, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Sprint(value)
+	}
+	return string(b)
+}
@@ -1,36 +1,136 @@
 package http
 
 import (
+	"context"
 	"github.com/gin-gonic/gin"
+	"github.com/jmoiron/sqlx"
 	swaggerFiles "github.com/swaggo/files"
 	ginSwagger "github.com/swaggo/gin-swagger"
-	"log"
+	"log/slog"
 	"net/http"
+	"os/signal"
 	"product-service/internal/api/handler"
 	"product-service/internal/api/routes"
+	"product-service/internal/api/rpc"
+	"product-service/internal/config"
+	"product-service/internal/db"
+	"product-service/pkg/apperror"
+	"product-service/pkg/auth"
+	"product-service/pkg/events"
+	"product-service/pkg/logging"
+	"product-service/pkg/metrics"
+	"product-service/pkg/response"
+	"product-service/pkg/tracing"
+	"sync"
+	"syscall"
+	"time"
 )
 
 type Server struct {
-	engine *gin.Engine
+	http            *http.Server
+	grpcAddr        string
+	shutdownTimeout time.Duration
+	db              *sqlx.DB
+	replica         *sqlx.DB
+	relay           *events.Relay
+	rpc             *rpc.Server
 }
 
-func NewServer(productHandler *handler.ProductHandler) *Server {
-	router := gin.Default()
-	router.Use(gin.Logger())
+func NewServer(cfg config.Config, primary *sqlx.DB, replica db.Replica, productHandler *handler.ProductHandler, rateHandler *handler.RateHandler, healthHandler *handler.HealthHandler, relay *events.Relay, rpcServer *rpc.Server) *Server {
+	router := gin.New()
+	router.Use(tracing.Middleware("product-service"))
+	router.Use(logging.Middleware(slog.Default()))
+	router.Use(metrics.Middleware())
 	router.Use(gin.Recovery())
+	router.Use(ErrorMiddleware())
 	router.Use(MethodNotAllowedMiddleware())
+	router.Use(auth.Middleware(cfg.TokenSecret))
 
 	router.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerFiles.Handler))
+	router.GET("/metrics", metrics.Handler())
+	router.GET("/healthz", healthHandler.Live)
+	router.GET("/readyz", healthHandler.Ready)
 
 	routes.InitRoutes(router.Group("/products"), productHandler)
+	routes.InitRateRoutes(router.Group("/rates"), rateHandler)
 
-	return &Server{router}
+	return &Server{
+		http: &http.Server{
+			Addr:         cfg.HTTPAddr,
+			Handler:      router,
+			ReadTimeout:  cfg.ReadTimeout,
+			WriteTimeout: cfg.WriteTimeout,
+			IdleTimeout:  cfg.IdleTimeout,
+		},
+		grpcAddr:        cfg.GRPCAddr,
+		shutdownTimeout: cfg.ShutdownTimeout,
+		db:              primary,
+		replica:         replica.DB,
+		relay:           relay,
+		rpc:             rpcServer,
+	}
 }
 
-func (s *Server) Run(infoLog *log.Logger, errorLog *log.Logger) {
-	infoLog.Printf("starting server on: 8001")
-	err := s.engine.Run(":8001")
-	errorLog.Fatal(err)
+// Run serves REST and gRPC and relays the outbox until SIGINT or SIGTERM.
+// It then stops accepting connections, gives the requests in flight
+// shutdownTimeout to finish, stops the relay and closes the databases.
+// The error is the one a server failed with, nil after a signal.
+func (s *Server) Run(logger *slog.Logger) error {
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	workersCtx, stopWorkers := context.WithCancel(context.Background())
+	var workers sync.WaitGroup
+	workers.Add(1)
+	go func() {
+		defer workers.Done()
+		s.relay.Run(workersCtx, logger)
+	}()
+
+	errs := make(chan error, 2)
+	go func() {
+		errs <- s.rpc.Run(s.grpcAddr, logger)
+	}()
+	go func() {
+		logger.Info("starting server", "addr", s.http.Addr)
+		errs <- s.http.ListenAndServe()
+	}()
+
+	var err error
+	select {
+	case err = <-errs:
+	case <-ctx.Done():
+		logger.Info("shutting down", "timeout", s.shutdownTimeout.String())
+	}
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), s.shutdownTimeout)
+	defer cancel()
+	if shutdownErr := s.http.Shutdown(shutdownCtx); shutdownErr != nil {
+		logger.Error("failed to drain connections", "error", shutdownErr)
+	}
+	s.rpc.Stop(shutdownCtx)
+
+	stopWorkers()
+	stopped := make(chan struct{})
+	go func() {
+		workers.Wait()
+		close(stopped)
+	}()
+	select {
+	case <-stopped:
+	case <-shutdownCtx.Done():
+		logger.Error("background workers did not stop in time")
+	}
+
+	if s.replica != s.db {
+		if closeErr := s.replica.Close(); closeErr != nil {
+			logger.Error("failed to close replica", "error", closeErr)
+		}
+	}
+	if closeErr := s.db.Close(); closeErr != nil {
+		logger.Error("failed to close database", "error", closeErr)
+	}
+	return err
 }
 
 func MethodNotAllowedMiddleware() gin.HandlerFunc {
@@ -39,6 +139,7 @@ func MethodNotAllowedMiddleware() gin.HandlerFunc {
 			"GET":    true,
 			"POST":   true,
 			"PUT":    true,
+			"PATCH":  true,
 			"DELETE": true,
 		}
 
@@ -53,3 +154,18 @@ func MethodNotAllowedMiddleware() gin.HandlerFunc {
 		c.Next()
 	}
 }
+
+// ErrorMiddleware answers with the error a handler passed to c.Error, in the
+// response envelope with the apperror.Error under error. Any other error is
+// answered as internal, the logging middleware logs what it was.
+func ErrorMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Next()
+
+		if len(c.Errors) == 0 || c.Writer.Written() {
+			return
+		}
+		appErr := apperror.From(c.Errors.Last().Err)
+		c.JSON(appErr.Status, response.ClientResponse(appErr.Status, appErr.Message, nil, appErr))
+	}
+}
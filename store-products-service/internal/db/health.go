@@ -0,0 +1,44 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"github.com/jmoiron/sqlx"
+	"github.com/pressly/goose/v3"
+	"product-service/pkg/health"
+)
+
+// Ping checks that the database answers.
+func Ping(db *sqlx.DB) health.Check {
+	return func(ctx context.Context) (string, error) {
+		return "", db.PingContext(ctx)
+	}
+}
+
+// Migrations checks that the latest migration of the service is applied,
+// reporting its version.
+func Migrations(db *sqlx.DB) health.Check {
+	return func(ctx context.Context) (string, error) {
+		migrations, err := goose.CollectMigrations(migrationsDir, 0, goose.MaxVersion)
+		if err != nil {
+			return "", err
+		}
+		last, err := migrations.Last()
+		if err != nil {
+			return "", err
+		}
+
+		var applied bool
+		query := fmt.Sprintf("SELECT is_applied FROM %s WHERE version_id = $1 ORDER BY id DESC LIMIT 1", goose.TableName())
+		err = db.GetContext(ctx, &applied, query, last.Version)
+		if errors.Is(err, sql.ErrNoRows) || err == nil && !applied {
+			return "", fmt.Errorf("migration %d is not applied", last.Version)
+		}
+		if err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("version %d", last.Version), nil
+	}
+}
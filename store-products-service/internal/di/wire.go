@@ -8,6 +8,7 @@ import (
 	_ "github.com/lib/pq"
 	http "product-service/internal/api"
 	"product-service/internal/api/handler"
+	"product-service/internal/api/rpc"
 	"product-service/internal/config"
 	"product-service/internal/db"
 	"product-service/internal/repository"
@@ -17,9 +18,17 @@ import (
 func InitializeAPI(cfg config.Config) (*http.Server, error) {
 	wire.Build(
 		db.ConnectDatabase,
+		db.ConnectReplica,
 		handler.NewProductHandler,
+		handler.NewRateHandler,
+		handler.NewHealthHandler,
 		repository.NewProductRepository,
+		repository.NewRateRepository,
 		service.NewProductService,
+		service.NewRateService,
+		NewHealthChecker,
+		NewRelay,
+		rpc.NewServer,
 		http.NewServer,
 	)
 	return &http.Server{}, nil
@@ -8,6 +8,7 @@ import (
 	_ "github.com/lib/pq"
 	"product-service/internal/api"
 	"product-service/internal/api/handler"
+	"product-service/internal/api/rpc"
 	"product-service/internal/config"
 	"product-service/internal/db"
 	"product-service/internal/repository"
@@ -19,13 +20,26 @@ func InitializeAPI(cfg config.Config) (*http.Server, error) {
 	if err != nil {
 		return nil, err
 	}
-	err = db.Migrate(sqlxDB)
+	if cfg.AutoMigrate {
+		err = db.Migrate(sqlxDB)
+		if err != nil {
+			return nil, err
+		}
+	}
+	replica, err := db.ConnectReplica(cfg, sqlxDB)
 	if err != nil {
 		return nil, err
 	}
-	productRepository := repository.NewProductRepository(sqlxDB)
-	productService := service.NewProductService(productRepository)
+	productRepository := repository.NewProductRepository(sqlxDB, replica)
+	rateRepository := repository.NewRateRepository(sqlxDB)
+	rateService := service.NewRateService(rateRepository)
+	productService := service.NewProductService(productRepository, rateService)
 	productHandler := handler.NewProductHandler(productService)
-	server := http.NewServer(productHandler)
+	rateHandler := handler.NewRateHandler(rateService)
+	checker := NewHealthChecker(sqlxDB, replica)
+	healthHandler := handler.NewHealthHandler(checker)
+	relay := NewRelay(cfg, sqlxDB)
+	rpcServer := rpc.NewServer(cfg, productService, rateService)
+	server := http.NewServer(cfg, sqlxDB, replica, productHandler, rateHandler, healthHandler, relay, rpcServer)
 	return server, nil
 }
@@ -1,50 +1,108 @@
 package product
 
 import (
-	"errors"
+	"product-service/pkg/apperror"
+	"product-service/pkg/money"
+	"product-service/pkg/validate"
 	"time"
 )
 
 var (
-	ErrorNotFound           = errors.New("payment not found")
-	ErrorInvalidTitle       = errors.New("invalid title")
-	ErrorInvalidDescription = errors.New("invalid description")
-	ErrorInvalidDate        = errors.New("invalid date format")
-	ErrorInvalidStatus      = errors.New("invalid status")
-	ErrorInvalidPrice       = errors.New("invalid price")
-	ErrorInvalidCategory    = errors.New("invalid category")
-	ErrorInvalidQuantity    = errors.New("invalid quantity")
-	ErrorInvalidSearch      = errors.New("invalid search filter")
+	ErrorNotFound        = apperror.NotFound("product_not_found", "product not found")
+	ErrorInvalidDate     = apperror.BadRequest("invalid_date", "invalid date format")
+	ErrorInvalidStatus   = apperror.BadRequest("invalid_status", "invalid status")
+	ErrorInvalidPrice    = apperror.BadRequest("invalid_price", "invalid price")
+	ErrorInvalidQuantity = apperror.BadRequest("invalid_quantity", "invalid quantity")
+	ErrorInvalidWeight   = apperror.BadRequest("invalid_weight", "invalid weight")
+	ErrorInvalidSearch   = apperror.BadRequest("invalid_search", "invalid search filter")
+	ErrorInvalidFormat   = apperror.BadRequest("invalid_format", "invalid format")
+	ErrorInvalidRecord   = apperror.BadRequest("invalid_record", "invalid record")
+	ErrorVersionMismatch = apperror.PreconditionFailed("version_mismatch", "product was changed since it was read")
+	ErrorNotDeleted      = apperror.Conflict("product_not_deleted", "product is not deleted")
+)
+
+// ErrorUnsupportedCurrency is a display currency there is no exchange rate
+// for.
+var ErrorUnsupportedCurrency = apperror.BadRequest("currency_not_supported", "currency is not supported")
+
+const (
+	FormatCSV    = "csv"
+	FormatNDJSON = "ndjson"
 )
 
 type Request struct {
-	Title       string  `json:"title"`
-	Description string  `json:"description"`
-	Price       float64 `json:"price"`
-	Category    string  `json:"category"`
-	Quantity    int     `json:"quantity"`
+	Title       string      `json:"title" validate:"required,max=200"`
+	Description string      `json:"description" validate:"required,max=5000"`
+	Price       money.Money `json:"price" validate:"money,positive"`
+	Category    string      `json:"category" validate:"required,max=100"`
+	Quantity    int         `json:"quantity" validate:"gte=0"`
+	Weight      int         `json:"weight" example:"500" validate:"gte=0"`
+}
+
+// Patch is a merge patch of a product, nil fields are left as they are.
+type Patch struct {
+	Title       *string      `json:"title"`
+	Description *string      `json:"description"`
+	Price       *money.Money `json:"price"`
+	Category    *string      `json:"category"`
+	Quantity    *int         `json:"quantity"`
+	Weight      *int         `json:"weight" example:"500"`
+}
+
+// Record is a single product row of a bulk import file. A record with an
+// empty ID creates a new product, otherwise the product with that ID is
+// created or overwritten.
+type Record struct {
+	ID string `json:"id"`
+	Request
+}
+
+// ImportError is a row that was not imported. Violations lists the fields
+// of a record that break the rules for products.
+type ImportError struct {
+	Row        int                  `json:"row"`
+	Error      string               `json:"error"`
+	Violations []validate.Violation `json:"violations,omitempty"`
+}
+
+type ImportResponse struct {
+	Total    int           `json:"total"`
+	Imported int           `json:"imported"`
+	Failed   int           `json:"failed"`
+	Errors   []ImportError `json:"errors"`
 }
 
 type Response struct {
-	ID          string    `json:"id"`
-	Title       string    `json:"title"`
-	Description string    `json:"description"`
-	Price       float64   `json:"price"`
-	Category    string    `json:"category"`
-	Quantity    int       `json:"quantity"`
-	CreatedAt   time.Time `json:"created_at"`
+	ID          string       `json:"id"`
+	Title       string       `json:"title"`
+	Description string       `json:"description"`
+	Price       money.Money  `json:"price"`
+	BasePrice   *money.Money `json:"base_price,omitempty"`
+	Category    string       `json:"category"`
+	Quantity    int          `json:"quantity"`
+	Weight      int          `json:"weight"`
+	CreatedAt   time.Time    `json:"created_at"`
+	Version     int          `json:"version"`
+	// DeletedAt is only set for a deleted product, e.g. in the audit log
+	DeletedAt *time.Time `json:"deleted_at,omitempty"`
 }
 
 func ParseFromEntity(entity Entity) Response {
-	return Response{
+	res := Response{
 		ID:          entity.ID,
 		Title:       entity.Title,
 		Description: entity.Description,
-		Price:       entity.Price,
+		Price:       money.New(entity.Price, entity.Currency),
 		Category:    entity.Category,
 		Quantity:    entity.Quantity,
+		Weight:      entity.Weight,
 		CreatedAt:   entity.CreatedAt,
+		Version:     entity.Version,
 	}
+	if entity.DeletedAt.Valid {
+		res.DeletedAt = &entity.DeletedAt.Time
+	}
+	return res
 }
 
 func ParseFromEntities(data []Entity) (res []Response) {
@@ -56,22 +114,38 @@ func ParseFromEntities(data []Entity) (res []Response) {
 }
 
 func (r *Request) Validate() error {
-	if r.Title == "" && len(r.Title) < 200 {
-		return ErrorInvalidTitle
+	return validate.Struct(r)
+}
+
+// Apply returns the request that sets entity to what the patch asks for.
+func (p Patch) Apply(entity Entity) Request {
+	req := Request{
+		Title:       entity.Title,
+		Description: entity.Description,
+		Price:       money.New(entity.Price, entity.Currency),
+		Category:    entity.Category,
+		Quantity:    entity.Quantity,
+		Weight:      entity.Weight,
+	}
+	if p.Title != nil {
+		req.Title = *p.Title
+	}
+	if p.Description != nil {
+		req.Description = *p.Description
 	}
-	if r.Description == "" {
-		return ErrorInvalidDescription
+	if p.Price != nil {
+		req.Price = *p.Price
 	}
-	if r.Price <= 0 {
-		return ErrorInvalidPrice
+	if p.Category != nil {
+		req.Category = *p.Category
 	}
-	if r.Category == "" {
-		return ErrorInvalidCategory
+	if p.Quantity != nil {
+		req.Quantity = *p.Quantity
 	}
-	if r.Quantity <= 0 {
-		return ErrorInvalidQuantity
+	if p.Weight != nil {
+		req.Weight = *p.Weight
 	}
-	return nil
+	return req
 }
 
 func ParseDate(date string) (data time.Time) {
@@ -82,3 +156,7 @@ func ParseDate(date string) (data time.Time) {
 func IsValidFilter(filter string) bool {
 	return filter == "title" || filter == "category"
 }
+
+func IsValidFormat(format string) bool {
+	return format == FormatCSV || format == FormatNDJSON
+}
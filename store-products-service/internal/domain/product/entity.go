@@ -1,13 +1,20 @@
 package product
 
-import "time"
+import (
+	"database/sql"
+	"time"
+)
 
 type Entity struct {
-	ID          string    `db:"id" bson:"_id"`
-	Title       string    `db:"title" bson:"title"`
-	Description string    `db:"description" bson:"description"`
-	Price       float64   `db:"price" bson:"price"`
-	Category    string    `db:"category" bson:"category"`
-	Quantity    int       `db:"quantity" bson:"quantity"`
-	CreatedAt   time.Time `db:"created_at" bson:"created_at"`
+	ID          string       `db:"id" bson:"_id"`
+	Title       string       `db:"title" bson:"title"`
+	Description string       `db:"description" bson:"description"`
+	Price       int64        `db:"price" bson:"price"`
+	Currency    string       `db:"currency" bson:"currency"`
+	Category    string       `db:"category" bson:"category"`
+	Quantity    int          `db:"quantity" bson:"quantity"`
+	Weight      int          `db:"weight" bson:"weight"`
+	CreatedAt   time.Time    `db:"created_at" bson:"created_at"`
+	Version     int          `db:"version" bson:"version"`
+	DeletedAt   sql.NullTime `db:"deleted_at" bson:"deleted_at"`
 }
@@ -0,0 +1,27 @@
+package product
+
+import "product-service/pkg/money"
+
+// Events written to the outbox when products change.
+const (
+	EventCreated      = "ProductCreated"
+	EventUpdated      = "ProductUpdated"
+	EventPriceChanged = "ProductPriceChanged"
+	EventDeleted      = "ProductDeleted"
+	EventRestored     = "ProductRestored"
+)
+
+// AuditEntity names products in the audit log.
+const AuditEntity = "product"
+
+// PriceChanged is the payload of ProductPriceChanged.
+type PriceChanged struct {
+	ProductID string      `json:"product_id"`
+	OldPrice  money.Money `json:"old_price"`
+	NewPrice  money.Money `json:"new_price"`
+}
+
+// Deleted is the payload of ProductDeleted.
+type Deleted struct {
+	ProductID string `json:"product_id"`
+}
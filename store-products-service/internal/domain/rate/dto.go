@@ -0,0 +1,61 @@
+package rate
+
+import (
+	"product-service/pkg/apperror"
+	"product-service/pkg/validate"
+	"strings"
+	"time"
+)
+
+// Precision is the number of decimal places kept for exchange rates, it
+// matches the scale of the exchange_rates.rate column.
+const Precision = 10
+
+var (
+	ErrorNotFound        = apperror.NotFound("rate_not_found", "exchange rate not found")
+	ErrorInvalidCurrency = apperror.BadRequest("invalid_currency", "invalid currency")
+)
+
+type Request struct {
+	BaseCurrency  string `json:"base_currency" example:"USD" validate:"required,currency"`
+	QuoteCurrency string `json:"quote_currency" example:"KZT" validate:"required,currency"`
+	Rate          string `json:"rate" example:"478.25" validate:"required,exchange_rate"`
+}
+
+type Response struct {
+	BaseCurrency  string    `json:"base_currency"`
+	QuoteCurrency string    `json:"quote_currency"`
+	Rate          string    `json:"rate"`
+	UpdatedAt     time.Time `json:"updated_at"`
+}
+
+func ParseFromEntity(entity Entity) Response {
+	return Response{
+		BaseCurrency:  entity.BaseCurrency,
+		QuoteCurrency: entity.QuoteCurrency,
+		Rate:          entity.Rate,
+		UpdatedAt:     entity.UpdatedAt,
+	}
+}
+
+func ParseFromEntities(data []Entity) (res []Response) {
+	res = make([]Response, 0)
+	for _, entity := range data {
+		res = append(res, ParseFromEntity(entity))
+	}
+	return
+}
+
+func (r *Request) Validate() error {
+	r.BaseCurrency = strings.ToUpper(r.BaseCurrency)
+	r.QuoteCurrency = strings.ToUpper(r.QuoteCurrency)
+	violations := validate.Check(r)
+	if r.BaseCurrency != "" && r.BaseCurrency == r.QuoteCurrency {
+		violations = append(violations, validate.Violation{
+			Field:   "quote_currency",
+			Rule:    "nefield",
+			Message: "must differ from base_currency",
+		})
+	}
+	return validate.Error(violations)
+}
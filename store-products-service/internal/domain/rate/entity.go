@@ -0,0 +1,10 @@
+package rate
+
+import "time"
+
+type Entity struct {
+	BaseCurrency  string    `db:"base_currency" bson:"base_currency"`
+	QuoteCurrency string    `db:"quote_currency" bson:"quote_currency"`
+	Rate          string    `db:"rate" bson:"rate"`
+	UpdatedAt     time.Time `db:"updated_at" bson:"updated_at"`
+}
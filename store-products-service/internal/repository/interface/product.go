@@ -10,6 +10,14 @@ type ProductRepository interface {
 	List(ctx context.Context) (res []product.Entity, err error)
 	Get(ctx context.Context, id string) (res product.Entity, err error)
 	Delete(ctx context.Context, id string) (err error)
-	Update(ctx context.Context, id string, entity product.Entity) (err error)
+	Restore(ctx context.Context, id string) (dest product.Entity, err error)
+	Update(ctx context.Context, id string, version int, entity product.Entity) (res product.Entity, err error)
 	Search(ctx context.Context, filter, value string) (res []product.Entity, err error)
+	Import(ctx context.Context, fn func(upsert Upsert) error) (err error)
+	Export(ctx context.Context, fn func(product.Entity) error) (err error)
 }
+
+// Upsert writes one product of an import. rowErr is the error of that row
+// alone, which is rolled back and leaves the import going, err is a failure
+// of the import transaction.
+type Upsert func(entity product.Entity) (rowErr, err error)
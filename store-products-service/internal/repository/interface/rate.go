@@ -0,0 +1,13 @@
+package interfaces
+
+import (
+	"context"
+	"product-service/internal/domain/rate"
+)
+
+type RateRepository interface {
+	Upsert(ctx context.Context, entity rate.Entity) (err error)
+	List(ctx context.Context) (res []rate.Entity, err error)
+	Get(ctx context.Context, base, quote string) (res rate.Entity, err error)
+	Delete(ctx context.Context, base, quote string) (err error)
+}
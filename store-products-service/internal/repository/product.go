@@ -6,88 +6,212 @@ import (
 	"errors"
 	"fmt"
 	"github.com/jmoiron/sqlx"
+	"product-service/internal/db"
 	"product-service/internal/domain/product"
 	interfaces "product-service/internal/repository/interface"
-	"strings"
+	"product-service/pkg/audit"
+	"product-service/pkg/events"
+	"product-service/pkg/money"
 )
 
 type ProductRepository struct {
-	db *sqlx.DB
+	db      *sqlx.DB
+	replica *sqlx.DB
 }
 
-func NewProductRepository(db *sqlx.DB) interfaces.ProductRepository {
+func NewProductRepository(db *sqlx.DB, replica db.Replica) interfaces.ProductRepository {
 	return &ProductRepository{
-		db: db,
+		db:      db,
+		replica: replica.DB,
 	}
 }
 
 func (pr *ProductRepository) Create(ctx context.Context, data product.Entity) (id string, err error) {
+	tx, err := pr.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return
+	}
+	defer tx.Rollback()
+
+	var dest product.Entity
 	query := `
-		INSERT INTO products (title, description, price, category, quantity)
-		VALUES ($1, $2, $3, $4, $5) RETURNING id;`
+		INSERT INTO products (title, description, price, currency, category, quantity, weight)
+		VALUES ($1, $2, $3, $4, $5, $6, $7) RETURNING *;`
 	args := []any{
 		data.Title,
 		data.Description,
 		data.Price,
+		data.Currency,
 		data.Category,
 		data.Quantity,
+		data.Weight,
 	}
-	if err = pr.db.QueryRowContext(ctx, query, args...).Scan(&id); err != nil {
-		if errors.Is(err, sql.ErrNoRows) {
-			err = product.ErrorNotFound
-		}
+	if err = tx.GetContext(ctx, &dest, query, args...); err != nil {
+		err = pr.mapError(err)
+		return
+	}
+	if err = events.Store(ctx, tx, product.EventCreated, dest.ID, product.ParseFromEntity(dest)); err != nil {
+		return
 	}
+	if err = audit.Record(ctx, tx, product.AuditEntity, dest.ID, audit.ActionCreate, nil, product.ParseFromEntity(dest)); err != nil {
+		return
+	}
+	if err = tx.Commit(); err != nil {
+		return
+	}
+	id = dest.ID
 	return
 }
 
 func (pr *ProductRepository) List(ctx context.Context) (projects []product.Entity, err error) {
-	query := `SELECT * FROM products ORDER BY id;`
-	err = pr.db.SelectContext(ctx, &projects, query)
+	query := `SELECT * FROM products WHERE deleted_at IS NULL ORDER BY id;`
+	err = pr.replica.SelectContext(ctx, &projects, query)
 	return
 }
 
 func (pr *ProductRepository) Get(ctx context.Context, id string) (dest product.Entity, err error) {
-	query := `SELECT * FROM products WHERE id = $1;`
+	query := `SELECT * FROM products WHERE id = $1 AND deleted_at IS NULL;`
 	args := []any{id}
-	err = pr.db.GetContext(ctx, &dest, query, args...)
-	if err != nil {
-		if errors.Is(err, sql.ErrNoRows) {
-			err = product.ErrorNotFound
-		}
+	if err = pr.db.GetContext(ctx, &dest, query, args...); err != nil {
+		err = pr.mapError(err)
 	}
 	return
 }
 
 func (pr *ProductRepository) Delete(ctx context.Context, id string) (err error) {
-	query := `DELETE FROM products WHERE id = $1 RETURNING id;`
-	args := []any{id}
-	if err = pr.db.QueryRowContext(ctx, query, args...).Scan(&id); err != nil {
-		if errors.Is(err, sql.ErrNoRows) {
-			err = product.ErrorNotFound
-		}
+	tx, err := pr.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return
+	}
+	defer tx.Rollback()
+
+	before, err := pr.lock(ctx, tx, id)
+	if err != nil {
+		return
 	}
+	var dest product.Entity
+	query := `UPDATE products SET deleted_at = CURRENT_TIMESTAMP, version = version + 1 WHERE id = $1 RETURNING *;`
+	if err = tx.GetContext(ctx, &dest, query, id); err != nil {
+		return
+	}
+	if err = events.Store(ctx, tx, product.EventDeleted, id, product.Deleted{ProductID: id}); err != nil {
+		return
+	}
+	if err = audit.Record(ctx, tx, product.AuditEntity, id, audit.ActionDelete, product.ParseFromEntity(before), product.ParseFromEntity(dest)); err != nil {
+		return
+	}
+	err = tx.Commit()
 	return
 }
 
-func (pr *ProductRepository) Update(ctx context.Context, id string, data product.Entity) (err error) {
-	sets, args := pr.prepareArgs(data)
-	if len(args) > 0 {
-		args = append(args, id)
-		query := fmt.Sprintf("UPDATE products SET %s WHERE id = $%d RETURNING id;", strings.Join(sets, ","), len(args))
-		if err = pr.db.QueryRowContext(ctx, query, args...).Scan(&id); err != nil {
-			if errors.Is(err, sql.ErrNoRows) {
-				err = product.ErrorNotFound
-			}
-		}
+// Restore brings a deleted product back.
+func (pr *ProductRepository) Restore(ctx context.Context, id string) (dest product.Entity, err error) {
+	tx, err := pr.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return
+	}
+	defer tx.Rollback()
+
+	var before product.Entity
+	if err = tx.GetContext(ctx, &before, `SELECT * FROM products WHERE id = $1 FOR UPDATE;`, id); err != nil {
+		err = pr.mapError(err)
+		return
+	}
+	if !before.DeletedAt.Valid {
+		err = product.ErrorNotDeleted
+		return
+	}
+	query := `UPDATE products SET deleted_at = NULL, version = version + 1 WHERE id = $1 RETURNING *;`
+	if err = tx.GetContext(ctx, &dest, query, id); err != nil {
+		return
+	}
+	if err = events.Store(ctx, tx, product.EventRestored, id, product.ParseFromEntity(dest)); err != nil {
+		return
+	}
+	if err = audit.Record(ctx, tx, product.AuditEntity, id, audit.ActionRestore, product.ParseFromEntity(before), product.ParseFromEntity(dest)); err != nil {
+		return
+	}
+	err = tx.Commit()
+	return
+}
+
+// Update overwrites a product and writes ProductUpdated, and
+// ProductPriceChanged when the price moved. A version other than 0 must be
+// the current one, the stored version is bumped either way.
+func (pr *ProductRepository) Update(ctx context.Context, id string, version int, data product.Entity) (dest product.Entity, err error) {
+	tx, err := pr.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return
+	}
+	defer tx.Rollback()
+
+	old, err := pr.lock(ctx, tx, id)
+	if err != nil {
+		return
+	}
+	if version != 0 && version != old.Version {
+		err = product.ErrorVersionMismatch
+		return
+	}
+
+	query := `
+		UPDATE products SET title = $1, description = $2, price = $3, currency = $4, category = $5,
+			quantity = $6, weight = $7, version = version + 1
+		WHERE id = $8 RETURNING *;`
+	args := []any{
+		data.Title,
+		data.Description,
+		data.Price,
+		data.Currency,
+		data.Category,
+		data.Quantity,
+		data.Weight,
+		id,
+	}
+	if err = tx.GetContext(ctx, &dest, query, args...); err != nil {
+		return
+	}
+	if err = pr.storeChange(ctx, tx, old, dest); err != nil {
+		return
+	}
+	err = tx.Commit()
+	return
+}
+
+// lock reads a product that is not deleted and locks it until tx ends.
+func (pr *ProductRepository) lock(ctx context.Context, tx *sqlx.Tx, id string) (dest product.Entity, err error) {
+	if err = tx.GetContext(ctx, &dest, `SELECT * FROM products WHERE id = $1 AND deleted_at IS NULL FOR UPDATE;`, id); err != nil {
+		err = pr.mapError(err)
+	}
+	return
+}
+
+// storeChange writes the events and the audit entry of an update of the
+// product old into dest.
+func (pr *ProductRepository) storeChange(ctx context.Context, tx *sqlx.Tx, old, dest product.Entity) (err error) {
+	if err = events.Store(ctx, tx, product.EventUpdated, dest.ID, product.ParseFromEntity(dest)); err != nil {
+		return
+	}
+	if err = audit.Record(ctx, tx, product.AuditEntity, dest.ID, audit.ActionUpdate, product.ParseFromEntity(old), product.ParseFromEntity(dest)); err != nil {
+		return
 	}
+	if old.Price == dest.Price && old.Currency == dest.Currency {
+		return
+	}
+	changed := product.PriceChanged{
+		ProductID: dest.ID,
+		OldPrice:  money.New(old.Price, old.Currency),
+		NewPrice:  money.New(dest.Price, dest.Currency),
+	}
+	err = events.Store(ctx, tx, product.EventPriceChanged, dest.ID, changed)
 	return
 }
 
 func (pr *ProductRepository) Search(ctx context.Context, filter, value string) (dest []product.Entity, err error) {
 	dest = []product.Entity{}
 	filter = pr.prepareFilter(filter)
-	query := fmt.Sprintf("SELECT * FROM products WHERE %s = $1", filter)
-	err = pr.db.SelectContext(ctx, &dest, query, value)
+	query := fmt.Sprintf("SELECT * FROM products WHERE %s = $1 AND deleted_at IS NULL", filter)
+	err = pr.replica.SelectContext(ctx, &dest, query, value)
 	if err != nil {
 		return
 	}
@@ -98,27 +222,117 @@ func (pr *ProductRepository) Search(ctx context.Context, filter, value string) (
 	return
 }
 
-func (pr *ProductRepository) prepareArgs(data product.Entity) (sets []string, args []any) {
-	if data.Title != "" {
-		args = append(args, data.Title)
-		sets = append(sets, fmt.Sprintf("title = $%d", len(args)))
+// Import runs fn in one transaction and commits it when fn returns nil, an
+// error from fn rolls back every row of the import. Each call of upsert runs
+// under its own savepoint, so a failing row is rolled back alone. New rows
+// write ProductCreated, overwritten ones the same events as Update. A deleted
+// product is not overwritten, its row fails with ErrorNotFound.
+func (pr *ProductRepository) Import(ctx context.Context, fn func(upsert interfaces.Upsert) error) (err error) {
+	tx, err := pr.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return
 	}
-	if data.Description != "" {
-		args = append(args, data.Description)
-		sets = append(sets, fmt.Sprintf("description = $%d", len(args)))
+	defer tx.Rollback()
+
+	lock, err := tx.PreparexContext(ctx, `SELECT * FROM products WHERE id = $1 AND deleted_at IS NULL FOR UPDATE;`)
+	if err != nil {
+		return
 	}
-	if data.Price != 0 {
-		args = append(args, data.Price)
-		sets = append(sets, fmt.Sprintf("price = $%d", len(args)))
+	defer lock.Close()
+	query := `
+		INSERT INTO products (id, title, description, price, currency, category, quantity, weight)
+		VALUES (COALESCE(NULLIF($1, '')::UUID, GEN_RANDOM_UUID()), $2, $3, $4, $5, $6, $7, $8)
+		ON CONFLICT (id) DO UPDATE SET
+			title = EXCLUDED.title,
+			description = EXCLUDED.description,
+			price = EXCLUDED.price,
+			currency = EXCLUDED.currency,
+			category = EXCLUDED.category,
+			quantity = EXCLUDED.quantity,
+			weight = EXCLUDED.weight,
+			version = products.version + 1
+		WHERE products.deleted_at IS NULL
+		RETURNING *;`
+	stmt, err := tx.PreparexContext(ctx, query)
+	if err != nil {
+		return
 	}
-	if data.Category != "" {
-		args = append(args, data.Category)
-		sets = append(sets, fmt.Sprintf("category = $%d", len(args)))
+	defer stmt.Close()
+
+	err = fn(func(entity product.Entity) (rowErr, err error) {
+		if _, err = tx.ExecContext(ctx, "SAVEPOINT product_row;"); err != nil {
+			return
+		}
+		if rowErr = pr.upsertRow(ctx, tx, lock, stmt, entity); rowErr != nil {
+			_, err = tx.ExecContext(ctx, "ROLLBACK TO SAVEPOINT product_row;")
+			return
+		}
+		_, err = tx.ExecContext(ctx, "RELEASE SAVEPOINT product_row;")
+		return
+	})
+	if err != nil {
+		return
+	}
+	err = tx.Commit()
+	return
+}
+
+// upsertRow writes one row of Import, lock reads the product it overwrites.
+func (pr *ProductRepository) upsertRow(ctx context.Context, tx *sqlx.Tx, lock, stmt *sqlx.Stmt, entity product.Entity) (err error) {
+	var old product.Entity
+	found := false
+	if entity.ID != "" {
+		switch err = lock.QueryRowxContext(ctx, entity.ID).StructScan(&old); {
+		case err == nil:
+			found = true
+		case !errors.Is(err, sql.ErrNoRows):
+			return pr.mapError(err)
+		}
+	}
+
+	args := []any{
+		entity.ID,
+		entity.Title,
+		entity.Description,
+		entity.Price,
+		entity.Currency,
+		entity.Category,
+		entity.Quantity,
+		entity.Weight,
+	}
+	var dest product.Entity
+	if err = stmt.QueryRowxContext(ctx, args...).StructScan(&dest); err != nil {
+		return pr.mapError(err)
 	}
-	if data.Quantity != 0 {
-		args = append(args, data.Quantity)
-		sets = append(sets, fmt.Sprintf("quantity = $%d", len(args)))
+	if found {
+		return pr.storeChange(ctx, tx, old, dest)
 	}
+	if err = events.Store(ctx, tx, product.EventCreated, dest.ID, product.ParseFromEntity(dest)); err != nil {
+		return
+	}
+	return audit.Record(ctx, tx, product.AuditEntity, dest.ID, audit.ActionCreate, nil, product.ParseFromEntity(dest))
+}
+
+// Export walks the products table row by row and hands every product to fn,
+// so the caller can stream it out without holding the table in memory.
+func (pr *ProductRepository) Export(ctx context.Context, fn func(product.Entity) error) (err error) {
+	query := `SELECT * FROM products WHERE deleted_at IS NULL ORDER BY created_at, id;`
+	rows, err := pr.replica.QueryxContext(ctx, query)
+	if err != nil {
+		return
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var dest product.Entity
+		if err = rows.StructScan(&dest); err != nil {
+			return
+		}
+		if err = fn(dest); err != nil {
+			return
+		}
+	}
+	err = rows.Err()
 	return
 }
 
@@ -132,3 +346,11 @@ func (pr *ProductRepository) prepareFilter(filter string) string {
 		return ""
 	}
 }
+
+func (pr *ProductRepository) mapError(err error) error {
+	if errors.Is(err, sql.ErrNoRows) || db.IsInvalidText(err) {
+		// no such row, or a malformed ID in the path
+		return product.ErrorNotFound
+	}
+	return err
+}
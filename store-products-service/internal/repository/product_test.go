@@ -0,0 +1,103 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jmoiron/sqlx"
+	"product-service/internal/db"
+	"product-service/internal/domain/product"
+	interfaces "product-service/internal/repository/interface"
+	"testing"
+)
+
+func newMock(t *testing.T) (*sqlx.DB, sqlmock.Sqlmock) {
+	conn, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return sqlx.NewDb(conn, "postgres"), mock
+}
+
+// expectImport expects the transaction and statements of an import up to
+// the first row.
+func expectImport(mock sqlmock.Sqlmock) *sqlmock.ExpectedPrepare {
+	mock.ExpectBegin()
+	mock.ExpectPrepare(`SELECT \* FROM products WHERE id = \$1`)
+	return mock.ExpectPrepare(`INSERT INTO products`)
+}
+
+// TestImportIsOneTransaction checks that a failing row only rolls back to
+// its savepoint and the rows around it are committed together.
+func TestImportIsOneTransaction(t *testing.T) {
+	primary, mock := newMock(t)
+	repo := NewProductRepository(primary, db.Replica{DB: primary})
+	columns := []string{"id", "title", "price", "currency"}
+
+	insert := expectImport(mock)
+	mock.ExpectExec(`SAVEPOINT product_row`).WillReturnResult(sqlmock.NewResult(0, 0))
+	insert.ExpectQuery().WillReturnRows(sqlmock.NewRows(columns).AddRow("p1", "Lamp", 1250, "USD"))
+	mock.ExpectExec(`INSERT INTO outbox`).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(`INSERT INTO audit_log`).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(`RELEASE SAVEPOINT product_row`).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec(`SAVEPOINT product_row`).WillReturnResult(sqlmock.NewResult(0, 0))
+	insert.ExpectQuery().WillReturnError(errors.New("value too long"))
+	mock.ExpectExec(`ROLLBACK TO SAVEPOINT product_row`).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec(`SAVEPOINT product_row`).WillReturnResult(sqlmock.NewResult(0, 0))
+	insert.ExpectQuery().WillReturnRows(sqlmock.NewRows(columns).AddRow("p3", "Pen", 100, "EUR"))
+	mock.ExpectExec(`INSERT INTO outbox`).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(`INSERT INTO audit_log`).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(`RELEASE SAVEPOINT product_row`).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectCommit()
+
+	var rowErrs []error
+	err := repo.Import(context.Background(), func(upsert interfaces.Upsert) error {
+		for _, title := range []string{"Lamp", "Lamp with a title far too long", "Pen"} {
+			rowErr, err := upsert(product.Entity{Title: title})
+			if err != nil {
+				return err
+			}
+			rowErrs = append(rowErrs, rowErr)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rowErrs[0] != nil || rowErrs[1] == nil || rowErrs[2] != nil {
+		t.Errorf("row errors = %v, want only the second row to fail", rowErrs)
+	}
+	if err = mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
+
+// TestImportRollsBackOnError checks that an error that stops the import
+// rolls back the rows written before it.
+func TestImportRollsBackOnError(t *testing.T) {
+	primary, mock := newMock(t)
+	repo := NewProductRepository(primary, db.Replica{DB: primary})
+	errBroken := errors.New("broken file")
+
+	insert := expectImport(mock)
+	mock.ExpectExec(`SAVEPOINT product_row`).WillReturnResult(sqlmock.NewResult(0, 0))
+	insert.ExpectQuery().WillReturnRows(sqlmock.NewRows([]string{"id", "title"}).AddRow("p1", "Lamp"))
+	mock.ExpectExec(`INSERT INTO outbox`).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(`INSERT INTO audit_log`).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(`RELEASE SAVEPOINT product_row`).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectRollback()
+
+	err := repo.Import(context.Background(), func(upsert interfaces.Upsert) error {
+		if _, err := upsert(product.Entity{Title: "Lamp"}); err != nil {
+			return err
+		}
+		return errBroken
+	})
+	if !errors.Is(err, errBroken) {
+		t.Errorf("error = %v, want %v", err, errBroken)
+	}
+	if err = mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
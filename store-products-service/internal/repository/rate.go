@@ -0,0 +1,65 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"github.com/jmoiron/sqlx"
+	"product-service/internal/domain/rate"
+	interfaces "product-service/internal/repository/interface"
+)
+
+type RateRepository struct {
+	db *sqlx.DB
+}
+
+func NewRateRepository(db *sqlx.DB) interfaces.RateRepository {
+	return &RateRepository{
+		db: db,
+	}
+}
+
+func (rr *RateRepository) Upsert(ctx context.Context, data rate.Entity) (err error) {
+	query := `
+		INSERT INTO exchange_rates (base_currency, quote_currency, rate)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (base_currency, quote_currency) DO UPDATE SET
+			rate = EXCLUDED.rate,
+			updated_at = CURRENT_TIMESTAMP;`
+	args := []any{
+		data.BaseCurrency,
+		data.QuoteCurrency,
+		data.Rate,
+	}
+	_, err = rr.db.ExecContext(ctx, query, args...)
+	return
+}
+
+func (rr *RateRepository) List(ctx context.Context) (dest []rate.Entity, err error) {
+	query := `SELECT * FROM exchange_rates ORDER BY base_currency, quote_currency;`
+	err = rr.db.SelectContext(ctx, &dest, query)
+	return
+}
+
+func (rr *RateRepository) Get(ctx context.Context, base, quote string) (dest rate.Entity, err error) {
+	query := `SELECT * FROM exchange_rates WHERE base_currency = $1 AND quote_currency = $2;`
+	args := []any{base, quote}
+	err = rr.db.GetContext(ctx, &dest, query, args...)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			err = rate.ErrorNotFound
+		}
+	}
+	return
+}
+
+func (rr *RateRepository) Delete(ctx context.Context, base, quote string) (err error) {
+	query := `DELETE FROM exchange_rates WHERE base_currency = $1 AND quote_currency = $2 RETURNING base_currency;`
+	args := []any{base, quote}
+	if err = rr.db.QueryRowContext(ctx, query, args...).Scan(&base); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			err = rate.ErrorNotFound
+		}
+	}
+	return
+}
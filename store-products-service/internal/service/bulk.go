@@ -0,0 +1,236 @@
+package service
+
+import (
+	"bufio"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"product-service/internal/domain/product"
+	interfaces "product-service/internal/repository/interface"
+	"product-service/pkg/money"
+	"product-service/pkg/validate"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var csvHeader = []string{"id", "title", "description", "price", "currency", "category", "quantity", "weight", "created_at"}
+
+type recordReader interface {
+	// Read returns the next record and its 1-based row in the file. Errors
+	// wrapping product.ErrorInvalidRecord only reject that row, any other
+	// error aborts the import.
+	Read() (row int, rec product.Record, err error)
+}
+
+// ImportProducts reads the records of r one by one and upserts them in one
+// transaction. A record that cannot be read, breaks the rules for products
+// or fails to be written is skipped and reported in the row order of the
+// file. Any other error, e.g. a broken file or a database failure, rolls the
+// whole import back.
+func (ps *ProductService) ImportProducts(ctx context.Context, format string, r io.Reader) (res product.ImportResponse, err error) {
+	reader, err := newRecordReader(format, r)
+	if err != nil {
+		return
+	}
+
+	res.Errors = make([]product.ImportError, 0)
+	err = ps.productRepository.Import(ctx, func(upsert interfaces.Upsert) error {
+		for {
+			row, rec, readErr := reader.Read()
+			if errors.Is(readErr, io.EOF) {
+				return nil
+			}
+			if readErr != nil && !errors.Is(readErr, product.ErrorInvalidRecord) {
+				return readErr
+			}
+
+			res.Total++
+			if readErr == nil {
+				readErr = rec.Validate()
+			}
+			if readErr != nil {
+				res.Errors = append(res.Errors, product.ImportError{
+					Row:        row,
+					Error:      readErr.Error(),
+					Violations: validate.Violations(readErr),
+				})
+				continue
+			}
+
+			rowErr, err := upsert(product.Entity{
+				ID:          rec.ID,
+				Title:       rec.Title,
+				Description: rec.Description,
+				Price:       rec.Price.Amount,
+				Currency:    rec.Price.Currency,
+				Category:    rec.Category,
+				Quantity:    rec.Quantity,
+				Weight:      rec.Weight,
+			})
+			if err != nil {
+				return err
+			}
+			if rowErr != nil {
+				res.Errors = append(res.Errors, product.ImportError{Row: row, Error: rowErr.Error()})
+				continue
+			}
+			res.Imported++
+		}
+	})
+	if err != nil {
+		res = product.ImportResponse{}
+		return
+	}
+	res.Failed = len(res.Errors)
+	return
+}
+
+func (ps *ProductService) ExportProducts(ctx context.Context, format string, w io.Writer) (err error) {
+	switch format {
+	case product.FormatCSV:
+		writer := csv.NewWriter(w)
+		if err = writer.Write(csvHeader); err != nil {
+			return
+		}
+		err = ps.productRepository.Export(ctx, func(entity product.Entity) error {
+			return writer.Write([]string{
+				entity.ID,
+				entity.Title,
+				entity.Description,
+				money.New(entity.Price, entity.Currency).Decimal(),
+				entity.Currency,
+				entity.Category,
+				strconv.Itoa(entity.Quantity),
+				strconv.Itoa(entity.Weight),
+				entity.CreatedAt.Format(time.RFC3339),
+			})
+		})
+		if err != nil {
+			return
+		}
+		writer.Flush()
+		err = writer.Error()
+	case product.FormatNDJSON:
+		encoder := json.NewEncoder(w)
+		err = ps.productRepository.Export(ctx, func(entity product.Entity) error {
+			return encoder.Encode(product.ParseFromEntity(entity))
+		})
+	default:
+		err = product.ErrorInvalidFormat
+	}
+	return
+}
+
+func newRecordReader(format string, r io.Reader) (recordReader, error) {
+	switch format {
+	case product.FormatCSV:
+		reader := csv.NewReader(r)
+		reader.FieldsPerRecord = -1
+		reader.TrimLeadingSpace = true
+
+		header, err := reader.Read()
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				err = fmt.Errorf("%w: missing csv header", product.ErrorInvalidFormat)
+			}
+			return nil, err
+		}
+		columns := make(map[string]int, len(header))
+		for i, name := range header {
+			columns[strings.ToLower(strings.TrimSpace(name))] = i
+		}
+		for _, name := range []string{"title", "description", "price", "currency", "category", "quantity"} {
+			if _, ok := columns[name]; !ok {
+				return nil, fmt.Errorf("%w: missing csv column %q", product.ErrorInvalidFormat, name)
+			}
+		}
+		return &csvRecordReader{reader: reader, columns: columns, row: 1}, nil
+	case product.FormatNDJSON:
+		scanner := bufio.NewScanner(r)
+		scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+		return &ndjsonRecordReader{scanner: scanner}, nil
+	default:
+		return nil, product.ErrorInvalidFormat
+	}
+}
+
+type csvRecordReader struct {
+	reader  *csv.Reader
+	columns map[string]int
+	row     int
+}
+
+func (cr *csvRecordReader) Read() (row int, rec product.Record, err error) {
+	fields, err := cr.reader.Read()
+	if errors.Is(err, io.EOF) {
+		return
+	}
+	cr.row++
+	row = cr.row
+
+	var parseErr *csv.ParseError
+	if errors.As(err, &parseErr) {
+		err = fmt.Errorf("%w: %v", product.ErrorInvalidRecord, parseErr.Err)
+		return
+	}
+	if err != nil {
+		return
+	}
+
+	rec.ID = cr.field(fields, "id")
+	rec.Title = cr.field(fields, "title")
+	rec.Description = cr.field(fields, "description")
+	rec.Category = cr.field(fields, "category")
+	if rec.Price, err = money.Parse(cr.field(fields, "price"), strings.ToUpper(cr.field(fields, "currency"))); err != nil {
+		err = fmt.Errorf("%w: %w", product.ErrorInvalidRecord, product.ErrorInvalidPrice)
+		return
+	}
+	if rec.Quantity, err = strconv.Atoi(cr.field(fields, "quantity")); err != nil {
+		err = fmt.Errorf("%w: %w", product.ErrorInvalidRecord, product.ErrorInvalidQuantity)
+		return
+	}
+	// weight is optional so older export files still import
+	if weight := cr.field(fields, "weight"); weight != "" {
+		if rec.Weight, err = strconv.Atoi(weight); err != nil {
+			err = fmt.Errorf("%w: %w", product.ErrorInvalidRecord, product.ErrorInvalidWeight)
+			return
+		}
+	}
+	return
+}
+
+func (cr *csvRecordReader) field(fields []string, name string) string {
+	i, ok := cr.columns[name]
+	if !ok || i >= len(fields) {
+		return ""
+	}
+	return strings.TrimSpace(fields[i])
+}
+
+type ndjsonRecordReader struct {
+	scanner *bufio.Scanner
+	row     int
+}
+
+func (nr *ndjsonRecordReader) Read() (row int, rec product.Record, err error) {
+	for nr.scanner.Scan() {
+		nr.row++
+		line := strings.TrimSpace(nr.scanner.Text())
+		if line == "" {
+			continue
+		}
+		row = nr.row
+		if jsonErr := json.Unmarshal([]byte(line), &rec); jsonErr != nil {
+			err = fmt.Errorf("%w: %v", product.ErrorInvalidRecord, jsonErr)
+		}
+		return
+	}
+	if err = nr.scanner.Err(); err == nil {
+		err = io.EOF
+	}
+	return
+}
@@ -0,0 +1,235 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"product-service/internal/domain/product"
+	interfaces "product-service/internal/repository/interface"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// fakeImports keeps the products of the imports that were committed. An
+// import is committed when fn returns nil, the way Import commits its
+// transaction.
+type fakeImports struct {
+	interfaces.ProductRepository
+	committed []product.Entity
+	exported  []product.Entity
+	// rowErrs fail the upsert of the products with these titles
+	rowErrs map[string]error
+	// err fails every upsert as a failure of the transaction
+	err error
+}
+
+func (fi *fakeImports) Import(ctx context.Context, fn func(upsert interfaces.Upsert) error) error {
+	var upserted []product.Entity
+	err := fn(func(entity product.Entity) (rowErr, err error) {
+		if fi.err != nil {
+			return nil, fi.err
+		}
+		if rowErr = fi.rowErrs[entity.Title]; rowErr != nil {
+			return rowErr, nil
+		}
+		upserted = append(upserted, entity)
+		return nil, nil
+	})
+	if err != nil {
+		return err
+	}
+	fi.committed = append(fi.committed, upserted...)
+	return nil
+}
+
+func (fi *fakeImports) Export(ctx context.Context, fn func(product.Entity) error) error {
+	for _, entity := range fi.exported {
+		if err := fn(entity); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func TestImportProducts(t *testing.T) {
+	errDeleted := product.ErrorNotFound
+	errDown := errors.New("connection reset")
+
+	tests := []struct {
+		name      string
+		format    string
+		input     string
+		rowErrs   map[string]error
+		err       error
+		wantErr   error
+		want      product.ImportResponse
+		committed []string
+	}{
+		{
+			name:   "csv",
+			format: product.FormatCSV,
+			input: "Title, Description,PRICE,currency,category,quantity,weight\n" +
+				"Lamp,Desk lamp,12.50,usd,home,3,900\n" +
+				"Mug,Tea mug,4,EUR,kitchen,10,\n",
+			want:      product.ImportResponse{Total: 2, Imported: 2, Errors: []product.ImportError{}},
+			committed: []string{"Lamp", "Mug"},
+		},
+		{
+			name:   "csv rows that cannot be read or break the rules",
+			format: product.FormatCSV,
+			input: "title,description,price,currency,category,quantity\n" +
+				"Lamp,Desk lamp,twelve,USD,home,3\n" +
+				"Mug,Tea mug,4,EUR,kitchen,many\n" +
+				",No title,4,EUR,kitchen,1\n" +
+				"Pen,Blue pen,1,EUR,office,5\n",
+			want: product.ImportResponse{Total: 4, Imported: 1, Failed: 3, Errors: []product.ImportError{
+				{Row: 2, Error: "invalid record: invalid price"},
+				{Row: 3, Error: "invalid record: invalid quantity"},
+				{Row: 4},
+			}},
+			committed: []string{"Pen"},
+		},
+		{
+			name:   "ndjson skips blank lines and counts them as rows",
+			format: product.FormatNDJSON,
+			input: `{"title":"Lamp","description":"Desk lamp","price":{"amount":"12.50","currency":"USD"},"category":"home","quantity":3}` + "\n\n" +
+				`{"title":` + "\n" +
+				`{"id":"8e9f0a1b-2c3d-4e5f-8a7b-9c0d1e2f3a4b","title":"Mug","description":"Tea mug","price":{"amount":4,"currency":"EUR"},"category":"kitchen","quantity":10}` + "\n",
+			want: product.ImportResponse{Total: 3, Imported: 2, Failed: 1, Errors: []product.ImportError{
+				{Row: 3},
+			}},
+			committed: []string{"Lamp", "Mug"},
+		},
+		{
+			name:   "rows failing to be written are reported in file order",
+			format: product.FormatCSV,
+			input: "title,description,price,currency,category,quantity\n" +
+				"Lamp,Desk lamp,12.50,USD,home,3\n" +
+				"Mug,Tea mug,x,EUR,kitchen,10\n" +
+				"Pen,Blue pen,1,EUR,office,5\n",
+			rowErrs: map[string]error{"Lamp": errDeleted},
+			want: product.ImportResponse{Total: 3, Imported: 1, Failed: 2, Errors: []product.ImportError{
+				{Row: 2, Error: errDeleted.Error()},
+				{Row: 3, Error: "invalid record: invalid price"},
+			}},
+			committed: []string{"Pen"},
+		},
+		{
+			name:    "a failing transaction imports nothing",
+			format:  product.FormatCSV,
+			input:   "title,description,price,currency,category,quantity\nLamp,Desk lamp,12.50,USD,home,3\n",
+			err:     errDown,
+			wantErr: errDown,
+		},
+		{
+			name:    "a broken file imports nothing",
+			format:  product.FormatNDJSON,
+			input:   `{"title":"Lamp","description":"Desk lamp","price":{"amount":"12.50","currency":"USD"},"category":"home","quantity":3}` + "\n" + strings.Repeat("x", 2<<20) + "\n",
+			wantErr: errors.New("bufio.Scanner: token too long"),
+		},
+		{
+			name:    "missing csv column",
+			format:  product.FormatCSV,
+			input:   "title,description,price,currency,category\n",
+			wantErr: product.ErrorInvalidFormat,
+		},
+		{
+			name:    "unknown format",
+			format:  "xml",
+			wantErr: product.ErrorInvalidFormat,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			products := &fakeImports{rowErrs: tt.rowErrs, err: tt.err}
+			ps := &ProductService{productRepository: products}
+
+			res, err := ps.ImportProducts(context.Background(), tt.format, strings.NewReader(tt.input))
+			if tt.wantErr != nil {
+				if err == nil || !errors.Is(err, tt.wantErr) && err.Error() != tt.wantErr.Error() {
+					t.Fatalf("error = %v, want %v", err, tt.wantErr)
+				}
+			} else if err != nil {
+				t.Fatal(err)
+			}
+
+			// violations and JSON errors are checked by row only
+			for i := range res.Errors {
+				if i < len(tt.want.Errors) && tt.want.Errors[i].Error == "" {
+					res.Errors[i].Error = ""
+					res.Errors[i].Violations = nil
+				}
+			}
+			if tt.wantErr == nil && !reflect.DeepEqual(res, tt.want) {
+				t.Errorf("report = %+v, want %+v", res, tt.want)
+			}
+			var titles []string
+			for _, entity := range products.committed {
+				titles = append(titles, entity.Title)
+			}
+			if !reflect.DeepEqual(titles, tt.committed) {
+				t.Errorf("committed %q, want %q", titles, tt.committed)
+			}
+		})
+	}
+}
+
+func TestImportProductsParsesRecords(t *testing.T) {
+	products := &fakeImports{}
+	ps := &ProductService{productRepository: products}
+	input := "id,title,description,price,currency,category,quantity,weight\n" +
+		"8e9f0a1b-2c3d-4e5f-8a7b-9c0d1e2f3a4b, Lamp ,Desk lamp,12.50,usd,home,3,900\n"
+
+	if _, err := ps.ImportProducts(context.Background(), product.FormatCSV, strings.NewReader(input)); err != nil {
+		t.Fatal(err)
+	}
+	want := []product.Entity{{
+		ID:          "8e9f0a1b-2c3d-4e5f-8a7b-9c0d1e2f3a4b",
+		Title:       "Lamp",
+		Description: "Desk lamp",
+		Price:       1250,
+		Currency:    "USD",
+		Category:    "home",
+		Quantity:    3,
+		Weight:      900,
+	}}
+	if !reflect.DeepEqual(products.committed, want) {
+		t.Errorf("committed %+v, want %+v", products.committed, want)
+	}
+}
+
+func TestExportProducts(t *testing.T) {
+	created := time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC)
+	products := &fakeImports{exported: []product.Entity{
+		{ID: "p1", Title: "Lamp", Description: "Desk lamp, brass", Price: 1250, Currency: "USD", Category: "home", Quantity: 3, Weight: 900, CreatedAt: created},
+		{ID: "p2", Title: "Mug", Description: "Tea mug", Price: 400, Currency: "EUR", Category: "kitchen", Quantity: 10, CreatedAt: created},
+	}}
+	ps := &ProductService{productRepository: products}
+
+	tests := []struct {
+		format string
+		want   string
+	}{
+		{product.FormatCSV, "id,title,description,price,currency,category,quantity,weight,created_at\n" +
+			"p1,Lamp,\"Desk lamp, brass\",12.50,USD,home,3,900,2026-10-19T12:00:00Z\n" +
+			"p2,Mug,Tea mug,4.00,EUR,kitchen,10,0,2026-10-19T12:00:00Z\n"},
+		{product.FormatNDJSON, `{"id":"p1","title":"Lamp","description":"Desk lamp, brass","price":{"amount":"12.50","currency":"USD"},"category":"home","quantity":3,"weight":900,"created_at":"2026-10-19T12:00:00Z","version":0}` + "\n" +
+			`{"id":"p2","title":"Mug","description":"Tea mug","price":{"amount":"4.00","currency":"EUR"},"category":"kitchen","quantity":10,"weight":0,"created_at":"2026-10-19T12:00:00Z","version":0}` + "\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.format, func(t *testing.T) {
+			var out strings.Builder
+			if err := ps.ExportProducts(context.Background(), tt.format, &out); err != nil {
+				t.Fatal(err)
+			}
+			if out.String() != tt.want {
+				t.Errorf("export =\n%s\nwant\n%s", out.String(), tt.want)
+			}
+		})
+	}
+
+	if err := ps.ExportProducts(context.Background(), "xml", &strings.Builder{}); !errors.Is(err, product.ErrorInvalidFormat) {
+		t.Errorf("xml error = %v, want %v", err, product.ErrorInvalidFormat)
+	}
+}
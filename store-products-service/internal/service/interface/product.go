@@ -2,14 +2,19 @@ package interfaces
 
 import (
 	"context"
+	"io"
 	"product-service/internal/domain/product"
 )
 
 type ProductService interface {
 	CreateProduct(ctx context.Context, req product.Request) (id string, err error)
-	ListProduct(ctx context.Context) (res []product.Response, err error)
-	GetProduct(ctx context.Context, id string) (res product.Response, err error)
+	ListProduct(ctx context.Context, currency string) (res []product.Response, err error)
+	GetProduct(ctx context.Context, id, currency string) (res product.Response, err error)
 	DeleteProduct(ctx context.Context, id string) (err error)
-	UpdateProduct(ctx context.Context, id string, req product.Request) (err error)
-	SearchProduct(ctx context.Context, filter, value string) (res []product.Response, err error)
+	RestoreProduct(ctx context.Context, id string) (res product.Response, err error)
+	UpdateProduct(ctx context.Context, id string, version int, req product.Request) (res product.Response, err error)
+	PatchProduct(ctx context.Context, id string, version int, patch product.Patch) (res product.Response, err error)
+	SearchProduct(ctx context.Context, filter, value, currency string) (res []product.Response, err error)
+	ImportProducts(ctx context.Context, format string, r io.Reader) (res product.ImportResponse, err error)
+	ExportProducts(ctx context.Context, format string, w io.Writer) (err error)
 }
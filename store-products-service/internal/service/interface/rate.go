@@ -0,0 +1,13 @@
+package interfaces
+
+import (
+	"context"
+	"product-service/internal/domain/rate"
+)
+
+type RateService interface {
+	SetRate(ctx context.Context, req rate.Request) (err error)
+	ListRates(ctx context.Context) (res []rate.Response, err error)
+	GetRate(ctx context.Context, base, quote string) (res rate.Response, err error)
+	DeleteRate(ctx context.Context, base, quote string) (err error)
+}
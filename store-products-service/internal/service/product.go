@@ -2,18 +2,24 @@ package service
 
 import (
 	"context"
+	"errors"
 	"product-service/internal/domain/product"
+	"product-service/internal/domain/rate"
 	interfaces "product-service/internal/repository/interface"
 	services "product-service/internal/service/interface"
+	"product-service/pkg/money"
+	"strings"
 )
 
 type ProductService struct {
 	productRepository interfaces.ProductRepository
+	rateService       services.RateService
 }
 
-func NewProductService(productRepository interfaces.ProductRepository) services.ProductService {
+func NewProductService(productRepository interfaces.ProductRepository, rateService services.RateService) services.ProductService {
 	return &ProductService{
 		productRepository: productRepository,
+		rateService:       rateService,
 	}
 }
 
@@ -21,29 +27,37 @@ func (ps *ProductService) CreateProduct(ctx context.Context, req product.Request
 	data := product.Entity{
 		Title:       req.Title,
 		Description: req.Description,
-		Price:       req.Price,
+		Price:       req.Price.Amount,
+		Currency:    req.Price.Currency,
 		Category:    req.Category,
 		Quantity:    req.Quantity,
+		Weight:      req.Weight,
 	}
 	id, err = ps.productRepository.Create(ctx, data)
 	return
 }
 
-func (ps *ProductService) ListProduct(ctx context.Context) (res []product.Response, err error) {
+func (ps *ProductService) ListProduct(ctx context.Context, currency string) (res []product.Response, err error) {
 	data, err := ps.productRepository.List(ctx)
 	if err != nil {
 		return nil, err
 	}
 	res = product.ParseFromEntities(data)
+	err = ps.convertPrices(ctx, res, currency)
 	return
 }
 
-func (ps *ProductService) GetProduct(ctx context.Context, id string) (res product.Response, err error) {
+func (ps *ProductService) GetProduct(ctx context.Context, id, currency string) (res product.Response, err error) {
 	data, err := ps.productRepository.Get(ctx, id)
 	if err != nil {
 		return
 	}
 	res = product.ParseFromEntity(data)
+	converted := []product.Response{res}
+	if err = ps.convertPrices(ctx, converted, currency); err != nil {
+		return
+	}
+	res = converted[0]
 	return
 }
 
@@ -52,19 +66,55 @@ func (ps *ProductService) DeleteProduct(ctx context.Context, id string) (err err
 	return
 }
 
-func (ps *ProductService) UpdateProduct(ctx context.Context, id string, req product.Request) (err error) {
+func (ps *ProductService) RestoreProduct(ctx context.Context, id string) (res product.Response, err error) {
+	data, err := ps.productRepository.Restore(ctx, id)
+	if err != nil {
+		return
+	}
+	res = product.ParseFromEntity(data)
+	return
+}
+
+// UpdateProduct overwrites a product, version is the one the client read
+// or 0.
+func (ps *ProductService) UpdateProduct(ctx context.Context, id string, version int, req product.Request) (res product.Response, err error) {
 	data := product.Entity{
 		Title:       req.Title,
 		Description: req.Description,
-		Price:       req.Price,
+		Price:       req.Price.Amount,
+		Currency:    req.Price.Currency,
 		Category:    req.Category,
 		Quantity:    req.Quantity,
+		Weight:      req.Weight,
+	}
+	data, err = ps.productRepository.Update(ctx, id, version, data)
+	if err != nil {
+		return
 	}
-	err = ps.productRepository.Update(ctx, id, data)
+	res = product.ParseFromEntity(data)
 	return
 }
 
-func (ps *ProductService) SearchProduct(ctx context.Context, filter, value string) (res []product.Response, err error) {
+// PatchProduct applies a merge patch to the product. The patched product
+// must be as valid as a new one.
+func (ps *ProductService) PatchProduct(ctx context.Context, id string, version int, patch product.Patch) (res product.Response, err error) {
+	current, err := ps.productRepository.Get(ctx, id)
+	if err != nil {
+		return
+	}
+	if version != 0 && version != current.Version {
+		err = product.ErrorVersionMismatch
+		return
+	}
+
+	req := patch.Apply(current)
+	if err = req.Validate(); err != nil {
+		return
+	}
+	return ps.UpdateProduct(ctx, id, current.Version, req)
+}
+
+func (ps *ProductService) SearchProduct(ctx context.Context, filter, value, currency string) (res []product.Response, err error) {
 	if !product.IsValidFilter(filter) || value == "" {
 		err = product.ErrorInvalidSearch
 		return
@@ -74,5 +124,41 @@ func (ps *ProductService) SearchProduct(ctx context.Context, filter, value strin
 		return
 	}
 	res = product.ParseFromEntities(data)
+	err = ps.convertPrices(ctx, res, currency)
+	return
+}
+
+// convertPrices shows prices in the requested currency and keeps the catalog
+// price as BasePrice. An empty currency leaves the prices untouched.
+func (ps *ProductService) convertPrices(ctx context.Context, res []product.Response, currency string) (err error) {
+	if currency == "" {
+		return
+	}
+	currency = strings.ToUpper(currency)
+	if !money.IsValidCurrency(currency) {
+		return product.ErrorUnsupportedCurrency
+	}
+
+	rates := make(map[string]string)
+	for i := range res {
+		base := res[i].Price
+		if base.Currency == currency {
+			continue
+		}
+		if _, ok := rates[base.Currency]; !ok {
+			exchange, rateErr := ps.rateService.GetRate(ctx, base.Currency, currency)
+			if errors.Is(rateErr, rate.ErrorNotFound) {
+				return product.ErrorUnsupportedCurrency
+			}
+			if rateErr != nil {
+				return rateErr
+			}
+			rates[base.Currency] = exchange.Rate
+		}
+		if res[i].Price, err = money.Convert(base, currency, rates[base.Currency]); err != nil {
+			return
+		}
+		res[i].BasePrice = &base
+	}
 	return
 }
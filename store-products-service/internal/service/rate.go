@@ -0,0 +1,90 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"product-service/internal/domain/rate"
+	interfaces "product-service/internal/repository/interface"
+	services "product-service/internal/service/interface"
+	"product-service/pkg/money"
+	"strings"
+)
+
+type RateService struct {
+	rateRepository interfaces.RateRepository
+}
+
+func NewRateService(rateRepository interfaces.RateRepository) services.RateService {
+	return &RateService{
+		rateRepository: rateRepository,
+	}
+}
+
+func (rs *RateService) SetRate(ctx context.Context, req rate.Request) (err error) {
+	data := rate.Entity{
+		BaseCurrency:  req.BaseCurrency,
+		QuoteCurrency: req.QuoteCurrency,
+		Rate:          req.Rate,
+	}
+	err = rs.rateRepository.Upsert(ctx, data)
+	return
+}
+
+func (rs *RateService) ListRates(ctx context.Context) (res []rate.Response, err error) {
+	data, err := rs.rateRepository.List(ctx)
+	if err != nil {
+		return
+	}
+	res = rate.ParseFromEntities(data)
+	return
+}
+
+// GetRate returns the price of one unit of base in quote. When only the
+// opposite pair is stored its inverse is returned.
+func (rs *RateService) GetRate(ctx context.Context, base, quote string) (res rate.Response, err error) {
+	if base, quote, err = currencyPair(base, quote); err != nil {
+		return
+	}
+	if base == quote {
+		res = rate.Response{BaseCurrency: base, QuoteCurrency: quote, Rate: "1"}
+		return
+	}
+
+	data, err := rs.rateRepository.Get(ctx, base, quote)
+	if err == nil {
+		res = rate.ParseFromEntity(data)
+		return
+	}
+	if !errors.Is(err, rate.ErrorNotFound) {
+		return
+	}
+
+	data, err = rs.rateRepository.Get(ctx, quote, base)
+	if err != nil {
+		return
+	}
+	inverse, err := money.InvertRate(data.Rate, rate.Precision)
+	if err != nil {
+		return
+	}
+	res = rate.Response{BaseCurrency: base, QuoteCurrency: quote, Rate: inverse, UpdatedAt: data.UpdatedAt}
+	return
+}
+
+func (rs *RateService) DeleteRate(ctx context.Context, base, quote string) (err error) {
+	if base, quote, err = currencyPair(base, quote); err != nil {
+		return
+	}
+	err = rs.rateRepository.Delete(ctx, base, quote)
+	return
+}
+
+// currencyPair reads the currencies of a rate from the path, where they may
+// be in any case, the way rate.Request.Validate reads them from a body.
+func currencyPair(base, quote string) (string, string, error) {
+	base, quote = strings.ToUpper(base), strings.ToUpper(quote)
+	if !money.IsValidCurrency(base) || !money.IsValidCurrency(quote) {
+		return "", "", rate.ErrorInvalidCurrency
+	}
+	return base, quote, nil
+}
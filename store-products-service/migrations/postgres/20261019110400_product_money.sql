@@ -0,0 +1,16 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE products
+    ALTER COLUMN price TYPE BIGINT USING ROUND(price::NUMERIC * 100)::BIGINT,
+    ALTER COLUMN quantity TYPE INTEGER USING quantity::INTEGER,
+    ADD COLUMN currency CHAR(3) NOT NULL DEFAULT 'KZT';
+ALTER TABLE products ALTER COLUMN currency DROP DEFAULT;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE products
+    DROP COLUMN currency,
+    ALTER COLUMN quantity TYPE VARCHAR USING quantity::VARCHAR,
+    ALTER COLUMN price TYPE VARCHAR USING (price::NUMERIC / 100)::VARCHAR;
+-- +goose StatementEnd
@@ -0,0 +1,10 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE products ADD COLUMN deleted_at TIMESTAMP;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DELETE FROM products WHERE deleted_at IS NOT NULL;
+ALTER TABLE products DROP COLUMN deleted_at;
+-- +goose StatementEnd
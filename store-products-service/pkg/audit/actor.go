@@ -0,0 +1,15 @@
+package audit
+
+import (
+	"context"
+	"product-service/pkg/auth"
+)
+
+// Actor returns the ID of the user acting in ctx, the subject of the access
+// token the request was authenticated with. It is empty when no user is
+// known, for an anonymous request or a change made by an event. A user ID
+// the client merely claims, e.g. in a header, is never recorded.
+func Actor(ctx context.Context) string {
+	claims, _ := auth.FromContext(ctx)
+	return claims.Subject
+}
@@ -0,0 +1,35 @@
+package events
+
+import (
+	"context"
+	"sync"
+)
+
+// MemoryPublisher keeps published events in memory, for tests and local
+// runs without a broker.
+type MemoryPublisher struct {
+	mu     sync.Mutex
+	events []Event
+}
+
+func NewMemoryPublisher() *MemoryPublisher {
+	return &MemoryPublisher{}
+}
+
+func (mp *MemoryPublisher) Publish(ctx context.Context, event Event) error {
+	mp.mu.Lock()
+	defer mp.mu.Unlock()
+	mp.events = append(mp.events, event)
+	return nil
+}
+
+// Events returns the events published so far in publishing order.
+func (mp *MemoryPublisher) Events() []Event {
+	mp.mu.Lock()
+	defer mp.mu.Unlock()
+	return append([]Event(nil), mp.events...)
+}
+
+func (mp *MemoryPublisher) Close() error {
+	return nil
+}
@@ -0,0 +1,59 @@
+// Package mergepatch reads JSON merge patches (RFC 7396) into partial DTOs,
+// structs whose fields are all pointers. Bodies are read the same whether
+// they are sent as application/merge-patch+json or application/json.
+package mergepatch
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+)
+
+// Decode reads the merge patch in body into dst, a pointer to a partial DTO.
+// A member that is missing leaves its field nil, so the value stays as it
+// is. A member set to null gets a pointer to the zero value, so the value is
+// cleared, as RFC 7396 asks for.
+func Decode(body []byte, dst any) error {
+	if err := json.Unmarshal(body, dst); err != nil {
+		return err
+	}
+	var members map[string]json.RawMessage
+	if err := json.Unmarshal(body, &members); err != nil {
+		return err
+	}
+
+	v := reflect.ValueOf(dst).Elem()
+	for i := 0; i < v.NumField(); i++ {
+		field := v.Type().Field(i)
+		if !field.IsExported() || field.Type.Kind() != reflect.Pointer {
+			continue
+		}
+		raw, ok := member(members, jsonName(field))
+		if ok && string(raw) == "null" {
+			v.Field(i).Set(reflect.New(field.Type.Elem()))
+		}
+	}
+	return nil
+}
+
+// member looks name up the way encoding/json matches keys to fields, an
+// exact match first and then one that differs in case only.
+func member(members map[string]json.RawMessage, name string) (json.RawMessage, bool) {
+	if raw, ok := members[name]; ok {
+		return raw, true
+	}
+	for key, raw := range members {
+		if strings.EqualFold(key, name) {
+			return raw, true
+		}
+	}
+	return nil, false
+}
+
+func jsonName(field reflect.StructField) string {
+	name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+	if name == "" {
+		return field.Name
+	}
+	return name
+}
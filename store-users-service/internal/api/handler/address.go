@@ -0,0 +1,215 @@
+package handler
+
+import (
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"users-service/internal/domain/address"
+	interfaces "users-service/internal/service/interface"
+	"users-service/pkg/apperror"
+	"users-service/pkg/response"
+)
+
+type AddressHandler struct {
+	addressService interfaces.AddressService
+}
+
+func NewAddressHandler(service interfaces.AddressService) *AddressHandler {
+	return &AddressHandler{
+		addressService: service,
+	}
+}
+
+// CreateAddress godoc
+// @Summary Add an address to a user's address book
+// @Description Add an address, the first address of a user becomes the default one. A user manages its own addresses, those of another user require the users:write permission.
+// @Tags addresses
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "User ID"
+// @Param address body address.Request true "Address Request"
+// @Success 201 {object} response.Response
+// @Failure 400 {object} response.Response
+// @Failure 401 {object} response.Response
+// @Failure 403 {object} response.Response
+// @Failure 404 {object} response.Response
+// @Failure 422 {object} response.Response
+// @Failure 500 {object} response.Response
+// @Router /users/{id}/addresses [post]
+func (ah *AddressHandler) CreateAddress(c *gin.Context) {
+	req := address.Request{}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(apperror.InvalidBody(err))
+		return
+	}
+
+	if err := req.Validate(); err != nil {
+		c.Error(err)
+		return
+	}
+
+	res, err := ah.addressService.CreateAddress(c.Request.Context(), c.Param("id"), req)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	successRes := response.ClientResponse(http.StatusCreated, "the address was successfully created", res, nil)
+	c.JSON(http.StatusCreated, successRes)
+}
+
+// ListAddresses godoc
+// @Summary List a user's addresses
+// @Description Get the address book of a user, the default address first. A user reads its own addresses, those of another user require the users:read:any permission.
+// @Tags addresses
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "User ID"
+// @Success 200 {object} response.Response
+// @Failure 401 {object} response.Response
+// @Failure 403 {object} response.Response
+// @Failure 404 {object} response.Response
+// @Failure 500 {object} response.Response
+// @Router /users/{id}/addresses [get]
+func (ah *AddressHandler) ListAddresses(c *gin.Context) {
+	res, err := ah.addressService.ListAddresses(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	successRes := response.ClientResponse(http.StatusOK, "the addresses list", res, nil)
+	c.JSON(http.StatusOK, successRes)
+}
+
+// GetAddress godoc
+// @Summary Get an address by ID
+// @Description Get an address of a user's address book. A user reads its own addresses, those of another user require the users:read:any permission.
+// @Tags addresses
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "User ID"
+// @Param address_id path string true "Address ID"
+// @Success 200 {object} response.Response
+// @Failure 401 {object} response.Response
+// @Failure 403 {object} response.Response
+// @Failure 404 {object} response.Response
+// @Failure 500 {object} response.Response
+// @Router /users/{id}/addresses/{address_id} [get]
+func (ah *AddressHandler) GetAddress(c *gin.Context) {
+	res, err := ah.addressService.GetAddress(c.Request.Context(), c.Param("id"), c.Param("address_id"))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	successRes := response.ClientResponse(http.StatusOK, "the address details", res, nil)
+	c.JSON(http.StatusOK, successRes)
+}
+
+// GetDefaultAddress godoc
+// @Summary Get a user's default address
+// @Description Get the address used when an order does not name one. A user reads its own addresses, those of another user require the users:read:any permission.
+// @Tags addresses
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "User ID"
+// @Success 200 {object} response.Response
+// @Failure 401 {object} response.Response
+// @Failure 403 {object} response.Response
+// @Failure 404 {object} response.Response
+// @Failure 500 {object} response.Response
+// @Router /users/{id}/addresses/default [get]
+func (ah *AddressHandler) GetDefaultAddress(c *gin.Context) {
+	res, err := ah.addressService.GetDefaultAddress(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	successRes := response.ClientResponse(http.StatusOK, "the address details", res, nil)
+	c.JSON(http.StatusOK, successRes)
+}
+
+// UpdateAddress godoc
+// @Summary Update an address by ID
+// @Description Replace an address of a user's address book. A user manages its own addresses, those of another user require the users:write permission.
+// @Tags addresses
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "User ID"
+// @Param address_id path string true "Address ID"
+// @Param address body address.Request true "Address Request"
+// @Success 200 {object} response.Response
+// @Failure 400 {object} response.Response
+// @Failure 401 {object} response.Response
+// @Failure 403 {object} response.Response
+// @Failure 404 {object} response.Response
+// @Failure 422 {object} response.Response
+// @Failure 500 {object} response.Response
+// @Router /users/{id}/addresses/{address_id} [put]
+func (ah *AddressHandler) UpdateAddress(c *gin.Context) {
+	req := address.Request{}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(apperror.InvalidBody(err))
+		return
+	}
+
+	if err := req.Validate(); err != nil {
+		c.Error(err)
+		return
+	}
+
+	err := ah.addressService.UpdateAddress(c.Request.Context(), c.Param("id"), c.Param("address_id"), req)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	successRes := response.ClientResponse(http.StatusOK, "the address was successfully updated", nil, nil)
+	c.JSON(http.StatusOK, successRes)
+}
+
+// SetDefaultAddress godoc
+// @Summary Make an address the default one
+// @Description Mark an address as the user's default delivery address. A user manages its own addresses, those of another user require the users:write permission.
+// @Tags addresses
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "User ID"
+// @Param address_id path string true "Address ID"
+// @Success 200 {object} response.Response
+// @Failure 401 {object} response.Response
+// @Failure 403 {object} response.Response
+// @Failure 404 {object} response.Response
+// @Failure 500 {object} response.Response
+// @Router /users/{id}/addresses/{address_id}/default [put]
+func (ah *AddressHandler) SetDefaultAddress(c *gin.Context) {
+	err := ah.addressService.SetDefaultAddress(c.Request.Context(), c.Param("id"), c.Param("address_id"))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	successRes := response.ClientResponse(http.StatusOK, "the default address was successfully set", nil, nil)
+	c.JSON(http.StatusOK, successRes)
+}
+
+// DeleteAddress godoc
+// @Summary Delete an address by ID
+// @Description Delete an address, the oldest remaining one becomes the default when needed. A user manages its own addresses, those of another user require the users:write permission.
+// @Tags addresses
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "User ID"
+// @Param address_id path string true "Address ID"
+// @Success 200 {object} response.Response
+// @Failure 401 {object} response.Response
+// @Failure 403 {object} response.Response
+// @Failure 404 {object} response.Response
+// @Failure 500 {object} response.Response
+// @Router /users/{id}/addresses/{address_id} [delete]
+func (ah *AddressHandler) DeleteAddress(c *gin.Context) {
+	err := ah.addressService.DeleteAddress(c.Request.Context(), c.Param("id"), c.Param("address_id"))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	successRes := response.ClientResponse(http.StatusOK, "the address was successfully deleted", nil, nil)
+	c.JSON(http.StatusOK, successRes)
+}
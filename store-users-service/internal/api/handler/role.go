@@ -0,0 +1,109 @@
+package handler
+
+import (
+	"github.com/gin-gonic/gin"
+	"net/http"
+	interfaces "users-service/internal/service/interface"
+	"users-service/pkg/response"
+)
+
+type RoleHandler struct {
+	roleService interfaces.RoleService
+}
+
+func NewRoleHandler(service interfaces.RoleService) *RoleHandler {
+	return &RoleHandler{
+		roleService: service,
+	}
+}
+
+// ListRoles godoc
+// @Summary List all roles
+// @Description Get the roles with their permissions. Requires the roles:read permission.
+// @Tags roles
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} response.Response{data=[]role.Response}
+// @Failure 401 {object} response.Response
+// @Failure 403 {object} response.Response
+// @Failure 500 {object} response.Response
+// @Router /roles [get]
+func (rh *RoleHandler) ListRoles(c *gin.Context) {
+	res, err := rh.roleService.ListRoles(c.Request.Context())
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	successRes := response.ClientResponse(http.StatusOK, "the roles list", res, nil)
+	c.JSON(http.StatusOK, successRes)
+}
+
+// ListPermissions godoc
+// @Summary List all permissions
+// @Description Get the permissions the services check. Requires the roles:read permission.
+// @Tags roles
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} response.Response{data=[]role.PermissionResponse}
+// @Failure 401 {object} response.Response
+// @Failure 403 {object} response.Response
+// @Failure 500 {object} response.Response
+// @Router /roles/permissions [get]
+func (rh *RoleHandler) ListPermissions(c *gin.Context) {
+	res, err := rh.roleService.ListPermissions(c.Request.Context())
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	successRes := response.ClientResponse(http.StatusOK, "the permissions list", res, nil)
+	c.JSON(http.StatusOK, successRes)
+}
+
+// GrantPermission godoc
+// @Summary Grant a permission to a role
+// @Description Give a role a permission, a new role is created by its first permission. Requires the roles:write permission.
+// @Tags roles
+// @Produce json
+// @Security BearerAuth
+// @Param role path string true "Role"
+// @Param permission path string true "Permission, e.g. products:restore"
+// @Success 200 {object} response.Response{data=role.Response}
+// @Failure 400 {object} response.Response
+// @Failure 401 {object} response.Response
+// @Failure 403 {object} response.Response
+// @Failure 404 {object} response.Response
+// @Failure 500 {object} response.Response
+// @Router /roles/{role}/permissions/{permission} [put]
+func (rh *RoleHandler) GrantPermission(c *gin.Context) {
+	res, err := rh.roleService.GrantPermission(c.Request.Context(), c.Param("role"), c.Param("permission"))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	successRes := response.ClientResponse(http.StatusOK, "the permission was successfully granted", res, nil)
+	c.JSON(http.StatusOK, successRes)
+}
+
+// RevokePermission godoc
+// @Summary Revoke a permission from a role
+// @Description Take a permission away from a role, the role itself is kept. Requires the roles:write permission.
+// @Tags roles
+// @Produce json
+// @Security BearerAuth
+// @Param role path string true "Role"
+// @Param permission path string true "Permission, e.g. products:restore"
+// @Success 200 {object} response.Response{data=role.Response}
+// @Failure 401 {object} response.Response
+// @Failure 403 {object} response.Response
+// @Failure 404 {object} response.Response
+// @Failure 500 {object} response.Response
+// @Router /roles/{role}/permissions/{permission} [delete]
+func (rh *RoleHandler) RevokePermission(c *gin.Context) {
+	res, err := rh.roleService.RevokePermission(c.Request.Context(), c.Param("role"), c.Param("permission"))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	successRes := response.ClientResponse(http.StatusOK, "the permission was successfully revoked", res, nil)
+	c.JSON(http.StatusOK, successRes)
+}
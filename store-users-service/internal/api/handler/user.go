@@ -1,11 +1,11 @@
 package handler
 
 import (
-	"errors"
 	"github.com/gin-gonic/gin"
 	"net/http"
 	"users-service/internal/domain/user"
 	interfaces "users-service/internal/service/interface"
+	"users-service/pkg/apperror"
 	"users-service/pkg/response"
 )
 
@@ -21,38 +21,32 @@ func NewUserHandler(service interfaces.UserService) *UserHandler {
 
 // CreateUser godoc
 // @Summary Create a new user
-// @Description Create a new user with the input payload
+// @Description Sign up a new user with the input payload, no token is needed. The user gets the default role only.
 // @Tags users
 // @Accept json
 // @Produce json
 // @Param user body user.Request true "User Request"
 // @Success 201 {object} response.Response
 // @Failure 400 {object} response.Response
+// @Failure 409 {object} response.Response
+// @Failure 422 {object} response.Response
 // @Failure 500 {object} response.Response
 // @Router /users [post]
 func (uh *UserHandler) CreateUser(c *gin.Context) {
 	req := user.Request{}
-	if err := c.BindJSON(&req); err != nil {
-		errRes := response.ClientResponse(http.StatusBadRequest, "fields provided are wrong", nil, err.Error())
-		c.JSON(http.StatusBadRequest, errRes)
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(apperror.InvalidBody(err))
 		return
 	}
 
 	if err := req.Validate(); err != nil {
-		errRes := response.ClientResponse(http.StatusBadRequest, "fields provided are wrong", nil, err.Error())
-		c.JSON(http.StatusBadRequest, errRes)
+		c.Error(err)
 		return
 	}
 
 	res, err := uh.userService.CreateUser(c.Request.Context(), req)
 	if err != nil {
-		if errors.Is(err, user.ErrorNotFound) {
-			errRes := response.ClientResponse(http.StatusBadRequest, "fields provided are wrong", nil, err.Error())
-			c.JSON(http.StatusBadRequest, errRes)
-			return
-		}
-		errRes := response.ClientResponse(http.StatusInternalServerError, "failed to create user", nil, err.Error())
-		c.JSON(http.StatusInternalServerError, errRes)
+		c.Error(err)
 		return
 	}
 	successRes := response.ClientResponse(http.StatusCreated, "the user was successfully created", res, nil)
@@ -61,23 +55,20 @@ func (uh *UserHandler) CreateUser(c *gin.Context) {
 
 // ListUsers godoc
 // @Summary List all users
-// @Description Get a list of all users
+// @Description Get a list of all users. Requires the users:read:any permission.
 // @Tags users
 // @Produce json
+// @Security BearerAuth
 // @Success 200 {object} response.Response
+// @Failure 401 {object} response.Response
+// @Failure 403 {object} response.Response
 // @Failure 404 {object} response.Response
 // @Failure 500 {object} response.Response
 // @Router /users [get]
 func (uh *UserHandler) ListUsers(c *gin.Context) {
 	res, err := uh.userService.ListUsers(c.Request.Context())
 	if err != nil {
-		if errors.Is(err, user.ErrorNotFound) {
-			errRes := response.ClientResponse(http.StatusNotFound, "no users found", nil, err.Error())
-			c.JSON(http.StatusNotFound, errRes)
-			return
-		}
-		errRes := response.ClientResponse(http.StatusInternalServerError, "failed to list users", nil, err.Error())
-		c.JSON(http.StatusInternalServerError, errRes)
+		c.Error(err)
 		return
 	}
 
@@ -87,80 +78,133 @@ func (uh *UserHandler) ListUsers(c *gin.Context) {
 
 // GetUser godoc
 // @Summary Get a user by ID
-// @Description Get details of a user by its ID
+// @Description Get details of a user by its ID. A user reads itself, another user requires the users:read:any permission.
 // @Tags users
 // @Produce json
+// @Security BearerAuth
 // @Param id path string true "User ID"
 // @Success 200 {object} response.Response
+// @Header 200 {string} ETag "Version of the user"
+// @Failure 401 {object} response.Response
+// @Failure 403 {object} response.Response
+// @Failure 404 {object} response.Response
 // @Failure 500 {object} response.Response
 // @Router /users/{id} [get]
 func (uh *UserHandler) GetUser(c *gin.Context) {
 	id := c.Param("id")
 	res, err := uh.userService.GetUser(c.Request.Context(), id)
 	if err != nil {
-		if errors.Is(err, user.ErrorNotFound) {
-			errRes := response.ClientResponse(http.StatusNotFound, "user not found", nil, err.Error())
-			c.JSON(http.StatusNotFound, errRes)
-			return
-		}
-		errRes := response.ClientResponse(http.StatusInternalServerError, "failed to get user", nil, err.Error())
-		c.JSON(http.StatusInternalServerError, errRes)
+		c.Error(err)
 		return
 	}
+	setETag(c, res.Version)
 	successRes := response.ClientResponse(http.StatusOK, "the user details", res, nil)
 	c.JSON(http.StatusOK, successRes)
 }
 
 // UpdateUser godoc
 // @Summary Update a user by ID
-// @Description Update details of a user by its ID
+// @Description Overwrite a user by its ID. With If-Match only the version the ETag names is updated. A user changes itself, another user requires the users:write permission.
 // @Tags users
 // @Accept json
 // @Produce json
+// @Security BearerAuth
 // @Param id path string true "User ID"
+// @Param If-Match header string false "ETag of the user"
 // @Param user body user.Request true "User Request"
 // @Success 200 {object} response.Response
+// @Header 200 {string} ETag "Version of the user"
 // @Failure 400 {object} response.Response
+// @Failure 401 {object} response.Response
+// @Failure 403 {object} response.Response
 // @Failure 404 {object} response.Response
+// @Failure 409 {object} response.Response
+// @Failure 412 {object} response.Response
+// @Failure 422 {object} response.Response
 // @Failure 500 {object} response.Response
 // @Router /users/{id} [put]
 func (uh *UserHandler) UpdateUser(c *gin.Context) {
 	id := c.Param("id")
+	version, err := ifMatch(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
 	req := user.Request{}
-	if err := c.BindJSON(&req); err != nil {
-		errRes := response.ClientResponse(http.StatusBadRequest, "fields provided are wrong", nil, err.Error())
-		c.JSON(http.StatusBadRequest, errRes)
+	if err = c.ShouldBindJSON(&req); err != nil {
+		c.Error(apperror.InvalidBody(err))
 		return
 	}
 
-	if err := req.Validate(); err != nil {
-		errRes := response.ClientResponse(http.StatusBadRequest, "fields provided are wrong", nil, err.Error())
-		c.JSON(http.StatusBadRequest, errRes)
+	if err = req.Validate(); err != nil {
+		c.Error(err)
 		return
 	}
 
-	err := uh.userService.UpdateUser(c.Request.Context(), id, req)
+	res, err := uh.userService.UpdateUser(c.Request.Context(), id, version, req)
 	if err != nil {
-		if errors.Is(err, user.ErrorNotFound) {
-			errRes := response.ClientResponse(http.StatusNotFound, "user not found", nil, err.Error())
-			c.JSON(http.StatusNotFound, errRes)
-			return
-		}
-		errRes := response.ClientResponse(http.StatusInternalServerError, "failed to update user", nil, err.Error())
-		c.JSON(http.StatusInternalServerError, errRes)
+		c.Error(err)
 		return
 	}
-	successRes := response.ClientResponse(http.StatusOK, "the user was successfully updated", nil, nil)
+	setETag(c, res.Version)
+	successRes := response.ClientResponse(http.StatusOK, "the user was successfully updated", res, nil)
+	c.JSON(http.StatusOK, successRes)
+}
+
+// PatchUser godoc
+// @Summary Patch a user by ID
+// @Description Change the fields of a user given in a JSON merge patch, null clears a field. With If-Match only the version the ETag names is updated. A user changes itself, another user requires the users:write permission.
+// @Tags users
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "User ID"
+// @Param If-Match header string false "ETag of the user"
+// @Param user body user.Patch true "User Patch"
+// @Success 200 {object} response.Response
+// @Header 200 {string} ETag "Version of the user"
+// @Failure 400 {object} response.Response
+// @Failure 401 {object} response.Response
+// @Failure 403 {object} response.Response
+// @Failure 404 {object} response.Response
+// @Failure 409 {object} response.Response
+// @Failure 412 {object} response.Response
+// @Failure 422 {object} response.Response
+// @Failure 500 {object} response.Response
+// @Router /users/{id} [patch]
+func (uh *UserHandler) PatchUser(c *gin.Context) {
+	id := c.Param("id")
+	version, err := ifMatch(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	patch := user.Patch{}
+	if err = bindPatch(c, &patch); err != nil {
+		c.Error(err)
+		return
+	}
+
+	res, err := uh.userService.PatchUser(c.Request.Context(), id, version, patch)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	setETag(c, res.Version)
+	successRes := response.ClientResponse(http.StatusOK, "the user was successfully updated", res, nil)
 	c.JSON(http.StatusOK, successRes)
 }
 
 // DeleteUser godoc
 // @Summary Delete a user by ID
-// @Description Delete a user by its ID
+// @Description Delete a user by its ID. A user changes itself, another user requires the users:write permission.
 // @Tags users
 // @Produce json
+// @Security BearerAuth
 // @Param id path string true "User ID"
 // @Success 200 {object} response.Response
+// @Failure 401 {object} response.Response
+// @Failure 403 {object} response.Response
 // @Failure 404 {object} response.Response
 // @Failure 500 {object} response.Response
 // @Router /users/{id} [delete]
@@ -168,28 +212,52 @@ func (uh *UserHandler) DeleteUser(c *gin.Context) {
 	id := c.Param("id")
 	err := uh.userService.DeleteUser(c.Request.Context(), id)
 	if err != nil {
-		if errors.Is(err, user.ErrorNotFound) {
-			errRes := response.ClientResponse(http.StatusNotFound, "user not found", nil, err.Error())
-			c.JSON(http.StatusNotFound, errRes)
-			return
-		}
-		errRes := response.ClientResponse(http.StatusInternalServerError, "failed to delete user", nil, err.Error())
-		c.JSON(http.StatusInternalServerError, errRes)
+		c.Error(err)
 		return
 	}
 	successRes := response.ClientResponse(http.StatusOK, "the user was successfully deleted", nil, nil)
 	c.JSON(http.StatusOK, successRes)
 }
 
+// RestoreUser godoc
+// @Summary Restore a deleted user
+// @Description Bring a deleted user back, for admins
+// @Tags users
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "User ID"
+// @Success 200 {object} response.Response
+// @Header 200 {string} ETag "Version of the user"
+// @Failure 401 {object} response.Response
+// @Failure 403 {object} response.Response
+// @Failure 404 {object} response.Response
+// @Failure 409 {object} response.Response
+// @Failure 500 {object} response.Response
+// @Router /users/{id}/restore [post]
+func (uh *UserHandler) RestoreUser(c *gin.Context) {
+	id := c.Param("id")
+	res, err := uh.userService.RestoreUser(c.Request.Context(), id)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	setETag(c, res.Version)
+	successRes := response.ClientResponse(http.StatusOK, "the user was successfully restored", res, nil)
+	c.JSON(http.StatusOK, successRes)
+}
+
 // SearchUsers godoc
 // @Summary Search users by name or email
-// @Description Search users by name or email
+// @Description Search users by name or email. Requires the users:read:any permission.
 // @Tags users
 // @Produce json
+// @Security BearerAuth
 // @Param filter query string true "Query"
 // @Param val query string true "Value"
 // @Success 200 {object} response.Response
 // @Failure 400 {object} response.Response
+// @Failure 401 {object} response.Response
+// @Failure 403 {object} response.Response
 // @Failure 404 {object} response.Response
 // @Failure 500 {object} response.Response
 // @Router /users/search [get]
@@ -198,27 +266,94 @@ func (uh *UserHandler) SearchUsers(c *gin.Context) {
 	val := c.Query("val")
 
 	if filter == "" || val == "" {
-		errRes := response.ClientResponse(http.StatusBadRequest, "filter and val query parameters are required", nil, "missing query parameters")
-		c.JSON(http.StatusBadRequest, errRes)
+		c.Error(apperror.BadRequest("missing_query", "filter and val query parameters are required"))
 		return
 	}
 
 	res, err := uh.userService.SearchUser(c.Request.Context(), filter, val)
 	if err != nil {
-		if errors.Is(err, user.ErrorInvalidSearch) {
-			errRes := response.ClientResponse(http.StatusBadRequest, "fields provided are wrong", nil, err.Error())
-			c.JSON(http.StatusBadRequest, errRes)
-			return
-		}
-		if errors.Is(err, user.ErrorNotFound) {
-			errRes := response.ClientResponse(http.StatusNotFound, "no users found", nil, err.Error())
-			c.JSON(http.StatusNotFound, errRes)
-			return
-		}
-		errRes := response.ClientResponse(http.StatusInternalServerError, "failed to search users", nil, err.Error())
-		c.JSON(http.StatusInternalServerError, errRes)
+		c.Error(err)
 		return
 	}
 	successRes := response.ClientResponse(http.StatusOK, "the users list", res, nil)
 	c.JSON(http.StatusOK, successRes)
 }
+
+// IssueToken godoc
+// @Summary Issue an access token
+// @Description Sign an access token for the user with the permissions of its roles. Grants and revokes show in the next token. A user renews its own token with the current one until its session reaches the token max age. A token for another user, or one starting a new session, requires the tokens:issue permission or the bootstrap secret.
+// @Tags users
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "User ID"
+// @Param X-Bootstrap-Secret header string false "Bootstrap secret of the service"
+// @Success 201 {object} response.Response{data=user.Token}
+// @Failure 401 {object} response.Response
+// @Failure 403 {object} response.Response
+// @Failure 404 {object} response.Response
+// @Failure 500 {object} response.Response
+// @Router /users/{id}/token [post]
+func (uh *UserHandler) IssueToken(c *gin.Context) {
+	id := c.Param("id")
+	res, err := uh.userService.IssueToken(c.Request.Context(), id)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	successRes := response.ClientResponse(http.StatusCreated, "the token was successfully issued", res, nil)
+	c.JSON(http.StatusCreated, successRes)
+}
+
+// GrantRole godoc
+// @Summary Grant a role to a user
+// @Description Give the user a role, granting one it has changes nothing. Requires the roles:write permission.
+// @Tags users
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "User ID"
+// @Param role path string true "Role"
+// @Success 200 {object} response.Response
+// @Header 200 {string} ETag "Version of the user"
+// @Failure 401 {object} response.Response
+// @Failure 403 {object} response.Response
+// @Failure 404 {object} response.Response
+// @Failure 500 {object} response.Response
+// @Router /users/{id}/roles/{role} [put]
+func (uh *UserHandler) GrantRole(c *gin.Context) {
+	id := c.Param("id")
+	res, err := uh.userService.GrantRole(c.Request.Context(), id, c.Param("role"))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	setETag(c, res.Version)
+	successRes := response.ClientResponse(http.StatusOK, "the role was successfully granted", res, nil)
+	c.JSON(http.StatusOK, successRes)
+}
+
+// RevokeRole godoc
+// @Summary Revoke a role from a user
+// @Description Take a role away from the user. Requires the roles:write permission.
+// @Tags users
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "User ID"
+// @Param role path string true "Role"
+// @Success 200 {object} response.Response
+// @Header 200 {string} ETag "Version of the user"
+// @Failure 401 {object} response.Response
+// @Failure 403 {object} response.Response
+// @Failure 404 {object} response.Response
+// @Failure 500 {object} response.Response
+// @Router /users/{id}/roles/{role} [delete]
+func (uh *UserHandler) RevokeRole(c *gin.Context) {
+	id := c.Param("id")
+	res, err := uh.userService.RevokeRole(c.Request.Context(), id, c.Param("role"))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	setETag(c, res.Version)
+	successRes := response.ClientResponse(http.StatusOK, "the role was successfully revoked", res, nil)
+	c.JSON(http.StatusOK, successRes)
+}
@@ -3,13 +3,44 @@ package routes
 import (
 	"github.com/gin-gonic/gin"
 	"users-service/internal/api/handler"
+	"users-service/pkg/auth"
 )
 
-func InitRoutes(router *gin.RouterGroup, userHandler *handler.UserHandler) {
-	router.GET("/", userHandler.ListUsers)
+func InitRoutes(router *gin.RouterGroup, userHandler *handler.UserHandler, bootstrapSecret string) {
+	readAny := auth.RequirePermission("users:read:any")
+	read := auth.RequireSelfOrPermission("id", "users:read:any")
+	write := auth.RequireSelfOrPermission("id", "users:write")
+	router.GET("/", readAny, userHandler.ListUsers)
+	// sign-up is open, a new user gets user.DefaultRole and nothing else
 	router.POST("/", userHandler.CreateUser)
-	router.GET("/:id", userHandler.GetUser)
-	router.PUT("/:id", userHandler.UpdateUser)
-	router.DELETE("/:id", userHandler.DeleteUser)
-	router.GET("/search", userHandler.SearchUsers)
+	router.GET("/:id", read, userHandler.GetUser)
+	router.PUT("/:id", write, userHandler.UpdateUser)
+	router.PATCH("/:id", write, userHandler.PatchUser)
+	router.DELETE("/:id", write, userHandler.DeleteUser)
+	router.POST("/:id/restore", auth.RequirePermission("users:restore"), userHandler.RestoreUser)
+	router.POST("/:id/token", auth.Bootstrap(bootstrapSecret, "tokens:issue"), auth.RequireSelfOrPermission("id", "tokens:issue"), userHandler.IssueToken)
+	router.PUT("/:id/roles/:role", auth.RequirePermission("roles:write"), userHandler.GrantRole)
+	router.DELETE("/:id/roles/:role", auth.RequirePermission("roles:write"), userHandler.RevokeRole)
+	router.GET("/search", readAny, userHandler.SearchUsers)
+}
+
+func InitRoleRoutes(router *gin.RouterGroup, roleHandler *handler.RoleHandler) {
+	read := auth.RequirePermission("roles:read", "roles:write")
+	write := auth.RequirePermission("roles:write")
+	router.GET("/", read, roleHandler.ListRoles)
+	router.GET("/permissions", read, roleHandler.ListPermissions)
+	router.PUT("/:role/permissions/:permission", write, roleHandler.GrantPermission)
+	router.DELETE("/:role/permissions/:permission", write, roleHandler.RevokePermission)
+}
+
+func InitAddressRoutes(router *gin.RouterGroup, addressHandler *handler.AddressHandler) {
+	read := auth.RequireSelfOrPermission("id", "users:read:any")
+	write := auth.RequireSelfOrPermission("id", "users:write")
+	router.GET("/", read, addressHandler.ListAddresses)
+	router.POST("/", write, addressHandler.CreateAddress)
+	router.GET("/default", read, addressHandler.GetDefaultAddress)
+	router.GET("/:address_id", read, addressHandler.GetAddress)
+	router.PUT("/:address_id", write, addressHandler.UpdateAddress)
+	router.DELETE("/:address_id", write, addressHandler.DeleteAddress)
+	router.PUT("/:address_id/default", write, addressHandler.SetDefaultAddress)
 }
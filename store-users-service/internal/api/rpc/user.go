@@ -0,0 +1,181 @@
+package rpc
+
+import (
+	"context"
+	"google.golang.org/protobuf/types/known/timestamppb"
+	"strings"
+	"users-service/internal/domain/address"
+	"users-service/internal/domain/user"
+	services "users-service/internal/service/interface"
+	"users-service/pkg/auth"
+	"users-service/pkg/pb/userpb"
+)
+
+// UserServer checks the permissions the REST routes check, a user reads and
+// changes itself without any.
+type UserServer struct {
+	userpb.UnimplementedUserServiceServer
+	userService    services.UserService
+	addressService services.AddressService
+}
+
+func NewUserServer(userService services.UserService, addressService services.AddressService) *UserServer {
+	return &UserServer{
+		userService:    userService,
+		addressService: addressService,
+	}
+}
+
+// CreateUser ignores the roles of the request, a new user gets
+// user.DefaultRole and roles are granted over REST only.
+func (us *UserServer) CreateUser(ctx context.Context, in *userpb.CreateUserRequest) (*userpb.CreateUserResponse, error) {
+	if err := auth.Authorize(ctx, "", "users:write"); err != nil {
+		return nil, statusError(err)
+	}
+
+	req := user.Request{
+		Name:    in.GetName(),
+		Email:   in.GetEmail(),
+		Address: in.GetAddress(),
+	}
+	if err := req.Validate(); err != nil {
+		return nil, statusError(err)
+	}
+
+	id, err := us.userService.CreateUser(ctx, req)
+	if err != nil {
+		return nil, statusError(err)
+	}
+	return &userpb.CreateUserResponse{Id: id}, nil
+}
+
+func (us *UserServer) ListUsers(ctx context.Context, in *userpb.ListUsersRequest) (*userpb.ListUsersResponse, error) {
+	if err := auth.Authorize(ctx, "", "users:read:any"); err != nil {
+		return nil, statusError(err)
+	}
+
+	res, err := us.userService.ListUsers(ctx)
+	if err != nil {
+		return nil, statusError(err)
+	}
+	return &userpb.ListUsersResponse{Users: parseUsers(res)}, nil
+}
+
+func (us *UserServer) GetUser(ctx context.Context, in *userpb.GetUserRequest) (*userpb.User, error) {
+	if err := auth.Authorize(ctx, in.GetId(), "users:read:any"); err != nil {
+		return nil, statusError(err)
+	}
+
+	res, err := us.userService.GetUser(ctx, in.GetId())
+	if err != nil {
+		return nil, statusError(err)
+	}
+	return parseUser(res), nil
+}
+
+// UpdateUser ignores the roles of the request like CreateUser.
+func (us *UserServer) UpdateUser(ctx context.Context, in *userpb.UpdateUserRequest) (*userpb.UpdateUserResponse, error) {
+	if err := auth.Authorize(ctx, in.GetId(), "users:write"); err != nil {
+		return nil, statusError(err)
+	}
+
+	req := user.Request{
+		Name:    in.GetName(),
+		Email:   in.GetEmail(),
+		Address: in.GetAddress(),
+	}
+	if err := req.Validate(); err != nil {
+		return nil, statusError(err)
+	}
+
+	// the gRPC API has no version, updates are unconditional
+	if _, err := us.userService.UpdateUser(ctx, in.GetId(), 0, req); err != nil {
+		return nil, statusError(err)
+	}
+	return &userpb.UpdateUserResponse{}, nil
+}
+
+func (us *UserServer) DeleteUser(ctx context.Context, in *userpb.DeleteUserRequest) (*userpb.DeleteUserResponse, error) {
+	if err := auth.Authorize(ctx, in.GetId(), "users:write"); err != nil {
+		return nil, statusError(err)
+	}
+
+	if err := us.userService.DeleteUser(ctx, in.GetId()); err != nil {
+		return nil, statusError(err)
+	}
+	return &userpb.DeleteUserResponse{}, nil
+}
+
+func (us *UserServer) SearchUsers(ctx context.Context, in *userpb.SearchUsersRequest) (*userpb.ListUsersResponse, error) {
+	if err := auth.Authorize(ctx, "", "users:read:any"); err != nil {
+		return nil, statusError(err)
+	}
+
+	res, err := us.userService.SearchUser(ctx, in.GetFilter(), in.GetValue())
+	if err != nil {
+		return nil, statusError(err)
+	}
+	return &userpb.ListUsersResponse{Users: parseUsers(res)}, nil
+}
+
+func (us *UserServer) GetAddress(ctx context.Context, in *userpb.GetAddressRequest) (*userpb.Address, error) {
+	if err := auth.Authorize(ctx, in.GetUserId(), "users:read:any"); err != nil {
+		return nil, statusError(err)
+	}
+
+	res, err := us.addressService.GetAddress(ctx, in.GetUserId(), in.GetId())
+	if err != nil {
+		return nil, statusError(err)
+	}
+	return parseAddress(res), nil
+}
+
+func (us *UserServer) GetDefaultAddress(ctx context.Context, in *userpb.GetDefaultAddressRequest) (*userpb.Address, error) {
+	if err := auth.Authorize(ctx, in.GetUserId(), "users:read:any"); err != nil {
+		return nil, statusError(err)
+	}
+
+	res, err := us.addressService.GetDefaultAddress(ctx, in.GetUserId())
+	if err != nil {
+		return nil, statusError(err)
+	}
+	return parseAddress(res), nil
+}
+
+func parseUser(res user.Response) *userpb.User {
+	return &userpb.User{
+		Id:      res.ID.String(),
+		Name:    res.Name,
+		Email:   res.Email,
+		Address: res.Address,
+		RegDate: timestamppb.New(res.RegDate),
+		// the message has a single string, the roles go comma separated
+		Roles: strings.Join(res.Roles, ","),
+	}
+}
+
+func parseUsers(data []user.Response) (res []*userpb.User) {
+	res = make([]*userpb.User, 0, len(data))
+	for _, item := range data {
+		res = append(res, parseUser(item))
+	}
+	return
+}
+
+func parseAddress(res address.Response) *userpb.Address {
+	return &userpb.Address{
+		Id:         res.ID.String(),
+		UserId:     res.UserID.String(),
+		Label:      res.Label,
+		Recipient:  res.Recipient,
+		Line1:      res.Line1,
+		Line2:      res.Line2,
+		City:       res.City,
+		Region:     res.Region,
+		PostalCode: res.PostalCode,
+		Country:    res.Country,
+		Phone:      res.Phone,
+		IsDefault:  res.IsDefault,
+		CreatedAt:  timestamppb.New(res.CreatedAt),
+	}
+}
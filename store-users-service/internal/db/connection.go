@@ -1,26 +1,99 @@
 package db
 
 import (
+	"context"
 	_ "database/sql"
 	"fmt"
+	"github.com/XSAM/otelsql"
 	_ "github.com/golang-migrate/migrate/v4/database/postgres"
 	"github.com/jmoiron/sqlx"
-	"log"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"log/slog"
+	"net"
+	"net/url"
+	"time"
 	"users-service/internal/config"
+	"users-service/pkg/metrics"
 )
 
-func ConnectDatabase(cfg config.Config) (store *sqlx.DB, err error) {
-	psqlUrl := fmt.Sprintf("postgres://%s:%s@%s:%s/%s?sslmode=disable",
-		cfg.DBUser, cfg.DBPassword, cfg.DBHost, cfg.DBPort, cfg.DBName)
+// pingTimeout bounds one attempt to reach the database, maxRetryInterval the
+// wait between attempts while it is starting.
+const (
+	pingTimeout      = 5 * time.Second
+	maxRetryInterval = 5 * time.Second
+)
+
+// Replica is the database list and search queries read from. It may lag
+// behind the primary, so reads that must see a write just made go to the
+// primary. Without a replica in the configuration it is the primary.
+type Replica struct {
+	*sqlx.DB
+}
 
-	log.Printf("url: %v", psqlUrl)
+// ConnectDatabase connects to the primary database, retrying with a growing
+// interval for up to cfg.DBConnectTimeout while it is not ready yet.
+func ConnectDatabase(cfg config.Config) (*sqlx.DB, error) {
+	psqlUrl := url.URL{
+		Scheme:   "postgres",
+		User:     url.UserPassword(cfg.DBUser, cfg.DBPassword),
+		Host:     net.JoinHostPort(cfg.DBHost, cfg.DBPort),
+		Path:     cfg.DBName,
+		RawQuery: "sslmode=disable",
+	}
+	return connect(cfg, &psqlUrl, cfg.DBName)
+}
+
+// ConnectReplica connects to the read replica at cfg.DBReplicaURL the way
+// ConnectDatabase connects to the primary.
+func ConnectReplica(cfg config.Config, primary *sqlx.DB) (Replica, error) {
+	if cfg.DBReplicaURL == "" {
+		return Replica{primary}, nil
+	}
+	replicaUrl, err := url.Parse(cfg.DBReplicaURL)
+	if err != nil {
+		return Replica{}, err
+	}
+	store, err := connect(cfg, replicaUrl, cfg.DBName+"_replica")
+	return Replica{store}, err
+}
 
-	store, err = sqlx.Connect("postgres", psqlUrl)
+func connect(cfg config.Config, psqlUrl *url.URL, name string) (store *sqlx.DB, err error) {
+	// otelsql traces every query made through the pool as a child span of
+	// the request that ran it
+	sqlDB, err := otelsql.Open("postgres", psqlUrl.String(),
+		otelsql.WithAttributes(semconv.DBSystemPostgreSQL),
+		otelsql.WithSpanOptions(otelsql.SpanOptions{OmitConnResetSession: true, OmitRows: true}),
+	)
 	if err != nil {
-		log.Fatalf("Failed to connect to the database: %v", err)
 		return
 	}
-	log.Printf("Connected to database with URL: %s", psqlUrl)
-	return
+	sqlDB.SetMaxOpenConns(cfg.DBMaxOpenConns)
+	sqlDB.SetMaxIdleConns(cfg.DBMaxIdleConns)
+	sqlDB.SetConnMaxLifetime(cfg.DBConnMaxLifetime)
+	store = sqlx.NewDb(sqlDB, "postgres")
+
+	deadline := time.Now().Add(cfg.DBConnectTimeout)
+	interval := 500 * time.Millisecond
+	for attempt := 1; ; attempt++ {
+		if err = ping(store); err == nil {
+			break
+		}
+		if time.Now().Add(interval).After(deadline) {
+			store.Close()
+			return nil, fmt.Errorf("failed to connect to the database %s after %d attempts: %w", psqlUrl.Redacted(), attempt, err)
+		}
+		slog.Warn("database is not ready", "url", psqlUrl.Redacted(), "attempt", attempt, "retry_in", interval.String(), "error", err)
+		time.Sleep(interval)
+		interval = min(interval*2, maxRetryInterval)
+	}
+
+	metrics.RegisterDB(sqlDB, name)
+	slog.Info("connected to database", "url", psqlUrl.Redacted())
+	return store, nil
+}
 
+func ping(store *sqlx.DB) error {
+	ctx, cancel := context.WithTimeout(context.Background(), pingTimeout)
+	defer cancel()
+	return store.PingContext(ctx)
 }
@@ -0,0 +1,12 @@
+package di
+
+import (
+	"github.com/jmoiron/sqlx"
+	"users-service/internal/config"
+	"users-service/pkg/events"
+)
+
+// NewRelay relays the outbox of the service to the broker at cfg.EventsURL.
+func NewRelay(cfg config.Config, db *sqlx.DB) *events.Relay {
+	return events.NewRelay(db, events.NewPublisher(cfg.EventsURL), "users-service")
+}
@@ -8,6 +8,7 @@ import (
 	_ "github.com/lib/pq"
 	http "todo-list/internal/api"
 	"users-service/internal/api/handler"
+	"users-service/internal/api/rpc"
 	"users-service/internal/config"
 	"users-service/internal/db"
 	"users-service/internal/repository"
@@ -17,9 +18,20 @@ import (
 func InitializeAPI(cfg config.Config) (*http.Server, error) {
 	wire.Build(
 		db.ConnectDatabase,
+		db.ConnectReplica,
 		handler.NewUserHandler,
+		handler.NewAddressHandler,
+		handler.NewRoleHandler,
+		handler.NewHealthHandler,
 		repository.NewUserRepository,
+		repository.NewAddressRepository,
+		repository.NewRoleRepository,
 		service.NewUserService,
+		service.NewAddressService,
+		service.NewRoleService,
+		NewHealthChecker,
+		NewRelay,
+		rpc.NewServer,
 		http.NewServer,
 	)
 	return &http.Server{}, nil
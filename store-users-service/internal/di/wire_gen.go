@@ -8,6 +8,7 @@ import (
 	_ "github.com/lib/pq"
 	"users-service/internal/api"
 	"users-service/internal/api/handler"
+	"users-service/internal/api/rpc"
 	"users-service/internal/config"
 	"users-service/internal/db"
 	"users-service/internal/repository"
@@ -19,13 +20,29 @@ func InitializeAPI(cfg config.Config) (*http.Server, error) {
 	if err != nil {
 		return nil, err
 	}
-	err = db.Migrate(sqlxDB)
+	if cfg.AutoMigrate {
+		err = db.Migrate(sqlxDB)
+		if err != nil {
+			return nil, err
+		}
+	}
+	replica, err := db.ConnectReplica(cfg, sqlxDB)
 	if err != nil {
 		return nil, err
 	}
-	userRepository := repository.NewUserRepository(sqlxDB)
-	userService := service.NewUserService(userRepository)
+	userRepository := repository.NewUserRepository(sqlxDB, replica)
+	userService := service.NewUserService(cfg, userRepository)
 	userHandler := handler.NewUserHandler(userService)
-	server := http.NewServer(userHandler)
+	addressRepository := repository.NewAddressRepository(sqlxDB, replica)
+	addressService := service.NewAddressService(addressRepository)
+	addressHandler := handler.NewAddressHandler(addressService)
+	roleRepository := repository.NewRoleRepository(sqlxDB)
+	roleService := service.NewRoleService(roleRepository)
+	roleHandler := handler.NewRoleHandler(roleService)
+	checker := NewHealthChecker(sqlxDB, replica)
+	healthHandler := handler.NewHealthHandler(checker)
+	relay := NewRelay(cfg, sqlxDB)
+	rpcServer := rpc.NewServer(cfg, userService, addressService)
+	server := http.NewServer(cfg, sqlxDB, replica, userHandler, addressHandler, roleHandler, healthHandler, relay, rpcServer)
 	return server, nil
 }
@@ -0,0 +1,72 @@
+package address
+
+import (
+	"github.com/google/uuid"
+	"strings"
+	"time"
+	"users-service/pkg/apperror"
+	"users-service/pkg/validate"
+)
+
+var ErrorNotFound = apperror.NotFound("address_not_found", "address not found")
+
+type Request struct {
+	Label      string `json:"label" validate:"max=50"`
+	Recipient  string `json:"recipient" validate:"required,max=100"`
+	Line1      string `json:"line1" validate:"required,max=200"`
+	Line2      string `json:"line2" validate:"max=200"`
+	City       string `json:"city" validate:"required,max=100"`
+	Region     string `json:"region" validate:"max=100"`
+	PostalCode string `json:"postal_code" validate:"max=20"`
+	Country    string `json:"country" example:"KZ" validate:"required,country"`
+	Phone      string `json:"phone" validate:"max=30"`
+	IsDefault  bool   `json:"is_default"`
+}
+
+type Response struct {
+	ID         uuid.UUID `json:"id"`
+	UserID     uuid.UUID `json:"user_id"`
+	Label      string    `json:"label"`
+	Recipient  string    `json:"recipient"`
+	Line1      string    `json:"line1"`
+	Line2      string    `json:"line2"`
+	City       string    `json:"city"`
+	Region     string    `json:"region"`
+	PostalCode string    `json:"postal_code"`
+	Country    string    `json:"country"`
+	Phone      string    `json:"phone"`
+	IsDefault  bool      `json:"is_default"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+func ParseFromEntity(entity Entity) Response {
+	return Response{
+		ID:         entity.ID,
+		UserID:     entity.UserID,
+		Label:      entity.Label,
+		Recipient:  entity.Recipient,
+		Line1:      entity.Line1,
+		Line2:      entity.Line2,
+		City:       entity.City,
+		Region:     entity.Region,
+		PostalCode: entity.PostalCode,
+		Country:    entity.Country,
+		Phone:      entity.Phone,
+		IsDefault:  entity.IsDefault,
+		CreatedAt:  entity.CreatedAt,
+	}
+}
+
+func ParseFromEntities(data []Entity) (res []Response) {
+	res = make([]Response, 0)
+	for _, entity := range data {
+		res = append(res, ParseFromEntity(entity))
+	}
+	return
+}
+
+func (r *Request) Validate() error {
+	r.Country = strings.ToUpper(strings.TrimSpace(r.Country))
+	r.Region = strings.TrimSpace(r.Region)
+	return validate.Struct(r)
+}
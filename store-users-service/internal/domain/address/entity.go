@@ -0,0 +1,22 @@
+package address
+
+import (
+	"github.com/google/uuid"
+	"time"
+)
+
+type Entity struct {
+	ID         uuid.UUID `db:"id" bson:"_id"`
+	UserID     uuid.UUID `db:"user_id" bson:"user_id"`
+	Label      string    `db:"label" bson:"label"`
+	Recipient  string    `db:"recipient" bson:"recipient"`
+	Line1      string    `db:"line1" bson:"line1"`
+	Line2      string    `db:"line2" bson:"line2"`
+	City       string    `db:"city" bson:"city"`
+	Region     string    `db:"region" bson:"region"`
+	PostalCode string    `db:"postal_code" bson:"postal_code"`
+	Country    string    `db:"country" bson:"country"`
+	Phone      string    `db:"phone" bson:"phone"`
+	IsDefault  bool      `db:"is_default" bson:"is_default"`
+	CreatedAt  time.Time `db:"created_at" bson:"created_at"`
+}
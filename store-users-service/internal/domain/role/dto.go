@@ -0,0 +1,63 @@
+package role
+
+import (
+	"regexp"
+	"time"
+	"users-service/pkg/apperror"
+)
+
+var (
+	ErrorNotFound           = apperror.NotFound("role_not_found", "role not found")
+	ErrorInvalidName        = apperror.BadRequest("invalid_role", "role names are lower case letters, digits, - and _")
+	ErrorPermissionNotFound = apperror.NotFound("permission_not_found", "permission not found")
+	ErrorNotGranted         = apperror.NotFound("permission_not_granted", "role does not have the permission")
+)
+
+var validName = regexp.MustCompile(`^[a-z][a-z0-9_-]{0,49}$`)
+
+type Response struct {
+	Name        string    `json:"name"`
+	Permissions []string  `json:"permissions"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+type PermissionResponse struct {
+	Name        string `json:"name" example:"products:restore"`
+	Description string `json:"description"`
+}
+
+// ValidateName reports whether name can name a new role.
+func ValidateName(name string) error {
+	if !validName.MatchString(name) {
+		return ErrorInvalidName
+	}
+	return nil
+}
+
+func ParseFromEntity(entity Entity) Response {
+	res := Response{
+		Name:        entity.Name,
+		Permissions: entity.Permissions,
+		CreatedAt:   entity.CreatedAt,
+	}
+	if res.Permissions == nil {
+		res.Permissions = []string{}
+	}
+	return res
+}
+
+func ParseFromEntities(data []Entity) (res []Response) {
+	res = make([]Response, 0)
+	for _, entity := range data {
+		res = append(res, ParseFromEntity(entity))
+	}
+	return
+}
+
+func ParseFromPermissions(data []Permission) (res []PermissionResponse) {
+	res = make([]PermissionResponse, 0)
+	for _, p := range data {
+		res = append(res, PermissionResponse{Name: p.Name, Description: p.Description})
+	}
+	return
+}
@@ -0,0 +1,19 @@
+package role
+
+import (
+	"github.com/lib/pq"
+	"time"
+)
+
+type Entity struct {
+	Name        string         `db:"name"`
+	Permissions pq.StringArray `db:"permissions"`
+	CreatedAt   time.Time      `db:"created_at"`
+}
+
+// Permission is one the services check, see the roles migration for the
+// ones they know.
+type Permission struct {
+	Name        string `db:"name"`
+	Description string `db:"description"`
+}
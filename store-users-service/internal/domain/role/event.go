@@ -0,0 +1,4 @@
+package role
+
+// AuditEntity names roles in the audit log.
+const AuditEntity = "role"
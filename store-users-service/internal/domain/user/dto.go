@@ -1,26 +1,37 @@
 package user
 
 import (
-	"errors"
 	"github.com/google/uuid"
-	"regexp"
+	"strings"
 	"time"
+	"users-service/pkg/apperror"
+	"users-service/pkg/validate"
 )
 
 var (
-	ErrorNotFound       = errors.New("user not found")
-	ErrorInvalidSearch  = errors.New("invalid search parameters")
-	ErrorInvalidName    = errors.New("invalid name")
-	ErrorInvalidEmail   = errors.New("invalid email")
-	ErrorInvalidRole    = errors.New("invalid role")
-	ErrorInvalidAddress = errors.New("invalid address")
+	ErrorNotFound        = apperror.NotFound("user_not_found", "user not found")
+	ErrorInvalidSearch   = apperror.BadRequest("invalid_search", "invalid search parameters")
+	ErrorEmailTaken      = apperror.Conflict("email_taken", "email is already registered")
+	ErrorVersionMismatch = apperror.PreconditionFailed("version_mismatch", "user was changed since it was read")
+	ErrorNotDeleted      = apperror.Conflict("user_not_deleted", "user is not deleted")
+	ErrorRoleNotGranted  = apperror.NotFound("role_not_granted", "user does not have the role")
+	ErrorSessionExpired  = apperror.Unauthorized("session_expired", "session is too old to renew the token")
 )
 
+// DefaultRole is granted to every new user.
+const DefaultRole = "user"
+
 type Request struct {
-	Name    string `json:"name"`
-	Email   string `json:"email"`
-	Address string `json:"address"`
-	Roles   string `json:"roles"`
+	Name    string `json:"name" validate:"required,max=100"`
+	Email   string `json:"email" validate:"required,email,max=254"`
+	Address string `json:"address" validate:"max=500"`
+}
+
+// Patch is a merge patch of a user, nil fields are left as they are.
+type Patch struct {
+	Name    *string `json:"name"`
+	Email   *string `json:"email"`
+	Address *string `json:"address"`
 }
 
 type Response struct {
@@ -29,18 +40,36 @@ type Response struct {
 	Email   string    `json:"email"`
 	Address string    `json:"address"`
 	RegDate time.Time `json:"reg_date"`
-	Roles   string    `json:"roles"`
+	Roles   []string  `json:"roles"`
+	Version int       `json:"version"`
+	// DeletedAt is only set for a deleted user, e.g. in the audit log
+	DeletedAt *time.Time `json:"deleted_at,omitempty"`
 }
 
 func ParseFromEntity(entity Entity) Response {
-	return Response{
+	res := Response{
 		ID:      entity.ID,
 		Name:    entity.Name,
 		Email:   entity.Email,
 		Address: entity.Address,
 		RegDate: entity.RegDate,
 		Roles:   entity.Roles,
+		Version: entity.Version,
+	}
+	if entity.DeletedAt.Valid {
+		res.DeletedAt = &entity.DeletedAt.Time
 	}
+	return res
+}
+
+// Token is an access token of a user with the roles and permissions it
+// carries, they are fixed until the token expires.
+type Token struct {
+	AccessToken string    `json:"access_token"`
+	TokenType   string    `json:"token_type"`
+	ExpiresAt   time.Time `json:"expires_at"`
+	Roles       []string  `json:"roles"`
+	Permissions []string  `json:"permissions"`
 }
 
 func ParseFromEntities(data []Entity) (res []Response) {
@@ -51,34 +80,27 @@ func ParseFromEntities(data []Entity) (res []Response) {
 	return
 }
 func (r *Request) Validate() error {
-	if r.Name == "" {
-		return ErrorInvalidName
-	}
-	if r.Address == "" {
-		return ErrorInvalidAddress
+	r.Email = strings.ToLower(strings.TrimSpace(r.Email))
+	return validate.Struct(r)
+}
+
+// Apply returns the request that sets entity to what the patch asks for.
+func (p Patch) Apply(entity Entity) Request {
+	req := Request{
+		Name:    entity.Name,
+		Email:   entity.Email,
+		Address: entity.Address,
 	}
-	if !isValidEmail(r.Email) {
-		return ErrorInvalidEmail
+	if p.Name != nil {
+		req.Name = *p.Name
 	}
-	if !isValidRole(r.Roles) {
-		return ErrorInvalidRole
+	if p.Email != nil {
+		req.Email = *p.Email
 	}
-	return nil
-}
-
-func isValidEmail(email string) bool {
-	re := regexp.MustCompile(`^[a-z0-9._%+\-]+@[a-z0-9.\-]+\.[a-z]{2,}$`)
-	return re.MatchString(email)
-}
-
-func isValidRole(role string) bool {
-	validRoles := map[string]bool{
-		"admin":     true,
-		"user":      true,
-		"manager":   true,
-		"developer": true,
+	if p.Address != nil {
+		req.Address = *p.Address
 	}
-	return validRoles[role]
+	return req
 }
 
 func IsValidFilter(filter string) bool {
@@ -0,0 +1,17 @@
+package user
+
+// Events written to the outbox when users change.
+const (
+	EventRegistered = "UserRegistered"
+	EventUpdated    = "UserUpdated"
+	EventDeleted    = "UserDeleted"
+	EventRestored   = "UserRestored"
+)
+
+// AuditEntity names users in the audit log.
+const AuditEntity = "user"
+
+// Deleted is the payload of UserDeleted.
+type Deleted struct {
+	UserID string `json:"user_id"`
+}
@@ -0,0 +1,174 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"github.com/jmoiron/sqlx"
+	"users-service/internal/db"
+	"users-service/internal/domain/address"
+	"users-service/internal/domain/user"
+	interfaces "users-service/internal/repository/interface"
+)
+
+type AddressRepository struct {
+	db      *sqlx.DB
+	replica *sqlx.DB
+}
+
+func NewAddressRepository(db *sqlx.DB, replica db.Replica) interfaces.AddressRepository {
+	return &AddressRepository{
+		db:      db,
+		replica: replica.DB,
+	}
+}
+
+// Create adds an address to the user's book. The first address of a user is
+// always the default one.
+func (ar *AddressRepository) Create(ctx context.Context, data address.Entity) (id string, err error) {
+	err = ar.inTx(ctx, func(tx *sqlx.Tx) (err error) {
+		var count int
+		if err = tx.QueryRowContext(ctx, `SELECT COUNT(*) FROM addresses WHERE user_id = $1;`, data.UserID).Scan(&count); err != nil {
+			return
+		}
+		if count == 0 {
+			data.IsDefault = true
+		}
+		if data.IsDefault {
+			if _, err = tx.ExecContext(ctx, `UPDATE addresses SET is_default = FALSE WHERE user_id = $1;`, data.UserID); err != nil {
+				return
+			}
+		}
+
+		query := `
+			INSERT INTO addresses (user_id, label, recipient, line1, line2, city, region, postal_code, country, phone, is_default)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11) RETURNING id;`
+		args := []any{
+			data.UserID,
+			data.Label,
+			data.Recipient,
+			data.Line1,
+			data.Line2,
+			data.City,
+			data.Region,
+			data.PostalCode,
+			data.Country,
+			data.Phone,
+			data.IsDefault,
+		}
+		err = tx.QueryRowContext(ctx, query, args...).Scan(&id)
+		return
+	})
+	return
+}
+
+func (ar *AddressRepository) List(ctx context.Context, userID string) (dest []address.Entity, err error) {
+	dest = []address.Entity{}
+	query := `SELECT * FROM addresses WHERE user_id = $1 ORDER BY is_default DESC, created_at;`
+	if err = ar.replica.SelectContext(ctx, &dest, query, userID); err != nil {
+		err = ar.mapError(err)
+	}
+	return
+}
+
+func (ar *AddressRepository) Get(ctx context.Context, userID, id string) (dest address.Entity, err error) {
+	query := `SELECT * FROM addresses WHERE user_id = $1 AND id = $2;`
+	if err = ar.db.GetContext(ctx, &dest, query, userID, id); err != nil {
+		err = ar.mapError(err)
+	}
+	return
+}
+
+func (ar *AddressRepository) GetDefault(ctx context.Context, userID string) (dest address.Entity, err error) {
+	query := `SELECT * FROM addresses WHERE user_id = $1 AND is_default;`
+	if err = ar.db.GetContext(ctx, &dest, query, userID); err != nil {
+		err = ar.mapError(err)
+	}
+	return
+}
+
+func (ar *AddressRepository) Update(ctx context.Context, userID, id string, data address.Entity) (err error) {
+	err = ar.inTx(ctx, func(tx *sqlx.Tx) (err error) {
+		if data.IsDefault {
+			if _, err = tx.ExecContext(ctx, `UPDATE addresses SET is_default = FALSE WHERE user_id = $1 AND id <> $2;`, userID, id); err != nil {
+				return
+			}
+		}
+
+		// an address stays the default until another one takes its place
+		query := `
+			UPDATE addresses SET label = $1, recipient = $2, line1 = $3, line2 = $4, city = $5, region = $6,
+				postal_code = $7, country = $8, phone = $9, is_default = is_default OR $10
+			WHERE user_id = $11 AND id = $12 RETURNING id;`
+		args := []any{
+			data.Label,
+			data.Recipient,
+			data.Line1,
+			data.Line2,
+			data.City,
+			data.Region,
+			data.PostalCode,
+			data.Country,
+			data.Phone,
+			data.IsDefault,
+			userID,
+			id,
+		}
+		err = tx.QueryRowContext(ctx, query, args...).Scan(&id)
+		return
+	})
+	return
+}
+
+// Delete removes an address, when it was the default the oldest remaining
+// address becomes the default.
+func (ar *AddressRepository) Delete(ctx context.Context, userID, id string) (err error) {
+	err = ar.inTx(ctx, func(tx *sqlx.Tx) (err error) {
+		var isDefault bool
+		query := `DELETE FROM addresses WHERE user_id = $1 AND id = $2 RETURNING is_default;`
+		if err = tx.QueryRowContext(ctx, query, userID, id).Scan(&isDefault); err != nil || !isDefault {
+			return
+		}
+		query = `
+			UPDATE addresses SET is_default = TRUE
+			WHERE id = (SELECT id FROM addresses WHERE user_id = $1 ORDER BY created_at LIMIT 1);`
+		_, err = tx.ExecContext(ctx, query, userID)
+		return
+	})
+	return
+}
+
+func (ar *AddressRepository) SetDefault(ctx context.Context, userID, id string) (err error) {
+	err = ar.inTx(ctx, func(tx *sqlx.Tx) (err error) {
+		if _, err = tx.ExecContext(ctx, `UPDATE addresses SET is_default = FALSE WHERE user_id = $1 AND id <> $2;`, userID, id); err != nil {
+			return
+		}
+		query := `UPDATE addresses SET is_default = TRUE WHERE user_id = $1 AND id = $2 RETURNING id;`
+		err = tx.QueryRowContext(ctx, query, userID, id).Scan(&id)
+		return
+	})
+	return
+}
+
+func (ar *AddressRepository) inTx(ctx context.Context, fn func(tx *sqlx.Tx) error) (err error) {
+	tx, err := ar.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return
+	}
+	if err = fn(tx); err != nil {
+		tx.Rollback()
+		return ar.mapError(err)
+	}
+	return tx.Commit()
+}
+
+func (ar *AddressRepository) mapError(err error) error {
+	switch {
+	case db.IsForeignKeyViolation(err):
+		return user.ErrorNotFound
+	case errors.Is(err, sql.ErrNoRows), db.IsInvalidText(err):
+		// no such row, or a malformed UUID in the path
+		return address.ErrorNotFound
+	}
+	return err
+}
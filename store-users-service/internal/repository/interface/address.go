@@ -0,0 +1,16 @@
+package interfaces
+
+import (
+	"context"
+	"users-service/internal/domain/address"
+)
+
+type AddressRepository interface {
+	Create(ctx context.Context, entity address.Entity) (id string, err error)
+	List(ctx context.Context, userID string) (res []address.Entity, err error)
+	Get(ctx context.Context, userID, id string) (res address.Entity, err error)
+	GetDefault(ctx context.Context, userID string) (res address.Entity, err error)
+	Update(ctx context.Context, userID, id string, entity address.Entity) (err error)
+	Delete(ctx context.Context, userID, id string) (err error)
+	SetDefault(ctx context.Context, userID, id string) (err error)
+}
@@ -0,0 +1,13 @@
+package interfaces
+
+import (
+	"context"
+	"users-service/internal/domain/role"
+)
+
+type RoleRepository interface {
+	List(ctx context.Context) (roles []role.Entity, err error)
+	Permissions(ctx context.Context) (permissions []role.Permission, err error)
+	GrantPermission(ctx context.Context, name, permission string) (dest role.Entity, err error)
+	RevokePermission(ctx context.Context, name, permission string) (dest role.Entity, err error)
+}
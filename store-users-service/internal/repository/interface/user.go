@@ -10,6 +10,10 @@ type UserRepository interface {
 	List(ctx context.Context) (users []user.Entity, err error)
 	Get(ctx context.Context, id string) (dest user.Entity, err error)
 	Delete(ctx context.Context, id string) (err error)
-	Update(ctx context.Context, id string, data user.Entity) (err error)
+	Restore(ctx context.Context, id string) (dest user.Entity, err error)
+	Update(ctx context.Context, id string, version int, data user.Entity) (dest user.Entity, err error)
 	Search(ctx context.Context, filter, value string) (users []user.Entity, err error)
+	GrantRole(ctx context.Context, id, role string) (dest user.Entity, err error)
+	RevokeRole(ctx context.Context, id, role string) (dest user.Entity, err error)
+	Permissions(ctx context.Context, id string) (permissions []string, err error)
 }
@@ -0,0 +1,134 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"github.com/jmoiron/sqlx"
+	"slices"
+	"users-service/internal/db"
+	"users-service/internal/domain/role"
+	interfaces "users-service/internal/repository/interface"
+	"users-service/pkg/audit"
+)
+
+// selectRoles reads roles with their permissions, sorted.
+const selectRoles = `
+	SELECT r.name, r.created_at,
+		ARRAY(SELECT permission FROM role_permissions WHERE role = r.name ORDER BY permission) AS permissions
+	FROM roles r`
+
+type RoleRepository struct {
+	db *sqlx.DB
+}
+
+// NewRoleRepository reads roles from the primary only, a grant is seen by
+// the next token issued.
+func NewRoleRepository(db *sqlx.DB) interfaces.RoleRepository {
+	return &RoleRepository{
+		db: db,
+	}
+}
+
+func (rr *RoleRepository) List(ctx context.Context) (roles []role.Entity, err error) {
+	roles = []role.Entity{}
+	err = rr.db.SelectContext(ctx, &roles, selectRoles+` ORDER BY r.name;`)
+	return
+}
+
+func (rr *RoleRepository) Permissions(ctx context.Context) (permissions []role.Permission, err error) {
+	permissions = []role.Permission{}
+	err = rr.db.SelectContext(ctx, &permissions, `SELECT name, description FROM permissions ORDER BY name;`)
+	return
+}
+
+// GrantPermission gives a role a permission, the role is created when it
+// does not exist yet. Granting a permission the role has is a no-op.
+func (rr *RoleRepository) GrantPermission(ctx context.Context, name, permission string) (dest role.Entity, err error) {
+	tx, err := rr.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return
+	}
+	defer tx.Rollback()
+
+	res, err := tx.ExecContext(ctx, `INSERT INTO roles (name) VALUES ($1) ON CONFLICT (name) DO NOTHING;`, name)
+	if err != nil {
+		return
+	}
+	created, err := res.RowsAffected()
+	if err != nil {
+		return
+	}
+	current, err := rr.lock(ctx, tx, name)
+	if err != nil {
+		return
+	}
+	if slices.Contains(current.Permissions, permission) {
+		return current, nil
+	}
+	action, before := audit.ActionCreate, any(nil)
+	if created == 0 {
+		action, before = audit.ActionUpdate, role.ParseFromEntity(current)
+	}
+
+	query := `INSERT INTO role_permissions (role, permission) VALUES ($1, $2);`
+	if _, err = tx.ExecContext(ctx, query, name, permission); err != nil {
+		if db.IsForeignKeyViolation(err) {
+			err = role.ErrorPermissionNotFound
+		}
+		return
+	}
+	if dest, err = rr.get(ctx, tx, name); err != nil {
+		return
+	}
+	if err = audit.Record(ctx, tx, role.AuditEntity, name, action, before, role.ParseFromEntity(dest)); err != nil {
+		return
+	}
+	err = tx.Commit()
+	return
+}
+
+// RevokePermission takes a permission away from a role. The role is kept
+// even without permissions, its users keep it too.
+func (rr *RoleRepository) RevokePermission(ctx context.Context, name, permission string) (dest role.Entity, err error) {
+	tx, err := rr.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return
+	}
+	defer tx.Rollback()
+
+	before, err := rr.lock(ctx, tx, name)
+	if err != nil {
+		return
+	}
+	if !slices.Contains(before.Permissions, permission) {
+		err = role.ErrorNotGranted
+		return
+	}
+	query := `DELETE FROM role_permissions WHERE role = $1 AND permission = $2;`
+	if _, err = tx.ExecContext(ctx, query, name, permission); err != nil {
+		return
+	}
+	if dest, err = rr.get(ctx, tx, name); err != nil {
+		return
+	}
+	if err = audit.Record(ctx, tx, role.AuditEntity, name, audit.ActionUpdate, role.ParseFromEntity(before), role.ParseFromEntity(dest)); err != nil {
+		return
+	}
+	err = tx.Commit()
+	return
+}
+
+// lock reads a role and locks it until tx ends, so that concurrent grants
+// of one role are recorded one after the other.
+func (rr *RoleRepository) lock(ctx context.Context, tx *sqlx.Tx, name string) (dest role.Entity, err error) {
+	if err = tx.GetContext(ctx, &dest, selectRoles+` WHERE r.name = $1 FOR UPDATE OF r;`, name); errors.Is(err, sql.ErrNoRows) {
+		err = role.ErrorNotFound
+	}
+	return
+}
+
+func (rr *RoleRepository) get(ctx context.Context, tx *sqlx.Tx, name string) (dest role.Entity, err error) {
+	err = tx.GetContext(ctx, &dest, selectRoles+` WHERE r.name = $1;`, name)
+	return
+}
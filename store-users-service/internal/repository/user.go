@@ -2,118 +2,325 @@ package repository
 
 import (
 	"context"
+	"database/sql"
+	"errors"
 	"fmt"
+	"github.com/google/uuid"
 	"github.com/jmoiron/sqlx"
-	"log"
-	"strings"
+	"github.com/lib/pq"
+	"log/slog"
+	"slices"
+	"users-service/internal/db"
+	"users-service/internal/domain/role"
 	"users-service/internal/domain/user"
 	interfaces "users-service/internal/repository/interface"
+	"users-service/pkg/audit"
+	"users-service/pkg/events"
 )
 
 type UserRepository struct {
-	db *sqlx.DB
+	db      *sqlx.DB
+	replica *sqlx.DB
 }
 
-func NewUserRepository(db *sqlx.DB) interfaces.UserRepository {
+func NewUserRepository(db *sqlx.DB, replica db.Replica) interfaces.UserRepository {
 	return &UserRepository{
-		db: db,
+		db:      db,
+		replica: replica.DB,
 	}
 }
 
 func (ur *UserRepository) Create(ctx context.Context, data user.Entity) (id string, err error) {
-	query := `INSERT INTO users (name, address, email, roles) VALUES ($1,$2,$3,$4) RETURNING id;`
+	tx, err := ur.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return
+	}
+	defer tx.Rollback()
+
+	var dest user.Entity
+	query := `INSERT INTO users (name, address, email) VALUES ($1,$2,$3) RETURNING *;`
 	args := []any{
 		data.Name,
 		data.Address,
 		data.Email,
-		data.Roles,
 	}
-	if err = ur.db.QueryRowContext(ctx, query, args...).Scan(&id); err != nil {
-		if err != nil {
-			err = user.ErrorNotFound
-		}
+	if err = tx.GetContext(ctx, &dest, query, args...); err != nil {
+		err = ur.mapError(err)
+		return
+	}
+	id = dest.ID.String()
+	if _, err = tx.ExecContext(ctx, `INSERT INTO user_roles (user_id, role) VALUES ($1, $2);`, id, user.DefaultRole); err != nil {
+		return
 	}
+	dest.Roles = []string{user.DefaultRole}
+	if err = events.Store(ctx, tx, user.EventRegistered, id, user.ParseFromEntity(dest)); err != nil {
+		return
+	}
+	if err = audit.Record(ctx, tx, user.AuditEntity, id, audit.ActionCreate, nil, user.ParseFromEntity(dest)); err != nil {
+		return
+	}
+	err = tx.Commit()
 	return
 }
 
+// Delete marks a user deleted. A deleted user is left out of every read
+// but the one of Restore.
 func (ur *UserRepository) Delete(ctx context.Context, id string) (err error) {
-	query := `DELETE FROM users WHERE id = $1 RETURNING id;`
-	args := []any{id}
+	tx, err := ur.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return
+	}
+	defer tx.Rollback()
 
-	if err = ur.db.QueryRowContext(ctx, query, args...).Scan(&id); err != nil {
-		if err != nil {
-			err = user.ErrorNotFound
-		}
+	before, err := ur.lock(ctx, tx, id)
+	if err != nil {
+		return
+	}
+	var dest user.Entity
+	query := `UPDATE users SET deleted_at = CURRENT_TIMESTAMP, version = version + 1 WHERE id = $1 RETURNING *;`
+	if err = tx.GetContext(ctx, &dest, query, id); err != nil {
+		return
+	}
+	dest.Roles = before.Roles
+	if err = events.Store(ctx, tx, user.EventDeleted, id, user.Deleted{UserID: id}); err != nil {
+		return
+	}
+	if err = audit.Record(ctx, tx, user.AuditEntity, id, audit.ActionDelete, user.ParseFromEntity(before), user.ParseFromEntity(dest)); err != nil {
+		return
+	}
+	err = tx.Commit()
+	return
+}
+
+// Restore brings a deleted user back. It fails with ErrorEmailTaken when the
+// email was registered again in the meantime.
+func (ur *UserRepository) Restore(ctx context.Context, id string) (dest user.Entity, err error) {
+	tx, err := ur.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return
+	}
+	defer tx.Rollback()
+
+	var before user.Entity
+	if err = tx.GetContext(ctx, &before, `SELECT * FROM users WHERE id = $1 FOR UPDATE;`, id); err != nil {
+		err = ur.mapError(err)
+		return
+	}
+	if !before.DeletedAt.Valid {
+		err = user.ErrorNotDeleted
+		return
+	}
+	if err = ur.loadRoles(ctx, tx, &before); err != nil {
+		return
+	}
+	query := `UPDATE users SET deleted_at = NULL, version = version + 1 WHERE id = $1 RETURNING *;`
+	if err = tx.GetContext(ctx, &dest, query, id); err != nil {
+		err = ur.mapError(err)
+		return
+	}
+	dest.Roles = before.Roles
+	if err = events.Store(ctx, tx, user.EventRestored, id, user.ParseFromEntity(dest)); err != nil {
+		return
+	}
+	if err = audit.Record(ctx, tx, user.AuditEntity, id, audit.ActionRestore, user.ParseFromEntity(before), user.ParseFromEntity(dest)); err != nil {
+		return
 	}
+	err = tx.Commit()
 	return
 }
 
 func (ur *UserRepository) Get(ctx context.Context, id string) (dest user.Entity, err error) {
-	query := `SELECT * FROM users WHERE id = $1;`
+	query := `SELECT * FROM users WHERE id = $1 AND deleted_at IS NULL;`
 	args := []any{id}
-	err = ur.db.GetContext(ctx, &dest, query, args...)
-	if err != nil {
-		if err != nil {
-			err = user.ErrorNotFound
-		}
+	if err = ur.db.GetContext(ctx, &dest, query, args...); err != nil {
+		err = ur.mapError(err)
+		return
 	}
+	err = ur.loadRoles(ctx, ur.db, &dest)
 	return
 }
 
 func (ur *UserRepository) List(ctx context.Context) (users []user.Entity, err error) {
-	query := `SELECT * FROM users;`
-	err = ur.db.SelectContext(ctx, &users, query)
+	query := `SELECT * FROM users WHERE deleted_at IS NULL;`
+	if err = ur.replica.SelectContext(ctx, &users, query); err != nil {
+		return
+	}
+	err = ur.loadRoles(ctx, ur.replica, pointers(users)...)
+	return
+}
+
+// Update overwrites a user. A version other than 0 must be the current one,
+// the stored version is bumped either way.
+func (ur *UserRepository) Update(ctx context.Context, id string, version int, data user.Entity) (dest user.Entity, err error) {
+	tx, err := ur.db.BeginTxx(ctx, nil)
 	if err != nil {
-		if err != nil {
-			err = user.ErrorNotFound
-		}
+		return
+	}
+	defer tx.Rollback()
+
+	before, err := ur.lock(ctx, tx, id)
+	if err != nil {
+		return
 	}
+	if version != 0 && version != before.Version {
+		err = user.ErrorVersionMismatch
+		return
+	}
+
+	query := `
+		UPDATE users SET name = $1, email = $2, address = $3, version = version + 1
+		WHERE id = $4 RETURNING *;`
+	if err = tx.GetContext(ctx, &dest, query, data.Name, data.Email, data.Address, id); err != nil {
+		err = ur.mapError(err)
+		return
+	}
+	dest.Roles = before.Roles
+	if err = events.Store(ctx, tx, user.EventUpdated, id, user.ParseFromEntity(dest)); err != nil {
+		return
+	}
+	if err = audit.Record(ctx, tx, user.AuditEntity, id, audit.ActionUpdate, user.ParseFromEntity(before), user.ParseFromEntity(dest)); err != nil {
+		return
+	}
+	err = tx.Commit()
 	return
 }
 
-func (ur *UserRepository) Update(ctx context.Context, id string, data user.Entity) (err error) {
-	sets, args := ur.prepareArgs(data)
-	if len(args) > 0 {
-		args = append(args, id)
+// GrantRole gives a user the role name, granting one the user has is a no-op.
+func (ur *UserRepository) GrantRole(ctx context.Context, id, name string) (dest user.Entity, err error) {
+	tx, err := ur.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return
 	}
-	query := fmt.Sprintf(`UPDATE users SET %s WHERE id = $%d;`, strings.Join(sets, ","), len(args))
-	_, err = ur.db.ExecContext(ctx, query, args...)
+	defer tx.Rollback()
+
+	before, err := ur.lock(ctx, tx, id)
 	if err != nil {
-		if err != nil {
-			err = user.ErrorNotFound
+		return
+	}
+	if slices.Contains(before.Roles, name) {
+		return before, nil
+	}
+	if _, err = tx.ExecContext(ctx, `INSERT INTO user_roles (user_id, role) VALUES ($1, $2);`, id, name); err != nil {
+		if db.IsForeignKeyViolation(err) {
+			err = role.ErrorNotFound
 		}
+		return
+	}
+	if dest, err = ur.storeRoles(ctx, tx, before, append(slices.Clone(before.Roles), name)); err != nil {
+		return
 	}
+	err = tx.Commit()
 	return
 }
 
-func (ur *UserRepository) prepareArgs(data user.Entity) (sets []string, args []any) {
-	if data.Name != "" {
-		args = append(args, data.Name)
-		sets = append(sets, fmt.Sprintf("name = $%d", len(args)))
+// RevokeRole takes the role name away from a user.
+func (ur *UserRepository) RevokeRole(ctx context.Context, id, name string) (dest user.Entity, err error) {
+	tx, err := ur.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return
+	}
+	defer tx.Rollback()
+
+	before, err := ur.lock(ctx, tx, id)
+	if err != nil {
+		return
 	}
-	if data.Email != "" {
-		args = append(args, data.Email)
-		sets = append(sets, fmt.Sprintf("email = $%d", len(args)))
+	if !slices.Contains(before.Roles, name) {
+		err = user.ErrorRoleNotGranted
+		return
 	}
-	if data.Roles != "" {
-		args = append(args, data.Roles)
-		sets = append(sets, fmt.Sprintf("roles = $%d", len(args)))
+	if _, err = tx.ExecContext(ctx, `DELETE FROM user_roles WHERE user_id = $1 AND role = $2;`, id, name); err != nil {
+		return
 	}
-	if data.Address != "" {
-		args = append(args, data.Address)
-		sets = append(sets, fmt.Sprintf("address = $%d", len(args)))
+	rest := slices.DeleteFunc(slices.Clone(before.Roles), func(r string) bool { return r == name })
+	if dest, err = ur.storeRoles(ctx, tx, before, rest); err != nil {
+		return
 	}
+	err = tx.Commit()
 	return
 }
 
+// storeRoles bumps the version of a user whose roles changed to roles and
+// records the change like any other update.
+func (ur *UserRepository) storeRoles(ctx context.Context, tx *sqlx.Tx, before user.Entity, roles []string) (dest user.Entity, err error) {
+	id := before.ID.String()
+	if err = tx.GetContext(ctx, &dest, `UPDATE users SET version = version + 1 WHERE id = $1 RETURNING *;`, id); err != nil {
+		return
+	}
+	slices.Sort(roles)
+	dest.Roles = roles
+	if err = events.Store(ctx, tx, user.EventUpdated, id, user.ParseFromEntity(dest)); err != nil {
+		return
+	}
+	err = audit.Record(ctx, tx, user.AuditEntity, id, audit.ActionUpdate, user.ParseFromEntity(before), user.ParseFromEntity(dest))
+	return
+}
+
+// Permissions returns what the roles of a user allow, sorted.
+func (ur *UserRepository) Permissions(ctx context.Context, id string) (permissions []string, err error) {
+	permissions = []string{}
+	query := `
+		SELECT DISTINCT rp.permission FROM user_roles ur
+		JOIN role_permissions rp ON rp.role = ur.role
+		WHERE ur.user_id = $1 ORDER BY rp.permission;`
+	err = ur.db.SelectContext(ctx, &permissions, query, id)
+	return
+}
+
+// lock reads a user that is not deleted and locks it until tx ends.
+func (ur *UserRepository) lock(ctx context.Context, tx *sqlx.Tx, id string) (dest user.Entity, err error) {
+	if err = tx.GetContext(ctx, &dest, `SELECT * FROM users WHERE id = $1 AND deleted_at IS NULL FOR UPDATE;`, id); err != nil {
+		err = ur.mapError(err)
+		return
+	}
+	err = ur.loadRoles(ctx, tx, &dest)
+	return
+}
+
+// loadRoles fills in the roles of users with one query.
+func (ur *UserRepository) loadRoles(ctx context.Context, q sqlx.QueryerContext, users ...*user.Entity) error {
+	if len(users) == 0 {
+		return nil
+	}
+	byID := make(map[uuid.UUID]*user.Entity, len(users))
+	ids := make([]string, 0, len(users))
+	for _, u := range users {
+		u.Roles = []string{}
+		byID[u.ID] = u
+		ids = append(ids, u.ID.String())
+	}
+	var rows []struct {
+		UserID uuid.UUID `db:"user_id"`
+		Role   string    `db:"role"`
+	}
+	query := `SELECT user_id, role FROM user_roles WHERE user_id = ANY($1) ORDER BY role;`
+	if err := sqlx.SelectContext(ctx, q, &rows, query, pq.Array(ids)); err != nil {
+		return err
+	}
+	for _, row := range rows {
+		if u, ok := byID[row.UserID]; ok {
+			u.Roles = append(u.Roles, row.Role)
+		}
+	}
+	return nil
+}
+
+func pointers(users []user.Entity) []*user.Entity {
+	res := make([]*user.Entity, len(users))
+	for i := range users {
+		res[i] = &users[i]
+	}
+	return res
+}
+
 func (ur *UserRepository) Search(ctx context.Context, filter, value string) (users []user.Entity, err error) {
 	users = []user.Entity{}
 
-	log.Printf("filter: %s, value: %s", filter, value)
+	slog.DebugContext(ctx, "searching users", "filter", filter)
 	filter = ur.prepareFilter(filter)
 
-	query := fmt.Sprintf("SELECT * FROM users WHERE %s = $1;", filter)
-	err = ur.db.SelectContext(ctx, &users, query, value)
+	query := fmt.Sprintf("SELECT * FROM users WHERE %s = $1 AND deleted_at IS NULL;", filter)
+	err = ur.replica.SelectContext(ctx, &users, query, value)
 	if err != nil {
 		return
 	}
@@ -122,6 +329,7 @@ func (ur *UserRepository) Search(ctx context.Context, filter, value string) (use
 		err = user.ErrorNotFound
 		return
 	}
+	err = ur.loadRoles(ctx, ur.replica, pointers(users)...)
 	return
 }
 
@@ -135,3 +343,14 @@ func (ur *UserRepository) prepareFilter(filter string) string {
 		return ""
 	}
 }
+
+func (ur *UserRepository) mapError(err error) error {
+	switch {
+	case errors.Is(err, sql.ErrNoRows), db.IsInvalidText(err):
+		// no such row, or a malformed UUID in the path
+		return user.ErrorNotFound
+	case db.IsUniqueViolation(err):
+		return user.ErrorEmailTaken
+	}
+	return err
+}
@@ -0,0 +1,99 @@
+package service
+
+import (
+	"context"
+	"github.com/google/uuid"
+	"users-service/internal/domain/address"
+	"users-service/internal/domain/user"
+	interfaces "users-service/internal/repository/interface"
+	services "users-service/internal/service/interface"
+)
+
+type AddressService struct {
+	addressRepository interfaces.AddressRepository
+}
+
+func NewAddressService(addressRepository interfaces.AddressRepository) services.AddressService {
+	return &AddressService{
+		addressRepository: addressRepository,
+	}
+}
+
+func (as *AddressService) CreateAddress(ctx context.Context, userID string, req address.Request) (id string, err error) {
+	owner, err := uuid.Parse(userID)
+	if err != nil {
+		return "", user.ErrorNotFound
+	}
+	data := address.Entity{
+		UserID:     owner,
+		Label:      req.Label,
+		Recipient:  req.Recipient,
+		Line1:      req.Line1,
+		Line2:      req.Line2,
+		City:       req.City,
+		Region:     req.Region,
+		PostalCode: req.PostalCode,
+		Country:    req.Country,
+		Phone:      req.Phone,
+		IsDefault:  req.IsDefault,
+	}
+	id, err = as.addressRepository.Create(ctx, data)
+	return
+}
+
+func (as *AddressService) ListAddresses(ctx context.Context, userID string) (res []address.Response, err error) {
+	if _, err = uuid.Parse(userID); err != nil {
+		return nil, user.ErrorNotFound
+	}
+	data, err := as.addressRepository.List(ctx, userID)
+	if err != nil {
+		return
+	}
+	res = address.ParseFromEntities(data)
+	return
+}
+
+func (as *AddressService) GetAddress(ctx context.Context, userID, id string) (res address.Response, err error) {
+	data, err := as.addressRepository.Get(ctx, userID, id)
+	if err != nil {
+		return
+	}
+	res = address.ParseFromEntity(data)
+	return
+}
+
+func (as *AddressService) GetDefaultAddress(ctx context.Context, userID string) (res address.Response, err error) {
+	data, err := as.addressRepository.GetDefault(ctx, userID)
+	if err != nil {
+		return
+	}
+	res = address.ParseFromEntity(data)
+	return
+}
+
+func (as *AddressService) UpdateAddress(ctx context.Context, userID, id string, req address.Request) (err error) {
+	data := address.Entity{
+		Label:      req.Label,
+		Recipient:  req.Recipient,
+		Line1:      req.Line1,
+		Line2:      req.Line2,
+		City:       req.City,
+		Region:     req.Region,
+		PostalCode: req.PostalCode,
+		Country:    req.Country,
+		Phone:      req.Phone,
+		IsDefault:  req.IsDefault,
+	}
+	err = as.addressRepository.Update(ctx, userID, id, data)
+	return
+}
+
+func (as *AddressService) DeleteAddress(ctx context.Context, userID, id string) (err error) {
+	err = as.addressRepository.Delete(ctx, userID, id)
+	return
+}
+
+func (as *AddressService) SetDefaultAddress(ctx context.Context, userID, id string) (err error) {
+	err = as.addressRepository.SetDefault(ctx, userID, id)
+	return
+}
@@ -0,0 +1,16 @@
+package interfaces
+
+import (
+	"context"
+	"users-service/internal/domain/address"
+)
+
+type AddressService interface {
+	CreateAddress(ctx context.Context, userID string, req address.Request) (id string, err error)
+	ListAddresses(ctx context.Context, userID string) (res []address.Response, err error)
+	GetAddress(ctx context.Context, userID, id string) (res address.Response, err error)
+	GetDefaultAddress(ctx context.Context, userID string) (res address.Response, err error)
+	UpdateAddress(ctx context.Context, userID, id string, req address.Request) (err error)
+	DeleteAddress(ctx context.Context, userID, id string) (err error)
+	SetDefaultAddress(ctx context.Context, userID, id string) (err error)
+}
@@ -0,0 +1,13 @@
+package interfaces
+
+import (
+	"context"
+	"users-service/internal/domain/role"
+)
+
+type RoleService interface {
+	ListRoles(ctx context.Context) (res []role.Response, err error)
+	ListPermissions(ctx context.Context) (res []role.PermissionResponse, err error)
+	GrantPermission(ctx context.Context, name, permission string) (res role.Response, err error)
+	RevokePermission(ctx context.Context, name, permission string) (res role.Response, err error)
+}
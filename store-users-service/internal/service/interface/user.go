@@ -10,6 +10,11 @@ type UserService interface {
 	ListUsers(ctx context.Context) (res []user.Response, err error)
 	GetUser(ctx context.Context, id string) (res user.Response, err error)
 	DeleteUser(ctx context.Context, id string) (err error)
-	UpdateUser(ctx context.Context, id string, req user.Request) (err error)
+	RestoreUser(ctx context.Context, id string) (res user.Response, err error)
+	UpdateUser(ctx context.Context, id string, version int, req user.Request) (res user.Response, err error)
+	PatchUser(ctx context.Context, id string, version int, patch user.Patch) (res user.Response, err error)
 	SearchUser(ctx context.Context, filter, value string) (res []user.Response, err error)
+	GrantRole(ctx context.Context, id, role string) (res user.Response, err error)
+	RevokeRole(ctx context.Context, id, role string) (res user.Response, err error)
+	IssueToken(ctx context.Context, id string) (res user.Token, err error)
 }
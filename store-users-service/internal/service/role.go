@@ -0,0 +1,59 @@
+package service
+
+import (
+	"context"
+	"users-service/internal/domain/role"
+	interfaces "users-service/internal/repository/interface"
+	services "users-service/internal/service/interface"
+)
+
+type RoleService struct {
+	roleRepository interfaces.RoleRepository
+}
+
+func NewRoleService(roleRepository interfaces.RoleRepository) services.RoleService {
+	return &RoleService{
+		roleRepository: roleRepository,
+	}
+}
+
+func (rs *RoleService) ListRoles(ctx context.Context) (res []role.Response, err error) {
+	data, err := rs.roleRepository.List(ctx)
+	if err != nil {
+		return
+	}
+	res = role.ParseFromEntities(data)
+	return
+}
+
+func (rs *RoleService) ListPermissions(ctx context.Context) (res []role.PermissionResponse, err error) {
+	data, err := rs.roleRepository.Permissions(ctx)
+	if err != nil {
+		return
+	}
+	res = role.ParseFromPermissions(data)
+	return
+}
+
+// GrantPermission gives the role name a permission, a role that does not
+// exist yet is created by its first permission.
+func (rs *RoleService) GrantPermission(ctx context.Context, name, permission string) (res role.Response, err error) {
+	if err = role.ValidateName(name); err != nil {
+		return
+	}
+	data, err := rs.roleRepository.GrantPermission(ctx, name, permission)
+	if err != nil {
+		return
+	}
+	res = role.ParseFromEntity(data)
+	return
+}
+
+func (rs *RoleService) RevokePermission(ctx context.Context, name, permission string) (res role.Response, err error) {
+	data, err := rs.roleRepository.RevokePermission(ctx, name, permission)
+	if err != nil {
+		return
+	}
+	res = role.ParseFromEntity(data)
+	return
+}
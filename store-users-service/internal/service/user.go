@@ -2,18 +2,27 @@ package service
 
 import (
 	"context"
+	"time"
+	"users-service/internal/config"
 	"users-service/internal/domain/user"
 	interfaces "users-service/internal/repository/interface"
 	services "users-service/internal/service/interface"
+	"users-service/pkg/auth"
 )
 
 type UserService struct {
 	userRepository interfaces.UserRepository
+	tokenSecret    string
+	tokenTTL       time.Duration
+	tokenMaxAge    time.Duration
 }
 
-func NewUserService(userRepository interfaces.UserRepository) services.UserService {
+func NewUserService(cfg config.Config, userRepository interfaces.UserRepository) services.UserService {
 	return &UserService{
 		userRepository: userRepository,
+		tokenSecret:    cfg.TokenSecret,
+		tokenTTL:       cfg.TokenTTL,
+		tokenMaxAge:    cfg.TokenMaxAge,
 	}
 }
 
@@ -22,7 +31,6 @@ func (us *UserService) CreateUser(ctx context.Context, req user.Request) (id str
 		Name:    req.Name,
 		Email:   req.Email,
 		Address: req.Address,
-		Roles:   req.Roles,
 	}
 	id, err = us.userRepository.Create(ctx, data)
 	return
@@ -51,17 +59,49 @@ func (us *UserService) DeleteUser(ctx context.Context, id string) (err error) {
 	return
 }
 
-func (us *UserService) UpdateUser(ctx context.Context, id string, req user.Request) (err error) {
+func (us *UserService) RestoreUser(ctx context.Context, id string) (res user.Response, err error) {
+	data, err := us.userRepository.Restore(ctx, id)
+	if err != nil {
+		return
+	}
+	res = user.ParseFromEntity(data)
+	return
+}
+
+// UpdateUser overwrites a user, version is the one the client read or 0.
+func (us *UserService) UpdateUser(ctx context.Context, id string, version int, req user.Request) (res user.Response, err error) {
 	data := user.Entity{
 		Name:    req.Name,
 		Email:   req.Email,
 		Address: req.Address,
-		Roles:   req.Roles,
 	}
-	err = us.userRepository.Update(ctx, id, data)
+	data, err = us.userRepository.Update(ctx, id, version, data)
+	if err != nil {
+		return
+	}
+	res = user.ParseFromEntity(data)
 	return
 }
 
+// PatchUser applies a merge patch to the user. The patched user must be as
+// valid as a new one.
+func (us *UserService) PatchUser(ctx context.Context, id string, version int, patch user.Patch) (res user.Response, err error) {
+	current, err := us.userRepository.Get(ctx, id)
+	if err != nil {
+		return
+	}
+	if version != 0 && version != current.Version {
+		err = user.ErrorVersionMismatch
+		return
+	}
+
+	req := patch.Apply(current)
+	if err = req.Validate(); err != nil {
+		return
+	}
+	return us.UpdateUser(ctx, id, current.Version, req)
+}
+
 func (us *UserService) SearchUser(ctx context.Context, filter, value string) (res []user.Response, err error) {
 	if !user.IsValidFilter(filter) || value == "" {
 		err = user.ErrorInvalidSearch
@@ -74,3 +114,71 @@ func (us *UserService) SearchUser(ctx context.Context, filter, value string) (re
 	res = user.ParseFromEntities(data)
 	return
 }
+
+func (us *UserService) GrantRole(ctx context.Context, id, role string) (res user.Response, err error) {
+	data, err := us.userRepository.GrantRole(ctx, id, role)
+	if err != nil {
+		return
+	}
+	res = user.ParseFromEntity(data)
+	return
+}
+
+func (us *UserService) RevokeRole(ctx context.Context, id, role string) (res user.Response, err error) {
+	data, err := us.userRepository.RevokeRole(ctx, id, role)
+	if err != nil {
+		return
+	}
+	res = user.ParseFromEntity(data)
+	return
+}
+
+// IssueToken signs an access token with the roles the user has now and the
+// permissions they give. Later grants and revokes show in the next token, and
+// a deleted user gets none. A user renewing its own token stays in the session
+// of that token, which ends tokenMaxAge after it began, any other caller
+// starts a new session.
+func (us *UserService) IssueToken(ctx context.Context, id string) (res user.Token, err error) {
+	now := time.Now()
+	authTime := now.Unix()
+	if caller, ok := auth.FromContext(ctx); ok && caller.Subject == id && !caller.Can("tokens:issue") {
+		authTime = caller.AuthTime
+		if authTime == 0 {
+			authTime = caller.IssuedAt
+		}
+		if now.Sub(time.Unix(authTime, 0)) >= us.tokenMaxAge {
+			err = user.ErrorSessionExpired
+			return
+		}
+	}
+
+	data, err := us.userRepository.Get(ctx, id)
+	if err != nil {
+		return
+	}
+	permissions, err := us.userRepository.Permissions(ctx, id)
+	if err != nil {
+		return
+	}
+
+	claims := auth.Claims{
+		Subject:     data.ID.String(),
+		Roles:       data.Roles,
+		Permissions: permissions,
+		IssuedAt:    now.Unix(),
+		ExpiresAt:   now.Add(us.tokenTTL).Unix(),
+		AuthTime:    authTime,
+	}
+	token, err := auth.Sign(claims, us.tokenSecret)
+	if err != nil {
+		return
+	}
+	res = user.Token{
+		AccessToken: token,
+		TokenType:   "Bearer",
+		ExpiresAt:   time.Unix(claims.ExpiresAt, 0).UTC(),
+		Roles:       claims.Roles,
+		Permissions: claims.Permissions,
+	}
+	return
+}
@@ -0,0 +1,88 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"github.com/google/uuid"
+	"reflect"
+	"testing"
+	"time"
+	"users-service/internal/domain/user"
+	interfaces "users-service/internal/repository/interface"
+	"users-service/pkg/auth"
+)
+
+const tokenSecret = "0123456789abcdef0123456789abcdef"
+
+// fakeUsers keeps users with their roles, a deleted user is not there.
+type fakeUsers struct {
+	interfaces.UserRepository
+	users       map[string]user.Entity
+	permissions map[string][]string
+}
+
+func (fu *fakeUsers) Get(ctx context.Context, id string) (user.Entity, error) {
+	data, ok := fu.users[id]
+	if !ok {
+		return data, user.ErrorNotFound
+	}
+	return data, nil
+}
+
+func (fu *fakeUsers) Permissions(ctx context.Context, id string) ([]string, error) {
+	var permissions []string
+	for _, role := range fu.users[id].Roles {
+		permissions = append(permissions, fu.permissions[role]...)
+	}
+	return permissions, nil
+}
+
+func TestIssueToken(t *testing.T) {
+	id := uuid.New()
+	now := time.Now()
+	users := &fakeUsers{
+		users:       map[string]user.Entity{id.String(): {ID: id, Roles: []string{"manager"}}},
+		permissions: map[string][]string{"manager": {"shipments:write"}},
+	}
+	us := &UserService{userRepository: users, tokenSecret: tokenSecret, tokenTTL: 15 * time.Minute, tokenMaxAge: time.Hour}
+	caller := func(subject string, authTime time.Time, permissions ...string) context.Context {
+		claims := auth.Claims{Subject: subject, Roles: []string{"user"}, Permissions: permissions, IssuedAt: authTime.Unix(), AuthTime: authTime.Unix()}
+		return auth.WithClaims(context.Background(), claims)
+	}
+
+	tests := []struct {
+		name     string
+		ctx      context.Context
+		id       string
+		authTime time.Time
+		wantErr  error
+	}{
+		{"renewal keeps the session", caller(id.String(), now.Add(-30*time.Minute)), id.String(), now.Add(-30 * time.Minute), nil},
+		{"renewal past the max age", caller(id.String(), now.Add(-2*time.Hour)), id.String(), time.Time{}, user.ErrorSessionExpired},
+		{"issued with tokens:issue starts a session", caller("admin", now.Add(-2*time.Hour), "tokens:issue"), id.String(), now, nil},
+		{"bootstrap starts a session", auth.WithClaims(context.Background(), auth.Claims{Subject: auth.BootstrapSubject, Permissions: []string{"tokens:issue"}}), id.String(), now, nil},
+		{"deleted user", caller("gone", now), "gone", time.Time{}, user.ErrorNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			res, err := us.IssueToken(tt.ctx, tt.id)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("error = %v, want %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			claims, err := auth.Verify(res.AccessToken, tokenSecret, now)
+			if err != nil {
+				t.Fatal(err)
+			}
+			// the roles and permissions are read anew, not taken from the caller
+			if !reflect.DeepEqual(claims.Roles, []string{"manager"}) || !reflect.DeepEqual(claims.Permissions, []string{"shipments:write"}) {
+				t.Errorf("roles %v, permissions %v, want manager with shipments:write", claims.Roles, claims.Permissions)
+			}
+			if diff := claims.AuthTime - tt.authTime.Unix(); diff < -1 || diff > 1 {
+				t.Errorf("auth time = %v, want %v", time.Unix(claims.AuthTime, 0), tt.authTime)
+			}
+		})
+	}
+}
@@ -0,0 +1,32 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS addresses (
+    id UUID PRIMARY KEY DEFAULT GEN_RANDOM_UUID(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    label VARCHAR NOT NULL DEFAULT '',
+    recipient VARCHAR NOT NULL,
+    line1 VARCHAR NOT NULL,
+    line2 VARCHAR NOT NULL DEFAULT '',
+    city VARCHAR NOT NULL DEFAULT '',
+    region VARCHAR NOT NULL DEFAULT '',
+    postal_code VARCHAR NOT NULL DEFAULT '',
+    country VARCHAR NOT NULL DEFAULT '',
+    phone VARCHAR NOT NULL DEFAULT '',
+    is_default BOOLEAN NOT NULL DEFAULT FALSE,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+CREATE INDEX IF NOT EXISTS addresses_user_idx ON addresses (user_id);
+CREATE UNIQUE INDEX IF NOT EXISTS addresses_default_idx ON addresses (user_id) WHERE is_default;
+
+-- the free-text address becomes the default entry of the address book
+INSERT INTO addresses (user_id, recipient, line1, is_default)
+SELECT id, name, address, TRUE FROM users WHERE address <> '';
+
+ALTER TABLE users ALTER COLUMN address SET DEFAULT '';
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE users ALTER COLUMN address DROP DEFAULT;
+DROP TABLE IF EXISTS addresses;
+-- +goose StatementEnd
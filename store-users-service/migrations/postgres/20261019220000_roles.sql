@@ -0,0 +1,64 @@
+-- +goose Up
+-- +goose StatementBegin
+-- permissions are the ones the services check, named resource:action[:scope]
+CREATE TABLE IF NOT EXISTS permissions (
+    name VARCHAR(100) PRIMARY KEY,
+    description VARCHAR NOT NULL DEFAULT ''
+);
+
+CREATE TABLE IF NOT EXISTS roles (
+    name VARCHAR(50) PRIMARY KEY,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE IF NOT EXISTS role_permissions (
+    role VARCHAR(50) NOT NULL REFERENCES roles (name) ON DELETE CASCADE,
+    permission VARCHAR(100) NOT NULL REFERENCES permissions (name) ON DELETE CASCADE,
+    PRIMARY KEY (role, permission)
+);
+
+CREATE TABLE IF NOT EXISTS user_roles (
+    user_id UUID NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    role VARCHAR(50) NOT NULL REFERENCES roles (name) ON DELETE CASCADE,
+    PRIMARY KEY (user_id, role)
+);
+
+INSERT INTO permissions (name, description) VALUES
+    ('roles:read', 'list roles and permissions'),
+    ('roles:write', 'grant and revoke roles and permissions'),
+    ('users:restore', 'restore deleted users'),
+    ('products:restore', 'restore deleted products'),
+    ('orders:restore', 'restore deleted orders'),
+    ('payments:restore', 'restore deleted payments'),
+    ('shipments:read', 'see the shipments of any order'),
+    ('shipments:write', 'pack, ship and deliver orders');
+
+INSERT INTO roles (name) VALUES ('admin'), ('manager'), ('developer'), ('user');
+
+INSERT INTO role_permissions (role, permission)
+SELECT 'admin', name FROM permissions;
+INSERT INTO role_permissions (role, permission) VALUES
+    ('manager', 'shipments:read'),
+    ('manager', 'shipments:write');
+
+INSERT INTO user_roles (user_id, role) SELECT id, roles FROM users;
+ALTER TABLE users DROP COLUMN roles;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+-- a user keeps the most powerful of the old roles it has
+ALTER TABLE users ADD COLUMN roles VARCHAR NOT NULL DEFAULT 'user' CHECK (roles IN ('admin', 'user', 'manager', 'developer'));
+UPDATE users u SET roles = r.role
+FROM (
+    SELECT DISTINCT ON (user_id) user_id, role FROM user_roles
+    WHERE role IN ('admin', 'manager', 'developer', 'user')
+    ORDER BY user_id, ARRAY_POSITION(ARRAY['admin', 'manager', 'developer', 'user']::VARCHAR[], role)
+) r
+WHERE u.id = r.user_id;
+ALTER TABLE users ALTER COLUMN roles DROP DEFAULT;
+DROP TABLE IF EXISTS user_roles;
+DROP TABLE IF EXISTS role_permissions;
+DROP TABLE IF EXISTS roles;
+DROP TABLE IF EXISTS permissions;
+-- +goose StatementEnd
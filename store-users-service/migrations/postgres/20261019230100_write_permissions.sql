@@ -0,0 +1,34 @@
+-- +goose Up
+-- +goose StatementBegin
+INSERT INTO permissions (name, description) VALUES
+    ('users:read:any', 'see any user and their addresses'),
+    ('users:write', 'create users and change or delete any user'),
+    ('products:write', 'create, change, delete and import products'),
+    ('rates:write', 'set and delete exchange rates'),
+    ('orders:read:any', 'see the orders of any user'),
+    ('orders:write', 'change and delete orders, order for any user'),
+    ('promotions:read', 'see promotions and their codes'),
+    ('promotions:write', 'create, change and delete promotions'),
+    ('taxes:write', 'set and delete tax rates'),
+    ('shipping:write', 'set and delete shipping rates'),
+    ('payments:read:any', 'see the payments of any user'),
+    ('payments:write', 'change, refund and delete payments, pay for any user')
+ON CONFLICT (name) DO NOTHING;
+
+INSERT INTO role_permissions (role, permission)
+SELECT 'admin', name FROM permissions
+ON CONFLICT DO NOTHING;
+INSERT INTO role_permissions (role, permission) VALUES
+    ('manager', 'orders:read:any'),
+    ('manager', 'products:write')
+ON CONFLICT DO NOTHING;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DELETE FROM permissions WHERE name IN (
+    'users:read:any', 'users:write', 'products:write', 'rates:write',
+    'orders:read:any', 'orders:write', 'promotions:read', 'promotions:write',
+    'taxes:write', 'shipping:write', 'payments:read:any', 'payments:write'
+);
+-- +goose StatementEnd
@@ -0,0 +1,46 @@
+// Package audit keeps the audit log of a service: every change of an entity
+// is recorded with the user who made it and the entity before and after.
+// Entries are written in the transaction of the change, like the outbox
+// events, so the log cannot miss a change or record one that rolled back.
+package audit
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/jmoiron/sqlx"
+)
+
+// Actions recorded in the audit log.
+const (
+	ActionCreate  = "create"
+	ActionUpdate  = "update"
+	ActionDelete  = "delete"
+	ActionRestore = "restore"
+)
+
+// Record writes an entry to the audit_log table through tx, the actor is
+// the user of ctx. before is nil for a created entity, before and after are
+// stored as JSON.
+func Record(ctx context.Context, tx sqlx.ExecerContext, entity, entityID, action string, before, after any) error {
+	beforeJSON, err := marshal(before)
+	if err != nil {
+		return err
+	}
+	afterJSON, err := marshal(after)
+	if err != nil {
+		return err
+	}
+	query := `
+		INSERT INTO audit_log (actor, action, entity, entity_id, before, after)
+		VALUES (NULLIF($1, ''), $2, $3, $4, $5, $6);`
+	_, err = tx.ExecContext(ctx, query, Actor(ctx), action, entity, entityID, beforeJSON, afterJSON)
+	return err
+}
+
+// marshal encodes v as JSON, nil is stored as NULL.
+func marshal(v any) (any, error) {
+	if v == nil {
+		return nil, nil
+	}
+	return json.Marshal(v)
+}
@@ -0,0 +1,129 @@
+package auth
+
+import (
+	"context"
+	"github.com/gin-gonic/gin"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"slices"
+	"strings"
+	"time"
+	"users-service/pkg/apperror"
+)
+
+// metadataKey is the Authorization header as gRPC metadata, whose keys are
+// lower case. The gateway sends the token of the client in it.
+const metadataKey = "authorization"
+
+// ErrorPermissionDenied is the answer to a token without the permission a
+// route needs, its details list the permissions that would do.
+var ErrorPermissionDenied = apperror.Forbidden("permission_denied", "access denied")
+
+type claimsKey struct{}
+
+// WithClaims returns a copy of ctx carrying claims.
+func WithClaims(ctx context.Context, claims Claims) context.Context {
+	return context.WithValue(ctx, claimsKey{}, claims)
+}
+
+// FromContext returns the claims of the token ctx was authenticated with,
+// ok is false for an anonymous request.
+func FromContext(ctx context.Context) (claims Claims, ok bool) {
+	claims, ok = ctx.Value(claimsKey{}).(Claims)
+	return
+}
+
+// Middleware checks the bearer token of a request, if it has one, and puts
+// its claims in the request context. A request without a token goes on
+// anonymous, one with a bad or expired token is refused.
+func Middleware(secret string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx, err := authenticate(c.Request.Context(), c.GetHeader("Authorization"), secret)
+		if err != nil {
+			c.Error(err)
+			c.Abort()
+			return
+		}
+		c.Request = c.Request.WithContext(ctx)
+		c.Next()
+	}
+}
+
+// Authorize returns nil when ctx carries the token of owner, unless owner is
+// empty, or a token granting one of permissions. It is ErrorTokenRequired
+// for an anonymous request and ErrorPermissionDenied for any other.
+func Authorize(ctx context.Context, owner string, permissions ...string) error {
+	claims, ok := FromContext(ctx)
+	if !ok {
+		return ErrorTokenRequired
+	}
+	if (owner == "" || claims.Subject != owner) && !slices.ContainsFunc(permissions, claims.Can) {
+		return ErrorPermissionDenied.WithDetail("permissions", permissions)
+	}
+	return nil
+}
+
+// RequirePermission lets a request through only when its token grants one
+// of permissions.
+func RequirePermission(permissions ...string) gin.HandlerFunc {
+	return require(func(c *gin.Context) string { return "" }, permissions)
+}
+
+// RequireSelfOrPermission lets a request through when its token belongs to
+// the user named by the param path parameter, or grants one of permissions.
+func RequireSelfOrPermission(param string, permissions ...string) gin.HandlerFunc {
+	return require(func(c *gin.Context) string { return c.Param(param) }, permissions)
+}
+
+func require(owner func(c *gin.Context) string, permissions []string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if err := Authorize(c.Request.Context(), owner(c), permissions...); err != nil {
+			c.Error(err)
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
+
+// UnaryServerInterceptor does for gRPC calls what Middleware does for HTTP
+// requests, the token is taken from the authorization metadata. A bad token
+// fails the call with Unauthenticated.
+func UnaryServerInterceptor(secret string) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		var value string
+		if values := metadata.ValueFromIncomingContext(ctx, metadataKey); len(values) > 0 {
+			value = values[0]
+		}
+		ctx, err := authenticate(ctx, value, secret)
+		if err != nil {
+			return nil, status.Error(codes.Unauthenticated, err.Error())
+		}
+		return handler(ctx, req)
+	}
+}
+
+// authenticate returns ctx with the claims of the bearer token in the
+// Authorization value, ctx itself when there is no token.
+func authenticate(ctx context.Context, authorization, secret string) (context.Context, error) {
+	token := bearer(authorization)
+	if token == "" {
+		return ctx, nil
+	}
+	claims, err := Verify(token, secret, time.Now())
+	if err != nil {
+		return ctx, err
+	}
+	return WithClaims(ctx, claims), nil
+}
+
+// bearer returns the token of an Authorization header, empty for none.
+func bearer(value string) string {
+	scheme, token, ok := strings.Cut(value, " ")
+	if !ok || !strings.EqualFold(scheme, "Bearer") {
+		return ""
+	}
+	return strings.TrimSpace(token)
+}
@@ -0,0 +1,38 @@
+package auth
+
+import (
+	"crypto/subtle"
+	"github.com/gin-gonic/gin"
+	"users-service/pkg/apperror"
+)
+
+// BootstrapHeader carries the bootstrap secret, with which the first
+// administrator gets a token before anybody holds one.
+const BootstrapHeader = "X-Bootstrap-Secret"
+
+// BootstrapSubject is the subject of the claims of a bootstrap request.
+const BootstrapSubject = "bootstrap"
+
+var ErrorBootstrapSecret = apperror.Unauthorized("invalid_bootstrap_secret", "wrong "+BootstrapHeader+" header")
+
+// Bootstrap gives a request carrying secret in BootstrapHeader the claims of
+// BootstrapSubject with permissions, in place of those of its token. A
+// request with a wrong secret is refused, and an empty secret turns
+// bootstrapping off, the header is then refused too.
+func Bootstrap(secret string, permissions ...string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		value := c.GetHeader(BootstrapHeader)
+		if value == "" {
+			c.Next()
+			return
+		}
+		if secret == "" || subtle.ConstantTimeCompare([]byte(value), []byte(secret)) != 1 {
+			c.Error(ErrorBootstrapSecret)
+			c.Abort()
+			return
+		}
+		claims := Claims{Subject: BootstrapSubject, Permissions: permissions}
+		c.Request = c.Request.WithContext(WithClaims(c.Request.Context(), claims))
+		c.Next()
+	}
+}
@@ -0,0 +1,47 @@
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+)
+
+// SubjectPrefix is prepended to the event type to form the subject an event
+// is published on, e.g. "store.events.OrderCreated".
+const SubjectPrefix = "store.events"
+
+// StreamName is the JetStream stream the events are stored in.
+const StreamName = "STORE_EVENTS"
+
+// Event is a domain event of a service, such as OrderCreated or
+// PaymentCaptured. Payload holds the JSON body of the event, usually the
+// response DTO of the changed entity.
+type Event struct {
+	ID          string          `json:"id"`
+	Type        string          `json:"type"`
+	Source      string          `json:"source"`
+	AggregateID string          `json:"aggregate_id"`
+	Payload     json.RawMessage `json:"payload"`
+	OccurredAt  time.Time       `json:"occurred_at"`
+}
+
+// Publisher delivers events to a message broker. Delivery is at least once,
+// consumers tell duplicates apart by the event ID.
+type Publisher interface {
+	Publish(ctx context.Context, event Event) error
+	Close() error
+}
+
+// Subject returns the subject events of the given type are published on.
+func Subject(eventType string) string {
+	return SubjectPrefix + "." + eventType
+}
+
+// NewPublisher returns a NATS publisher for url, or nil when no url is set,
+// in which case events stay in the outbox until one is configured.
+func NewPublisher(url string) Publisher {
+	if url == "" {
+		return nil
+	}
+	return NewNATSPublisher(url)
+}
@@ -0,0 +1,293 @@
+// Package settings loads the configuration of a service into a struct from
+// its defaults, a YAML file, the environment and command line flags.
+//
+// Every exported field of the struct is a setting, it is named by its tags:
+//
+//	DBHost string `yaml:"db_host" env:"DBHost" required:"true"`
+//
+// The yaml key is the key in the file and, with dashes for underscores, the
+// name of the flag (-db-host). A field tagged secret is masked by Print.
+package settings
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"github.com/joho/godotenv"
+	"gopkg.in/yaml.v3"
+	"io"
+	"net/url"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// FileEnv names the variable holding the path of the YAML file when there is
+// no -config flag. Without either, DefaultFile is read if it exists.
+const (
+	FileEnv     = "configFile"
+	DefaultFile = "config.yaml"
+)
+
+const mask = "******"
+
+var durationType = reflect.TypeOf(time.Duration(0))
+
+// setting is a field of the configuration struct.
+type setting struct {
+	key      string
+	env      string
+	flag     string
+	required bool
+	secret   bool
+	value    reflect.Value
+}
+
+// Load fills cfg, a pointer to a struct, from its sources. Each source
+// overrides the ones before it: the values cfg already holds as defaults,
+// the YAML file, the environment, which a .env file in the working directory
+// adds to, and the flags in args. A required setting that is still empty is
+// an error naming where it can be set. printConfig reports the
+// -print-config flag, asking to print the settings instead of running.
+func Load(cfg any, args []string) (printConfig bool, err error) {
+	settings, err := fields(cfg)
+	if err != nil {
+		return
+	}
+
+	flags := flag.NewFlagSet(os.Args[0], flag.ContinueOnError)
+	file := flags.String("config", "", "YAML `file` with the settings, "+DefaultFile+" by default")
+	flags.BoolVar(&printConfig, "print-config", false, "print the effective settings with secrets masked and exit")
+	set := make(map[string]string)
+	for _, s := range settings {
+		s := s
+		flags.Func(s.flag, fmt.Sprintf("%s (env %s)", s.key, s.env), func(value string) error {
+			set[s.flag] = value
+			return nil
+		})
+	}
+	if err = flags.Parse(args); err != nil {
+		return
+	}
+
+	if err = readFile(cfg, *file); err != nil {
+		return
+	}
+
+	_ = godotenv.Load()
+	var errs []error
+	for _, s := range settings {
+		if value, ok := os.LookupEnv(s.env); ok && value != "" {
+			if err := parse(s.value, value); err != nil {
+				errs = append(errs, fmt.Errorf("env %s: %w", s.env, err))
+			}
+		}
+	}
+	for _, s := range settings {
+		if value, ok := set[s.flag]; ok {
+			if err := parse(s.value, value); err != nil {
+				errs = append(errs, fmt.Errorf("flag -%s: %w", s.flag, err))
+			}
+		}
+	}
+	for _, s := range settings {
+		if s.required && s.value.IsZero() {
+			errs = append(errs, fmt.Errorf("%s is required, set it in the config file, as env %s or with -%s", s.key, s.env, s.flag))
+		}
+	}
+	return printConfig, errors.Join(errs...)
+}
+
+// Print writes the settings in cfg as YAML in the order of the fields.
+// Secrets are masked, and so are passwords in URLs.
+func Print(w io.Writer, cfg any) error {
+	settings, err := fields(cfg)
+	if err != nil {
+		return err
+	}
+	doc := &yaml.Node{Kind: yaml.MappingNode}
+	for _, s := range settings {
+		value := format(s.value)
+		if s.secret && value != "" {
+			value = mask
+		} else if u, err := url.Parse(value); err == nil && u.User != nil {
+			if _, ok := u.User.Password(); ok {
+				value = u.Redacted()
+			}
+		}
+		node := &yaml.Node{Kind: yaml.ScalarNode, Value: value}
+		if s.value.Kind() == reflect.String {
+			node.Style = yaml.DoubleQuotedStyle
+		}
+		doc.Content = append(doc.Content, &yaml.Node{Kind: yaml.ScalarNode, Value: s.key}, node)
+	}
+	encoder := yaml.NewEncoder(w)
+	encoder.SetIndent(2)
+	if err = encoder.Encode(doc); err != nil {
+		return err
+	}
+	return encoder.Close()
+}
+
+// readFile decodes the YAML file into cfg. A file that was asked for must
+// exist, the default one may not.
+func readFile(cfg any, path string) error {
+	if path == "" {
+		path = os.Getenv(FileEnv)
+	}
+	optional := path == ""
+	if optional {
+		path = DefaultFile
+	}
+
+	file, err := os.Open(path)
+	if optional && errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	decoder := yaml.NewDecoder(file)
+	decoder.KnownFields(true)
+	if err = decoder.Decode(cfg); err != nil && !errors.Is(err, io.EOF) {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+	return nil
+}
+
+func fields(cfg any) (res []setting, err error) {
+	v := reflect.ValueOf(cfg)
+	if v.Kind() == reflect.Pointer {
+		v = v.Elem()
+	}
+	if v.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("settings: %T is not a struct", cfg)
+	}
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		key, _, _ := strings.Cut(field.Tag.Get("yaml"), ",")
+		if !field.IsExported() || key == "" || key == "-" {
+			continue
+		}
+		env := field.Tag.Get("env")
+		if env == "" {
+			env = field.Name
+		}
+		res = append(res, setting{
+			key:      key,
+			env:      env,
+			flag:     strings.ReplaceAll(key, "_", "-"),
+			required: field.Tag.Get("required") == "true",
+			secret:   field.Tag.Get("secret") == "true",
+			value:    v.Field(i),
+		})
+	}
+	return
+}
+
+func parse(v reflect.Value, value string) error {
+	if v.Type() == durationType {
+		d, err := time.ParseDuration(value)
+		if err != nil {
+			return err
+		}
+		v.SetInt(int64(d))
+		return nil
+	}
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString(value)
+	case reflect.Int:
+		n, err := strconv.Atoi(value)
+		if err != nil {
+			return fmt.Errorf("%q is not a number", value)
+		}
+		v.SetInt(int64(n))
+	case reflect.Bool:
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return fmt.Errorf("%q is not true or false", value)
+		}
+		v.SetBool(b)
+	default:
+		return fmt.Errorf("settings of type %s are not supported", v.Type())
+	}
+	return nil
+}
+
+func format(v reflect.Value) string {
+	if v.Type() == durationType {
+		return time.Duration(v.Int()).String()
+	}
+	return fmt.Sprint(v.Interface())
+}
+
+// OneOf checks that the setting key is one of values.
+func OneOf(key, value string, values ...string) error {
+	for _, v := range values {
+		if value == v {
+			return nil
+		}
+	}
+	return fmt.Errorf("%s is %q, it must be one of %s", key, value, strings.Join(values, ", "))
+}
+
+// HTTPURL checks that the setting key, when set, is an absolute http or
+// https URL.
+func HTTPURL(key, value string) error {
+	if value == "" {
+		return nil
+	}
+	u, err := url.Parse(value)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("%s is %q, it must be an http or https URL", key, value)
+	}
+	return nil
+}
+
+// Positive checks that the duration setting key is above zero.
+func Positive(key string, value time.Duration) error {
+	if value <= 0 {
+		return fmt.Errorf("%s is %s, it must be positive", key, value)
+	}
+	return nil
+}
+
+// PostgresURL checks that the setting key, when set, is a postgres URL.
+func PostgresURL(key, value string) error {
+	if value == "" {
+		return nil
+	}
+	u, err := url.Parse(value)
+	if err != nil || (u.Scheme != "postgres" && u.Scheme != "postgresql") || u.Host == "" {
+		return fmt.Errorf("%s must be a postgres:// URL", key)
+	}
+	return nil
+}
+
+// placeholders are the parts of values copied from examples and docs, a
+// secret that has one of them is as good as public.
+var placeholders = []string{"change-me", "changeme", "change_me", "replace-me", "example", "placeholder"}
+
+// Secret checks that the secret setting key, when set, has at least min
+// bytes and is not a placeholder.
+func Secret(key, value string, min int) error {
+	if value == "" {
+		return nil
+	}
+	lower := strings.ToLower(value)
+	for _, p := range placeholders {
+		if strings.Contains(lower, p) {
+			return fmt.Errorf("%s is a placeholder, set a secret of your own", key)
+		}
+	}
+	if len(value) < min {
+		return fmt.Errorf("%s must be at least %d bytes", key, min)
+	}
+	return nil
+}